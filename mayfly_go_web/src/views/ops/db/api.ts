@@ -8,13 +8,25 @@ export const dbApi = {
     saveDb: Api.newPost('/dbs'),
     deleteDb: Api.newDelete('/dbs/{id}'),
     dumpDb: Api.newPost('/dbs/{id}/dump'),
+    // 导出查询结果
+    exportQuery: Api.newGet('/dbs/{id}/export-query'),
+    // 表结构比对
+    schemaDiff: Api.newPost('/dbs/schema-diff'),
+    // 下载表结构同步sql
+    schemaDiffScript: Api.newGet('/dbs/schema-diff/script'),
+    // 执行表结构同步sql
+    execSchemaDiff: Api.newPost('/dbs/schema-diff/exec'),
     tableInfos: Api.newGet('/dbs/{id}/t-infos'),
     tableIndex: Api.newGet('/dbs/{id}/t-index'),
     tableDdl: Api.newGet('/dbs/{id}/t-create-ddl'),
     columnMetadata: Api.newGet('/dbs/{id}/c-metadata'),
     pgSchemas: Api.newGet('/dbs/{id}/pg/schemas'),
+    // 根据表结构定义预览建表或修改表sql
+    tableDesignPreview: Api.newPost('/dbs/{id}/table-design/preview'),
     // 获取表即列提示
     hintTables: Api.newGet('/dbs/{id}/hint-tables'),
+    // 获取指定版本之后变更的表即列提示
+    hintTablesChanges: Api.newGet('/dbs/{id}/hint-tables/changes'),
     sqlExec: Api.newPost('/dbs/{id}/exec-sql').withBeforeHandler((param: any) => {
         // sql编码处理
         if (param.sql) {
@@ -26,6 +38,25 @@ export const dbApi = {
         }
         return param;
     }),
+    // 获取sql执行计划
+    explainSql: Api.newPost('/dbs/{id}/explain').withBeforeHandler((param: any) => {
+        if (param.sql) {
+            param.sql = Base64.encode(param.sql);
+        }
+        return param;
+    }),
+    // 根据过滤条件、排序分页查询表数据
+    tableData: Api.newPost('/dbs/{id}/table-data'),
+    // 根据主键保存表数据的新增、修改及删除
+    saveRowChanges: Api.newPost('/dbs/{id}/row-changes'),
+    // 预览导入的数据文件及默认列映射
+    importDataPreview: Api.newPost('/dbs/{id}/import-data/preview'),
+    // 导入csv、xlsx、jsonl数据文件至表
+    importData: Api.newPost('/dbs/{id}/import-data'),
+    // 获取当前账号执行中的sql
+    runningSqls: Api.newGet('/dbs/running-sqls'),
+    // 取消执行中的sql
+    cancelRunningSql: Api.newPost('/dbs/running-sqls/{execId}/cancel'),
     // 保存sql
     saveSql: Api.newPost('/dbs/{id}/sql'),
     // 获取保存的sql
@@ -35,6 +66,12 @@ export const dbApi = {
     deleteDbSql: Api.newDelete('/dbs/{id}/sql'),
     // 获取数据库sql执行记录
     getSqlExecs: Api.newGet('/dbs/{dbId}/sql-execs'),
+    // 审批待执行的sql
+    approveSqlExec: Api.newPost('/dbs/{dbId}/sql-execs/{execId}/approve'),
+    // 获取sql执行记录的回滚sql
+    getSqlExecRollbackSql: Api.newGet('/dbs/{dbId}/sql-execs/{execId}/rollback-sql'),
+    // 回滚sql执行记录
+    rollbackSqlExec: Api.newPost('/dbs/{dbId}/sql-execs/{execId}/rollback'),
 
     instances: Api.newGet('/instances'),
     getInstance: Api.newGet('/instances/{instanceId}'),
@@ -52,8 +89,10 @@ export const dbApi = {
     enableDbBackup: Api.newPut('/dbs/{dbId}/backups/{backupId}/enable'),
     disableDbBackup: Api.newPut('/dbs/{dbId}/backups/{backupId}/disable'),
     startDbBackup: Api.newPut('/dbs/{dbId}/backups/{backupId}/start'),
+    cancelDbBackup: Api.newPut('/dbs/{dbId}/backups/{backupId}/cancel'),
     saveDbBackup: Api.newPut('/dbs/{dbId}/backups/{id}'),
     getDbBackupHistories: Api.newGet('/dbs/{dbId}/backup-histories'),
+    verifyDbBackupHistory: Api.newPost('/dbs/{dbId}/backup-histories/{historyId}/verify'),
 
     // 获取数据库备份列表
     getDbRestores: Api.newGet('/dbs/{dbId}/restores'),
@@ -61,6 +100,7 @@ export const dbApi = {
     getDbNamesWithoutRestore: Api.newGet('/dbs/{dbId}/db-names-without-restore'),
     enableDbRestore: Api.newPut('/dbs/{dbId}/restores/{restoreId}/enable'),
     disableDbRestore: Api.newPut('/dbs/{dbId}/restores/{restoreId}/disable'),
+    cancelDbRestore: Api.newPut('/dbs/{dbId}/restores/{restoreId}/cancel'),
     saveDbRestore: Api.newPut('/dbs/{dbId}/restores/{id}'),
 
     // 数据同步相关
@@ -72,10 +112,35 @@ export const dbApi = {
         }
         return param;
     }),
+    previewDatasyncTask: Api.newPost('/datasync/tasks/preview').withBeforeHandler((param: any) => {
+        // sql编码处理
+        if (param.dataSql) {
+            param.dataSql = Base64.encode(param.dataSql);
+        }
+        return param;
+    }),
     getDatasyncTask: Api.newGet('/datasync/tasks/{taskId}'),
     deleteDatasyncTask: Api.newDelete('/datasync/tasks/{taskId}/del'),
     updateDatasyncTaskStatus: Api.newPost('/datasync/tasks/{taskId}/status'),
     runDatasyncTask: Api.newPost('/datasync/tasks/{taskId}/run'),
     stopDatasyncTask: Api.newPost('/datasync/tasks/{taskId}/stop'),
     datasyncLogs: Api.newGet('/datasync/tasks/{taskId}/logs'),
+
+    // 数据库迁移
+    dbTransferTasks: Api.newGet('/dbtransfer/tasks'),
+    saveDbTransferTask: Api.newPost('/dbtransfer/tasks/save'),
+    deleteDbTransferTask: Api.newDelete('/dbtransfer/tasks/{taskId}/del'),
+    dbTransferCreateTableSql: Api.newGet('/dbtransfer/tasks/{taskId}/create-table-sql'),
+    runDbTransferTask: Api.newPost('/dbtransfer/tasks/{taskId}/run'),
+    stopDbTransferTask: Api.newPost('/dbtransfer/tasks/{taskId}/stop'),
+
+    // 数据脱敏规则
+    dataMaskRules: Api.newGet('/dbdatamask/rules'),
+    saveDataMaskRule: Api.newPost('/dbdatamask/rules'),
+    deleteDataMaskRule: Api.newDelete('/dbdatamask/rules/{ruleId}'),
+
+    // sql审核规则
+    sqlAuditRules: Api.newGet('/dbsqlaudit/rules'),
+    saveSqlAuditRule: Api.newPost('/dbsqlaudit/rules'),
+    deleteSqlAuditRule: Api.newDelete('/dbsqlaudit/rules/{ruleId}'),
 };
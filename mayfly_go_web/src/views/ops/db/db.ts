@@ -1,5 +1,6 @@
 /* eslint-disable no-unused-vars */
 import { dbApi } from './api';
+import { confirmSqlAudit } from './sqlAudit';
 import { getTextWidth } from '@/common/utils/string';
 import SqlExecBox from './component/sqleditor/SqlExecBox';
 import * as monaco from 'monaco-editor/esm/vs/editor/editor.api';
@@ -82,9 +83,9 @@ export class DbInst {
         if (!reload && tables) {
             return tables;
         }
-        // 重置列信息缓存与表提示信息
+        // 重置列信息缓存，表提示信息标记为过期，下次使用时增量获取
         db.columnsMap?.clear();
-        db.tableHints = null;
+        db.hintsStale = true;
         console.log(`load tables -> dbName: ${dbName}`);
         tables = await dbApi.tableInfos.request({ id: this.id, db: dbName });
         db.tables = tables;
@@ -174,13 +175,20 @@ export class DbInst {
      */
     async loadDbHints(dbName: string) {
         const db = this.getDb(dbName);
-        if (db.tableHints) {
+        if (db.tableHints && !db.hintsStale) {
             return db.tableHints;
         }
-        console.log(`load db-hits -> dbName: ${dbName}`);
-        const hits = await dbApi.hintTables.request({ id: this.id, db: db.name });
-        db.tableHints = hits;
-        return hits;
+        console.log(`load db-hits -> dbName: ${dbName}, version: ${db.hintsVersion}`);
+        const changes = await dbApi.hintTablesChanges.request({ id: this.id, db: db.name, version: db.tableHints ? db.hintsVersion : 0 });
+        if (changes.full || !db.tableHints) {
+            db.tableHints = changes.tables;
+        } else {
+            Object.assign(db.tableHints, changes.tables);
+            changes.dropped?.forEach((table: string) => delete db.tableHints[table]);
+        }
+        db.hintsVersion = changes.version;
+        db.hintsStale = false;
+        return db.tableHints;
     }
 
     /**
@@ -190,11 +198,22 @@ export class DbInst {
      * @param remark 执行备注
      */
     async runSql(dbName: string, sql: string, remark: string = '') {
+        const res = await dbApi.sqlExec.request({
+            id: this.id,
+            db: dbName,
+            sql: sql.trim(),
+            remark,
+        });
+        if (!res.auditConfirm) {
+            return res;
+        }
+        await confirmSqlAudit(res.auditConfirm);
         return await dbApi.sqlExec.request({
             id: this.id,
             db: dbName,
             sql: sql.trim(),
             remark,
+            auditConfirmed: true,
         });
     }
 
@@ -203,14 +222,16 @@ export class DbInst {
      *
      * @param sql sql
      * @param remark 执行备注
+     * @param auditConfirmed 是否已确认sql审核提示的风险
      */
-    execSql(dbName: string, sql: string, remark: string = '') {
+    execSql(dbName: string, sql: string, remark: string = '', auditConfirmed: boolean = false) {
         let dbId = this.id;
         return dbApi.sqlExec.useApi({
             id: dbId,
             db: dbName,
             sql: sql.trim(),
             remark,
+            auditConfirmed,
         });
     }
 
@@ -424,6 +445,8 @@ class Db {
     tables: []; // 数据库实例表信息
     columnsMap: Map<string, any> = new Map(); // table -> columns
     tableHints: any = null; // 提示词
+    hintsVersion: number = 0; // 提示词版本，用于增量获取变更的表
+    hintsStale: boolean = false; // 提示词是否过期
 
     /**
      * 获取指定表列信息（前提需要dbInst.loadColumns）
@@ -0,0 +1,56 @@
+import { reactive } from 'vue';
+import { ElNotification } from 'element-plus';
+import syssocket from '@/common/syssocket';
+import { formatByteSize } from '@/common/utils/format';
+
+/**
+ * 正在运行的数据库备份、恢复任务进度，key -> jobType:jobId
+ */
+export const dbJobProgressMap: Map<string, any> = reactive(new Map());
+
+export const getDbJobProgress = (jobType: string, jobId: any) => {
+    return dbJobProgressMap.get(`${jobType}:${jobId}`);
+};
+
+/**
+ * 格式化任务进度，如：备份 120MB 表 3/10 user
+ */
+export const formatDbJobProgress = (progress: any) => {
+    if (!progress) {
+        return '';
+    }
+    let desc = `${progress.stage} ${formatByteSize(progress.bytes)}`;
+    if (progress.tableTotal > 0) {
+        desc += ` 表 ${progress.tables}/${progress.tableTotal}`;
+    } else if (progress.tables > 0) {
+        desc += ` 表 ${progress.tables}`;
+    }
+    if (progress.tableName) {
+        desc += ` ${progress.tableName}`;
+    }
+    if (progress.binlogFileTotal > 0) {
+        desc += ` binlog ${progress.binlogFiles}/${progress.binlogFileTotal}`;
+    }
+    return desc;
+};
+
+/**
+ * 注册数据库备份、恢复任务进度消息处理器
+ */
+export const registerDbJobProgressHandler = () => {
+    syssocket.registerMsgHandler('dbJobProgress', function (message: any) {
+        const content = JSON.parse(message.msg);
+        const key = `${content.jobType}:${content.jobId}`;
+        if (!content.terminated) {
+            dbJobProgressMap.set(key, content);
+            return;
+        }
+
+        dbJobProgressMap.delete(key);
+        ElNotification({
+            title: message.title,
+            message: `[${content.dbName}] ${content.status}`,
+            type: syssocket.getMsgType(message.type),
+        });
+    });
+};
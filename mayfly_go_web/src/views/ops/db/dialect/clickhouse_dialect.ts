@@ -0,0 +1,279 @@
+import {
+    commonCustomKeywords,
+    DataType,
+    DbDialect,
+    DialectInfo,
+    EditorCompletion,
+    EditorCompletionItem,
+    IndexDefinition,
+    RowDefinition,
+    sqlColumnType,
+} from './index';
+import { DbInst } from '@/views/ops/db/db';
+import { language as sqlLanguage } from 'monaco-editor/esm/vs/basic-languages/sql/sql.js';
+
+export { ClickhouseDialect };
+
+// 参考官方文档：https://clickhouse.com/docs/zh/sql-reference/data-types
+const CLICKHOUSE_TYPE_LIST: sqlColumnType[] = [
+    // 整数
+    { udtName: 'Int8', dataType: 'Int8', desc: '微整数', space: '1字节', range: '-128 ~ 127' },
+    { udtName: 'Int16', dataType: 'Int16', desc: '小整数', space: '2字节', range: '-32,768 ~ 32,767' },
+    { udtName: 'Int32', dataType: 'Int32', desc: '整数', space: '4字节', range: '-2,147,483,648 ~ 2,147,483,647' },
+    { udtName: 'Int64', dataType: 'Int64', desc: '大整数', space: '8字节', range: '-2^63 ~ 2^63-1' },
+    { udtName: 'UInt8', dataType: 'UInt8', desc: '无符号微整数', space: '1字节', range: '0 ~ 255' },
+    { udtName: 'UInt16', dataType: 'UInt16', desc: '无符号小整数', space: '2字节', range: '0 ~ 65,535' },
+    { udtName: 'UInt32', dataType: 'UInt32', desc: '无符号整数', space: '4字节', range: '0 ~ 4,294,967,295' },
+    { udtName: 'UInt64', dataType: 'UInt64', desc: '无符号大整数', space: '8字节', range: '0 ~ 2^64-1' },
+    // 小数
+    { udtName: 'Float32', dataType: 'Float32', desc: '单精度浮点数', space: '4字节', range: '' },
+    { udtName: 'Float64', dataType: 'Float64', desc: '双精度浮点数', space: '8字节', range: '' },
+    { udtName: 'Decimal', dataType: 'Decimal', desc: '精确小数，可指定精度及小数位数', space: '4-32字节', range: '' },
+    // 布尔
+    { udtName: 'Bool', dataType: 'Bool', desc: '布尔值', space: '1字节', range: 'true、false' },
+    // 字符串
+    { udtName: 'String', dataType: 'String', desc: '任意长度字符串', space: '', range: '' },
+    { udtName: 'FixedString', dataType: 'FixedString', desc: '定长字符串，需指定字节数', space: '', range: '' },
+    { udtName: 'UUID', dataType: 'UUID', desc: '通用唯一标识符', space: '16字节', range: '' },
+    // 日期和时间
+    { udtName: 'Date', dataType: 'Date', desc: '日期', space: '2字节', range: '1970-01-01 ~ 2149-06-06' },
+    { udtName: 'Date32', dataType: 'Date32', desc: '日期，范围更大', space: '4字节', range: '1900-01-01 ~ 2299-12-31' },
+    { udtName: 'DateTime', dataType: 'DateTime', desc: '日期时间，精确到秒', space: '4字节', range: '1970-01-01 00:00:00 ~ 2106-02-07 06:28:15' },
+    { udtName: 'DateTime64', dataType: 'DateTime64', desc: '日期时间，可指定秒的小数位数', space: '8字节', range: '' },
+    // 其他
+    { udtName: 'JSON', dataType: 'JSON', desc: 'json数据', space: '', range: '' },
+    { udtName: 'IPv4', dataType: 'IPv4', desc: 'IPv4地址', space: '4字节', range: '' },
+    { udtName: 'IPv6', dataType: 'IPv6', desc: 'IPv6地址', space: '16字节', range: '' },
+];
+
+const addCustomKeywords = ['ENGINE', 'MergeTree', 'PARTITION BY', 'ORDER BY', 'SETTINGS', 'FINAL', 'PREWHERE', 'SAMPLE', 'OPTIMIZE TABLE'];
+
+// 参考官方文档：https://clickhouse.com/docs/zh/sql-reference/functions
+const functions: EditorCompletionItem[] = [
+    { label: 'now', insertText: 'now()', description: '返回当前日期时间' },
+    { label: 'today', insertText: 'today()', description: '返回当前日期' },
+    { label: 'toDate', insertText: 'toDate(expr)', description: '转换为日期' },
+    { label: 'toDateTime', insertText: 'toDateTime(expr)', description: '转换为日期时间' },
+    { label: 'toStartOfDay', insertText: 'toStartOfDay(datetime)', description: '返回当天的开始时间' },
+    { label: 'toYYYYMM', insertText: 'toYYYYMM(date)', description: '返回年月数值，常用于分区键' },
+    { label: 'formatDateTime', insertText: "formatDateTime(datetime, '%Y-%m-%d %H:%i:%S')", description: '格式化日期时间' },
+    { label: 'dateDiff', insertText: "dateDiff('unit', startdate, enddate)", description: '返回两个日期的差值' },
+    { label: 'toString', insertText: 'toString(expr)', description: '转换为字符串' },
+    { label: 'toInt64', insertText: 'toInt64(expr)', description: '转换为Int64' },
+    { label: 'ifNull', insertText: 'ifNull(x, alt)', description: '为空时返回替换值' },
+    { label: 'coalesce', insertText: 'coalesce(X,Y,...)', description: '返回第一个不为空的值' },
+    { label: 'if', insertText: 'if(cond, then, else)', description: '条件为真返回第一个值，否则返回第二个值' },
+    { label: 'length', insertText: 'length(x)', description: '返回字符串字节数或数组长度' },
+    { label: 'substring', insertText: 'substring(s, offset, length)', description: '截取字符串' },
+    { label: 'concat', insertText: 'concat(X,Y,...)', description: '拼接字符串' },
+    { label: 'lower', insertText: 'lower(s)', description: '返回小写字符' },
+    { label: 'upper', insertText: 'upper(s)', description: '返回大写字符' },
+    { label: 'count', insertText: 'count()', description: '计数' },
+    { label: 'uniq', insertText: 'uniq(x)', description: '近似去重计数' },
+    { label: 'uniqExact', insertText: 'uniqExact(x)', description: '精确去重计数' },
+    { label: 'groupArray', insertText: 'groupArray(x)', description: '将分组值聚合为数组' },
+    { label: 'arrayJoin', insertText: 'arrayJoin(arr)', description: '将数组展开为多行' },
+    { label: 'quantile', insertText: 'quantile(level)(x)', description: '近似分位数' },
+];
+
+let clickhouseDialectInfo: DialectInfo;
+class ClickhouseDialect implements DbDialect {
+    getInfo(): DialectInfo {
+        if (clickhouseDialectInfo) {
+            return clickhouseDialectInfo;
+        }
+
+        let { keywords, operators, builtinVariables, builtinFunctions } = sqlLanguage;
+        let replaceFunctionNames = functions.map((a) => a.label.toUpperCase());
+        let excludeKeywords = new Set(builtinFunctions.concat(operators));
+
+        let editorCompletions: EditorCompletion = {
+            keywords: keywords
+                .filter((a: string) => !excludeKeywords.has(a) && addCustomKeywords.indexOf(a) === -1)
+                .map((a: string): EditorCompletionItem => ({ label: a, description: 'keyword' }))
+                .concat(commonCustomKeywords.map((a): EditorCompletionItem => ({ label: a, description: 'keyword' })))
+                .concat(addCustomKeywords.map((a): EditorCompletionItem => ({ label: a, description: 'keyword' }))),
+            operators: operators.map((a: string): EditorCompletionItem => ({ label: a, description: 'operator' })),
+            functions: builtinFunctions
+                .filter((a: string) => replaceFunctionNames.indexOf(a) < 0)
+                .map((a: string): EditorCompletionItem => ({ label: a, insertText: `${a}()`, description: 'func' }))
+                .concat(functions),
+            variables: builtinVariables.map((a: string): EditorCompletionItem => ({ label: a, description: 'var' })),
+        };
+
+        clickhouseDialectInfo = {
+            icon: 'DataAnalysis',
+            defaultPort: 9000,
+            formatSqlDialect: 'sql',
+            columnTypes: CLICKHOUSE_TYPE_LIST.sort((a, b) => a.udtName.localeCompare(b.udtName)),
+            editorCompletions,
+        };
+        return clickhouseDialectInfo;
+    }
+
+    getDefaultSelectSql(table: string, condition: string, orderBy: string, pageNum: number, limit: number) {
+        return `SELECT * FROM ${this.quoteIdentifier(table)} ${condition ? 'WHERE ' + condition : ''} ${orderBy ? orderBy : ''} ${this.getPageSql(pageNum, limit)};`;
+    }
+
+    getPageSql(pageNum: number, limit: number) {
+        return ` LIMIT ${limit} OFFSET ${(pageNum - 1) * limit}`;
+    }
+
+    getDefaultRows(): RowDefinition[] {
+        return [
+            { name: 'id', type: 'UInt64', length: '', numScale: '', value: '', notNull: true, pri: true, auto_increment: false, remark: '主键ID' },
+            { name: 'creator_id', type: 'UInt64', length: '', numScale: '', value: '', notNull: true, pri: false, auto_increment: false, remark: '创建人id' },
+            { name: 'creator', type: 'String', length: '', numScale: '', value: '', notNull: true, pri: false, auto_increment: false, remark: '创建人姓名' },
+            {
+                name: 'create_time',
+                type: 'DateTime',
+                length: '',
+                numScale: '',
+                value: 'now()',
+                notNull: true,
+                pri: false,
+                auto_increment: false,
+                remark: '创建时间',
+            },
+            { name: 'updator_id', type: 'UInt64', length: '', numScale: '', value: '', notNull: true, pri: false, auto_increment: false, remark: '修改人id' },
+            { name: 'updator', type: 'String', length: '', numScale: '', value: '', notNull: true, pri: false, auto_increment: false, remark: '修改人姓名' },
+            {
+                name: 'update_time',
+                type: 'DateTime',
+                length: '',
+                numScale: '',
+                value: 'now()',
+                notNull: true,
+                pri: false,
+                auto_increment: false,
+                remark: '修改时间',
+            },
+        ];
+    }
+
+    getDefaultIndex(): IndexDefinition {
+        return {
+            indexName: '',
+            columnNames: [],
+            unique: false,
+            indexType: 'minmax',
+            indexComment: '',
+        };
+    }
+
+    quoteIdentifier = (name: string) => {
+        return `\`${name.replace(/`/g, '``')}\``;
+    };
+
+    getDefaultValueSql(cl: any): string {
+        if (!cl.value) {
+            return '';
+        }
+        // 函数及数值默认值不需要加引号
+        if (/\(.*\)$/.test(cl.value) || DbInst.isNumber(cl.type)) {
+            return ` DEFAULT ${cl.value}`;
+        }
+        return ` DEFAULT '${cl.value}'`;
+    }
+
+    getTypeSql(cl: any) {
+        let type = cl.type;
+        if (cl.length && /^(FixedString|Decimal|DateTime64)$/i.test(cl.type)) {
+            type += cl.numScale && /^Decimal$/i.test(cl.type) ? `(${cl.length}, ${cl.numScale})` : `(${cl.length})`;
+        }
+        // clickhouse的字段默认不可为空，可为空需使用Nullable包裹
+        return cl.notNull ? type : `Nullable(${type})`;
+    }
+
+    genColumnBasicSql(cl: any): string {
+        let comment = cl.remark ? ` COMMENT '${cl.remark}'` : '';
+        return ` ${this.quoteIdentifier(cl.name)} ${this.getTypeSql(cl)}${this.getDefaultValueSql(cl)}${comment} `;
+    }
+
+    getCreateTableSql(data: any): string {
+        let pks = [] as string[];
+        let fields: string[] = [];
+        data.fields.res.forEach((item: any) => {
+            if (!item.name) {
+                return;
+            }
+            fields.push(this.genColumnBasicSql(item));
+            if (item.pri) {
+                pks.push(this.quoteIdentifier(item.name));
+            }
+        });
+        // MergeTree引擎的主键默认与排序键一致
+        let orderBy = pks.length > 0 ? `(${pks.join(', ')})` : 'tuple()';
+        let comment = data.tableComment ? ` COMMENT '${data.tableComment}'` : '';
+        return `CREATE TABLE ${this.quoteIdentifier(data.tableName)} ( ${fields.join(',')} ) ENGINE = MergeTree ORDER BY ${orderBy}${comment};`;
+    }
+
+    getCreateIndexSql(tableData: any): string {
+        let sql: string[] = [];
+        tableData.indexs.res.forEach((a: any) => {
+            sql.push(this.genAddIndexSql(tableData.tableName, a));
+        });
+        return sql.join(';');
+    }
+
+    // clickhouse仅支持跳数索引，索引类型如 minmax、set(100)、bloom_filter
+    genAddIndexSql(tableName: string, index: any): string {
+        let columns = index.columnNames.map((a: string) => this.quoteIdentifier(a));
+        return `ALTER TABLE ${this.quoteIdentifier(tableName)} ADD INDEX ${this.quoteIdentifier(index.indexName)} (${columns.join(', ')}) TYPE ${
+            index.indexType || 'minmax'
+        } GRANULARITY 1`;
+    }
+
+    getModifyColumnSql(tableData: any, tableName: string, changeData: { del: RowDefinition[]; add: RowDefinition[]; upd: RowDefinition[] }): string {
+        let dbTable = this.quoteIdentifier(tableName);
+        let sql = [] as string[];
+
+        changeData.add.forEach((a) => {
+            sql.push(`ALTER TABLE ${dbTable} ADD COLUMN ${this.genColumnBasicSql(a)}`);
+        });
+
+        changeData.upd.forEach((a) => {
+            if (a.oldName && a.oldName !== a.name) {
+                sql.push(`ALTER TABLE ${dbTable} RENAME COLUMN ${this.quoteIdentifier(a.oldName)} TO ${this.quoteIdentifier(a.name)}`);
+            }
+            sql.push(`ALTER TABLE ${dbTable} MODIFY COLUMN ${this.genColumnBasicSql(a)}`);
+        });
+
+        changeData.del.forEach((a) => {
+            sql.push(`ALTER TABLE ${dbTable} DROP COLUMN ${this.quoteIdentifier(a.name)}`);
+        });
+        return sql.map((a) => a + ';').join('');
+    }
+
+    getModifyIndexSql(tableName: string, changeData: { del: any[]; add: any[]; upd: any[] }): string {
+        // 不能直接修改索引名或字段、需要先删后加
+        let sql = [] as string[];
+        changeData.del.concat(changeData.upd).forEach((a) => {
+            sql.push(`ALTER TABLE ${this.quoteIdentifier(tableName)} DROP INDEX ${this.quoteIdentifier(a.indexName)}`);
+        });
+        changeData.add.concat(changeData.upd).forEach((a) => {
+            sql.push(this.genAddIndexSql(tableName, a));
+        });
+        return sql.join(';');
+    }
+
+    getDataType(columnType: string): DataType {
+        if (/\b(u?int|float|decimal)\d*\b/gi.test(columnType)) {
+            return DataType.Number;
+        }
+        // 日期时间类型
+        if (/datetime/gi.test(columnType)) {
+            return DataType.DateTime;
+        }
+        // 日期类型
+        if (/date/gi.test(columnType)) {
+            return DataType.Date;
+        }
+        return DataType.String;
+    }
+
+    // eslint-disable-next-line @typescript-eslint/no-unused-vars,no-unused-vars
+    wrapStrValue(columnType: string, value: string): string {
+        return `'${value}'`;
+    }
+}
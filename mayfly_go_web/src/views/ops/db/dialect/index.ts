@@ -4,6 +4,8 @@ import { DMDialect } from '@/views/ops/db/dialect/dm_dialect';
 import { OracleDialect } from '@/views/ops/db/dialect/oracle_dialect';
 import { MariadbDialect } from '@/views/ops/db/dialect/mariadb_dialect';
 import { SqliteDialect } from '@/views/ops/db/dialect/sqlite_dialect';
+import { MssqlDialect } from '@/views/ops/db/dialect/mssql_dialect';
+import { ClickhouseDialect } from '@/views/ops/db/dialect/clickhouse_dialect';
 
 export interface sqlColumnType {
     udtName: string;
@@ -113,6 +115,8 @@ export const DbType = {
     dm: 'dm', // 达梦
     oracle: 'oracle',
     sqlite: 'sqlite',
+    mssql: 'mssql', // sql server
+    clickhouse: 'clickhouse',
 };
 
 export const compatibleMysql = (dbType: string): boolean => {
@@ -193,6 +197,8 @@ let postgresDialect = new PostgresqlDialect();
 let dmDialect = new DMDialect();
 let oracleDialect = new OracleDialect();
 let sqliteDialect = new SqliteDialect();
+let mssqlDialect = new MssqlDialect();
+let clickhouseDialect = new ClickhouseDialect();
 
 export const getDbDialect = (dbType: string | undefined): DbDialect => {
     if (!dbType) {
@@ -211,6 +217,10 @@ export const getDbDialect = (dbType: string | undefined): DbDialect => {
             return oracleDialect;
         case DbType.sqlite:
             return sqliteDialect;
+        case DbType.mssql:
+            return mssqlDialect;
+        case DbType.clickhouse:
+            return clickhouseDialect;
         default:
             throw new Error('不支持的数据库');
     }
@@ -0,0 +1,336 @@
+import {
+    commonCustomKeywords,
+    DataType,
+    DbDialect,
+    DialectInfo,
+    EditorCompletion,
+    EditorCompletionItem,
+    IndexDefinition,
+    RowDefinition,
+    sqlColumnType,
+} from './index';
+import { DbInst } from '@/views/ops/db/db';
+import { language as sqlLanguage } from 'monaco-editor/esm/vs/basic-languages/sql/sql.js';
+
+export { MssqlDialect };
+
+// 参考官方文档：https://learn.microsoft.com/zh-cn/sql/t-sql/data-types/data-types-transact-sql
+const MSSQL_TYPE_LIST: sqlColumnType[] = [
+    // 精确数值
+    { udtName: 'bit', dataType: 'bit', desc: '0、1或NULL', space: '1字节', range: '0 ~ 1' },
+    { udtName: 'tinyint', dataType: 'tinyint', desc: '微整数', space: '1字节', range: '0 ~ 255' },
+    { udtName: 'smallint', dataType: 'smallint', desc: '小整数', space: '2字节', range: '-32,768 ~ 32,767' },
+    { udtName: 'int', dataType: 'int', desc: '整数', space: '4字节', range: '-2,147,483,648 ~ 2,147,483,647' },
+    { udtName: 'bigint', dataType: 'bigint', desc: '大整数', space: '8字节', range: '-2^63 ~ 2^63-1' },
+    { udtName: 'decimal', dataType: 'decimal', desc: '精确小数，可指定精度及小数位数', space: '5-17字节', range: '' },
+    { udtName: 'numeric', dataType: 'numeric', desc: '同decimal', space: '5-17字节', range: '' },
+    { udtName: 'smallmoney', dataType: 'smallmoney', desc: '货币', space: '4字节', range: '' },
+    { udtName: 'money', dataType: 'money', desc: '货币', space: '8字节', range: '' },
+    // 近似数值
+    { udtName: 'real', dataType: 'real', desc: '单精度浮点数', space: '4字节', range: '' },
+    { udtName: 'float', dataType: 'float', desc: '双精度浮点数', space: '8字节', range: '' },
+    // 日期和时间
+    { udtName: 'date', dataType: 'date', desc: '日期', space: '3字节', range: '0001-01-01 ~ 9999-12-31' },
+    { udtName: 'time', dataType: 'time', desc: '时间，可指定秒的小数位数', space: '3-5字节', range: '' },
+    { udtName: 'datetime', dataType: 'datetime', desc: '日期时间，精确到3.33毫秒', space: '8字节', range: '1753-01-01 ~ 9999-12-31' },
+    { udtName: 'datetime2', dataType: 'datetime2', desc: '日期时间，可指定秒的小数位数', space: '6-8字节', range: '0001-01-01 ~ 9999-12-31' },
+    { udtName: 'smalldatetime', dataType: 'smalldatetime', desc: '日期时间，精确到分钟', space: '4字节', range: '1900-01-01 ~ 2079-06-06' },
+    { udtName: 'datetimeoffset', dataType: 'datetimeoffset', desc: '带时区偏移的日期时间', space: '10字节', range: '' },
+    // 字符串
+    { udtName: 'char', dataType: 'char', desc: '定长字符串', space: '', range: '1 ~ 8000' },
+    { udtName: 'varchar', dataType: 'varchar', desc: '变长字符串，长度可为max', space: '', range: '1 ~ 8000' },
+    { udtName: 'text', dataType: 'text', desc: '变长字符串（已弃用，建议使用varchar(max)）', space: '', range: '' },
+    { udtName: 'nchar', dataType: 'nchar', desc: '定长unicode字符串', space: '', range: '1 ~ 4000' },
+    { udtName: 'nvarchar', dataType: 'nvarchar', desc: '变长unicode字符串，长度可为max', space: '', range: '1 ~ 4000' },
+    { udtName: 'ntext', dataType: 'ntext', desc: '变长unicode字符串（已弃用，建议使用nvarchar(max)）', space: '', range: '' },
+    // 二进制
+    { udtName: 'binary', dataType: 'binary', desc: '定长二进制数据', space: '', range: '1 ~ 8000' },
+    { udtName: 'varbinary', dataType: 'varbinary', desc: '变长二进制数据，长度可为max', space: '', range: '1 ~ 8000' },
+    { udtName: 'image', dataType: 'image', desc: '变长二进制数据（已弃用，建议使用varbinary(max)）', space: '', range: '' },
+    // 其他
+    { udtName: 'uniqueidentifier', dataType: 'uniqueidentifier', desc: 'GUID', space: '16字节', range: '' },
+    { udtName: 'xml', dataType: 'xml', desc: 'xml数据', space: '', range: '' },
+];
+
+const addCustomKeywords = ['TOP', 'OFFSET', 'FETCH', 'NEXT', 'ROWS', 'ONLY', 'IDENTITY', 'NOLOCK'];
+
+// 参考官方文档：https://learn.microsoft.com/zh-cn/sql/t-sql/functions/functions
+const functions: EditorCompletionItem[] = [
+    { label: 'getdate', insertText: 'getdate()', description: '返回当前日期时间' },
+    { label: 'sysdatetime', insertText: 'sysdatetime()', description: '返回当前日期时间，精度更高' },
+    { label: 'dateadd', insertText: 'dateadd(datepart, number, date)', description: '日期加减' },
+    { label: 'datediff', insertText: 'datediff(datepart, startdate, enddate)', description: '返回两个日期的差值' },
+    { label: 'datepart', insertText: 'datepart(datepart, date)', description: '返回日期指定部分的整数' },
+    { label: 'format', insertText: "format(value, 'yyyy-MM-dd')", description: '格式化日期或数值' },
+    { label: 'convert', insertText: 'convert(data_type, expression, style)', description: '类型转换' },
+    { label: 'cast', insertText: 'cast(expression AS data_type)', description: '类型转换' },
+    { label: 'isnull', insertText: 'isnull(check_expression, replacement_value)', description: '为空时返回替换值' },
+    { label: 'coalesce', insertText: 'coalesce(X,Y,...)', description: '返回第一个不为空的值' },
+    { label: 'iif', insertText: 'iif(boolean_expression, true_value, false_value)', description: '条件为真返回第一个值，否则返回第二个值' },
+    { label: 'len', insertText: 'len(string)', description: '返回字符串长度，不含尾随空格' },
+    { label: 'datalength', insertText: 'datalength(expression)', description: '返回字节数' },
+    { label: 'substring', insertText: 'substring(expression, start, length)', description: '截取字符串' },
+    { label: 'charindex', insertText: 'charindex(find, search)', description: '返回字符串出现的位置' },
+    { label: 'replace', insertText: 'replace(string, old, new)', description: '替换字符串' },
+    { label: 'concat', insertText: 'concat(X,Y,...)', description: '拼接字符串' },
+    { label: 'string_agg', insertText: "string_agg(expression, ',')", description: '分组字符串拼接' },
+    { label: 'ltrim', insertText: 'ltrim(string)', description: '左trim' },
+    { label: 'rtrim', insertText: 'rtrim(string)', description: '右trim' },
+    { label: 'upper', insertText: 'upper(string)', description: '返回大写字符' },
+    { label: 'lower', insertText: 'lower(string)', description: '返回小写字符' },
+    { label: 'newid', insertText: 'newid()', description: '生成GUID' },
+    { label: 'row_number', insertText: 'row_number() over (order by column)', description: '返回分区内的行号' },
+    { label: 'object_id', insertText: "object_id('object_name')", description: '返回对象id' },
+];
+
+let mssqlDialectInfo: DialectInfo;
+class MssqlDialect implements DbDialect {
+    getInfo(): DialectInfo {
+        if (mssqlDialectInfo) {
+            return mssqlDialectInfo;
+        }
+
+        let { keywords, operators, builtinVariables, builtinFunctions } = sqlLanguage;
+        let replaceFunctionNames = functions.map((a) => a.label.toUpperCase());
+        let excludeKeywords = new Set(builtinFunctions.concat(operators));
+
+        let editorCompletions: EditorCompletion = {
+            keywords: keywords
+                .filter((a: string) => !excludeKeywords.has(a) && addCustomKeywords.indexOf(a) === -1)
+                .map((a: string): EditorCompletionItem => ({ label: a, description: 'keyword' }))
+                .concat(commonCustomKeywords.map((a): EditorCompletionItem => ({ label: a, description: 'keyword' })))
+                .concat(addCustomKeywords.map((a): EditorCompletionItem => ({ label: a, description: 'keyword' }))),
+            operators: operators.map((a: string): EditorCompletionItem => ({ label: a, description: 'operator' })),
+            functions: builtinFunctions
+                .filter((a: string) => replaceFunctionNames.indexOf(a) < 0)
+                .map((a: string): EditorCompletionItem => ({ label: a, insertText: `${a}()`, description: 'func' }))
+                .concat(functions),
+            variables: builtinVariables.map((a: string): EditorCompletionItem => ({ label: a, description: 'var' })),
+        };
+
+        mssqlDialectInfo = {
+            icon: 'Coin',
+            defaultPort: 1433,
+            formatSqlDialect: 'transactsql',
+            columnTypes: MSSQL_TYPE_LIST.sort((a, b) => a.udtName.localeCompare(b.udtName)),
+            editorCompletions,
+        };
+        return mssqlDialectInfo;
+    }
+
+    getDefaultSelectSql(table: string, condition: string, orderBy: string, pageNum: number, limit: number) {
+        // OFFSET FETCH 分页必须指定排序
+        return `SELECT * FROM ${this.quoteIdentifier(table)} ${condition ? 'WHERE ' + condition : ''} ${orderBy ? orderBy : 'ORDER BY (SELECT NULL)'} ${this.getPageSql(
+            pageNum,
+            limit
+        )};`;
+    }
+
+    getPageSql(pageNum: number, limit: number) {
+        return ` OFFSET ${(pageNum - 1) * limit} ROWS FETCH NEXT ${limit} ROWS ONLY`;
+    }
+
+    getDefaultRows(): RowDefinition[] {
+        return [
+            { name: 'id', type: 'bigint', length: '', numScale: '', value: '', notNull: true, pri: true, auto_increment: true, remark: '主键ID' },
+            { name: 'creator_id', type: 'bigint', length: '', numScale: '', value: '', notNull: true, pri: false, auto_increment: false, remark: '创建人id' },
+            {
+                name: 'creator',
+                type: 'nvarchar',
+                length: '100',
+                numScale: '',
+                value: '',
+                notNull: true,
+                pri: false,
+                auto_increment: false,
+                remark: '创建人姓名',
+            },
+            {
+                name: 'create_time',
+                type: 'datetime2',
+                length: '',
+                numScale: '',
+                value: 'getdate()',
+                notNull: true,
+                pri: false,
+                auto_increment: false,
+                remark: '创建时间',
+            },
+            { name: 'updator_id', type: 'bigint', length: '', numScale: '', value: '', notNull: true, pri: false, auto_increment: false, remark: '修改人id' },
+            {
+                name: 'updator',
+                type: 'nvarchar',
+                length: '100',
+                numScale: '',
+                value: '',
+                notNull: true,
+                pri: false,
+                auto_increment: false,
+                remark: '修改人姓名',
+            },
+            {
+                name: 'update_time',
+                type: 'datetime2',
+                length: '',
+                numScale: '',
+                value: 'getdate()',
+                notNull: true,
+                pri: false,
+                auto_increment: false,
+                remark: '修改时间',
+            },
+        ];
+    }
+
+    getDefaultIndex(): IndexDefinition {
+        return {
+            indexName: '',
+            columnNames: [],
+            unique: false,
+            indexType: 'NONCLUSTERED',
+            indexComment: '',
+        };
+    }
+
+    quoteIdentifier = (name: string) => {
+        return `[${name.replace(/]/g, ']]')}]`;
+    };
+
+    getDefaultValueSql(cl: any): string {
+        if (!cl.value) {
+            return '';
+        }
+        // 函数及数值默认值不需要加引号
+        if (/\(.*\)$/.test(cl.value) || DbInst.isNumber(cl.type)) {
+            return ` DEFAULT ${cl.value}`;
+        }
+        return ` DEFAULT N'${cl.value}'`;
+    }
+
+    getTypeLengthSql(cl: any) {
+        if (!cl.length || !/char|binary|decimal|numeric|time|datetime2|datetimeoffset/i.test(cl.type)) {
+            return '';
+        }
+        if (cl.numScale && /decimal|numeric/i.test(cl.type)) {
+            return `(${cl.length}, ${cl.numScale})`;
+        }
+        return `(${cl.length})`;
+    }
+
+    genColumnBasicSql(cl: any): string {
+        let identity = cl.auto_increment ? ' IDENTITY(1,1)' : '';
+        return ` ${this.quoteIdentifier(cl.name)} ${cl.type}${this.getTypeLengthSql(cl)}${identity} ${cl.notNull ? 'NOT NULL' : 'NULL'}${this.getDefaultValueSql(cl)} `;
+    }
+
+    // 表及字段注释使用扩展属性 MS_Description 保存
+    genCommentSql(tableName: string, comment: string, columnName?: string): string {
+        let columnArgs = columnName ? `, 'COLUMN', N'${columnName}'` : '';
+        let minorId = columnName ? `COLUMNPROPERTY(OBJECT_ID(N'${tableName}'), N'${columnName}', 'ColumnId')` : '0';
+        let args = `'MS_Description', N'${comment}', 'SCHEMA', 'dbo', 'TABLE', N'${tableName}'${columnArgs}`;
+        return `IF EXISTS (SELECT 1 FROM sys.extended_properties WHERE major_id = OBJECT_ID(N'${tableName}') AND minor_id = ${minorId} AND name = 'MS_Description') EXEC sp_updateextendedproperty ${args} ELSE EXEC sp_addextendedproperty ${args}`;
+    }
+
+    getCreateTableSql(data: any): string {
+        let pks = [] as string[];
+        let fields: string[] = [];
+        let commentSql: string[] = [];
+        data.fields.res.forEach((item: any) => {
+            if (!item.name) {
+                return;
+            }
+            fields.push(this.genColumnBasicSql(item));
+            if (item.pri) {
+                pks.push(this.quoteIdentifier(item.name));
+            }
+            if (item.remark) {
+                commentSql.push(this.genCommentSql(data.tableName, item.remark, item.name));
+            }
+        });
+        if (pks.length > 0) {
+            fields.push(` PRIMARY KEY (${pks.join(', ')})`);
+        }
+        if (data.tableComment) {
+            commentSql.unshift(this.genCommentSql(data.tableName, data.tableComment));
+        }
+        let createSql = `CREATE TABLE ${this.quoteIdentifier(data.tableName)} ( ${fields.join(',')} );`;
+        return createSql + commentSql.map((a) => a + ';').join('');
+    }
+
+    getCreateIndexSql(tableData: any): string {
+        let sql: string[] = [];
+        tableData.indexs.res.forEach((a: any) => {
+            sql.push(this.genCreateIndexSql(tableData.tableName, a));
+        });
+        return sql.join(';');
+    }
+
+    genCreateIndexSql(tableName: string, index: any): string {
+        let columns = index.columnNames.map((a: string) => this.quoteIdentifier(a));
+        return `CREATE ${index.unique ? 'UNIQUE ' : ''}INDEX ${this.quoteIdentifier(index.indexName)} ON ${this.quoteIdentifier(tableName)} (${columns.join(', ')})`;
+    }
+
+    getModifyColumnSql(tableData: any, tableName: string, changeData: { del: RowDefinition[]; add: RowDefinition[]; upd: RowDefinition[] }): string {
+        let dbTable = this.quoteIdentifier(tableName);
+        let sql = [] as string[];
+
+        changeData.add.forEach((a) => {
+            sql.push(`ALTER TABLE ${dbTable} ADD ${this.genColumnBasicSql(a)}`);
+            if (a.remark) {
+                sql.push(this.genCommentSql(tableName, a.remark, a.name));
+            }
+        });
+
+        changeData.upd.forEach((a) => {
+            // 修改了字段名，sql server需使用sp_rename重命名
+            if (a.oldName && a.oldName !== a.name) {
+                sql.push(`EXEC sp_rename N'${tableName}.${a.oldName}', N'${a.name}', 'COLUMN'`);
+            }
+            // 默认值为约束，修改类型时不做处理
+            sql.push(`ALTER TABLE ${dbTable} ALTER COLUMN ${this.quoteIdentifier(a.name)} ${a.type}${this.getTypeLengthSql(a)} ${a.notNull ? 'NOT NULL' : 'NULL'}`);
+            if (a.remark) {
+                sql.push(this.genCommentSql(tableName, a.remark, a.name));
+            }
+        });
+
+        changeData.del.forEach((a) => {
+            sql.push(`ALTER TABLE ${dbTable} DROP COLUMN ${this.quoteIdentifier(a.name)}`);
+        });
+        return sql.map((a) => a + ';').join('');
+    }
+
+    getModifyIndexSql(tableName: string, changeData: { del: any[]; add: any[]; upd: any[] }): string {
+        // 不能直接修改索引名或字段、需要先删后加
+        let sql = [] as string[];
+        changeData.del.concat(changeData.upd).forEach((a) => {
+            sql.push(`DROP INDEX ${this.quoteIdentifier(a.indexName)} ON ${this.quoteIdentifier(tableName)}`);
+        });
+        changeData.add.concat(changeData.upd).forEach((a) => {
+            sql.push(this.genCreateIndexSql(tableName, a));
+        });
+        return sql.join(';');
+    }
+
+    getDataType(columnType: string): DataType {
+        if (DbInst.isNumber(columnType) || /money|real|bit/gi.test(columnType)) {
+            return DataType.Number;
+        }
+        // 日期时间类型
+        if (/datetime/gi.test(columnType)) {
+            return DataType.DateTime;
+        }
+        // 日期类型
+        if (/date/gi.test(columnType)) {
+            return DataType.Date;
+        }
+        // 时间类型，timestamp为行版本号，不属于时间类型
+        if (/^time/gi.test(columnType) && !/^timestamp$/gi.test(columnType)) {
+            return DataType.Time;
+        }
+        return DataType.String;
+    }
+
+    // eslint-disable-next-line @typescript-eslint/no-unused-vars,no-unused-vars
+    wrapStrValue(columnType: string, value: string): string {
+        return `N'${value}'`;
+    }
+}
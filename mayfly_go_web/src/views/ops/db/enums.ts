@@ -9,6 +9,17 @@ export const DbSqlExecTypeEnum = {
     Other: EnumValue.of(-1, 'OTHER').setTagColor('#F9E2AE'),
 };
 
+// 数据库sql执行状态
+export const DbSqlExecStatusEnum = {
+    Wait: EnumValue.of(1, '待审批').setTagType('primary'),
+    Success: EnumValue.of(2, '成功').setTagType('success'),
+    RolledBack: EnumValue.of(3, '已回滚').setTagType('info'),
+    Approving: EnumValue.of(4, '审批执行中').setTagType('primary'),
+    Fail: EnumValue.of(-1, '失败').setTagType('danger'),
+    Reject: EnumValue.of(-2, '审批拒绝').setTagType('warning'),
+    Expired: EnumValue.of(-3, '审批过期').setTagType('info'),
+};
+
 export const DbDataSyncRecentStateEnum = {
     Success: EnumValue.of(1, '成功').setTagType('success'),
     Fail: EnumValue.of(-1, '失败').setTagType('danger'),
@@ -20,8 +31,64 @@ export const DbDataSyncLogStatusEnum = {
     Fail: EnumValue.of(-1, '失败').setTagType('danger'),
 };
 
+// 数据库备份校验状态
+export const DbBackupVerifyStatusEnum = {
+    None: EnumValue.of(0, '未校验').setTagType('info'),
+    Running: EnumValue.of(1, '校验中').setTagType('primary'),
+    Success: EnumValue.of(2, '成功').setTagType('success'),
+    Fail: EnumValue.of(-1, '失败').setTagType('danger'),
+};
+
 export const DbDataSyncRunningStateEnum = {
     Success: EnumValue.of(1, '运行中').setTagType('success'),
     Wait: EnumValue.of(2, '待运行').setTagType('primary'),
     Fail: EnumValue.of(3, '已停止').setTagType('danger'),
 };
+
+export const DbDataSyncDuplicateStrategyEnum = {
+    None: EnumValue.of(-1, '报错'),
+    Ignore: EnumValue.of(1, '忽略'),
+    Update: EnumValue.of(2, '更新'),
+};
+
+export const DbDataSyncTransformEnum = {
+    Constant: EnumValue.of('constant', '常量'),
+    Default: EnumValue.of('default', '空值默认值'),
+    Mask: EnumValue.of('mask', '脱敏'),
+    Hash: EnumValue.of('hash', 'hash'),
+    DateFormat: EnumValue.of('dateFormat', '日期格式'),
+    Expr: EnumValue.of('expr', '表达式'),
+};
+
+export const DbDataMaskTypeEnum = {
+    Phone: EnumValue.of('phone', '手机号'),
+    IdCard: EnumValue.of('idCard', '身份证号'),
+    Email: EnumValue.of('email', '邮箱'),
+    BankCard: EnumValue.of('bankCard', '银行卡号'),
+    Name: EnumValue.of('name', '姓名'),
+    Full: EnumValue.of('full', '完全隐藏'),
+};
+
+// sql审核规则类型
+export const DbSqlAuditTypeEnum = {
+    NoWhere: EnumValue.of('noWhere', 'update/delete未带where'),
+    SelectStar: EnumValue.of('selectStar', '大表select *'),
+    DropTruncate: EnumValue.of('dropTruncate', 'drop/truncate'),
+    NoLimit: EnumValue.of('noLimit', '查询未带limit'),
+    FullScan: EnumValue.of('fullScan', '全表扫描'),
+    DdlLargeTable: EnumValue.of('ddlLargeTable', '大表ddl'),
+};
+
+// sql审核风险等级
+export const DbSqlAuditLevelEnum = {
+    Warn: EnumValue.of(1, '警告').setTagType('warning'),
+    Confirm: EnumValue.of(2, '需确认').setTagType('warning'),
+    Block: EnumValue.of(3, '禁止执行').setTagType('danger'),
+};
+
+// 数据库备份存储类型
+export const DbBackupStorageTypeEnum = {
+    Local: EnumValue.of('local', '本地磁盘'),
+    Sftp: EnumValue.of('sftp', 'SFTP'),
+    S3: EnumValue.of('s3', 'S3对象存储'),
+};
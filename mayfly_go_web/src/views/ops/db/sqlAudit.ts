@@ -0,0 +1,15 @@
+import { h } from 'vue';
+import { ElMessageBox } from 'element-plus';
+
+/**
+ * sql审核存在需确认的风险时提示用户确认，取消则抛出异常
+ *
+ * @param auditConfirm 需确认的风险信息
+ */
+export const confirmSqlAudit = async (auditConfirm: string[]) => {
+    await ElMessageBox.confirm(h('div', auditConfirm.map((msg) => h('p', msg))), 'SQL风险提示, 是否继续执行?', {
+        confirmButtonText: '继续执行',
+        cancelButtonText: '取消',
+        type: 'warning',
+    });
+};
@@ -5,6 +5,7 @@ go 1.21
 require (
 	gitee.com/chunanyong/dm v1.8.13
 	gitee.com/liuzongyang/libpq v1.0.9
+	github.com/ClickHouse/clickhouse-go/v2 v2.23.2
 	github.com/buger/jsonparser v1.1.1
 	github.com/emirpasic/gods v1.18.1
 	github.com/gin-gonic/gin v1.9.1
@@ -20,6 +21,7 @@ require (
 	github.com/gorilla/websocket v1.5.1
 	github.com/kanzihuang/vitess/go/vt/sqlparser v0.0.0-20231018071450-ac8d9f0167e9
 	github.com/lionsoul2014/ip2region/binding/golang v0.0.0-20230712084735-068dc2aee82d
+	github.com/microsoft/go-mssqldb v1.7.2
 	github.com/mojocn/base64Captcha v1.3.6 // 验证码
 	github.com/pkg/errors v0.9.1
 	github.com/pkg/sftp v1.13.6
@@ -31,6 +33,7 @@ require (
 	go.mongodb.org/mongo-driver v1.13.1 // mongo
 	golang.org/x/crypto v0.18.0 // ssh
 	golang.org/x/oauth2 v0.15.0
+	golang.org/x/sync v0.1.0
 	gopkg.in/yaml.v3 v3.0.1
 	// gorm
 	gorm.io/driver/mysql v1.5.2
@@ -83,7 +86,6 @@ require (
 	golang.org/x/exp v0.0.0-20230519143937-03e91628a987
 	golang.org/x/image v0.13.0 // indirect
 	golang.org/x/net v0.19.0 // indirect
-	golang.org/x/sync v0.1.0 // indirect
 	golang.org/x/sys v0.16.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
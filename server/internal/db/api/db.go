@@ -9,6 +9,7 @@ import (
 	"mayfly-go/internal/db/api/form"
 	"mayfly-go/internal/db/api/vo"
 	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/config"
 	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/domain/entity"
 	msgapp "mayfly-go/internal/msg/application"
@@ -32,11 +33,22 @@ import (
 )
 
 type Db struct {
-	InstanceApp  application.Instance  `inject:"DbInstanceApp"`
-	DbApp        application.Db        `inject:""`
-	DbSqlExecApp application.DbSqlExec `inject:""`
-	MsgApp       msgapp.Msg            `inject:""`
-	TagApp       tagapp.TagTree        `inject:"TagTreeApp"`
+	InstanceApp     application.Instance     `inject:"DbInstanceApp"`
+	DbApp           application.Db           `inject:""`
+	DbSqlExecApp    application.DbSqlExec    `inject:""`
+	DataMaskRuleApp application.DataMaskRule `inject:"DbDataMaskRuleApp"`
+	SqlAuditRuleApp application.SqlAuditRule `inject:"DbSqlAuditRuleApp"`
+	DbMetaCacheApp  application.DbMetaCache  `inject:""`
+	MsgApp          msgapp.Msg               `inject:""`
+	TagApp          tagapp.TagTree           `inject:"TagTreeApp"`
+}
+
+// 查看未脱敏的敏感数据原始值权限码
+const dbDataRawPermCode = "db:data:raw"
+
+// 判断当前账号是否拥有查看敏感数据原始值的权限
+func canShowRawData(rc *req.Ctx) bool {
+	return req.GetPermissionCodeRegistery().HasCode(rc.GetLoginAccount().Id, dbDataRawPermCode)
 }
 
 // @router /api/dbs [get]
@@ -104,19 +116,30 @@ func (d *Db) ExecSql(rc *req.Ctx) {
 	biz.NotEmpty(form.Sql, "sql不能为空")
 
 	execReq := &application.DbSqlExecReq{
-		DbId:   dbId,
-		Db:     form.Db,
-		Remark: form.Remark,
-		DbConn: dbConn,
+		DbId:           dbId,
+		Db:             form.Db,
+		Remark:         form.Remark,
+		DbConn:         dbConn,
+		ShowRawData:    canShowRawData(rc),
+		AuditConfirmed: form.AuditConfirmed,
 	}
 
-	// 比前端超时时间稍微快一点，可以提示到前端
-	ctx, cancel := context.WithTimeout(rc.MetaCtx, 58*time.Second)
+	ctx, cancel := context.WithTimeout(rc.MetaCtx, config.GetDbExecTimeout())
 	defer cancel()
+	// 注册为执行中的sql，以便用户可取消执行
+	ctx, release, err := d.DbSqlExecApp.RegisterRunningSql(ctx, form.ExecId, dbConn, sql)
+	biz.ErrIsNil(err)
+	defer release()
 
-	sqls, err := sqlparser.SplitStatementToPieces(sql, sqlparser.WithDialect(dbConn.Info.Type.Dialect()))
+	sqls, err := dbConn.Info.Type.SplitSql(sql)
 	biz.ErrIsNil(err, "SQL解析错误,请检查您的执行SQL")
 	isMulti := len(sqls) > 1
+
+	// 执行前先审核所有sql，存在需确认的风险则返回风险信息，由用户确认后再次提交执行
+	if confirmMsgs := d.auditSqls(ctx, execReq, sqls); len(confirmMsgs) > 0 {
+		rc.ResData = collx.M{"auditConfirm": confirmMsgs}
+		return
+	}
 	var execResAll *application.DbSqlExecRes
 
 	for _, s := range sqls {
@@ -140,9 +163,135 @@ func (d *Db) ExecSql(rc *req.Ctx) {
 	colAndRes := make(map[string]any)
 	colAndRes["columns"] = execResAll.Columns
 	colAndRes["res"] = execResAll.Res
+	colAndRes["warnings"] = execResAll.Warnings
 	rc.ResData = colAndRes
 }
 
+// 审核待执行的sql，存在禁止执行的风险则直接返回错误，未确认时返回需确认的风险信息。
+// 审核器保存至执行请求中，执行时复用审核结果而不再重复审核
+func (d *Db) auditSqls(ctx context.Context, execReq *application.DbSqlExecReq, sqls []string) []string {
+	auditor, err := d.SqlAuditRuleApp.NewAuditor(execReq.DbConn)
+	biz.ErrIsNil(err)
+	execReq.Auditor = auditor
+
+	confirmMsgs := make([]string, 0)
+	for _, s := range sqls {
+		s = stringx.TrimSpaceAndBr(s)
+		stmt, _ := sqlparser.Parse(s)
+		auditRes := auditor.Audit(ctx, s, stmt)
+		if auditRes.NeedConfirm() {
+			if !execReq.AuditConfirmed {
+				confirmMsgs = append(confirmMsgs, fmt.Sprintf("[%s] -> %s", s, strings.Join(auditRes.Messages, "; ")))
+			}
+			continue
+		}
+		biz.ErrIsNil(auditRes.Check(s, false))
+	}
+	return confirmMsgs
+}
+
+// 获取sql的执行计划
+func (d *Db) ExplainSql(rc *req.Ctx) {
+	g := rc.GinCtx
+	form := &form.DbSqlExplainForm{}
+	ginx.BindJsonAndValid(g, form)
+
+	dbConn, err := d.DbApp.GetDbConn(getDbId(g), form.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.TagPath...), "%s")
+
+	sqlBytes, err := base64.StdEncoding.DecodeString(form.Sql)
+	biz.ErrIsNilAppendErr(err, "sql解码失败: %s")
+	sql := stringx.TrimSpaceAndBr(string(sqlBytes))
+	biz.NotEmpty(sql, "sql不能为空")
+	rc.ReqParam = fmt.Sprintf("%s\n-> %s", dbConn.Info.GetLogDesc(), sql)
+
+	ctx, cancel := context.WithTimeout(rc.MetaCtx, config.GetDbExecTimeout())
+	defer cancel()
+	plan, err := dbConn.GetDialect().Explain(ctx, sql)
+	biz.ErrIsNilAppendErr(err, "获取执行计划失败: %s")
+	rc.ResData = plan
+}
+
+// 分页查询表数据，无需编写分页sql
+func (d *Db) GetTableData(rc *req.Ctx) {
+	g := rc.GinCtx
+	form := &form.DbTableDataForm{}
+	ginx.BindJsonAndValid(g, form)
+
+	dbConn, err := d.DbApp.GetDbConn(getDbId(g), form.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.TagPath...), "%s")
+	rc.ReqParam = fmt.Sprintf("%s -> table: %s", dbConn.Info.GetLogDesc(), form.Table)
+
+	// 每页条数不能超过系统配置的最大查询条数
+	if maxCount := config.GetDbQueryMaxCount(); maxCount > 0 && form.PageSize > maxCount {
+		form.PageSize = maxCount
+	}
+	query := &dbi.TableDataQuery{
+		Table:    form.Table,
+		Conds:    form.Conds,
+		Orders:   form.Orders,
+		PageNum:  form.PageNum,
+		PageSize: form.PageSize,
+		Count:    form.Count,
+		Keyset:   form.Keyset,
+		AfterKey: form.AfterKey,
+	}
+
+	ctx, cancel := context.WithTimeout(rc.MetaCtx, config.GetDbExecTimeout())
+	defer cancel()
+	res, err := d.DbSqlExecApp.GetTableData(ctx, dbConn, query, canShowRawData(rc))
+	biz.ErrIsNilAppendErr(err, "查询表数据失败: %s")
+	rc.ResData = res
+}
+
+// 根据主键保存表数据的新增、修改及删除
+func (d *Db) SaveRowChanges(rc *req.Ctx) {
+	g := rc.GinCtx
+	form := &form.DbRowChangeForm{}
+	ginx.BindJsonAndValid(g, form)
+
+	dbId := getDbId(g)
+	dbConn, err := d.DbApp.GetDbConn(dbId, form.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.TagPath...), "%s")
+	rc.ReqParam = fmt.Sprintf("%s -> table: %s, inserts: %d, updates: %d, deletes: %d", dbConn.Info.GetLogDesc(), form.Table, len(form.Inserts), len(form.Updates), len(form.Deletes))
+
+	updates := make([]*application.DbRowUpdate, 0, len(form.Updates))
+	for _, update := range form.Updates {
+		updates = append(updates, &application.DbRowUpdate{PrimaryKey: update.PrimaryKey, Values: update.Values})
+	}
+	execRes, err := d.DbSqlExecApp.SaveRowChanges(rc.MetaCtx, &application.DbRowChangeReq{
+		DbId:    dbId,
+		Db:      form.Db,
+		Table:   form.Table,
+		Remark:  form.Remark,
+		DbConn:  dbConn,
+		Inserts: form.Inserts,
+		Updates: updates,
+		Deletes: form.Deletes,
+	})
+	biz.ErrIsNil(err)
+
+	colAndRes := make(map[string]any)
+	colAndRes["columns"] = execRes.Columns
+	colAndRes["res"] = execRes.Res
+	rc.ResData = colAndRes
+}
+
+// 获取当前账号执行中的sql列表
+func (d *Db) RunningSqls(rc *req.Ctx) {
+	rc.ResData = d.DbSqlExecApp.GetRunningSqls(rc.GetLoginAccount().Id)
+}
+
+// 取消执行中的sql
+func (d *Db) CancelRunningSql(rc *req.Ctx) {
+	execId := ginx.PathParam(rc.GinCtx, "execId")
+	rc.ReqParam = execId
+	biz.ErrIsNil(d.DbSqlExecApp.CancelRunningSql(rc.MetaCtx, execId))
+}
+
 // progressCategory sql文件执行进度消息类型
 const progressCategory = "execSqlFileProgress"
 
@@ -170,6 +319,8 @@ func (d *Db) ExecSqlFile(rc *req.Ctx) {
 	dbConn, err := d.DbApp.GetDbConn(dbId, dbName)
 	biz.ErrIsNil(err)
 	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.TagPath...), "%s")
+	// sql文件中的语句无法逐条提交审批，需审批的库不支持直接执行sql文件
+	checkSqlFileApproval(dbConn)
 	rc.ReqParam = fmt.Sprintf("filename: %s -> %s", filename, dbConn.Info.GetLogDesc())
 
 	defer func() {
@@ -182,11 +333,13 @@ func (d *Db) ExecSqlFile(rc *req.Ctx) {
 		}
 	}()
 
+	// sql文件无法逐条确认，需在上传时确认执行存在风险的语句，未确认则在该语句处中断执行
 	execReq := &application.DbSqlExecReq{
-		DbId:   dbId,
-		Db:     dbName,
-		Remark: filename,
-		DbConn: dbConn,
+		DbId:           dbId,
+		Db:             dbName,
+		Remark:         filename,
+		DbConn:         dbConn,
+		AuditConfirmed: g.Query("auditConfirmed") == "true",
 	}
 
 	var sql string
@@ -238,7 +391,9 @@ func (d *Db) ExecSqlFile(rc *req.Ctx) {
 			dbConn, err = d.DbApp.GetDbConn(dbId, stmtUse.DBName.String())
 			biz.ErrIsNil(err)
 			biz.ErrIsNilAppendErr(d.TagApp.CanAccess(laId, dbConn.Info.TagPath...), "%s")
+			checkSqlFileApproval(dbConn)
 			execReq.DbConn = dbConn
+			execReq.Auditor = nil
 		}
 		// 需要记录执行记录
 		const maxRecordStatements = 64
@@ -246,6 +401,13 @@ func (d *Db) ExecSqlFile(rc *req.Ctx) {
 			execReq.Sql = sql
 			_, err = d.DbSqlExecApp.Exec(rc.MetaCtx, execReq)
 		} else {
+			// 不记录执行记录的语句同样需要审核
+			if execReq.Auditor == nil {
+				execReq.Auditor, err = d.SqlAuditRuleApp.NewAuditor(dbConn)
+				biz.ErrIsNil(err)
+			}
+			stmt, _ := sqlparser.Parse(sql)
+			biz.ErrIsNil(execReq.Auditor.Audit(rc.MetaCtx, sql, stmt).Check(sql, execReq.AuditConfirmed))
 			_, err = dbConn.Exec(sql)
 		}
 
@@ -254,6 +416,10 @@ func (d *Db) ExecSqlFile(rc *req.Ctx) {
 	d.MsgApp.CreateAndSend(rc.GetLoginAccount(), msgdto.SuccessSysMsg("sql脚本执行成功", fmt.Sprintf("sql脚本执行完成：%s", rc.ReqParam)).WithClientId(clientId))
 }
 
+func checkSqlFileApproval(dbConn *dbi.DbConn) {
+	biz.IsTrue(!config.GetDbSqlApproval().NeedApproval(dbConn.Info.TagPath...), "库[%s]需审批后执行sql, 不支持直接执行sql文件", dbConn.Info.GetLogDesc())
+}
+
 // 数据库dump
 func (d *Db) DumpSql(rc *req.Ctx) {
 	g := rc.GinCtx
@@ -306,14 +472,107 @@ func (d *Db) DumpSql(rc *req.Ctx) {
 		writer.Close()
 	}()
 
+	showRawData := canShowRawData(rc)
 	for _, dbName := range dbNames {
-		d.dumpDb(writer, dbId, dbName, tables, needStruct, needData)
+		d.dumpDb(rc.MetaCtx, writer, dbId, dbName, tables, needStruct, needData, showRawData)
 	}
 
 	rc.ReqParam = collx.Kvs("db", db, "databases", dbNamesStr, "tables", tablesStr, "dumpType", dumpType)
 }
 
-func (d *Db) dumpDb(writer *gzipWriter, dbId uint64, dbName string, tables []string, needStruct bool, needData bool) {
+// 流式导出任意查询语句的结果集，不受系统最大查询结果集数限制
+func (d *Db) ExportQuery(rc *req.Ctx) {
+	g := rc.GinCtx
+	dbId := getDbId(g)
+	dbName := getDbName(g)
+	format := g.Query("format")
+	extName := g.Query("extName")
+	switch extName {
+	case ".gz", ".gzip", "gz", "gzip":
+		extName = ".gz"
+	default:
+		extName = ""
+	}
+
+	sqlBytes, err := base64.StdEncoding.DecodeString(g.Query("sql"))
+	biz.ErrIsNilAppendErr(err, "sql解码失败: %s")
+	sql := stringx.TrimSpaceAndBr(string(sqlBytes))
+	biz.NotEmpty(sql, "sql不能为空")
+
+	la := rc.GetLoginAccount()
+	dbConn, err := d.DbApp.GetDbConn(dbId, dbName)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(la.Id, dbConn.Info.TagPath...), "%s")
+
+	tableName := g.Query("tableName")
+	// 仅允许单条查询语句，防止通过导出执行多条语句或数据修改语句而绕过审批及审核
+	stmt, err := application.ParseSingleSelect(dbConn.Info.Type, sql)
+	biz.ErrIsNil(err)
+	// 未指定表名，则使用查询语句的第一个表名作为insert语句表名
+	if selectStmt, ok := stmt.(*sqlparser.Select); ok && tableName == "" && len(selectStmt.From) > 0 {
+		tableName = dbConn.Info.Type.RemoveQuote(strings.Split(sqlparser.String(selectStmt.From[0]), " ")[0])
+	}
+
+	rc.ReqParam = fmt.Sprintf("%s format: %s\n-> %s", dbConn.Info.GetLogDesc(), format, sql)
+
+	writer := newGzipWriter(g.Writer)
+	exportWriter, err := newExportWriter(format, writer, dbConn.Info.Type, tableName)
+	biz.ErrIsNil(err)
+
+	// 注册为执行中的sql，以便用户可取消导出
+	ctx, release, err := d.DbSqlExecApp.RegisterRunningSql(rc.MetaCtx, g.Query("execId"), dbConn, sql)
+	biz.ErrIsNil(err)
+	defer release()
+
+	filename := fmt.Sprintf("%s.%s.%s%s", dbName, time.Now().Format("20060102150405"), format, extName)
+	g.Header("Content-Type", "application/octet-stream")
+	g.Header("Content-Disposition", "attachment; filename="+filename)
+	if extName != ".gz" {
+		g.Header("Content-Encoding", "gzip")
+	}
+
+	defer func() {
+		msg := anyx.ToString(recover())
+		if len(msg) > 0 {
+			msg = "查询结果导出失败: " + msg
+			writer.WriteString(msg)
+			d.MsgApp.CreateAndSend(la, msgdto.ErrSysMsg("查询结果导出失败", msg))
+		}
+		writer.Close()
+	}()
+
+	// 任意查询语句可能关联多表，故忽略规则的表名匹配，只要字段名匹配即脱敏
+	masker, err := d.DataMaskRuleApp.GetDataMasker(dbConn)
+	biz.ErrIsNil(err)
+	showRawData := canShowRawData(rc)
+
+	rowCount := 0
+	// 结果集为空时也写入表头
+	err = dbConn.WalkQueryRowsWithColumns(ctx, sql, func(columns []*dbi.QueryColumn) error {
+		if err := masker.ResolveSelectMaskTypes(stmt, columns); err != nil && !showRawData {
+			return err
+		}
+		// 存在敏感字段时，有查看原始数据权限则记录审计日志，否则脱敏
+		if maskColumns := masker.MaskColumns(columns); len(maskColumns) == 0 {
+			masker = nil
+		} else if showRawData {
+			d.DataMaskRuleApp.SaveRawDataAudit(ctx, dbConn, sql, maskColumns)
+			masker = nil
+		}
+		return exportWriter.WriteHeader(columns)
+	}, func(row map[string]any, columns []*dbi.QueryColumn) error {
+		masker.MaskRow(row)
+		rowCount++
+		writer.TryFlush()
+		return exportWriter.WriteRow(row, columns)
+	})
+	biz.ErrIsNil(err)
+	biz.ErrIsNil(exportWriter.Close())
+
+	rc.ReqParam = fmt.Sprintf("%s format: %s, rows: %d\n-> %s", dbConn.Info.GetLogDesc(), format, rowCount, sql)
+}
+
+func (d *Db) dumpDb(ctx context.Context, writer *gzipWriter, dbId uint64, dbName string, tables []string, needStruct bool, needData bool, showRawData bool) {
 	dbConn, err := d.DbApp.GetDbConn(dbId, dbName)
 	biz.ErrIsNil(err)
 	writer.WriteString("\n-- ----------------------------")
@@ -355,7 +614,22 @@ func (d *Db) dumpDb(writer *gzipWriter, dbId uint64, dbName string, tables []str
 			writer.WriteString("BEGIN;\n")
 		}
 		insertSql := "INSERT INTO %s VALUES (%s);\n"
-		dbMeta.WalkTableRecord(table, func(record map[string]any, columns []*dbi.QueryColumn) error {
+		masker, err := d.DataMaskRuleApp.GetDataMasker(dbConn, table)
+		biz.ErrIsNil(err)
+		maskChecked := false
+		dbMeta.WalkTableRecord(ctx, table, func(record map[string]any, columns []*dbi.QueryColumn) error {
+			// 首行数据时判断是否存在敏感字段，有查看原始数据权限则记录审计日志，否则脱敏
+			if !maskChecked {
+				maskChecked = true
+				if maskColumns := masker.MaskColumns(columns); len(maskColumns) == 0 {
+					masker = nil
+				} else if showRawData {
+					d.DataMaskRuleApp.SaveRawDataAudit(ctx, dbConn, fmt.Sprintf("-- 导出表数据: %s", table), maskColumns)
+					masker = nil
+				}
+			}
+			masker.MaskRow(record)
+
 			var values []string
 			writer.TryFlush()
 			for _, column := range columns {
@@ -408,43 +682,16 @@ func (d *Db) ColumnMA(rc *req.Ctx) {
 
 // @router /api/db/:dbId/hint-tables [get]
 func (d *Db) HintTables(rc *req.Ctx) {
-	dbi := d.getDbConn(rc.GinCtx)
-
-	dm := dbi.GetDialect()
-	// 获取所有表
-	tables, err := dm.GetTables()
-	biz.ErrIsNil(err)
-	tableNames := make([]string, 0)
-	for _, v := range tables {
-		tableNames = append(tableNames, v.TableName)
-	}
-	// key = 表名，value = 列名数组
-	res := make(map[string][]string)
-
-	// 表为空，则直接返回
-	if len(tableNames) == 0 {
-		rc.ResData = res
-		return
-	}
-
-	// 获取所有表下的所有列信息
-	columnMds, err := dm.GetColumns(tableNames...)
-	biz.ErrIsNil(err)
-	for _, v := range columnMds {
-		tName := v.TableName
-		if res[tName] == nil {
-			res[tName] = make([]string, 0)
-		}
-
-		columnName := fmt.Sprintf("%s  [%s]", v.ColumnName, v.ColumnType)
-		comment := v.ColumnComment
-		// 如果字段备注不为空，则加上备注信息
-		if comment != "" {
-			columnName = fmt.Sprintf("%s[%s]", columnName, comment)
-		}
+	res, err := d.DbMetaCacheApp.GetHintTables(d.getDbConn(rc.GinCtx))
+	biz.ErrIsNilAppendErr(err, "获取表提示信息失败: %s")
+	rc.ResData = res
+}
 
-		res[tName] = append(res[tName], columnName)
-	}
+// 获取指定版本之后变更的表提示信息
+func (d *Db) HintTablesChange(rc *req.Ctx) {
+	version := int64(ginx.QueryInt(rc.GinCtx, "version", 0))
+	res, err := d.DbMetaCacheApp.GetHintTablesChange(d.getDbConn(rc.GinCtx), version)
+	biz.ErrIsNilAppendErr(err, "获取表提示信息失败: %s")
 	rc.ResData = res
 }
 
@@ -2,11 +2,16 @@ package api
 
 import (
 	"context"
+	"mayfly-go/internal/common/consts"
 	"mayfly-go/internal/db/api/form"
 	"mayfly-go/internal/db/api/vo"
 	"mayfly-go/internal/db/application"
 	"mayfly-go/internal/db/domain/entity"
+	machineapp "mayfly-go/internal/machine/application"
+	machineentity "mayfly-go/internal/machine/domain/entity"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/ginx"
 	"mayfly-go/pkg/req"
 	"strconv"
@@ -16,6 +21,8 @@ import (
 type DbBackup struct {
 	DbBackupApp *application.DbBackupApp
 	DbApp       application.Db
+	MachineApp  machineapp.Machine
+	TagApp      tagapp.TagTree
 }
 
 // todo: 鉴权，避免未经授权进行数据库备份和恢复
@@ -45,6 +52,8 @@ func (d *DbBackup) Create(rc *req.Ctx) {
 
 	dbNames := strings.Fields(backupForm.DbNames)
 	biz.IsTrue(len(dbNames) > 0, "解析数据库备份任务失败：数据库名称未定义")
+	checkRetention(backupForm.Retention)
+	storage := d.checkStorage(rc, backupForm.Storage, "")
 
 	dbId := uint64(ginx.PathParamInt(rc.GinCtx, "dbId"))
 	biz.IsTrue(dbId > 0, "无效的 dbId: %v", dbId)
@@ -60,6 +69,8 @@ func (d *DbBackup) Create(rc *req.Ctx) {
 			StartTime:     backupForm.StartTime,
 			Interval:      backupForm.Interval,
 			Name:          backupForm.Name,
+			Retention:     backupForm.Retention,
+			Storage:       storage,
 		}
 		job.DbName = dbName
 		jobs = append(jobs, job)
@@ -79,9 +90,47 @@ func (d *DbBackup) Update(rc *req.Ctx) {
 	job.Name = backupForm.Name
 	job.StartTime = backupForm.StartTime
 	job.Interval = backupForm.Interval
+	job.Retention = backupForm.Retention
+	checkRetention(backupForm.Retention)
+	oldJob, err := d.DbBackupApp.GetById(backupForm.Id)
+	biz.ErrIsNilAppendErr(err, "获取数据库备份任务失败: %v")
+	job.Storage = d.checkStorage(rc, backupForm.Storage, oldJob.Storage)
 	biz.ErrIsNilAppendErr(d.DbBackupApp.Update(rc.MetaCtx, job), "保存数据库备份任务失败: %v")
 }
 
+func checkRetention(retention string) {
+	_, err := (&entity.DbBackup{Retention: retention}).GetRetention()
+	biz.ErrIsNilAppendErr(err, "备份保留策略格式错误: %v")
+}
+
+// 校验备份存储配置并加密 secret key，未填写 secret key 则沿用原配置的 secret key，
+// sftp存储需校验当前账号是否拥有该机器的访问权限
+func (d *DbBackup) checkStorage(rc *req.Ctx, storage string, oldStorage string) string {
+	bs, err := entity.ParseDbBackupStorage(storage)
+	biz.ErrIsNilAppendErr(err, "备份存储配置格式错误: %v")
+	switch bs.Type {
+	case entity.DbBackupStorageLocal:
+		return bs.String()
+	case entity.DbBackupStorageSftp:
+		biz.IsTrue(bs.MachineId > 0 && bs.Path != "", "sftp备份存储需指定机器及目录")
+		machine, err := d.MachineApp.GetById(new(machineentity.Machine), bs.MachineId, "code")
+		biz.ErrIsNilAppendErr(err, "sftp备份存储的机器不存在: %v")
+		biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, d.TagApp.ListTagPathByResource(consts.TagResourceTypeMachine, machine.Code)...), "%s")
+	case entity.DbBackupStorageS3:
+		biz.IsTrue(bs.Endpoint != "" && bs.Bucket != "" && bs.AccessKey != "", "s3备份存储需指定服务地址、存储桶及access key")
+		if bs.SecretKey == "" {
+			old, err := entity.ParseDbBackupStorage(oldStorage)
+			biz.IsTrue(err == nil && old.Type == entity.DbBackupStorageS3 && old.SecretKey != "", "s3备份存储需指定secret key")
+			bs.SecretKey = old.SecretKey
+		} else {
+			biz.ErrIsNil(bs.SecretEncrypt())
+		}
+	default:
+		panic(errorx.NewBiz("不支持的备份存储类型: %s", bs.Type))
+	}
+	return bs.String()
+}
+
 func (d *DbBackup) walk(rc *req.Ctx, fn func(ctx context.Context, backupId uint64) error) error {
 	idsStr := ginx.PathParam(rc.GinCtx, "backupId")
 	biz.NotEmpty(idsStr, "backupId 为空")
@@ -129,6 +178,13 @@ func (d *DbBackup) Start(rc *req.Ctx) {
 	biz.ErrIsNilAppendErr(err, "运行数据库备份任务失败: %v")
 }
 
+// Cancel 取消正在运行的数据库备份任务
+// @router /api/dbs/:dbId/backups/:backupId/cancel [PUT]
+func (d *DbBackup) Cancel(rc *req.Ctx) {
+	err := d.walk(rc, d.DbBackupApp.Cancel)
+	biz.ErrIsNilAppendErr(err, "取消数据库备份任务失败: %v")
+}
+
 // GetDbNamesWithoutBackup 获取未配置定时备份的数据库名称
 // @router /api/dbs/:dbId/db-names-without-backup [GET]
 func (d *DbBackup) GetDbNamesWithoutBackup(rc *req.Ctx) {
@@ -156,3 +212,11 @@ func (d *DbBackup) GetHistoryPageList(rc *req.Ctx) {
 	biz.ErrIsNilAppendErr(err, "获取数据库备份历史失败: %v")
 	rc.ResData = res
 }
+
+// VerifyHistory 校验数据库备份
+// @router /api/dbs/:dbId/backup-histories/:historyId/verify [POST]
+func (d *DbBackup) VerifyHistory(rc *req.Ctx) {
+	historyId := uint64(ginx.PathParamInt(rc.GinCtx, "historyId"))
+	rc.ReqParam = historyId
+	biz.ErrIsNilAppendErr(d.DbBackupApp.VerifyHistory(rc.MetaCtx, historyId), "校验数据库备份失败: %v")
+}
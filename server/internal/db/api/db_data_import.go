@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"mayfly-go/internal/db/api/form"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/dbm/dbi"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ginx"
+	"mayfly-go/pkg/req"
+)
+
+// 预览数据文件的前几行及默认的列映射
+func (d *Db) PreviewImportData(rc *req.Ctx) {
+	importReq, closeFile := d.getDataImportReq(rc)
+	defer closeFile()
+
+	preview, err := d.DbSqlExecApp.PreviewImportData(rc.MetaCtx, importReq)
+	biz.ErrIsNil(err)
+	rc.ResData = preview
+}
+
+// 导入csv、xlsx、jsonl数据文件至表，导入进度通过ws推送
+func (d *Db) ImportData(rc *req.Ctx) {
+	importReq, closeFile := d.getDataImportReq(rc)
+	defer closeFile()
+	biz.IsTrue(len(importReq.Mappings) > 0, "请选择需要导入的列")
+
+	progress, err := d.DbSqlExecApp.ImportData(rc.MetaCtx, importReq)
+	if err != nil {
+		d.MsgApp.CreateAndSend(rc.GetLoginAccount(), msgdto.ErrSysMsg("数据导入失败", fmt.Sprintf("%s 导入失败: %s", rc.ReqParam, err.Error())).WithClientId(importReq.ClientId))
+	}
+	biz.ErrIsNil(err)
+	d.MsgApp.CreateAndSend(rc.GetLoginAccount(), msgdto.SuccessSysMsg("数据导入成功", fmt.Sprintf("%s 导入完成, 成功%d条, 错误%d条", rc.ReqParam, progress.Inserted, progress.ErrorCount)).WithClientId(importReq.ClientId))
+	rc.ResData = progress
+}
+
+// 获取数据导入请求信息，返回关闭上传文件的函数
+func (d *Db) getDataImportReq(rc *req.Ctx) (*application.DbDataImportReq, func() error) {
+	g := rc.GinCtx
+	importForm := new(form.DbDataImportForm)
+	if err := g.ShouldBind(importForm); err != nil {
+		panic(ginx.ConvBindValidationError(importForm, err))
+	}
+	fileheader, err := g.FormFile("file")
+	biz.ErrIsNilAppendErr(err, "读取文件失败: %s")
+
+	dbId := getDbId(g)
+	dbConn, err := d.DbApp.GetDbConn(dbId, importForm.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.TagPath...), "%s")
+	rc.ReqParam = fmt.Sprintf("filename: %s -> %s, table: %s", fileheader.Filename, dbConn.Info.GetLogDesc(), importForm.Table)
+
+	mappings := make([]*application.DbDataImportMapping, 0)
+	if importForm.Mappings != "" {
+		biz.ErrIsNilAppendErr(json.Unmarshal([]byte(importForm.Mappings), &mappings), "列映射信息错误: %s")
+	}
+	duplicateStrategy := dbi.DuplicateStrategy(importForm.DuplicateStrategy)
+	if !duplicateStrategy.NeedHandle() {
+		duplicateStrategy = dbi.DuplicateStrategyNone
+	}
+
+	file, err := fileheader.Open()
+	biz.ErrIsNilAppendErr(err, "读取文件失败: %s")
+	return &application.DbDataImportReq{
+		DbId:              dbId,
+		Db:                importForm.Db,
+		Table:             importForm.Table,
+		DbConn:            dbConn,
+		Filename:          fileheader.Filename,
+		File:              file,
+		FileSize:          fileheader.Size,
+		HasHeader:         importForm.HasHeader,
+		Mappings:          mappings,
+		BatchSize:         importForm.BatchSize,
+		DuplicateStrategy: duplicateStrategy,
+		IgnoreError:       importForm.IgnoreError,
+		ClientId:          importForm.ClientId,
+	}, file.Close
+}
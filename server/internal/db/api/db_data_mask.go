@@ -0,0 +1,42 @@
+package api
+
+import (
+	"mayfly-go/internal/db/api/form"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ginx"
+	"mayfly-go/pkg/req"
+	"strconv"
+	"strings"
+)
+
+type DataMaskRule struct {
+	DataMaskRuleApp application.DataMaskRule `inject:"DbDataMaskRuleApp"`
+}
+
+func (d *DataMaskRule) Rules(rc *req.Ctx) {
+	queryCond, page := ginx.BindQueryAndPage[*entity.DataMaskRuleQuery](rc.GinCtx, new(entity.DataMaskRuleQuery))
+	res, err := d.DataMaskRuleApp.GetPageList(queryCond, page, new([]entity.DataMaskRule))
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (d *DataMaskRule) SaveRule(rc *req.Ctx) {
+	form := &form.DataMaskRuleForm{}
+	rule := ginx.BindJsonAndCopyTo[*entity.DataMaskRule](rc.GinCtx, form, new(entity.DataMaskRule))
+	rc.ReqParam = form
+	biz.ErrIsNil(d.DataMaskRuleApp.Save(rc.MetaCtx, rule))
+}
+
+func (d *DataMaskRule) DeleteRule(rc *req.Ctx) {
+	ruleId := ginx.PathParam(rc.GinCtx, "ruleId")
+	rc.ReqParam = ruleId
+	ids := strings.Split(ruleId, ",")
+
+	for _, v := range ids {
+		value, err := strconv.Atoi(v)
+		biz.ErrIsNilAppendErr(err, "string类型转换为int异常: %s")
+		biz.ErrIsNil(d.DataMaskRuleApp.DeleteById(rc.MetaCtx, uint64(value)))
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"mayfly-go/internal/db/api/vo"
 	"mayfly-go/internal/db/application"
 	"mayfly-go/internal/db/domain/entity"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/biz"
 	"mayfly-go/pkg/ginx"
 	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/stringx"
 	"strconv"
 	"strings"
@@ -20,6 +22,8 @@ import (
 
 type DataSyncTask struct {
 	DataSyncTaskApp application.DataSyncTask `inject:"DbDataSyncTaskApp"`
+	DbApp           application.Db           `inject:""`
+	TagApp          tagapp.TagTree           `inject:"TagTreeApp"`
 }
 
 func (d *DataSyncTask) Tasks(rc *req.Ctx) {
@@ -58,6 +62,30 @@ func (d *DataSyncTask) SaveTask(rc *req.Ctx) {
 	biz.ErrIsNil(d.DataSyncTaskApp.Save(rc.MetaCtx, task))
 }
 
+// 预览字段转换后的同步数据，用于启用任务前确认字段映射及转换规则
+func (d *DataSyncTask) Preview(rc *req.Ctx) {
+	form := &form.DataSyncTaskPreviewForm{}
+	task := ginx.BindJsonAndCopyTo[*entity.DataSyncTask](rc.GinCtx, form, new(entity.DataSyncTask))
+
+	sqlBytes, err := base64.StdEncoding.DecodeString(task.DataSql)
+	biz.ErrIsNilAppendErr(err, "sql解码失败: %s")
+	task.DataSql = stringx.TrimSpaceAndBr(string(sqlBytes))
+
+	srcConn, err := d.DbApp.GetDbConn(uint64(task.SrcDbId), task.SrcDbName)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, srcConn.Info.TagPath...), "%s")
+
+	limit := form.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	rows, err := d.DataSyncTaskApp.Preview(rc.MetaCtx, task, limit, canShowRawData(rc))
+	biz.ErrIsNilAppendErr(err, "预览数据失败: %s")
+
+	fieldMaps, _ := task.GetFieldMaps()
+	rc.ResData = collx.Kvs("columns", collx.ArrayMap(fieldMaps, func(fm *entity.DataSyncFieldMap) string { return fm.Target }), "res", rows)
+}
+
 func (d *DataSyncTask) DeleteTask(rc *req.Ctx) {
 	taskId := ginx.PathParam(rc.GinCtx, "taskId")
 	rc.ReqParam = taskId
@@ -89,17 +117,13 @@ func (d *DataSyncTask) ChangeStatus(rc *req.Ctx) {
 func (d *DataSyncTask) Run(rc *req.Ctx) {
 	taskId := getTaskId(rc.GinCtx)
 	rc.ReqParam = taskId
-	d.DataSyncTaskApp.RunCronJob(taskId)
+	biz.ErrIsNil(d.DataSyncTaskApp.RunCronJob(taskId))
 }
 
 func (d *DataSyncTask) Stop(rc *req.Ctx) {
 	taskId := getTaskId(rc.GinCtx)
 	rc.ReqParam = taskId
-
-	task := new(entity.DataSyncTask)
-	task.Id = taskId
-	task.RunningState = entity.DataSyncTaskRunStateStop
-	_ = d.DataSyncTaskApp.UpdateById(context.Background(), task)
+	biz.ErrIsNil(d.DataSyncTaskApp.StopTask(rc.MetaCtx, taskId))
 }
 
 func (d *DataSyncTask) GetTask(rc *req.Ctx) {
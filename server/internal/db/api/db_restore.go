@@ -2,10 +2,12 @@ package api
 
 import (
 	"context"
+	"mayfly-go/internal/common/consts"
 	"mayfly-go/internal/db/api/form"
 	"mayfly-go/internal/db/api/vo"
 	"mayfly-go/internal/db/application"
 	"mayfly-go/internal/db/domain/entity"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/biz"
 	"mayfly-go/pkg/ginx"
 	"mayfly-go/pkg/req"
@@ -16,6 +18,7 @@ import (
 type DbRestore struct {
 	DbRestoreApp *application.DbRestoreApp
 	DbApp        application.Db
+	TagApp       tagapp.TagTree
 }
 
 // GetPageList 获取数据库恢复任务
@@ -59,6 +62,19 @@ func (d *DbRestore) Create(rc *req.Ctx) {
 		DbBackupHistoryName: restoreForm.DbBackupHistoryName,
 	}
 	job.DbName = restoreForm.DbName
+	job.TargetDbName = strings.TrimSpace(restoreForm.TargetDbName)
+
+	// 未指定目标数据库时恢复至备份所在数据库，均需拥有目标数据库的操作权限，目标库不存在时自动创建
+	targetDbId := restoreForm.TargetDbId
+	if targetDbId == 0 {
+		targetDbId = dbId
+	}
+	targetDb, err := d.DbApp.GetById(new(entity.Db), targetDbId, "instanceId", "code")
+	biz.ErrIsNilAppendErr(err, "获取目标数据库信息失败: %v")
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, d.TagApp.ListTagPathByResource(consts.TagResourceTypeDb, targetDb.Code)...), "%s")
+	if restoreForm.TargetDbId > 0 {
+		job.TargetDbInstanceId = targetDb.InstanceId
+	}
 	biz.ErrIsNilAppendErr(d.DbRestoreApp.Create(rc.MetaCtx, job), "添加数据库恢复任务失败: %v")
 }
 
@@ -116,6 +132,13 @@ func (d *DbRestore) Disable(rc *req.Ctx) {
 	biz.ErrIsNilAppendErr(err, "禁用数据库恢复任务失败: %v")
 }
 
+// Cancel 取消正在运行的数据库恢复任务
+// @router /api/dbs/:dbId/restores/:restoreId/cancel [PUT]
+func (d *DbRestore) Cancel(rc *req.Ctx) {
+	err := d.walk(rc, d.DbRestoreApp.Cancel)
+	biz.ErrIsNilAppendErr(err, "取消数据库恢复任务失败: %v")
+}
+
 // GetDbNamesWithoutRestore 获取未配置定时恢复的数据库名称
 // @router /api/dbs/:dbId/db-names-without-backup [GET]
 func (d *DbRestore) GetDbNamesWithoutRestore(rc *req.Ctx) {
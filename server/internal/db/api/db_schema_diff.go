@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"mayfly-go/internal/db/api/form"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ginx"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/stringx"
+	"strings"
+	"time"
+)
+
+// 比对两个库的表结构，并返回目标库同步为源库表结构所需的sql
+func (d *Db) SchemaDiff(rc *req.Ctx) {
+	diffForm := ginx.BindJsonAndValid(rc.GinCtx, new(form.DbSchemaDiffForm))
+	diff, targetConn, sqls := d.compareSchema(rc, diffForm)
+	rc.ResData = collx.Kvs("diff", diff, "sqls", sqls, "targetDbType", targetConn.Info.Type)
+}
+
+// 下载表结构同步sql脚本
+func (d *Db) DownloadSchemaDiffSql(rc *req.Ctx) {
+	diffForm := ginx.BindQuery(rc.GinCtx, new(form.DbSchemaDiffForm))
+	_, targetConn, sqls := d.compareSchema(rc, diffForm)
+
+	script := new(strings.Builder)
+	script.WriteString("-- ----------------------------\n")
+	script.WriteString("-- 导出平台: mayfly-go\n")
+	script.WriteString(fmt.Sprintf("-- 导出时间: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	script.WriteString(fmt.Sprintf("-- 表结构同步: %s -> %s\n", diffForm.SrcDb, diffForm.TargetDb))
+	script.WriteString("-- ----------------------------\n\n")
+	for _, sql := range sqls {
+		if strings.HasPrefix(sql, "--") {
+			script.WriteString(sql + "\n")
+			continue
+		}
+		script.WriteString(sql + ";\n\n")
+	}
+
+	filename := fmt.Sprintf("%s.diff.%s.sql", targetConn.Info.Name, time.Now().Format("20060102150405"))
+	ginx.Download(rc.GinCtx, strings.NewReader(script.String()), filename)
+}
+
+// 在目标库执行表结构同步sql，走sql执行流程（审批、执行记录等）
+func (d *Db) ExecSchemaDiffSql(rc *req.Ctx) {
+	diffForm := ginx.BindJsonAndValid(rc.GinCtx, new(form.DbSchemaDiffForm))
+	_, targetConn, sqls := d.compareSchema(rc, diffForm)
+	biz.IsTrue(len(sqls) > 0, "两库表结构一致，无需同步")
+
+	remark := diffForm.Remark
+	if remark == "" {
+		remark = fmt.Sprintf("表结构同步: %s -> %s", diffForm.SrcDb, diffForm.TargetDb)
+	}
+	execReq := &application.DbSqlExecReq{
+		DbId:           diffForm.TargetDbId,
+		Db:             diffForm.TargetDb,
+		Remark:         remark,
+		DbConn:         targetConn,
+		AuditConfirmed: diffForm.AuditConfirmed,
+	}
+
+	execSqls := make([]string, 0, len(sqls))
+	for _, sql := range sqls {
+		if strings.HasPrefix(sql, "--") {
+			continue
+		}
+		// 源库建表语句可能包含多条语句（如pgsql的注释、索引语句）
+		stmts, err := targetConn.Info.Type.SplitSql(sql)
+		biz.ErrIsNil(err, "SQL解析错误,请检查您的执行SQL")
+		for _, stmt := range stmts {
+			if stmt = stringx.TrimSpaceAndBr(stmt); stmt != "" {
+				execSqls = append(execSqls, stmt)
+			}
+		}
+	}
+	// 与sql执行一致，存在需确认的风险则返回风险信息，由用户确认后再次提交执行
+	if confirmMsgs := d.auditSqls(rc.MetaCtx, execReq, execSqls); len(confirmMsgs) > 0 {
+		rc.ResData = collx.M{"auditConfirm": confirmMsgs}
+		return
+	}
+
+	var execResAll *application.DbSqlExecRes
+	for _, sql := range execSqls {
+		execReq.Sql = sql
+		execRes, err := d.DbSqlExecApp.Exec(rc.MetaCtx, execReq)
+		biz.ErrIsNilAppendErr(err, fmt.Sprintf("[%s] -> 执行失败: ", sql)+"%s")
+		if execResAll == nil {
+			execResAll = execRes
+		} else {
+			execResAll.Merge(execRes)
+		}
+	}
+
+	biz.IsTrue(execResAll != nil, "无可执行的同步sql")
+	rc.ResData = collx.Kvs("columns", execResAll.Columns, "res", execResAll.Res)
+}
+
+func (d *Db) compareSchema(rc *req.Ctx, diffForm *form.DbSchemaDiffForm) (*dbi.SchemaDiff, *dbi.DbConn, []string) {
+	la := rc.GetLoginAccount()
+	srcConn, err := d.DbApp.GetDbConn(diffForm.SrcDbId, diffForm.SrcDb)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(la.Id, srcConn.Info.TagPath...), "%s")
+
+	targetConn, err := d.DbApp.GetDbConn(diffForm.TargetDbId, diffForm.TargetDb)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(la.Id, targetConn.Info.TagPath...), "%s")
+
+	rc.ReqParam = fmt.Sprintf("%s -> %s", srcConn.Info.GetLogDesc(), targetConn.Info.GetLogDesc())
+
+	var tables []string
+	if diffForm.Tables != "" {
+		tables = strings.Split(diffForm.Tables, ",")
+	}
+	diff, err := dbi.CompareSchema(srcConn, targetConn, tables...)
+	biz.ErrIsNilAppendErr(err, "表结构比对失败: %s")
+	return diff, targetConn, diff.GenSql(targetConn.Info.Type, diffForm.DropTable)
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"mayfly-go/internal/db/api/form"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ginx"
+	"mayfly-go/pkg/req"
+	"strconv"
+	"strings"
+)
+
+type SqlAuditRule struct {
+	SqlAuditRuleApp application.SqlAuditRule `inject:"DbSqlAuditRuleApp"`
+}
+
+func (d *SqlAuditRule) Rules(rc *req.Ctx) {
+	queryCond, page := ginx.BindQueryAndPage[*entity.SqlAuditRuleQuery](rc.GinCtx, new(entity.SqlAuditRuleQuery))
+	res, err := d.SqlAuditRuleApp.GetPageList(queryCond, page, new([]entity.SqlAuditRule))
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (d *SqlAuditRule) SaveRule(rc *req.Ctx) {
+	form := &form.SqlAuditRuleForm{}
+	rule := ginx.BindJsonAndCopyTo[*entity.SqlAuditRule](rc.GinCtx, form, new(entity.SqlAuditRule))
+	rc.ReqParam = form
+	biz.ErrIsNil(d.SqlAuditRuleApp.Save(rc.MetaCtx, rule))
+}
+
+func (d *SqlAuditRule) DeleteRule(rc *req.Ctx) {
+	ruleId := ginx.PathParam(rc.GinCtx, "ruleId")
+	rc.ReqParam = ruleId
+	ids := strings.Split(ruleId, ",")
+
+	for _, v := range ids {
+		value, err := strconv.Atoi(v)
+		biz.ErrIsNilAppendErr(err, "string类型转换为int异常: %s")
+		biz.ErrIsNil(d.SqlAuditRuleApp.DeleteById(rc.MetaCtx, uint64(value)))
+	}
+}
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"fmt"
+	"mayfly-go/internal/db/api/form"
 	"mayfly-go/internal/db/application"
 	"mayfly-go/internal/db/domain/entity"
 	"mayfly-go/pkg/biz"
 	"mayfly-go/pkg/ginx"
 	"mayfly-go/pkg/req"
+	"strings"
 )
 
 type DbSqlExec struct {
@@ -18,3 +21,35 @@ func (d *DbSqlExec) DbSqlExecs(rc *req.Ctx) {
 	biz.ErrIsNil(err)
 	rc.ResData = res
 }
+
+// 审批待执行的sql
+func (d *DbSqlExec) Approve(rc *req.Ctx) {
+	approveForm := new(form.DbSqlExecApproveForm)
+	ginx.BindJsonAndValid(rc.GinCtx, approveForm)
+	execId := uint64(ginx.PathParamInt(rc.GinCtx, "execId"))
+	rc.ReqParam = fmt.Sprintf("execId: %d, pass: %v, remark: %s", execId, approveForm.Pass, approveForm.Remark)
+
+	biz.ErrIsNil(d.DbSqlExecApp.Approve(rc.MetaCtx, execId, approveForm.Pass, approveForm.Remark))
+}
+
+// 获取update、delete执行记录的回滚sql
+func (d *DbSqlExec) GetRollbackSql(rc *req.Ctx) {
+	execId := uint64(ginx.PathParamInt(rc.GinCtx, "execId"))
+	rollbackSqls, err := d.DbSqlExecApp.GenRollbackSql(rc.MetaCtx, execId)
+	biz.ErrIsNilAppendErr(err, "生成回滚sql失败: %s")
+	rc.ResData = strings.Join(rollbackSqls, ";\n") + ";"
+}
+
+// 一键回滚update、delete执行记录
+func (d *DbSqlExec) Rollback(rc *req.Ctx) {
+	execId := uint64(ginx.PathParamInt(rc.GinCtx, "execId"))
+	rc.ReqParam = fmt.Sprintf("execId: %d", execId)
+
+	execRes, err := d.DbSqlExecApp.Rollback(rc.MetaCtx, execId)
+	biz.ErrIsNilAppendErr(err, "回滚失败: %s")
+
+	colAndRes := make(map[string]any)
+	colAndRes["columns"] = execRes.Columns
+	colAndRes["res"] = execRes.Res
+	rc.ResData = colAndRes
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"mayfly-go/internal/db/api/form"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ginx"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/stringx"
+	"strings"
+)
+
+// 预览根据表结构定义生成的建表或修改表sql
+func (d *Db) PreviewTableDesignSql(rc *req.Ctx) {
+	designForm := ginx.BindJsonAndValid(rc.GinCtx, new(form.DbTableDesignForm))
+	_, sqls := d.genTableDesignSql(rc, designForm)
+	rc.ResData = sqls
+}
+
+// 执行根据表结构定义生成的sql，走sql执行流程（审批、执行记录等）
+func (d *Db) ExecTableDesignSql(rc *req.Ctx) {
+	designForm := ginx.BindJsonAndValid(rc.GinCtx, new(form.DbTableDesignForm))
+	dbConn, sqls := d.genTableDesignSql(rc, designForm)
+
+	remark := designForm.Remark
+	if remark == "" {
+		remark = fmt.Sprintf("设计表: %s", designForm.Table.TableName)
+	}
+	execReq := &application.DbSqlExecReq{
+		DbId:           getDbId(rc.GinCtx),
+		Db:             designForm.Db,
+		Remark:         remark,
+		DbConn:         dbConn,
+		AuditConfirmed: designForm.AuditConfirmed,
+	}
+
+	execSqls := make([]string, 0, len(sqls))
+	// 无法自动执行的变更以 -- 注释返回，需用户手动处理
+	warnings := make([]string, 0)
+	for _, sql := range sqls {
+		sql = stringx.TrimSpaceAndBr(sql)
+		if strings.HasPrefix(sql, "--") {
+			warnings = append(warnings, strings.TrimSpace(strings.TrimPrefix(sql, "--")))
+		} else if sql != "" {
+			execSqls = append(execSqls, sql)
+		}
+	}
+	biz.IsTrue(len(execSqls) > 0 || len(warnings) == 0, "以下变更无法自动执行, 请手动处理: %s", strings.Join(warnings, "; "))
+
+	// 与sql执行一致，存在需确认的风险则返回风险信息，由用户确认后再次提交执行；无法自动执行的变更同样需确认
+	confirmMsgs := d.auditSqls(rc.MetaCtx, execReq, execSqls)
+	if !designForm.AuditConfirmed {
+		for _, warning := range warnings {
+			confirmMsgs = append(confirmMsgs, fmt.Sprintf("[无法自动执行] -> %s", warning))
+		}
+	}
+	if len(confirmMsgs) > 0 {
+		rc.ResData = collx.M{"auditConfirm": confirmMsgs}
+		return
+	}
+
+	var execResAll *application.DbSqlExecRes
+	for _, sql := range execSqls {
+		execReq.Sql = sql
+		execRes, err := d.DbSqlExecApp.Exec(rc.MetaCtx, execReq)
+		biz.ErrIsNilAppendErr(err, fmt.Sprintf("[%s] -> 执行失败: ", sql)+"%s")
+		if execResAll == nil {
+			execResAll = execRes
+		} else {
+			execResAll.Merge(execRes)
+		}
+	}
+
+	biz.IsTrue(execResAll != nil, "表结构无变更，无需执行")
+	rc.ResData = collx.Kvs("columns", execResAll.Columns, "res", execResAll.Res, "warnings", warnings)
+}
+
+func (d *Db) genTableDesignSql(rc *req.Ctx, designForm *form.DbTableDesignForm) (*dbi.DbConn, []string) {
+	dbConn, err := d.DbApp.GetDbConn(getDbId(rc.GinCtx), designForm.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.TagPath...), "%s")
+	rc.ReqParam = fmt.Sprintf("%s -> table: %s", dbConn.Info.GetLogDesc(), designForm.Table.TableName)
+
+	sqls, err := dbConn.GetDialect().GenTableDesignSql(designForm.Table)
+	biz.ErrIsNilAppendErr(err, "生成表结构sql失败: %s")
+	return dbConn, sqls
+}
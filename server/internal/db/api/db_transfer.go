@@ -0,0 +1,97 @@
+package api
+
+import (
+	"mayfly-go/internal/db/api/form"
+	"mayfly-go/internal/db/api/vo"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/domain/entity"
+	tagapp "mayfly-go/internal/tag/application"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ginx"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+	"strconv"
+	"strings"
+)
+
+type DbTransferTask struct {
+	DbTransferTaskApp application.DbTransferTask `inject:"DbTransferTaskApp"`
+	DbApp             application.Db             `inject:""`
+	TagApp            tagapp.TagTree             `inject:"TagTreeApp"`
+}
+
+func (d *DbTransferTask) Tasks(rc *req.Ctx) {
+	queryCond, page := ginx.BindQueryAndPage[*entity.DbTransferTaskQuery](rc.GinCtx, new(entity.DbTransferTaskQuery))
+	res, err := d.DbTransferTaskApp.GetPageList(queryCond, page, new([]vo.DbTransferTaskListVO))
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (d *DbTransferTask) SaveTask(rc *req.Ctx) {
+	form := &form.DbTransferTaskForm{}
+	task := ginx.BindJsonAndCopyTo[*entity.DbTransferTask](rc.GinCtx, form, new(entity.DbTransferTask))
+	rc.ReqParam = form
+
+	// 修改任务时，需有原任务源库及目标库的权限
+	if task.Id != 0 {
+		d.checkTaskAccess(rc, task.Id)
+	}
+
+	laId := rc.GetLoginAccount().Id
+	srcConn, err := d.DbApp.GetDbConn(task.SrcDbId, task.SrcDbName)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(laId, srcConn.Info.TagPath...), "%s")
+	targetConn, err := d.DbApp.GetDbConn(task.TargetDbId, task.TargetDbName)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(laId, targetConn.Info.TagPath...), "%s")
+	biz.IsTrue(task.SrcDbId != task.TargetDbId || task.SrcDbName != task.TargetDbName, "源库与目标库不能相同")
+
+	task.SrcTagPath = strings.Join(srcConn.Info.TagPath, ",")
+	task.TargetTagPath = strings.Join(targetConn.Info.TagPath, ",")
+	biz.ErrIsNil(d.DbTransferTaskApp.Save(rc.MetaCtx, task))
+}
+
+func (d *DbTransferTask) DeleteTask(rc *req.Ctx) {
+	taskId := ginx.PathParam(rc.GinCtx, "taskId")
+	rc.ReqParam = taskId
+	ids := strings.Split(taskId, ",")
+
+	for _, v := range ids {
+		value, err := strconv.Atoi(v)
+		biz.ErrIsNilAppendErr(err, "string类型转换为int异常: %s")
+		d.checkTaskAccess(rc, uint64(value))
+		biz.ErrIsNil(d.DbTransferTaskApp.Delete(rc.MetaCtx, uint64(value)))
+	}
+}
+
+// 预览迁移至目标库的建表语句
+func (d *DbTransferTask) CreateTableSql(rc *req.Ctx) {
+	taskId := getTaskId(rc.GinCtx)
+	d.checkTaskAccess(rc, taskId)
+	sqls, err := d.DbTransferTaskApp.GenCreateTableSql(rc.MetaCtx, taskId)
+	biz.ErrIsNilAppendErr(err, "生成建表语句失败: %s")
+	rc.ResData = sqls
+}
+
+func (d *DbTransferTask) Run(rc *req.Ctx) {
+	taskId := getTaskId(rc.GinCtx)
+	d.checkTaskAccess(rc, taskId)
+	runForm := ginx.BindJsonAndValid(rc.GinCtx, new(form.DbTransferRunForm))
+	rc.ReqParam = collx.Kvs("taskId", taskId, "reset", runForm.Reset)
+	biz.ErrIsNil(d.DbTransferTaskApp.Run(rc.MetaCtx, taskId, runForm.Reset, runForm.ClientId))
+}
+
+func (d *DbTransferTask) Stop(rc *req.Ctx) {
+	taskId := getTaskId(rc.GinCtx)
+	rc.ReqParam = taskId
+	d.checkTaskAccess(rc, taskId)
+	biz.ErrIsNil(d.DbTransferTaskApp.Stop(rc.MetaCtx, taskId))
+}
+
+func (d *DbTransferTask) checkTaskAccess(rc *req.Ctx, taskId uint64) {
+	task, err := d.DbTransferTaskApp.GetById(new(entity.DbTransferTask), taskId)
+	biz.ErrIsNil(err, "该任务不存在")
+	laId := rc.GetLoginAccount().Id
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(laId, strings.Split(task.SrcTagPath, ",")...), "%s")
+	biz.ErrIsNilAppendErr(d.TagApp.CanAccess(laId, strings.Split(task.TargetTagPath, ",")...), "%s")
+}
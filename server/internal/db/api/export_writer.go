@@ -0,0 +1,255 @@
+package api
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"strings"
+)
+
+const (
+	ExportFormatCsv  = "csv"
+	ExportFormatXlsx = "xlsx"
+	ExportFormatJson = "json"
+	ExportFormatSql  = "sql"
+)
+
+// 查询结果导出写入器
+type exportWriter interface {
+	// 写入表头，遍历数据前调用（结果集为空时也会调用）
+	WriteHeader(columns []*dbi.QueryColumn) error
+
+	// 写入一行数据
+	WriteRow(row map[string]any, columns []*dbi.QueryColumn) error
+
+	// 结束写入
+	Close() error
+}
+
+// 根据导出格式创建对应的写入器
+func newExportWriter(format string, writer io.Writer, dbType dbi.DbType, tableName string) (exportWriter, error) {
+	switch format {
+	case ExportFormatCsv:
+		return &csvExportWriter{writer: writer, csvWriter: csv.NewWriter(writer)}, nil
+	case ExportFormatXlsx:
+		return &xlsxExportWriter{zipWriter: zip.NewWriter(writer)}, nil
+	case ExportFormatJson:
+		return &jsonExportWriter{writer: writer}, nil
+	case ExportFormatSql:
+		if tableName == "" {
+			return nil, errorx.NewBiz("导出INSERT语句需指定表名")
+		}
+		return &sqlExportWriter{writer: writer, dbType: dbType, quotedTable: application.QuoteTableName(dbType, tableName)}, nil
+	default:
+		return nil, errorx.NewBiz("不支持的导出格式: %s", format)
+	}
+}
+
+/******************* csv *******************/
+
+type csvExportWriter struct {
+	writer    io.Writer
+	csvWriter *csv.Writer
+	rowCount  int
+}
+
+func (c *csvExportWriter) WriteHeader(columns []*dbi.QueryColumn) error {
+	// 写入utf8 bom，防止excel打开中文乱码
+	if _, err := c.writer.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	return c.csvWriter.Write(header)
+}
+
+func (c *csvExportWriter) WriteRow(row map[string]any, columns []*dbi.QueryColumn) error {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = anyx.ToString(row[column.Name])
+	}
+	c.rowCount++
+	if c.rowCount%1000 == 0 {
+		c.csvWriter.Flush()
+	}
+	return c.csvWriter.Write(values)
+}
+
+func (c *csvExportWriter) Close() error {
+	c.csvWriter.Flush()
+	return c.csvWriter.Error()
+}
+
+/******************* json lines *******************/
+
+type jsonExportWriter struct {
+	writer io.Writer
+}
+
+func (j *jsonExportWriter) WriteHeader(columns []*dbi.QueryColumn) error {
+	return nil
+}
+
+// 每行数据为一个json对象，并按查询列顺序输出字段
+func (j *jsonExportWriter) WriteRow(row map[string]any, columns []*dbi.QueryColumn) error {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, column := range columns {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		key, _ := json.Marshal(column.Name)
+		value, err := json.Marshal(row[column.Name])
+		if err != nil {
+			return err
+		}
+		sb.Write(key)
+		sb.WriteString(":")
+		sb.Write(value)
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(j.writer, sb.String())
+	return err
+}
+
+func (j *jsonExportWriter) Close() error {
+	return nil
+}
+
+/******************* sql insert *******************/
+
+type sqlExportWriter struct {
+	writer      io.Writer
+	dbType      dbi.DbType
+	quotedTable string
+	columnsStr  string
+}
+
+func (s *sqlExportWriter) WriteHeader(columns []*dbi.QueryColumn) error {
+	quotedColumns := make([]string, len(columns))
+	for i, column := range columns {
+		quotedColumns[i] = s.dbType.QuoteIdentifier(column.Name)
+	}
+	s.columnsStr = strings.Join(quotedColumns, ", ")
+	return nil
+}
+
+func (s *sqlExportWriter) WriteRow(row map[string]any, columns []*dbi.QueryColumn) error {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		value := row[column.Name]
+		if value == nil {
+			values[i] = "NULL"
+			continue
+		}
+		if strValue, ok := value.(string); ok {
+			values[i] = s.dbType.QuoteLiteral(strValue)
+		} else {
+			values[i] = anyx.ToString(value)
+		}
+	}
+	_, err := io.WriteString(s.writer, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);\n", s.quotedTable, s.columnsStr, strings.Join(values, ", ")))
+	return err
+}
+
+func (s *sqlExportWriter) Close() error {
+	return nil
+}
+
+/******************* xlsx *******************/
+
+// xlsx单个sheet最大行数
+const xlsxMaxRows = 1048576
+
+// xlsx流式写入器，仅包含单个sheet，单元格使用内联字符串，无需在内存中维护共享字符串表
+type xlsxExportWriter struct {
+	zipWriter   *zip.Writer
+	sheetWriter io.Writer
+	rowNum      int
+}
+
+var xlsxStaticFiles = [][2]string{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func (x *xlsxExportWriter) WriteHeader(columns []*dbi.QueryColumn) error {
+	for _, file := range xlsxStaticFiles {
+		w, err := x.zipWriter.Create(file[0])
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, file[1]); err != nil {
+			return err
+		}
+	}
+
+	sheetWriter, err := x.zipWriter.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheetWriter = sheetWriter
+	if _, err := io.WriteString(sheetWriter, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return err
+	}
+
+	header := make(map[string]any, len(columns))
+	for _, column := range columns {
+		header[column.Name] = column.Name
+	}
+	return x.WriteRow(header, columns)
+}
+
+func (x *xlsxExportWriter) WriteRow(row map[string]any, columns []*dbi.QueryColumn) error {
+	if x.rowNum >= xlsxMaxRows {
+		return errorx.NewBiz("xlsx最多支持导出%d行数据", xlsxMaxRows)
+	}
+	x.rowNum++
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<row r="%d">`, x.rowNum))
+	for _, column := range columns {
+		switch value := row[column.Name].(type) {
+		case nil:
+			sb.WriteString("<c/>")
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			sb.WriteString("<c><v>" + anyx.ToString(value) + "</v></c>")
+		default:
+			sb.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(&sb, []byte(anyx.ToString(value)))
+			sb.WriteString("</t></is></c>")
+		}
+	}
+	sb.WriteString("</row>")
+	_, err := io.WriteString(x.sheetWriter, sb.String())
+	return err
+}
+
+func (x *xlsxExportWriter) Close() error {
+	// 无数据时也需要写入表头等文件，保证为合法的xlsx文件
+	if x.sheetWriter == nil {
+		if err := x.WriteHeader(nil); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(x.sheetWriter, "</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	return x.zipWriter.Close()
+}
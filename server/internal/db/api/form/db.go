@@ -1,5 +1,7 @@
 package form
 
+import "mayfly-go/internal/db/dbm/dbi"
+
 type DbForm struct {
 	Id         uint64   `json:"id"`
 	Name       string   `binding:"required" json:"name"`
@@ -22,4 +24,78 @@ type DbSqlExecForm struct {
 	Db     string `binding:"required" json:"db"`  //数据库名
 	Sql    string `binding:"required" json:"sql"` // 执行sql
 	Remark string `json:"remark"`                 // 执行备注
+
+	AuditConfirmed bool `json:"auditConfirmed"` // 是否已确认sql审核提示的风险
+}
+
+// 获取SQL执行计划表单
+type DbSqlExplainForm struct {
+	Db  string `binding:"required" json:"db"`  // 数据库名
+	Sql string `binding:"required" json:"sql"` // 待分析的sql
+}
+
+// 表数据行修改表单，修改及删除均通过主键定位记录
+type DbRowChangeForm struct {
+	Db      string             `binding:"required" json:"db"`    // 数据库名
+	Table   string             `binding:"required" json:"table"` // 表名
+	Remark  string             `json:"remark"`                   // 执行备注
+	Inserts []map[string]any   `json:"inserts"`                  // 新增的行
+	Updates []*DbRowUpdateForm `json:"updates"`                  // 修改的行
+	Deletes []map[string]any   `json:"deletes"`                  // 删除行的主键值
+}
+
+type DbRowUpdateForm struct {
+	PrimaryKey map[string]any `binding:"required" json:"primaryKey"` // 主键列名 -> 值
+	Values     map[string]any `binding:"required" json:"values"`     // 修改的列名 -> 新值
+}
+
+// 表数据分页查询表单
+type DbTableDataForm struct {
+	Db       string                `binding:"required" json:"db"`    // 数据库名
+	Table    string                `binding:"required" json:"table"` // 表名
+	Conds    []*dbi.TableDataCond  `json:"conds"`                    // 过滤条件
+	Orders   []*dbi.TableDataOrder `json:"orders"`                   // 排序
+	PageNum  int                   `json:"pageNum"`
+	PageSize int                   `json:"pageSize"`
+	Count    bool                  `json:"count"`    // 是否统计总记录数
+	Keyset   bool                  `json:"keyset"`   // 是否使用主键键集分页
+	AfterKey map[string]any        `json:"afterKey"` // 键集分页时上一页最后一行的主键值
+}
+
+// 数据库SQL执行审批表单
+type DbSqlExecApproveForm struct {
+	Pass   bool   `json:"pass"`   // 是否通过
+	Remark string `json:"remark"` // 审批备注
+}
+
+// 数据库表结构比对表单
+type DbSchemaDiffForm struct {
+	SrcDbId        uint64 `binding:"required" json:"srcDbId" form:"srcDbId"`       // 源数据库id
+	SrcDb          string `binding:"required" json:"srcDb" form:"srcDb"`           // 源库名，pgsql等为db/schema
+	TargetDbId     uint64 `binding:"required" json:"targetDbId" form:"targetDbId"` // 目标数据库id
+	TargetDb       string `binding:"required" json:"targetDb" form:"targetDb"`     // 目标库名
+	Tables         string `json:"tables" form:"tables"`                            // 需比对的表名，逗号分隔，为空则比对所有表
+	DropTable      bool   `json:"dropTable" form:"dropTable"`                      // 是否生成删除目标库多余表的语句
+	Remark         string `json:"remark" form:"remark"`                            // 执行备注
+	AuditConfirmed bool   `json:"auditConfirmed" form:"auditConfirmed"`            // 是否已确认sql审核提示的风险
+}
+
+// 数据库表结构设计表单
+type DbTableDesignForm struct {
+	Db             string               `binding:"required" json:"db"`    // 数据库名
+	Table          *dbi.TableDefinition `binding:"required" json:"table"` // 表结构定义
+	Remark         string               `json:"remark"`                   // 执行备注
+	AuditConfirmed bool                 `json:"auditConfirmed"`           // 是否已确认sql审核提示的风险
+}
+
+// 数据文件导入表单，文件通过multipart的file字段上传
+type DbDataImportForm struct {
+	Db                string `binding:"required" form:"db"`    // 数据库名
+	Table             string `binding:"required" form:"table"` // 导入的表名
+	HasHeader         bool   `form:"hasHeader"`                // 文件首行是否为表头
+	Mappings          string `form:"mappings"`                 // 文件列与表列映射的json数组，预览时可为空
+	BatchSize         int    `form:"batchSize"`                // 每批次插入的数据条数
+	DuplicateStrategy int8   `form:"duplicateStrategy"`        // 数据重复时的处理策略
+	IgnoreError       bool   `form:"ignoreError"`              // 是否忽略错误行
+	ClientId          string `form:"clientId"`                 // 接收导入进度的客户端id
 }
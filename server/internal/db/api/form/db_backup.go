@@ -14,6 +14,8 @@ type DbBackupForm struct {
 	Interval    time.Duration `json:"-"`                            // 间隔时间: 为零表示单次执行，为正表示反复执行
 	IntervalDay uint64        `json:"intervalDay"`                  // 间隔天数: 为零表示单次执行，为正表示反复执行
 	Repeated    bool          `json:"repeated"`                     // 是否重复执行
+	Retention   string        `json:"retention"`                    // 备份保留策略json
+	Storage     string        `json:"storage"`                      // 备份存储json
 }
 
 func (restore *DbBackupForm) UnmarshalJSON(data []byte) error {
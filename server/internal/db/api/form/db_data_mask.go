@@ -0,0 +1,13 @@
+package form
+
+type DataMaskRuleForm struct {
+	Id            uint64 `json:"id"`
+	Name          string `binding:"required" json:"name"`
+	TagPath       string `json:"tagPath"`
+	DbPattern     string `json:"dbPattern"`
+	TablePattern  string `json:"tablePattern"`
+	ColumnPattern string `binding:"required" json:"columnPattern"`
+	MaskType      string `binding:"required" json:"maskType"`
+	Status        int8   `binding:"required" json:"status"`
+	Remark        string `json:"remark"`
+}
@@ -12,6 +12,7 @@ type DataSyncTaskForm struct {
 	SrcTagPath  string `binding:"required" json:"srcTagPath"`
 	DataSql     string `binding:"required" json:"dataSql"`
 	PageSize    int    `binding:"required" json:"pageSize"`
+	BatchCommit int8   `json:"batchCommit"`
 	UpdField    string `binding:"required" json:"updField"`
 	UpdFieldVal string `binding:"required" json:"updFieldVal"`
 
@@ -20,6 +21,20 @@ type DataSyncTaskForm struct {
 	TargetTagPath   string `binding:"required" json:"targetTagPath"`
 	TargetTableName string `binding:"required" json:"targetTableName"`
 	FieldMap        string `binding:"required" json:"fieldMap"`
+	RowFilter       string `json:"rowFilter"`
+
+	DuplicateStrategy int8   `json:"duplicateStrategy"` // 数据重复时的处理策略 -1报错 1忽略 2更新
+	DuplicateKey      string `json:"duplicateKey"`      // 判断数据重复的目标表字段，多个逗号分隔，为空则使用目标表主键
+}
+
+// 预览同步数据
+type DataSyncTaskPreviewForm struct {
+	SrcDbId   int64  `binding:"required" json:"srcDbId"`
+	SrcDbName string `binding:"required" json:"srcDbName"`
+	DataSql   string `binding:"required" json:"dataSql"` // base64编码的数据源查询sql
+	FieldMap  string `binding:"required" json:"fieldMap"`
+	RowFilter string `json:"rowFilter"`
+	Limit     int    `json:"limit"` // 预览条数
 }
 
 type DataSyncTaskStatusForm struct {
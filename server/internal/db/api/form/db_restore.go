@@ -18,6 +18,8 @@ type DbRestoreForm struct {
 	Interval            time.Duration  `json:"-"`                            // 间隔时间: 为零表示单次执行，为正表示反复执行
 	IntervalDay         uint64         `json:"intervalDay"`                  // 间隔天数: 为零表示单次执行，为正表示反复执行
 	Repeated            bool           `json:"repeated"`                     // 是否重复执行
+	TargetDbId          uint64         `json:"targetDbId"`                   // 恢复目标数据库ID，为0表示恢复至备份所在的数据库实例
+	TargetDbName        string         `json:"targetDbName"`                 // 恢复目标数据库名称，为空表示恢复至备份的数据库，不存在时自动创建
 }
 
 func (restore *DbRestoreForm) UnmarshalJSON(data []byte) error {
@@ -0,0 +1,12 @@
+package form
+
+type SqlAuditRuleForm struct {
+	Id        uint64 `json:"id"`
+	Name      string `binding:"required" json:"name"`
+	TagPath   string `json:"tagPath"`
+	RuleType  string `binding:"required" json:"ruleType"`
+	Level     int8   `binding:"required" json:"level"`
+	Threshold int64  `json:"threshold"`
+	Status    int8   `binding:"required" json:"status"`
+	Remark    string `json:"remark"`
+}
@@ -0,0 +1,21 @@
+package form
+
+type DbTransferTaskForm struct {
+	Id            uint64 `json:"id"`
+	TaskName      string `binding:"required" json:"taskName"`
+	Mode          int8   `binding:"required" json:"mode"`          // 迁移模式 1结构+数据 2仅结构 3仅数据
+	TableStrategy int8   `binding:"required" json:"tableStrategy"` // 目标表已存在时的处理策略 1保留 2删除后重建
+	BatchSize     int    `json:"batchSize"`
+
+	SrcDbId   uint64 `binding:"required" json:"srcDbId"`
+	SrcDbName string `binding:"required" json:"srcDbName"`
+	SrcTables string `json:"srcTables"` // 需迁移的表名，逗号分隔，为空则迁移所有表
+
+	TargetDbId   uint64 `binding:"required" json:"targetDbId"`
+	TargetDbName string `binding:"required" json:"targetDbName"`
+}
+
+type DbTransferRunForm struct {
+	Reset    bool   `json:"reset"`    // 是否忽略已迁移完成的表，从头开始迁移
+	ClientId string `json:"clientId"` // 接收迁移进度的客户端id
+}
@@ -26,6 +26,12 @@ func (g *gzipWriter) WriteString(data string) {
 	}
 }
 
+// 实现io.Writer接口，便于csv、xlsx等写入器直接写入
+func (g *gzipWriter) Write(data []byte) (int, error) {
+	g.WriteString(string(data))
+	return len(data), nil
+}
+
 func (g *gzipWriter) Close() {
 	g.writer.Close()
 }
@@ -20,11 +20,19 @@ type DbBackup struct {
 	LastResult   string         `json:"lastResult"`           // 最近一次执行结果
 	DbInstanceId uint64         `json:"dbInstanceId"`         // 数据库实例ID
 	Name         string         `json:"name"`                 // 备份任务名称
+	Retention    string         `json:"retention"`            // 备份保留策略json
+	Storage      string         `json:"storage"`              // 备份存储json
 }
 
 func (backup *DbBackup) MarshalJSON() ([]byte, error) {
 	type dbBackup DbBackup
 	backup.IntervalDay = uint64(backup.Interval / time.Hour / 24)
+	// 不返回备份存储的 secret key
+	if storage := make(map[string]any); json.Unmarshal([]byte(backup.Storage), &storage) == nil && storage["secretKey"] != nil {
+		delete(storage, "secretKey")
+		bytes, _ := json.Marshal(storage)
+		backup.Storage = string(bytes)
+	}
 	return json.Marshal((*dbBackup)(backup))
 }
 
@@ -35,4 +43,12 @@ type DbBackupHistory struct {
 	CreateTime time.Time `json:"createTime"`
 	DbName     string    `json:"dbName"` // 数据库名称
 	Name       string    `json:"name"`   // 备份历史名称
+
+	FileSize     int64      `json:"fileSize"`     // 备份文件大小
+	Checksum     string     `json:"checksum"`     // 备份文件sha256校验和
+	VerifyStatus int8       `json:"verifyStatus"` // 校验状态
+	VerifyResult string     `json:"verifyResult"` // 校验结果
+	VerifyTime   *time.Time `json:"verifyTime"`   // 校验时间
+	FileName     string     `json:"fileName"`     // 备份文件名
+	Progress     string     `json:"progress"`     // 备份进度json
 }
@@ -22,6 +22,8 @@ type DbRestore struct {
 	DbBackupHistoryId   uint64         `json:"dbBackupHistoryId"`    // 数据库备份历史ID
 	DbBackupHistoryName string         `json:"dbBackupHistoryName"`  // 数据库备份历史名称
 	DbInstanceId        uint64         `json:"dbInstanceId"`         // 数据库实例ID
+	TargetDbInstanceId  uint64         `json:"targetDbInstanceId"`   // 恢复目标数据库实例ID
+	TargetDbName        string         `json:"targetDbName"`         // 恢复目标数据库名称
 }
 
 func (restore *DbRestore) MarshalJSON() ([]byte, error) {
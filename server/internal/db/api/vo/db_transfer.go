@@ -0,0 +1,25 @@
+package vo
+
+import "time"
+
+type DbTransferTaskListVO struct {
+	Id            *int64     `json:"id"`
+	TaskName      *string    `json:"taskName"`
+	Mode          int8       `json:"mode"`
+	TableStrategy int8       `json:"tableStrategy"`
+	BatchSize     int        `json:"batchSize"`
+	SrcDbId       uint64     `json:"srcDbId"`
+	SrcDbName     string     `json:"srcDbName"`
+	SrcTagPath    string     `json:"srcTagPath"`
+	SrcTables     string     `json:"srcTables"`
+	TargetDbId    uint64     `json:"targetDbId"`
+	TargetDbName  string     `json:"targetDbName"`
+	TargetTagPath string     `json:"targetTagPath"`
+	Checkpoint    string     `json:"checkpoint"`
+	Res           string     `json:"res"`
+	UpdateTime    *time.Time `json:"updateTime"`
+	ModifierId    uint64     `json:"modifierId"`
+	Modifier      string     `json:"modifier"`
+	RecentState   *int       `json:"recentState"`
+	RunningState  *int       `json:"runningState"`
+}
@@ -22,6 +22,10 @@ func init() {
 	ioc.Register(new(dbSqlExecAppImpl), ioc.WithComponentName("DbSqlExecApp"))
 	ioc.Register(new(dbSqlAppImpl), ioc.WithComponentName("DbSqlApp"))
 	ioc.Register(new(dataSyncAppImpl), ioc.WithComponentName("DbDataSyncTaskApp"))
+	ioc.Register(new(dbTransferAppImpl), ioc.WithComponentName("DbTransferTaskApp"))
+	ioc.Register(new(dataMaskRuleAppImpl), ioc.WithComponentName("DbDataMaskRuleApp"))
+	ioc.Register(new(sqlAuditRuleAppImpl), ioc.WithComponentName("DbSqlAuditRuleApp"))
+	ioc.Register(new(dbMetaCacheAppImpl), ioc.WithComponentName("DbMetaCacheApp"))
 }
 
 func Init() {
@@ -55,6 +59,8 @@ func Init() {
 		}
 
 		GetDataSyncTaskApp().InitCronJob()
+		GetDbTransferTaskApp().ResetRunningState()
+		GetDbSqlExecApp().TimerExpireApproval()
 	})()
 }
 
@@ -89,3 +95,19 @@ func GetDbBinlogApp() *DbBinlogApp {
 func GetDataSyncTaskApp() DataSyncTask {
 	return ioc.Get[DataSyncTask]("DbDataSyncTaskApp")
 }
+
+func GetDbTransferTaskApp() DbTransferTask {
+	return ioc.Get[DbTransferTask]("DbTransferTaskApp")
+}
+
+func GetDataMaskRuleApp() DataMaskRule {
+	return ioc.Get[DataMaskRule]("DbDataMaskRuleApp")
+}
+
+func GetSqlAuditRuleApp() SqlAuditRule {
+	return ioc.Get[SqlAuditRule]("DbSqlAuditRuleApp")
+}
+
+func GetDbMetaCacheApp() DbMetaCache {
+	return ioc.Get[DbMetaCache]("DbMetaCacheApp")
+}
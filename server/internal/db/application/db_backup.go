@@ -2,11 +2,21 @@ package application
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
+	"fmt"
 	"github.com/google/uuid"
+	"io"
+	"mayfly-go/internal/db/dbm"
+	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/domain/entity"
 	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/model"
+	"os"
+	"time"
 )
 
 func newDbBackupApp(repositories *repository.Repositories, dbApp Db, scheduler *dbScheduler) (*DbBackupApp, error) {
@@ -64,6 +74,19 @@ func (app *DbBackupApp) Start(ctx context.Context, jobId uint64) error {
 	return app.scheduler.StartJobNow(ctx, entity.DbJobTypeBackup, jobId)
 }
 
+// Cancel 取消正在运行的备份任务
+func (app *DbBackupApp) Cancel(ctx context.Context, jobId uint64) error {
+	return app.scheduler.CancelJob(ctx, entity.DbJobTypeBackup, jobId)
+}
+
+func (app *DbBackupApp) GetById(backupId uint64) (*entity.DbBackup, error) {
+	backup := entity.NewDbJob(entity.DbJobTypeBackup).(*entity.DbBackup)
+	if err := app.backupRepo.GetById(backup, backupId); err != nil {
+		return nil, err
+	}
+	return backup, nil
+}
+
 // GetPageList 分页获取数据库备份任务
 func (app *DbBackupApp) GetPageList(condition *entity.DbJobQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
 	return app.backupRepo.GetPageList(condition, pageParam, toEntity, orderBy...)
@@ -74,6 +97,117 @@ func (app *DbBackupApp) GetDbNamesWithoutBackup(instanceId uint64, dbNames []str
 	return app.backupRepo.GetDbNamesWithoutBackup(instanceId, dbNames)
 }
 
+// VerifyHistory 校验数据库备份：校验备份文件的校验和，并将备份恢复至临时数据库，校验完成后删除临时数据库。
+// 校验在后台执行，结果保存至备份历史
+func (app *DbBackupApp) VerifyHistory(ctx context.Context, historyId uint64) error {
+	history := &entity.DbBackupHistory{}
+	if err := app.backupHistoryRepo.GetById(history, historyId); err != nil {
+		return errorx.NewBiz("数据库备份历史不存在")
+	}
+	if history.VerifyStatus == entity.DbBackupVerifyRunning {
+		return errorx.NewBiz("该备份正在校验中")
+	}
+	conn, err := app.scheduler.getDbConn(history.DbInstanceId, history.DbName)
+	if err != nil {
+		return err
+	}
+	if err := checkVerifySupported(conn.Info.Type); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	history.VerifyStatus = entity.DbBackupVerifyRunning
+	history.VerifyResult = ""
+	history.VerifyTime = &now
+	if err := app.updateVerifyResult(ctx, history); err != nil {
+		return err
+	}
+
+	go func() {
+		ctx := context.WithoutCancel(ctx)
+		result, err := app.verify(ctx, conn, history)
+		history.VerifyStatus = entity.DbBackupVerifySuccess
+		history.VerifyResult = result
+		if err != nil {
+			logx.Errorf("校验数据库备份[%s]失败: %v", history.Name, err)
+			history.VerifyStatus = entity.DbBackupVerifyFailed
+			history.VerifyResult = err.Error()
+		}
+		if err := app.updateVerifyResult(ctx, history); err != nil {
+			logx.Errorf("保存数据库备份校验结果失败: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (app *DbBackupApp) verify(ctx context.Context, conn *dbi.DbConn, history *entity.DbBackupHistory) (string, error) {
+	bs, err := app.scheduler.getBackupHistoryStorage(history, nil)
+	if err != nil {
+		return "", err
+	}
+	fileName, release, err := dbi.DownloadBackupFile(ctx, history, bs)
+	if err != nil {
+		return "", errorx.NewBiz("读取备份文件失败: %v", err)
+	}
+	defer release()
+	_, checksum, err := fileChecksum(fileName)
+	if err != nil {
+		return "", err
+	}
+	if history.Checksum != "" && history.Checksum != checksum {
+		return "", errorx.NewBiz("备份文件校验和不一致, 文件可能已损坏")
+	}
+
+	dbType := conn.Info.Type
+	if err := checkVerifySupported(dbType); err != nil {
+		return "", err
+	}
+	scratchDb := fmt.Sprintf("mayfly_verify_%d_%d", history.Id, time.Now().Unix())
+	if _, err := conn.ExecContext(ctx, dbType.StmtCreateDatabase(scratchDb)); err != nil {
+		return "", errorx.NewBiz("创建临时数据库失败: %v", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, dbType.StmtDropDatabase(scratchDb)); err != nil {
+			logx.Errorf("删除临时数据库[%s]失败: %v", scratchDb, err)
+		}
+	}()
+
+	scratchInfo := *conn.Info
+	scratchInfo.Id = 0
+	scratchInfo.Database = scratchDb
+	scratchConn, err := dbm.Conn(&scratchInfo)
+	if err != nil {
+		return "", errorx.NewBiz("连接临时数据库失败: %v", err)
+	}
+	// 需在删除临时数据库前关闭连接
+	defer scratchConn.Close()
+
+	dbProgram := scratchConn.GetDialect().GetDbProgram()
+	if dbProgram == nil {
+		return "", errorx.NewBiz("数据库类型 %s 暂不支持备份与恢复", dbType)
+	}
+	if err := dbProgram.RestoreBackupHistory(ctx, scratchDb, history, dbi.LocalBackupFileOpener(fileName)); err != nil {
+		return "", errorx.NewBiz("恢复至临时数据库失败: %v", err)
+	}
+	tables, err := scratchConn.GetDialect().GetTables()
+	if err != nil {
+		return "", errorx.NewBiz("获取临时数据库表信息失败: %v", err)
+	}
+	return fmt.Sprintf("校验成功, 已恢复 %d 张表", len(tables)), nil
+}
+
+// 校验需创建临时数据库，oracle、dm、sqlite 等不支持创建数据库的类型无法校验
+func checkVerifySupported(dbType dbi.DbType) error {
+	if dbType.StmtCreateDatabase("mayfly_verify") == "" || dbType.StmtDropDatabase("mayfly_verify") == "" {
+		return errorx.NewBiz("数据库类型 %s 暂不支持恢复至临时数据库进行校验", dbType)
+	}
+	return nil
+}
+
+func (app *DbBackupApp) updateVerifyResult(ctx context.Context, history *entity.DbBackupHistory) error {
+	return app.backupHistoryRepo.UpdateById(ctx, history, "verify_status", "verify_result", "verify_time")
+}
+
 // GetHistoryPageList 分页获取数据库备份历史
 func (app *DbBackupApp) GetHistoryPageList(condition *entity.DbBackupHistoryQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
 	return app.backupHistoryRepo.GetHistories(condition, pageParam, toEntity, orderBy...)
@@ -99,3 +233,19 @@ func NewIncUUID() (uuid.UUID, error) {
 
 	return uid, nil
 }
+
+// 计算文件大小及sha256校验和
+func fileChecksum(fileName string) (int64, string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,404 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/stringx"
+	"mayfly-go/pkg/ws"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 数据导入进度消息类型
+const DbDataImportProgressCategory = "dbDataImportProgress"
+
+// 数据导入预览的数据行数
+const dataImportPreviewRows = 10
+
+// 数据导入进度消息中返回的最大错误行数
+const maxDataImportRowErrors = 100
+
+// DbDataImportReq 数据文件导入请求
+type DbDataImportReq struct {
+	DbId     uint64
+	Db       string
+	Table    string
+	DbConn   *dbi.DbConn
+	Filename string
+	FileType string // 文件类型，为空则根据文件名后缀判断
+	File     io.ReaderAt
+	FileSize int64
+
+	HasHeader         bool                   // 文件首行是否为表头
+	Mappings          []*DbDataImportMapping // 文件列与表列的映射
+	BatchSize         int                    // 每批次插入的数据条数
+	DuplicateStrategy dbi.DuplicateStrategy  // 数据重复时的处理策略
+	IgnoreError       bool                   // 是否忽略格式错误的行，否则遇到错误行即终止导入并回滚
+	ClientId          string                 // 接收导入进度的客户端id
+}
+
+// DbDataImportMapping 文件列与表列的映射
+type DbDataImportMapping struct {
+	FileColumn int    `json:"fileColumn"` // 文件列序号，从0开始
+	Column     string `json:"column"`     // 表列名
+}
+
+// DbDataImportPreview 数据文件导入预览信息
+type DbDataImportPreview struct {
+	FileType    string                 `json:"fileType"`
+	HasHeader   bool                   `json:"hasHeader"`   // 首行是否为表头
+	FileColumns []string               `json:"fileColumns"` // 文件列名，无表头时为列序号
+	Rows        [][]any                `json:"rows"`        // 文件前几行数据
+	Columns     []dbi.Column           `json:"columns"`     // 表列信息
+	Mappings    []*DbDataImportMapping `json:"mappings"`    // 默认的列映射，有表头时按列名匹配，否则按顺序匹配
+}
+
+// DbDataImportProgress 数据导入进度消息
+type DbDataImportProgress struct {
+	Id         string                  `json:"id"`
+	Title      string                  `json:"title"`
+	Table      string                  `json:"table"`
+	Rows       int64                   `json:"rows"`       // 已读取的数据行数
+	Inserted   int64                   `json:"inserted"`   // 已插入的数据行数
+	ErrorCount int64                   `json:"errorCount"` // 错误行数
+	Errors     []*DbDataImportRowError `json:"errors"`     // 错误行信息，最多返回100条
+	Terminated bool                    `json:"terminated"` // 是否已结束
+	Err        string                  `json:"err"`
+}
+
+// DbDataImportRowError 数据导入的错误行信息
+type DbDataImportRowError struct {
+	Row int64  `json:"row"` // 文件中的行号，从1开始
+	Msg string `json:"msg"`
+}
+
+func (p *DbDataImportProgress) addRowError(row int64, err error) {
+	p.ErrorCount++
+	if len(p.Errors) < maxDataImportRowErrors {
+		p.Errors = append(p.Errors, &DbDataImportRowError{Row: row, Msg: err.Error()})
+	}
+}
+
+func (d *dbSqlExecAppImpl) PreviewImportData(ctx context.Context, importReq *DbDataImportReq) (*DbDataImportPreview, error) {
+	fileType, reader, err := newImportFileReader(importReq)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := getImportTableColumns(importReq)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]any, 0)
+	for len(rows) <= dataImportPreviewRows {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if dbi.IsDataFileRowError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errorx.NewBiz("读取文件失败: %s", err.Error())
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, errorx.NewBiz("文件中不存在数据")
+	}
+
+	preview := &DbDataImportPreview{
+		FileType:  fileType,
+		HasHeader: dbi.DetectDataFileHeader(fileType, rows[0]),
+		Columns:   columns,
+		Mappings:  make([]*DbDataImportMapping, 0),
+	}
+	columnCount := 0
+	for _, row := range rows {
+		columnCount = max(columnCount, len(row))
+	}
+	for i := 0; i < columnCount; i++ {
+		name := fmt.Sprintf("%d", i+1)
+		if preview.HasHeader && i < len(rows[0]) {
+			name = strings.TrimSpace(anyx.ToString(rows[0][i]))
+		}
+		preview.FileColumns = append(preview.FileColumns, name)
+	}
+	if preview.HasHeader {
+		rows = rows[1:]
+	}
+	preview.Rows = rows
+
+	for i, name := range preview.FileColumns {
+		for j, column := range columns {
+			if (preview.HasHeader && strings.EqualFold(name, column.ColumnName)) || (!preview.HasHeader && i == j) {
+				preview.Mappings = append(preview.Mappings, &DbDataImportMapping{FileColumn: i, Column: column.ColumnName})
+				break
+			}
+		}
+	}
+	return preview, nil
+}
+
+func (d *dbSqlExecAppImpl) ImportData(ctx context.Context, importReq *DbDataImportReq) (*DbDataImportProgress, error) {
+	if len(importReq.Mappings) == 0 {
+		return nil, errorx.NewBiz("请选择需要导入的列")
+	}
+	execSqlReq := &DbSqlExecReq{
+		DbId:   importReq.DbId,
+		Db:     importReq.Db,
+		Remark: importReq.Filename,
+		DbConn: importReq.DbConn,
+	}
+	if d.needApproval(execSqlReq) {
+		return nil, errorx.NewBiz("该库需审批后执行sql, 不支持直接导入数据")
+	}
+
+	fileType, reader, err := newImportFileReader(importReq)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := getImportTableColumns(importReq)
+	if err != nil {
+		return nil, err
+	}
+	// jsonl首行固定返回字段名
+	hasHeader := importReq.HasHeader || fileType == dbi.DataFileTypeJsonl
+
+	dbConn := importReq.DbConn
+	dialect := dbConn.GetDialect()
+	tableName := dbConn.Info.Type.RemoveQuote(importReq.Table)
+
+	// 按映射获取导入的表列及对应数据类型
+	importColumns := make([]dbi.Column, 0, len(importReq.Mappings))
+	dataTypes := make([]dbi.DataType, 0, len(importReq.Mappings))
+	quoteColumns := make([]string, 0, len(importReq.Mappings))
+	for _, mapping := range importReq.Mappings {
+		if mapping.FileColumn < 0 {
+			return nil, errorx.NewBiz("文件列序号错误: %d", mapping.FileColumn)
+		}
+		var column *dbi.Column
+		for i := range columns {
+			if strings.EqualFold(columns[i].ColumnName, mapping.Column) {
+				column = &columns[i]
+				break
+			}
+		}
+		if column == nil {
+			return nil, errorx.NewBiz("表[%s]不存在列[%s]", tableName, mapping.Column)
+		}
+		for _, ic := range importColumns {
+			if ic.ColumnName == column.ColumnName {
+				return nil, errorx.NewBiz("列[%s]重复映射", column.ColumnName)
+			}
+		}
+		importColumns = append(importColumns, *column)
+		dataTypes = append(dataTypes, dialect.GetDataType(column.ColumnType))
+		quoteColumns = append(quoteColumns, dbConn.Info.Type.QuoteIdentifier(column.ColumnName))
+	}
+
+	batchSize := importReq.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultTransferBatchSize
+	}
+	batchSize = min(batchSize, maxTransferBatchArgs/len(importColumns))
+
+	la := contextx.GetLoginAccount(ctx)
+	progress := &DbDataImportProgress{
+		Id:     stringx.Rand(32),
+		Title:  importReq.Filename,
+		Table:  tableName,
+		Errors: make([]*DbDataImportRowError, 0),
+	}
+	defer func() {
+		progress.Terminated = true
+		d.sendImportProgress(la, importReq.ClientId, progress)
+	}()
+
+	// 将文件行转换为表列对应的值，并返回不插入的列：
+	// 该行不存在对应文件列的列，及值为空但不可为空且有默认值或为自增的列，均由数据库生成默认值
+	toValues := func(row []any) ([]any, []bool, error) {
+		values := make([]any, 0, len(importColumns))
+		omits := make([]bool, len(importColumns))
+		for i, mapping := range importReq.Mappings {
+			column := importColumns[i]
+			notNull := strings.EqualFold(column.Nullable, "NO")
+			hasDefault := column.ColumnDefault != "" || dbi.IsAutoIncrement(column)
+			if mapping.FileColumn >= len(row) {
+				if notNull && !hasDefault {
+					return nil, nil, errorx.NewBiz("列[%s]不能为空", column.ColumnName)
+				}
+				omits[i] = true
+				continue
+			}
+			value, err := dbi.ConvertImportValue(dataTypes[i], row[mapping.FileColumn])
+			if err != nil {
+				return nil, nil, errorx.NewBiz("列[%s]: %s", column.ColumnName, err.Error())
+			}
+			if value == nil && notNull {
+				if !hasDefault {
+					return nil, nil, errorx.NewBiz("列[%s]不能为空", column.ColumnName)
+				}
+				omits[i] = true
+				continue
+			}
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			return nil, nil, errorx.NewBiz("导入的列均为空")
+		}
+		return values, omits, nil
+	}
+
+	tx, err := dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]any, 0, batchSize)
+	// 当前批次不插入的列及插入的列，不插入的列不同的行需分批插入
+	var batchOmits []bool
+	var batchColumns []string
+	lastSendTime := time.Now()
+
+	insertBatch := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		affected, err := dialect.BatchInsert(tx, tableName, batchColumns, values, importReq.DuplicateStrategy)
+		if err != nil {
+			return errorx.NewBiz("第%d行前的数据插入失败: %s", progress.Rows+1, err.Error())
+		}
+		// 忽略或更新重复数据时影响行数与数据条数不一致，以数据条数为准
+		if importReq.DuplicateStrategy.NeedHandle() || affected <= 0 {
+			affected = int64(len(values))
+		}
+		progress.Inserted += affected
+		values = values[:0]
+
+		// 控制进度推送频率
+		if time.Since(lastSendTime) > time.Second {
+			d.sendImportProgress(la, importReq.ClientId, progress)
+			lastSendTime = time.Now()
+		}
+		return nil
+	}
+
+	err = func() error {
+		var lineNo int64
+		for {
+			row, err := reader.Read()
+			if err == io.EOF {
+				return insertBatch()
+			}
+			lineNo++
+			if lineNo == 1 && hasHeader {
+				if err != nil {
+					return errorx.NewBiz("读取表头失败: %s", err.Error())
+				}
+				continue
+			}
+			if err != nil && !dbi.IsDataFileRowError(err) {
+				return errorx.NewBiz("读取文件失败: %s", err.Error())
+			}
+			if ctx.Err() != nil {
+				return errors.New("数据导入已被终止")
+			}
+
+			progress.Rows++
+			var rowValues []any
+			var omits []bool
+			if err == nil {
+				rowValues, omits, err = toValues(row)
+			}
+			if err != nil {
+				progress.addRowError(lineNo, err)
+				if importReq.IgnoreError {
+					continue
+				}
+				return errorx.NewBiz("第%d行数据错误: %s", lineNo, err.Error())
+			}
+
+			if !slices.Equal(omits, batchOmits) {
+				if err := insertBatch(); err != nil {
+					return err
+				}
+				batchOmits = omits
+				batchColumns = make([]string, 0, len(quoteColumns))
+				for i, quoteColumn := range quoteColumns {
+					if !omits[i] {
+						batchColumns = append(batchColumns, quoteColumn)
+					}
+				}
+			}
+			values = append(values, rowValues)
+			if len(values) >= batchSize {
+				if err := insertBatch(); err != nil {
+					return err
+				}
+			}
+		}
+	}()
+	if err != nil {
+		tx.Rollback()
+		progress.Inserted = 0
+		progress.Err = err.Error()
+		return progress, err
+	}
+	if err := tx.Commit(); err != nil {
+		progress.Inserted = 0
+		progress.Err = err.Error()
+		return progress, err
+	}
+
+	execSqlReq.Sql = fmt.Sprintf("-- 导入文件[%s]数据至表[%s], 列: %s", importReq.Filename, tableName, strings.Join(quoteColumns, ", "))
+	sqlExec := createSqlExecRecord(ctx, execSqlReq)
+	sqlExec.Type = entity.DbSqlExecTypeInsert
+	sqlExec.Table = tableName
+	sqlExec.Res = fmt.Sprintf("rowsAffected: %d, errorRows: %d", progress.Inserted, progress.ErrorCount)
+	d.saveSqlExecLog(false, sqlExec)
+	return progress, nil
+}
+
+func (d *dbSqlExecAppImpl) sendImportProgress(la *model.LoginAccount, clientId string, progress *DbDataImportProgress) {
+	if la == nil {
+		return
+	}
+	ws.SendJsonMsg(ws.UserId(la.Id), clientId, msgdto.InfoSysMsg("数据导入进度", progress).WithCategory(DbDataImportProgressCategory))
+}
+
+func newImportFileReader(importReq *DbDataImportReq) (string, dbi.DataFileReader, error) {
+	fileType := importReq.FileType
+	if fileType == "" {
+		var err error
+		if fileType, err = dbi.GetDataFileType(importReq.Filename); err != nil {
+			return "", nil, err
+		}
+	}
+	reader, err := dbi.NewDataFileReader(fileType, importReq.File, importReq.FileSize)
+	if err != nil {
+		return "", nil, err
+	}
+	return fileType, reader, nil
+}
+
+func getImportTableColumns(importReq *DbDataImportReq) ([]dbi.Column, error) {
+	tableName := importReq.DbConn.Info.Type.RemoveQuote(importReq.Table)
+	columns, err := importReq.DbConn.GetDialect().GetColumns(tableName)
+	if err != nil {
+		return nil, errorx.NewBiz("获取表列信息失败: %s", err.Error())
+	}
+	if len(columns) == 0 {
+		return nil, errorx.NewBiz("表[%s]不存在", tableName)
+	}
+	return columns, nil
+}
@@ -0,0 +1,409 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/cache"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/jsonx"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+)
+
+type DataMaskRule interface {
+	base.App[*entity.DataMaskRule]
+
+	// 分页获取数据脱敏规则
+	GetPageList(condition *entity.DataMaskRuleQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	Save(ctx context.Context, rule *entity.DataMaskRule) error
+
+	// GetDataMasker 获取指定数据库及表的数据脱敏器，tables为空表示无法确定所查询的表，此时忽略规则的表名匹配
+	GetDataMasker(dbConn *dbi.DbConn, tables ...string) (*DataMasker, error)
+
+	// SaveRawDataAudit 记录查看未脱敏原始数据的审计日志
+	SaveRawDataAudit(ctx context.Context, dbConn *dbi.DbConn, sql string, columns []string)
+}
+
+// 启用的数据脱敏规则缓存key
+const dataMaskRulesCacheKey = "db:data-mask:rules"
+
+type dataMaskRuleAppImpl struct {
+	base.AppImpl[*entity.DataMaskRule, repository.DataMaskRule]
+
+	DbSqlExecRepo repository.DbSqlExec `inject:""`
+}
+
+func (app *dataMaskRuleAppImpl) InjectDbDataMaskRuleRepo(repo repository.DataMaskRule) {
+	app.Repo = repo
+}
+
+func (app *dataMaskRuleAppImpl) GetPageList(condition *entity.DataMaskRuleQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	return app.GetRepo().GetPageList(condition, pageParam, toEntity, orderBy...)
+}
+
+func (app *dataMaskRuleAppImpl) Save(ctx context.Context, rule *entity.DataMaskRule) error {
+	if _, ok := dataMaskFuncs[rule.MaskType]; !ok {
+		return errorx.NewBiz("不支持的脱敏类型: %s", rule.MaskType)
+	}
+	if len(splitPatterns(rule.ColumnPattern)) == 0 {
+		return errorx.NewBiz("字段名匹配规则不能为空")
+	}
+	for _, pattern := range splitPatterns(rule.DbPattern + "," + rule.TablePattern + "," + rule.ColumnPattern) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errorx.NewBiz("匹配规则[%s]有误", pattern)
+		}
+	}
+	var err error
+	if rule.Id == 0 {
+		err = app.Insert(ctx, rule)
+	} else {
+		err = app.UpdateById(ctx, rule)
+	}
+	if err != nil {
+		return err
+	}
+	cache.Del(dataMaskRulesCacheKey)
+	return nil
+}
+
+func (app *dataMaskRuleAppImpl) DeleteById(ctx context.Context, id uint64) error {
+	if err := app.AppImpl.DeleteById(ctx, id); err != nil {
+		return err
+	}
+	cache.Del(dataMaskRulesCacheKey)
+	return nil
+}
+
+func (app *dataMaskRuleAppImpl) GetDataMasker(dbConn *dbi.DbConn, tables ...string) (*DataMasker, error) {
+	rules, err := app.getEnableRules()
+	if err != nil {
+		return nil, errorx.NewBiz("获取数据脱敏规则失败: %s", err.Error())
+	}
+
+	dbInfo := dbConn.Info
+	masker := &DataMasker{columnMaskTypes: make(map[string]string), exprMaskTypes: make(map[string]string)}
+	for _, rule := range rules {
+		if !matchTagPath(rule.TagPath, dbInfo.TagPath) || !matchPatterns(rule.DbPattern, dbInfo.Database) {
+			continue
+		}
+		if len(tables) > 0 && !matchTables(rule.TablePattern, tables, dbInfo.Type) {
+			continue
+		}
+		masker.rules = append(masker.rules, rule)
+	}
+	return masker, nil
+}
+
+// 获取启用的脱敏规则，优先从缓存中获取，规则变更时清除缓存
+func (app *dataMaskRuleAppImpl) getEnableRules() ([]*entity.DataMaskRule, error) {
+	var rules []*entity.DataMaskRule
+	if cacheStr := cache.GetStr(dataMaskRulesCacheKey); cacheStr != "" {
+		if err := json.Unmarshal([]byte(cacheStr), &rules); err == nil {
+			return rules, nil
+		}
+	}
+	if err := app.ListByCond(&entity.DataMaskRule{Status: entity.DataMaskRuleStatusEnable}, &rules); err != nil {
+		return nil, err
+	}
+	cache.SetStr(dataMaskRulesCacheKey, jsonx.ToStr(rules), -1)
+	return rules, nil
+}
+
+func (app *dataMaskRuleAppImpl) SaveRawDataAudit(ctx context.Context, dbConn *dbi.DbConn, sql string, columns []string) {
+	record := &entity.DbSqlExec{
+		DbId:     dbConn.Info.Id,
+		Db:       dbConn.Info.Database,
+		Sql:      sql,
+		Table:    "-",
+		OldValue: "-",
+		Type:     entity.DbSqlExecTypeQuery,
+		Status:   entity.DbSqlExecStatusSuccess,
+		Remark:   fmt.Sprintf("查看未脱敏数据, 敏感字段: %s", strings.Join(columns, ",")),
+	}
+	record.FillBaseInfo(model.IdGenTypeNone, contextx.GetLoginAccount(ctx))
+	app.DbSqlExecRepo.Insert(ctx, record)
+}
+
+// DataMasker 数据脱敏器，根据匹配的脱敏规则对字段值进行脱敏
+type DataMasker struct {
+	rules           []*entity.DataMaskRule
+	columnMaskTypes map[string]string // 字段名 -> 脱敏类型，无需脱敏的字段为空字符串
+	exprMaskTypes   map[string]string // 小写的结果列名 -> 脱敏类型，由查询表达式对应的源字段解析而来
+}
+
+// GetMaskType 获取字段的脱敏类型，无需脱敏则返回空字符串
+func (dm *DataMasker) GetMaskType(column string) string {
+	if dm == nil || len(dm.rules) == 0 {
+		return ""
+	}
+	if maskType := dm.exprMaskTypes[strings.ToLower(column)]; maskType != "" {
+		return maskType
+	}
+	return dm.getColumnMaskType(column)
+}
+
+// 根据规则的字段名匹配获取字段的脱敏类型
+func (dm *DataMasker) getColumnMaskType(column string) string {
+	if maskType, ok := dm.columnMaskTypes[column]; ok {
+		return maskType
+	}
+
+	maskType := ""
+	for _, rule := range dm.rules {
+		if rule.ColumnPattern != "" && matchPatterns(rule.ColumnPattern, column) {
+			maskType = rule.MaskType
+			break
+		}
+	}
+	dm.columnMaskTypes[column] = maskType
+	return maskType
+}
+
+// ResolveSelectMaskTypes 将查询语句的结果列对应到所使用的源字段：结果列直接为敏感字段（含别名）时使用该字段的脱敏类型，
+// 为使用了敏感字段的表达式（如concat、substr等）时完全隐藏；敏感字段用于子查询或无法对应到结果列时返回错误
+func (dm *DataMasker) ResolveSelectMaskTypes(stmt sqlparser.Statement, columns []*dbi.QueryColumn) error {
+	if dm == nil || len(dm.rules) == 0 || stmt == nil {
+		return nil
+	}
+	// 子查询、派生表中的字段无法确定与结果列的对应关系
+	err := sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		var sub sqlparser.SQLNode
+		switch node := node.(type) {
+		case *sqlparser.Subquery:
+			sub = node.Select
+		case *sqlparser.DerivedTable:
+			sub = node.Select
+		default:
+			return true, nil
+		}
+		if column := dm.findMaskColumn(sub); column != "" {
+			return false, errorx.NewBiz("敏感字段[%s]不支持在子查询中使用", column)
+		}
+		return false, nil
+	}, stmt)
+	if err != nil {
+		return err
+	}
+
+	for i, sel := range getSelects(stmt) {
+		hasStar := slices.ContainsFunc(sel.SelectExprs, func(expr sqlparser.SelectExpr) bool {
+			_, ok := expr.(*sqlparser.StarExpr)
+			return ok
+		})
+		for j, expr := range sel.SelectExprs {
+			aliased, ok := expr.(*sqlparser.AliasedExpr)
+			if !ok {
+				continue
+			}
+			maskType := dm.getExprMaskType(aliased.Expr)
+			if maskType == "" {
+				continue
+			}
+			// 不含*时结果列与查询表达式一一对应，否则只能通过别名或字段名对应（union的结果列名以第一个查询为准）
+			var name string
+			if !hasStar && len(sel.SelectExprs) == len(columns) {
+				name = columns[j].Name
+			} else if i == 0 && !aliased.As.IsEmpty() {
+				name = aliased.As.String()
+			} else if col, ok := aliased.Expr.(*sqlparser.ColName); ok && i == 0 {
+				name = col.Name.String()
+			} else {
+				return errorx.NewBiz("查询表达式[%s]使用了敏感字段, 无法对应到结果列, 请勿与*同时使用", sqlparser.String(aliased.Expr))
+			}
+			dm.exprMaskTypes[strings.ToLower(name)] = maskType
+		}
+	}
+	return nil
+}
+
+// 获取查询表达式的脱敏类型，表达式为敏感字段则使用该字段的脱敏类型，使用了敏感字段的其他表达式则完全隐藏
+func (dm *DataMasker) getExprMaskType(expr sqlparser.Expr) string {
+	if col, ok := expr.(*sqlparser.ColName); ok {
+		return dm.getColumnMaskType(col.Name.String())
+	}
+	if dm.findMaskColumn(expr) != "" {
+		return entity.DataMaskTypeFull
+	}
+	return ""
+}
+
+// 查找语法树节点中使用的敏感字段，不存在则返回空
+func (dm *DataMasker) findMaskColumn(node sqlparser.SQLNode) string {
+	column := ""
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if column != "" {
+			return false, nil
+		}
+		if col, ok := node.(*sqlparser.ColName); ok && dm.getColumnMaskType(col.Name.String()) != "" {
+			column = col.Name.String()
+			return false, nil
+		}
+		return true, nil
+	}, node)
+	return column
+}
+
+// CheckTableDataQuery 校验表数据查询参数，敏感字段不可作为过滤、排序条件及键集分页的主键，以防通过条件推测原始数据
+func (dm *DataMasker) CheckTableDataQuery(query *dbi.TableDataQuery) error {
+	for _, cond := range query.Conds {
+		if dm.GetMaskType(cond.Column) != "" {
+			return errorx.NewBiz("敏感字段[%s]不支持作为过滤条件", cond.Column)
+		}
+	}
+	for _, order := range query.Orders {
+		if dm.GetMaskType(order.Column) != "" {
+			return errorx.NewBiz("敏感字段[%s]不支持排序", order.Column)
+		}
+	}
+	for column := range query.AfterKey {
+		if dm.GetMaskType(column) != "" {
+			return errorx.NewBiz("主键[%s]为敏感字段, 不支持键集分页", column)
+		}
+	}
+	return nil
+}
+
+// MaskColumns 获取需要脱敏的字段名
+func (dm *DataMasker) MaskColumns(columns []*dbi.QueryColumn) []string {
+	maskColumns := make([]string, 0)
+	for _, column := range columns {
+		if dm.GetMaskType(column.Name) != "" {
+			maskColumns = append(maskColumns, column.Name)
+		}
+	}
+	return maskColumns
+}
+
+// MaskRow 对数据行中需要脱敏的字段值进行脱敏
+func (dm *DataMasker) MaskRow(row map[string]any) {
+	if dm == nil || len(dm.rules) == 0 {
+		return
+	}
+	for column, value := range row {
+		if maskType := dm.GetMaskType(column); maskType != "" && value != nil {
+			row[column] = dataMaskFuncs[maskType](anyx.ToString(value))
+		}
+	}
+}
+
+// MaskRows 对所有数据行进行脱敏
+func (dm *DataMasker) MaskRows(rows []map[string]any) {
+	for _, row := range rows {
+		dm.MaskRow(row)
+	}
+}
+
+// 各脱敏类型对应的脱敏函数
+var dataMaskFuncs = map[string]func(string) string{
+	entity.DataMaskTypePhone: func(s string) string {
+		return maskString(s, 3, 4)
+	},
+	entity.DataMaskTypeIdCard: func(s string) string {
+		return maskString(s, 4, 4)
+	},
+	entity.DataMaskTypeEmail: func(s string) string {
+		name, domain, ok := strings.Cut(s, "@")
+		if !ok {
+			return maskString(s, 1, 0)
+		}
+		return maskString(name, 1, 0) + "@" + domain
+	},
+	entity.DataMaskTypeBankCard: func(s string) string {
+		return maskString(s, 0, 4)
+	},
+	entity.DataMaskTypeName: func(s string) string {
+		return maskString(s, 1, 0)
+	},
+	entity.DataMaskTypeFull: func(s string) string {
+		return "******"
+	},
+}
+
+// 获取sql语句中的表名，无法解析则返回空
+func getStmtTables(stmt sqlparser.Statement) []string {
+	if stmt == nil {
+		return nil
+	}
+	tables := make([]string, 0)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if tableName, ok := node.(sqlparser.TableName); ok && tableName.Name.String() != "" {
+			tables = append(tables, tableName.Name.String())
+		}
+		return true, nil
+	}, stmt)
+	return tables
+}
+
+// 获取select或union语句中的所有select
+func getSelects(stmt sqlparser.Statement) []*sqlparser.Select {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Select:
+		return []*sqlparser.Select{stmt}
+	case *sqlparser.Union:
+		return append(getSelects(stmt.Left), getSelects(stmt.Right)...)
+	}
+	return nil
+}
+
+// 判断数据库标签路径是否匹配规则的标签路径（前缀匹配），规则标签路径为空则匹配所有
+func matchTagPath(ruleTagPath string, tagPaths []string) bool {
+	if ruleTagPath == "" {
+		return true
+	}
+	for _, rtp := range strings.Split(ruleTagPath, ",") {
+		rtp = strings.TrimSpace(rtp)
+		for _, tagPath := range tagPaths {
+			if rtp != "" && strings.HasPrefix(tagPath, rtp) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 判断查询的表中是否有表匹配规则的表名
+func matchTables(tablePattern string, tables []string, dbType dbi.DbType) bool {
+	for _, table := range tables {
+		if matchPatterns(tablePattern, dbType.RemoveQuote(table)) {
+			return true
+		}
+	}
+	return false
+}
+
+// 判断名称是否匹配任一规则（忽略大小写，支持*通配符），规则为空则匹配所有
+func matchPatterns(patterns string, name string) bool {
+	ps := splitPatterns(patterns)
+	if len(ps) == 0 {
+		return true
+	}
+	name = strings.ToLower(name)
+	for _, pattern := range ps {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func splitPatterns(patterns string) []string {
+	ps := make([]string, 0)
+	for _, p := range strings.Split(patterns, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
@@ -0,0 +1,105 @@
+package application
+
+import (
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"testing"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_dataMaskFuncs(t *testing.T) {
+	cases := []struct {
+		maskType string
+		value    string
+		want     string
+	}{
+		{entity.DataMaskTypePhone, "13812345678", "138****5678"},
+		{entity.DataMaskTypeIdCard, "110101199001011234", "1101**********1234"},
+		{entity.DataMaskTypeEmail, "admin@example.com", "a****@example.com"},
+		{entity.DataMaskTypeEmail, "admin", "a****"},
+		{entity.DataMaskTypeBankCard, "6222021234567890", "************7890"},
+		{entity.DataMaskTypeName, "张三丰", "张**"},
+		{entity.DataMaskTypeFull, "secret", "******"},
+		{entity.DataMaskTypePhone, "123", "1**"},
+		{entity.DataMaskTypeName, "", ""},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, dataMaskFuncs[c.maskType](c.value), c.maskType+": "+c.value)
+	}
+}
+
+func Test_matchPatterns(t *testing.T) {
+	require.True(t, matchPatterns("", "any"))
+	require.True(t, matchPatterns("phone, *_mobile", "PHONE"))
+	require.True(t, matchPatterns("phone, *_mobile", "user_mobile"))
+	require.False(t, matchPatterns("phone, *_mobile", "mobile_no"))
+
+	require.True(t, matchTables("t_user*", []string{"t_order", "`t_user_info`"}, dbi.DbTypeMysql))
+	require.False(t, matchTables("t_user*", []string{"t_order"}, dbi.DbTypeMysql))
+
+	require.True(t, matchTagPath("", []string{"a/b/"}))
+	require.True(t, matchTagPath("x/, a/", []string{"a/b/"}))
+	require.False(t, matchTagPath("a/c/", []string{"a/b/"}))
+}
+
+func newTestDataMasker() *DataMasker {
+	return &DataMasker{
+		rules: []*entity.DataMaskRule{
+			{ColumnPattern: "phone,*_mobile", MaskType: entity.DataMaskTypePhone},
+			{ColumnPattern: "email", MaskType: entity.DataMaskTypeEmail},
+		},
+		columnMaskTypes: make(map[string]string),
+		exprMaskTypes:   make(map[string]string),
+	}
+}
+
+func Test_DataMaskerGetMaskType(t *testing.T) {
+	dm := newTestDataMasker()
+	require.Equal(t, entity.DataMaskTypePhone, dm.GetMaskType("PHONE"))
+	require.Equal(t, entity.DataMaskTypePhone, dm.GetMaskType("user_mobile"))
+	require.Equal(t, entity.DataMaskTypeEmail, dm.GetMaskType("email"))
+	require.Equal(t, "", dm.GetMaskType("name"))
+
+	var nilMasker *DataMasker
+	require.Equal(t, "", nilMasker.GetMaskType("phone"))
+
+	row := map[string]any{"phone": "13812345678", "email": nil, "name": "abc"}
+	dm.MaskRow(row)
+	require.Equal(t, map[string]any{"phone": "138****5678", "email": nil, "name": "abc"}, row)
+}
+
+func Test_DataMaskerResolveSelectMaskTypes(t *testing.T) {
+	dm := newTestDataMasker()
+	// select phone as p, name from t_user
+	stmt := &sqlparser.Select{
+		SelectExprs: sqlparser.SelectExprs{
+			&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewIdentifierCI("phone")}, As: sqlparser.NewIdentifierCI("p")},
+			&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewIdentifierCI("name")}},
+		},
+	}
+	columns := []*dbi.QueryColumn{{Name: "p"}, {Name: "name"}}
+	require.NoError(t, dm.ResolveSelectMaskTypes(stmt, columns))
+	require.Equal(t, entity.DataMaskTypePhone, dm.GetMaskType("P"))
+	require.Equal(t, "", dm.GetMaskType("name"))
+	require.Equal(t, []string{"p"}, dm.MaskColumns(columns))
+}
+
+func Test_DataMaskerCheckTableDataQuery(t *testing.T) {
+	dm := newTestDataMasker()
+	require.NoError(t, dm.CheckTableDataQuery(&dbi.TableDataQuery{
+		Conds:  []*dbi.TableDataCond{{Column: "name", Op: dbi.TableDataOpEq, Value: "a"}},
+		Orders: []*dbi.TableDataOrder{{Column: "id"}},
+	}))
+	require.Error(t, dm.CheckTableDataQuery(&dbi.TableDataQuery{
+		Conds: []*dbi.TableDataCond{{Column: "Phone", Op: dbi.TableDataOpLike, Value: "138%"}},
+	}))
+	require.Error(t, dm.CheckTableDataQuery(&dbi.TableDataQuery{
+		Orders: []*dbi.TableDataOrder{{Column: "email", Desc: true}},
+	}))
+	require.Error(t, dm.CheckTableDataQuery(&dbi.TableDataQuery{
+		Keyset:   true,
+		AfterKey: map[string]any{"phone": "13812345678"},
+	}))
+}
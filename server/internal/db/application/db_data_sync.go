@@ -3,7 +3,6 @@ package application
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/domain/entity"
@@ -14,6 +13,8 @@ import (
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/model"
 	"mayfly-go/pkg/scheduler"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -33,15 +34,26 @@ type DataSyncTask interface {
 
 	RemoveCronJobById(taskId uint64)
 
+	// RunCronJob 执行任务，从上次提交的更新字段值继续同步
 	RunCronJob(id uint64) error
 
+	// StopTask 停止（暂停）任务，若任务正在执行则取消执行，未提交的数据将回滚
+	StopTask(ctx context.Context, id uint64) error
+
 	GetTaskLogList(condition *entity.DataSyncLogQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	// Preview 预览同步数据，返回源数据经字段转换及过滤后的前limit条目标数据，showRawData为false时源数据的敏感字段先脱敏
+	Preview(ctx context.Context, task *entity.DataSyncTask, limit int, showRawData bool) ([]map[string]any, error)
 }
 
 type dataSyncAppImpl struct {
 	base.AppImpl[*entity.DataSyncTask, repository.DataSyncTask]
 
 	DbDataSyncLogRepo repository.DataSyncLog `inject:""`
+	DataMaskRuleApp   DataMaskRule           `inject:"DbDataMaskRuleApp"`
+
+	// 执行中的任务取消函数 key: taskId
+	runningTasks sync.Map
 }
 
 func (d *dataSyncAppImpl) InjectDbDataSyncTaskRepo(repo repository.DataSyncTask) {
@@ -53,6 +65,11 @@ func (app *dataSyncAppImpl) GetPageList(condition *entity.DataSyncTaskQuery, pag
 }
 
 func (app *dataSyncAppImpl) Save(ctx context.Context, taskEntity *entity.DataSyncTask) error {
+	// 保存时校验字段转换规则及数据过滤条件，避免执行时才发现配置错误
+	if _, err := newDataSyncTransformer(taskEntity); err != nil {
+		return err
+	}
+
 	var err error
 	if taskEntity.Id == 0 {
 		err = app.Insert(ctx, taskEntity)
@@ -83,6 +100,10 @@ func (app *dataSyncAppImpl) AddCronJob(taskEntity *entity.DataSyncTask) {
 	// 根据状态添加新的任务
 	if taskEntity.Status == entity.DataSyncTaskStatusEnable {
 		scheduler.AddFunByKey(key, taskEntity.TaskCron, func() {
+			// 已手动停止（暂停）的任务不定时执行，需手动执行以恢复
+			if task, err := app.GetById(new(entity.DataSyncTask), taskEntity.Id, "running_state"); err == nil && task.RunningState == entity.DataSyncTaskRunStateStop {
+				return
+			}
 			if err := app.RunCronJob(taskEntity.Id); err != nil {
 				logx.Errorf("定时执行数据同步任务失败: %s", err.Error())
 			}
@@ -113,34 +134,68 @@ func (app *dataSyncAppImpl) RunCronJob(id uint64) error {
 	if task.RunningState == entity.DataSyncTaskRunStateRunning {
 		return errorx.NewBiz("该任务正在执行中")
 	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, loaded := app.runningTasks.LoadOrStore(id, cancel); loaded {
+		cancel()
+		return errorx.NewBiz("该任务正在执行中")
+	}
 	// 开始运行时，修改状态为运行中
 	app.changeRunningState(id, entity.DataSyncTaskRunStateRunning)
 
 	logx.Infof("开始执行数据同步任务：%s => %s", task.TaskName, task.TaskKey)
 
 	go func() {
-		// 通过占位符格式化sql
+		defer func() {
+			app.runningTasks.Delete(id)
+			cancel()
+		}()
+
+		// 通过占位符格式化sql，从上次提交的更新字段值继续同步
 		updSql := ""
 		orderSql := ""
 		if task.UpdFieldVal != "0" && task.UpdFieldVal != "" && task.UpdField != "" {
 			updSql = fmt.Sprintf("and %s > '%s'", task.UpdField, task.UpdFieldVal)
+		}
+		if task.UpdField != "" {
 			orderSql = "order by " + task.UpdField + " asc "
 		}
 		// 组装查询sql
 		sql := fmt.Sprintf("select * from (%s) t where 1 = 1 %s %s", task.DataSql, updSql, orderSql)
 
-		log, err := app.doDataSync(sql, task)
-		if err != nil {
-			log.ErrText = fmt.Sprintf("执行失败: %s", err.Error())
-			log.Status = entity.DataSyncTaskStateFail
-			app.endRunning(task, log)
+		log, err := app.doDataSync(ctx, sql, task)
+		if err == nil {
+			return
+		}
+		log.Status = entity.DataSyncTaskStateFail
+		if ctx.Err() != nil {
+			log.ErrText = fmt.Sprintf("任务已被手动停止，已提交：%d条", log.ResNum)
+			app.endRunning(task, log, entity.DataSyncTaskRunStateStop)
+			return
 		}
+		log.ErrText = fmt.Sprintf("执行失败: %s", err.Error())
+		app.endRunning(task, log, entity.DataSyncTaskRunStateReady)
 	}()
 
 	return nil
 }
 
-func (app *dataSyncAppImpl) doDataSync(sql string, task *entity.DataSyncTask) (*entity.DataSyncLog, error) {
+func (app *dataSyncAppImpl) StopTask(ctx context.Context, id uint64) error {
+	cancel, ok := app.runningTasks.Load(id)
+	if !ok {
+		return errorx.NewBiz("该任务未在执行中")
+	}
+	task := new(entity.DataSyncTask)
+	task.Id = id
+	task.RunningState = entity.DataSyncTaskRunStateStop
+	if err := app.UpdateById(ctx, task); err != nil {
+		return err
+	}
+	cancel.(context.CancelFunc)()
+	return nil
+}
+
+func (app *dataSyncAppImpl) doDataSync(ctx context.Context, sql string, task *entity.DataSyncTask) (*entity.DataSyncLog, error) {
 	now := time.Now()
 	syncLog := &entity.DataSyncLog{
 		TaskId:      task.Id,
@@ -173,21 +228,29 @@ func (app *dataSyncAppImpl) doDataSync(sql string, task *entity.DataSyncTask) (*
 
 	srcDialect := srcConn.GetDialect()
 
-	// task.FieldMap为json数组字符串 [{"src":"id","target":"id"}]，转为map
-	var fieldMap []map[string]string
-	err = json.Unmarshal([]byte(task.FieldMap), &fieldMap)
+	// task.FieldMap为json数组字符串 [{"src":"id","target":"id","transform":"","param":""}]
+	transformer, err := newDataSyncTransformer(task)
 	if err != nil {
-		return syncLog, errorx.NewBiz("解析字段映射json出错: %s", err.Error())
+		targetDbTx.Rollback()
+		return syncLog, err
 	}
 	var updFieldType dbi.DataType
 
+	// 是否每批次提交一次事务，否则所有数据在一个事务中提交
+	batchCommit := task.BatchCommit == entity.DataSyncTaskBatchCommitYes
 	// 记录本次同步数据总数
 	total := 0
 	batchSize := task.PageSize
 	result := make([]map[string]any, 0)
 	var queryColumns []*dbi.QueryColumn
+	// 数据按更新字段升序查询，同一更新字段值的数据可能跨批次，故仅在更新字段值变化时推进同步进度：
+	// checkpointVal之前（含）的数据均已遍历，继续同步时从大于该值的数据开始不会遗漏数据
+	lastUpdFieldVal, checkpointVal := "", ""
 
-	err = srcConn.WalkQueryRows(context.Background(), sql, func(row map[string]any, columns []*dbi.QueryColumn) error {
+	err = srcConn.WalkQueryRows(ctx, sql, func(row map[string]any, columns []*dbi.QueryColumn) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if len(queryColumns) == 0 {
 			queryColumns = columns
 
@@ -203,14 +266,37 @@ func (app *dataSyncAppImpl) doDataSync(sql string, task *entity.DataSyncTask) (*
 
 		total++
 		result = append(result, row)
+		if task.UpdField != "" {
+			updFieldVal := srcDialect.FormatStrData(fmt.Sprintf("%v", row[task.UpdField]), updFieldType)
+			if updFieldVal != lastUpdFieldVal {
+				checkpointVal = lastUpdFieldVal
+				lastUpdFieldVal = updFieldVal
+			}
+		}
 		if total%batchSize == 0 {
-			if err := app.srcData2TargetDb(result, fieldMap, updFieldType, task, srcDialect, targetConn, targetDbTx); err != nil {
+			if err := app.srcData2TargetDb(result, transformer, task, targetConn, targetDbTx); err != nil {
 				return err
 			}
 
+			if batchCommit {
+				if err := targetDbTx.Commit(); err != nil {
+					return errorx.NewBiz("数据同步-目标数据库事务提交失败: %s", err.Error())
+				}
+				// 保存同步进度，停止或失败后可从该位置继续同步
+				if checkpointVal != "" {
+					task.UpdFieldVal = checkpointVal
+					app.saveUpdFieldVal(task)
+				}
+				syncLog.ResNum = total
+
+				var err error
+				if targetDbTx, err = targetConn.Begin(); err != nil {
+					return errorx.NewBiz("开启目标数据库事务失败: %s", err.Error())
+				}
+			}
+
 			// 记录当前已同步的数据量
 			syncLog.ErrText = fmt.Sprintf("本次任务执行中，已同步：%d条", total)
-			syncLog.ResNum = total
 			app.saveLog(syncLog)
 
 			result = result[:0]
@@ -219,6 +305,10 @@ func (app *dataSyncAppImpl) doDataSync(sql string, task *entity.DataSyncTask) (*
 		return nil
 	})
 
+	// 任务被停止时，游标遍历会直接结束，需判断上下文是否已取消
+	if err == nil {
+		err = ctx.Err()
+	}
 	if err != nil {
 		targetDbTx.Rollback()
 		return syncLog, err
@@ -226,7 +316,7 @@ func (app *dataSyncAppImpl) doDataSync(sql string, task *entity.DataSyncTask) (*
 
 	// 处理剩余的数据
 	if len(result) > 0 {
-		if err := app.srcData2TargetDb(result, fieldMap, updFieldType, task, srcDialect, targetConn, targetDbTx); err != nil {
+		if err := app.srcData2TargetDb(result, transformer, task, targetConn, targetDbTx); err != nil {
 			targetDbTx.Rollback()
 			return syncLog, err
 		}
@@ -235,75 +325,142 @@ func (app *dataSyncAppImpl) doDataSync(sql string, task *entity.DataSyncTask) (*
 	if err := targetDbTx.Commit(); err != nil {
 		return syncLog, errorx.NewBiz("数据同步-目标数据库事务提交失败: %s", err.Error())
 	}
+	// 所有数据均已同步，同步进度推进至最后一条数据
+	if lastUpdFieldVal != "" {
+		task.UpdFieldVal = lastUpdFieldVal
+	}
 	logx.Infof("同步任务：[%s]，执行完毕，保存记录成功：[%d]条", task.TaskName, total)
 
 	// 保存执行成功日志
 	syncLog.ErrText = fmt.Sprintf("本次任务执行成功，新数据：%d 条", total)
 	syncLog.Status = entity.DataSyncTaskStateSuccess
 	syncLog.ResNum = total
-	app.endRunning(task, syncLog)
+	app.endRunning(task, syncLog, entity.DataSyncTaskRunStateReady)
 
 	return syncLog, nil
 }
 
-func (app *dataSyncAppImpl) srcData2TargetDb(srcRes []map[string]any, fieldMap []map[string]string, updFieldType dbi.DataType, task *entity.DataSyncTask, srcDialect dbi.Dialect, targetDbConn *dbi.DbConn, targetDbTx *sql.Tx) error {
+func (app *dataSyncAppImpl) Preview(ctx context.Context, task *entity.DataSyncTask, limit int, showRawData bool) ([]map[string]any, error) {
+	transformer, err := newDataSyncTransformer(task)
+	if err != nil {
+		return nil, err
+	}
+
+	srcConn, err := GetDbApp().GetDbConn(uint64(task.SrcDbId), task.SrcDbName)
+	if err != nil {
+		return nil, errorx.NewBiz("连接源数据库失败: %s", err.Error())
+	}
+	// sql会作为子查询执行，需校验为单条查询语句，防止拼接执行其他语句
+	stmt, err := ParseSingleSelect(srcConn.Info.Type, task.DataSql)
+	if err != nil {
+		return nil, err
+	}
+	var masker *DataMasker
+	if !showRawData {
+		if masker, err = app.DataMaskRuleApp.GetDataMasker(srcConn); err != nil {
+			return nil, err
+		}
+	}
+
+	// 获取到足够的数据后取消查询，不再遍历剩余结果集
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := make([]map[string]any, 0, limit)
+	err = srcConn.WalkQueryRowsWithColumns(ctx, fmt.Sprintf("select * from (%s) t", task.DataSql), func(columns []*dbi.QueryColumn) error {
+		// 解析查询列对应的敏感字段，无法解析sql时按查询列名匹配
+		if err := masker.ResolveSelectMaskTypes(stmt, columns); err != nil {
+			return err
+		}
+		if len(masker.MaskColumns(columns)) == 0 {
+			masker = nil
+		}
+		return nil
+	}, func(row map[string]any, columns []*dbi.QueryColumn) error {
+		if len(result) >= limit {
+			cancel()
+			return nil
+		}
+		masker.MaskRow(row)
+		targetRow, ok, err := transformer.Transform(row)
+		if err != nil {
+			return err
+		}
+		if ok {
+			result = append(result, targetRow)
+		}
+		return nil
+	})
+	if err != nil && len(result) < limit {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (app *dataSyncAppImpl) srcData2TargetDb(srcRes []map[string]any, transformer *dataSyncTransformer, task *entity.DataSyncTask, targetDbConn *dbi.DbConn, targetDbTx *sql.Tx) error {
 	var data = make([]map[string]any, 0)
 
-	// 遍历res，组装插入sql
+	// 遍历res，根据字段映射及转换规则组装目标表数据，并丢弃不满足过滤条件的数据
 	for _, record := range srcRes {
-		var rowData = make(map[string]any)
-		// 遍历字段映射, target字段的值为src字段取值
-		for _, item := range fieldMap {
-			srcField := item["src"]
-			targetField := item["target"]
-			// target字段的值为src字段取值
-			rowData[targetField] = record[srcField]
+		rowData, ok, err := transformer.Transform(record)
+		if err != nil {
+			return err
+		}
+		if ok {
+			data = append(data, rowData)
 		}
-
-		data = append(data, rowData)
 	}
 
-	updFieldVal := fmt.Sprintf("%v", srcRes[len(srcRes)-1][task.UpdField])
-	updFieldVal = srcDialect.FormatStrData(updFieldVal, updFieldType)
-	task.UpdFieldVal = updFieldVal
+	// 本批次数据均被过滤
+	if len(data) == 0 {
+		return nil
+	}
 
 	// 获取目标库字段数组
 	targetWrapColumns := make([]string, 0)
-	// 获取源库字段数组
-	srcColumns := make([]string, 0)
-	for _, item := range fieldMap {
-		targetField := item["target"]
-		srcField := item["target"]
-		targetWrapColumns = append(targetWrapColumns, targetDbConn.Info.Type.QuoteIdentifier(targetField))
-		srcColumns = append(srcColumns, srcField)
+	// 获取目标字段数组
+	targetColumns := make([]string, 0)
+	for _, item := range transformer.fieldMaps {
+		targetWrapColumns = append(targetWrapColumns, targetDbConn.Info.Type.QuoteIdentifier(item.Target))
+		targetColumns = append(targetColumns, item.Target)
 	}
 
-	// 从目标库数据中取出源库字段对应的值
+	// 从目标表数据中取出各目标字段对应的值
 	values := make([][]any, 0)
 	for _, record := range data {
 		rawValue := make([]any, 0)
-		for _, column := range srcColumns {
+		for _, column := range targetColumns {
 			rawValue = append(rawValue, record[column])
 		}
 		values = append(values, rawValue)
 	}
 
-	// 目标数据库执行sql批量插入
-	_, err := targetDbConn.GetDialect().BatchInsert(targetDbTx, task.TargetTableName, targetWrapColumns, values)
-	if err != nil {
-		return err
+	// 判断数据重复的字段
+	var keyColumns []string
+	if task.DuplicateKey != "" {
+		for _, key := range strings.Split(task.DuplicateKey, ",") {
+			keyColumns = append(keyColumns, targetDbConn.Info.Type.QuoteIdentifier(strings.TrimSpace(key)))
+		}
 	}
 
-	// 运行过程中，判断状态是否为已关闭，是则结束运行，否则继续运行
-	taskParam, _ := app.GetById(new(entity.DataSyncTask), task.Id)
-	if taskParam.RunningState == entity.DataSyncTaskRunStateStop {
-		return errorx.NewBiz("该任务已被手动终止")
+	// 目标数据库执行sql批量插入，根据重复策略忽略或更新已存在的数据，使重复执行同步时结果保持一致
+	_, err := targetDbConn.GetDialect().BatchInsert(targetDbTx, task.TargetTableName, targetWrapColumns, values, dbi.DuplicateStrategy(task.DuplicateStrategy), keyColumns...)
+	if err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func (app *dataSyncAppImpl) endRunning(taskEntity *entity.DataSyncTask, log *entity.DataSyncLog) {
+// 保存当前已提交的更新字段值
+func (app *dataSyncAppImpl) saveUpdFieldVal(taskEntity *entity.DataSyncTask) {
+	task := new(entity.DataSyncTask)
+	task.Id = taskEntity.Id
+	task.UpdFieldVal = taskEntity.UpdFieldVal
+	_ = app.UpdateById(context.Background(), task)
+}
+
+func (app *dataSyncAppImpl) endRunning(taskEntity *entity.DataSyncTask, log *entity.DataSyncLog, runningState int8) {
 	logx.Info(log.ErrText)
 
 	state := log.Status
@@ -313,7 +470,7 @@ func (app *dataSyncAppImpl) endRunning(taskEntity *entity.DataSyncTask, log *ent
 	if state == entity.DataSyncTaskStateSuccess {
 		task.UpdFieldVal = taskEntity.UpdFieldVal
 	}
-	task.RunningState = entity.DataSyncTaskRunStateReady
+	task.RunningState = runningState
 	// 运行失败之后设置任务状态为禁用
 	//if state == entity.DataSyncTaskStateFail {
 	//	taskEntity.Status = entity.DataSyncTaskStatusDisable
@@ -0,0 +1,288 @@
+package application
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 数据同步字段转换器，根据字段映射的转换规则及数据行过滤条件，将源数据转换为目标表数据
+type dataSyncTransformer struct {
+	fieldMaps []*entity.DataSyncFieldMap
+	filters   []*entity.DataSyncRowFilter
+	exprTmpls map[string]*template.Template // 目标字段 -> 表达式模板
+}
+
+func newDataSyncTransformer(task *entity.DataSyncTask) (*dataSyncTransformer, error) {
+	fieldMaps, err := task.GetFieldMaps()
+	if err != nil {
+		return nil, errorx.NewBiz("解析字段映射json出错: %s", err.Error())
+	}
+	filters, err := task.GetRowFilters()
+	if err != nil {
+		return nil, errorx.NewBiz("解析数据过滤条件json出错: %s", err.Error())
+	}
+
+	for _, filter := range filters {
+		if filter.Field == "" {
+			return nil, errorx.NewBiz("数据过滤条件的字段不能为空")
+		}
+		switch filter.Op {
+		case entity.DataSyncFilterOpEq, entity.DataSyncFilterOpNe, entity.DataSyncFilterOpGt, entity.DataSyncFilterOpGe, entity.DataSyncFilterOpLt, entity.DataSyncFilterOpLe,
+			entity.DataSyncFilterOpLike, entity.DataSyncFilterOpNull, entity.DataSyncFilterOpNotNull:
+		default:
+			return nil, errorx.NewBiz("字段[%s]过滤条件操作符[%s]不支持", filter.Field, filter.Op)
+		}
+	}
+
+	dt := &dataSyncTransformer{fieldMaps: fieldMaps, filters: filters, exprTmpls: make(map[string]*template.Template)}
+	for _, fm := range fieldMaps {
+		if fm.Target == "" {
+			return nil, errorx.NewBiz("字段映射的目标字段不能为空")
+		}
+		switch fm.Transform {
+		case "", entity.DataSyncTransformConstant, entity.DataSyncTransformDefault, entity.DataSyncTransformHash:
+		case entity.DataSyncTransformMask:
+			if _, _, err := parseMaskParam(fm.Param); err != nil {
+				return nil, errorx.NewBiz("字段[%s]脱敏参数错误: %s", fm.Target, err.Error())
+			}
+		case entity.DataSyncTransformDateFormat:
+			if fm.Param == "" {
+				return nil, errorx.NewBiz("字段[%s]未指定日期格式", fm.Target)
+			}
+		case entity.DataSyncTransformExpr:
+			tmpl, err := template.New(fm.Target).Option("missingkey=zero").Parse(fm.Param)
+			if err != nil {
+				return nil, errorx.NewBiz("字段[%s]表达式错误: %s", fm.Target, err.Error())
+			}
+			dt.exprTmpls[fm.Target] = tmpl
+		default:
+			return nil, errorx.NewBiz("字段[%s]转换规则[%s]不支持", fm.Target, fm.Transform)
+		}
+	}
+	return dt, nil
+}
+
+// Transform 转换源数据行，返回目标表数据行，若数据行不满足过滤条件则返回false
+func (dt *dataSyncTransformer) Transform(srcRow map[string]any) (map[string]any, bool, error) {
+	if !dt.match(srcRow) {
+		return nil, false, nil
+	}
+
+	targetRow := make(map[string]any, len(dt.fieldMaps))
+	for _, fm := range dt.fieldMaps {
+		val, err := dt.transformField(fm, srcRow)
+		if err != nil {
+			return nil, false, errorx.NewBiz("字段[%s]转换失败: %s", fm.Target, err.Error())
+		}
+		targetRow[fm.Target] = val
+	}
+	return targetRow, true, nil
+}
+
+func (dt *dataSyncTransformer) transformField(fm *entity.DataSyncFieldMap, srcRow map[string]any) (any, error) {
+	val := srcRow[fm.Src]
+
+	switch fm.Transform {
+	case entity.DataSyncTransformConstant:
+		return fm.Param, nil
+	case entity.DataSyncTransformDefault:
+		if isNullValue(val) {
+			return fm.Param, nil
+		}
+		return val, nil
+	case entity.DataSyncTransformMask:
+		if isNullValue(val) {
+			return val, nil
+		}
+		prefix, suffix, _ := parseMaskParam(fm.Param)
+		return maskString(anyx.ToString(val), prefix, suffix), nil
+	case entity.DataSyncTransformHash:
+		if isNullValue(val) {
+			return val, nil
+		}
+		return hashString(anyx.ToString(val), fm.Param)
+	case entity.DataSyncTransformDateFormat:
+		if isNullValue(val) {
+			return val, nil
+		}
+		return formatDate(val, fm.Param)
+	case entity.DataSyncTransformExpr:
+		var buf bytes.Buffer
+		if err := dt.exprTmpls[fm.Target].Execute(&buf, srcRow); err != nil {
+			return nil, err
+		}
+		return buf.String(), nil
+	default:
+		return val, nil
+	}
+}
+
+// 判断数据行是否满足所有过滤条件
+func (dt *dataSyncTransformer) match(srcRow map[string]any) bool {
+	for _, filter := range dt.filters {
+		val := srcRow[filter.Field]
+		switch filter.Op {
+		case entity.DataSyncFilterOpNull:
+			if !isNullValue(val) {
+				return false
+			}
+			continue
+		case entity.DataSyncFilterOpNotNull:
+			if isNullValue(val) {
+				return false
+			}
+			continue
+		}
+
+		if val == nil {
+			return false
+		}
+		strVal := anyx.ToString(val)
+		if filter.Op == entity.DataSyncFilterOpLike {
+			if !strings.Contains(strVal, filter.Value) {
+				return false
+			}
+			continue
+		}
+
+		cmp := compareValue(strVal, filter.Value)
+		var ok bool
+		switch filter.Op {
+		case entity.DataSyncFilterOpEq:
+			ok = cmp == 0
+		case entity.DataSyncFilterOpNe:
+			ok = cmp != 0
+		case entity.DataSyncFilterOpGt:
+			ok = cmp > 0
+		case entity.DataSyncFilterOpGe:
+			ok = cmp >= 0
+		case entity.DataSyncFilterOpLt:
+			ok = cmp < 0
+		case entity.DataSyncFilterOpLe:
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// 比较两个值，均为数字则按数值比较，否则按字符串比较
+func compareValue(a, b string) int {
+	af, aerr := strconv.ParseFloat(a, 64)
+	bf, berr := strconv.ParseFloat(b, 64)
+	if aerr == nil && berr == nil {
+		switch {
+		case af > bf:
+			return 1
+		case af < bf:
+			return -1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func isNullValue(val any) bool {
+	return val == nil || val == ""
+}
+
+// 解析脱敏参数，如：3,4 表示保留前3位及后4位
+func parseMaskParam(param string) (int, int, error) {
+	if param == "" {
+		return 0, 0, nil
+	}
+	parts := strings.Split(param, ",")
+	prefix, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || prefix < 0 {
+		return 0, 0, fmt.Errorf("保留位数需为非负整数，如：3,4")
+	}
+	suffix := 0
+	if len(parts) > 1 {
+		suffix, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || suffix < 0 {
+			return 0, 0, fmt.Errorf("保留位数需为非负整数，如：3,4")
+		}
+	}
+	return prefix, suffix, nil
+}
+
+func maskString(str string, prefix, suffix int) string {
+	runes := []rune(str)
+	if prefix+suffix >= len(runes) {
+		// 保留位数超过字符串长度，则仅保留首字符
+		if len(runes) <= 1 {
+			return strings.Repeat("*", len(runes))
+		}
+		return string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return string(runes[:prefix]) + strings.Repeat("*", len(runes)-prefix-suffix) + string(runes[len(runes)-suffix:])
+}
+
+func hashString(str string, algorithm string) (string, error) {
+	var h hash.Hash
+	switch strings.ToLower(algorithm) {
+	case "md5":
+		h = md5.New()
+	case "sha1":
+		h = sha1.New()
+	case "", "sha256":
+		h = sha256.New()
+	default:
+		return "", fmt.Errorf("不支持的hash算法: %s", algorithm)
+	}
+	h.Write([]byte(str))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// 源数据可能的日期格式
+var srcDateLayouts = []string{
+	time.RFC3339Nano,
+	time.DateTime,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	time.DateOnly,
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"20060102150405",
+	"20060102",
+}
+
+// 日期格式占位符 -> go日期layout
+var dateFormatReplacer = strings.NewReplacer(
+	"yyyy", "2006",
+	"MM", "01",
+	"dd", "02",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+	"SSS", "000",
+)
+
+func formatDate(val any, format string) (string, error) {
+	if t, ok := val.(time.Time); ok {
+		return t.Format(dateFormatReplacer.Replace(format)), nil
+	}
+
+	str := strings.TrimSpace(anyx.ToString(val))
+	for _, layout := range srcDateLayouts {
+		if t, err := time.ParseInLocation(layout, str, time.Local); err == nil {
+			return t.Format(dateFormatReplacer.Replace(format)), nil
+		}
+	}
+	return "", fmt.Errorf("无法解析日期: %s", str)
+}
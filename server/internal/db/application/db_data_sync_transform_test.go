@@ -0,0 +1,147 @@
+package application
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_maskString(t *testing.T) {
+	cases := []struct {
+		str    string
+		prefix int
+		suffix int
+		want   string
+	}{
+		{"13812345678", 3, 4, "138****5678"},
+		{"张三丰", 1, 0, "张**"},
+		{"abc", 2, 2, "a**"},
+		{"a", 0, 0, "*"},
+		{"", 1, 1, ""},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, maskString(c.str, c.prefix, c.suffix), c.str)
+	}
+}
+
+func Test_formatDate(t *testing.T) {
+	cases := []struct {
+		val    any
+		format string
+		want   string
+		err    bool
+	}{
+		{time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), "yyyy/MM/dd HH:mm:ss", "2024/01/02 03:04:05", false},
+		{"2024-01-02 03:04:05", "yyyyMMdd", "20240102", false},
+		{"2024/01/02", "yyyy-MM-dd HH:mm", "2024-01-02 00:00", false},
+		{"20240102150405", "HH:mm:ss", "15:04:05", false},
+		{"abc", "yyyy", "", true},
+	}
+	for _, c := range cases {
+		got, err := formatDate(c.val, c.format)
+		if c.err {
+			require.Error(t, err, c.val)
+			continue
+		}
+		require.NoError(t, err, c.val)
+		require.Equal(t, c.want, got, c.val)
+	}
+}
+
+func Test_compareValue(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"10", "9", 1},
+		{"1.5", "1.50", 0},
+		{"-1", "2", -1},
+		{"b", "a", 1},
+		{"10", "9a", -1},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, compareValue(c.a, c.b), c.a+" "+c.b)
+	}
+}
+
+func Test_dataSyncTransformerMatch(t *testing.T) {
+	row := map[string]any{"age": 18, "name": "mayfly", "remark": nil}
+	cases := []struct {
+		op    string
+		field string
+		value string
+		want  bool
+	}{
+		{entity.DataSyncFilterOpEq, "age", "18", true},
+		{entity.DataSyncFilterOpNe, "age", "18", false},
+		{entity.DataSyncFilterOpGt, "age", "9", true},
+		{entity.DataSyncFilterOpGe, "age", "18", true},
+		{entity.DataSyncFilterOpLt, "age", "18", false},
+		{entity.DataSyncFilterOpLe, "age", "18", true},
+		{entity.DataSyncFilterOpLike, "name", "fly", true},
+		{entity.DataSyncFilterOpNull, "remark", "", true},
+		{entity.DataSyncFilterOpNotNull, "remark", "", false},
+		{entity.DataSyncFilterOpEq, "remark", "", false},
+	}
+	for _, c := range cases {
+		dt := &dataSyncTransformer{filters: []*entity.DataSyncRowFilter{{Field: c.field, Op: c.op, Value: c.value}}}
+		require.Equal(t, c.want, dt.match(row), c.field+" "+c.op+" "+c.value)
+	}
+}
+
+func Test_newDataSyncTransformer(t *testing.T) {
+	cases := []struct {
+		fieldMap  string
+		rowFilter string
+		err       bool
+	}{
+		{`[{"src":"id","target":"id"}]`, `[{"field":"age","op":">=","value":"18"}]`, false},
+		{`[{"src":"id","target":"id"}]`, `[{"field":"age","op":"in","value":"18"}]`, true},
+		{`[{"src":"id","target":"id"}]`, `[{"field":"","op":"=","value":"18"}]`, true},
+		{`[{"src":"id","target":""}]`, ``, true},
+		{`[{"src":"phone","target":"phone","transform":"mask","param":"a,4"}]`, ``, true},
+		{`[{"src":"name","target":"name","transform":"expr","param":"{{.name"}]`, ``, true},
+		{`[{"src":"name","target":"name","transform":"upper"}]`, ``, true},
+	}
+	for _, c := range cases {
+		_, err := newDataSyncTransformer(&entity.DataSyncTask{FieldMap: c.fieldMap, RowFilter: c.rowFilter})
+		if c.err {
+			require.Error(t, err, c.fieldMap+c.rowFilter)
+		} else {
+			require.NoError(t, err, c.fieldMap+c.rowFilter)
+		}
+	}
+}
+
+func Test_dataSyncTransformerTransform(t *testing.T) {
+	dt, err := newDataSyncTransformer(&entity.DataSyncTask{
+		FieldMap: `[{"src":"id","target":"id"},
+			{"src":"phone","target":"phone","transform":"mask","param":"3,4"},
+			{"src":"remark","target":"remark","transform":"default","param":"-"},
+			{"src":"name","target":"name_md5","transform":"hash","param":"md5"},
+			{"target":"source","transform":"constant","param":"mayfly"},
+			{"target":"full_name","transform":"expr","param":"{{.first_name}}-{{.last_name}}"}]`,
+		RowFilter: `[{"field":"id","op":">","value":"1"}]`,
+	})
+	require.NoError(t, err)
+
+	row := map[string]any{"id": 2, "phone": "13812345678", "remark": nil, "name": "abc", "first_name": "may", "last_name": "fly"}
+	target, ok, err := dt.Transform(row)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{
+		"id":        2,
+		"phone":     "138****5678",
+		"remark":    "-",
+		"name_md5":  "900150983cd24fb0d6963f7d28e17f72",
+		"source":    "mayfly",
+		"full_name": "may-fly",
+	}, target)
+
+	row["id"] = 1
+	_, ok, err = dt.Transform(row)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,281 @@
+package application
+
+import (
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/infrastructure/cache"
+	"mayfly-go/pkg/logx"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	dbMetaRefreshInterval = 5 * time.Minute // 元数据缓存超过该时间则后台刷新
+	dbMetaDroppedRetain   = 24 * time.Hour  // 已删除表记录的保留时长
+)
+
+// 增量表提示信息
+type HintTablesChange struct {
+	Version int64               `json:"version"` // 当前版本，下次增量获取时传入
+	Full    bool                `json:"full"`    // 是否为全量信息，为true时需替换客户端已有的所有表信息
+	Tables  map[string][]string `json:"tables"`  // 变更或新增的表 -> 字段提示信息
+	Dropped []string            `json:"dropped"` // 已删除的表
+}
+
+// 库元数据缓存，缓存sql编辑器所需的表及字段提示信息
+type DbMetaCache interface {
+	// 获取所有表的字段提示信息，key = 表名，value = 字段提示信息
+	GetHintTables(dbConn *dbi.DbConn) (map[string][]string, error)
+
+	// 获取指定版本之后变更的表提示信息，version为0或已过期则返回全量信息
+	GetHintTablesChange(dbConn *dbi.DbConn, version int64) (*HintTablesChange, error)
+
+	// 使缓存失效，如执行ddl后调用，下次获取时将重新加载
+	Invalidate(dbConn *dbi.DbConn)
+}
+
+type dbMetaCacheAppImpl struct {
+	DbApp Db `inject:""`
+
+	refreshing sync.Map           // 正在后台刷新的连接id
+	loading    singleflight.Group // 合并同一连接并发的同步加载
+}
+
+func (d *dbMetaCacheAppImpl) GetHintTables(dbConn *dbi.DbConn) (map[string][]string, error) {
+	dbMeta, err := d.getDbMeta(dbConn)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]string, len(dbMeta.Tables))
+	for tableName, table := range dbMeta.Tables {
+		res[tableName] = table.Columns
+	}
+	return res, nil
+}
+
+func (d *dbMetaCacheAppImpl) GetHintTablesChange(dbConn *dbi.DbConn, version int64) (*HintTablesChange, error) {
+	dbMeta, err := d.getDbMeta(dbConn)
+	if err != nil {
+		return nil, err
+	}
+	return newHintTablesChange(dbMeta, version), nil
+}
+
+// 根据客户端已有的版本计算增量表提示信息
+func newHintTablesChange(dbMeta *cache.DbMeta, version int64) *HintTablesChange {
+	res := &HintTablesChange{
+		Version: dbMeta.Version,
+		Full:    version <= 0 || version < dbMeta.BaseVersion,
+		Tables:  make(map[string][]string),
+		Dropped: make([]string, 0),
+	}
+	for tableName, table := range dbMeta.Tables {
+		if res.Full || table.Version > version {
+			res.Tables[tableName] = table.Columns
+		}
+	}
+	if res.Full {
+		return res
+	}
+	for tableName, droppedVersion := range dbMeta.Dropped {
+		if droppedVersion > version {
+			res.Dropped = append(res.Dropped, tableName)
+		}
+	}
+	return res
+}
+
+func (d *dbMetaCacheAppImpl) Invalidate(dbConn *dbi.DbConn) {
+	if dbConn == nil || dbConn.Id == "" {
+		return
+	}
+	dbMeta := cache.GetDbMeta(dbConn.Id)
+	if dbMeta == nil {
+		return
+	}
+	// 保留已有信息用于计算增量，仅标记为需重新加载
+	dbMeta.RefreshTime = 0
+	if err := cache.SaveDbMeta(dbConn.Id, dbMeta); err != nil {
+		logx.Warnf("标记库元数据缓存失效失败: %s", err.Error())
+		cache.DelDbMeta(dbConn.Id)
+	}
+}
+
+// 获取库元数据，缓存不存在或已失效则同步加载，超过刷新间隔则先返回缓存并后台刷新
+func (d *dbMetaCacheAppImpl) getDbMeta(dbConn *dbi.DbConn) (*cache.DbMeta, error) {
+	if dbConn.Id == "" {
+		return d.refresh(dbConn, nil)
+	}
+
+	dbMeta := cache.GetDbMeta(dbConn.Id)
+	if dbMeta == nil || dbMeta.RefreshTime == 0 {
+		res, err, _ := d.loading.Do(dbConn.Id, func() (any, error) {
+			// 等待期间可能已被其他请求加载
+			old := cache.GetDbMeta(dbConn.Id)
+			if old != nil && old.RefreshTime != 0 {
+				return old, nil
+			}
+			newMeta, err := d.refresh(dbConn, old)
+			if err != nil {
+				return nil, err
+			}
+			d.save(dbConn.Id, newMeta)
+			return newMeta, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.(*cache.DbMeta), nil
+	}
+
+	if time.Since(time.UnixMilli(dbMeta.RefreshTime)) > dbMetaRefreshInterval {
+		d.asyncRefresh(dbConn, dbMeta)
+	}
+	return dbMeta, nil
+}
+
+func (d *dbMetaCacheAppImpl) asyncRefresh(dbConn *dbi.DbConn, old *cache.DbMeta) {
+	connId := dbConn.Id
+	if _, loaded := d.refreshing.LoadOrStore(connId, struct{}{}); loaded {
+		return
+	}
+
+	dbId, database := dbConn.Info.Id, dbConn.Info.Database
+	go func() {
+		defer d.refreshing.Delete(connId)
+		// 重新获取连接，避免使用已被关闭的连接
+		conn, err := d.DbApp.GetDbConn(dbId, database)
+		if err != nil {
+			logx.Warnf("后台刷新库元数据缓存失败[%s]: %s", connId, err.Error())
+			return
+		}
+		dbMeta, err := d.refresh(conn, old)
+		if err != nil {
+			logx.Warnf("后台刷新库元数据缓存失败[%s]: %s", connId, err.Error())
+			return
+		}
+		// 刷新期间缓存已被标记失效（如执行了ddl），则不覆盖，由下次获取时重新加载
+		if current := cache.GetDbMeta(connId); current != nil && current.RefreshTime == 0 {
+			return
+		}
+		d.save(connId, dbMeta)
+	}()
+}
+
+// 从数据库加载元数据，并与旧缓存比较计算各表版本（不保存至缓存）
+func (d *dbMetaCacheAppImpl) refresh(dbConn *dbi.DbConn, old *cache.DbMeta) (*cache.DbMeta, error) {
+	tables, err := loadHintTables(dbConn)
+	if err != nil {
+		return nil, err
+	}
+	return diffDbMeta(old, tables, time.Now()), nil
+}
+
+// 比较新加载的表信息与旧缓存，变更或新增的表及删除的表记录为新版本
+func diffDbMeta(old *cache.DbMeta, tables map[string][]string, now time.Time) *cache.DbMeta {
+	if old == nil {
+		version := now.UnixMilli()
+		dbMeta := &cache.DbMeta{
+			BaseVersion: version,
+			Version:     version,
+			RefreshTime: version,
+			Tables:      make(map[string]*cache.TableMeta, len(tables)),
+			Dropped:     make(map[string]int64),
+		}
+		for tableName, columns := range tables {
+			dbMeta.Tables[tableName] = &cache.TableMeta{Version: version, Columns: columns}
+		}
+		return dbMeta
+	}
+
+	// 新版本需大于旧版本，避免时钟回拨导致客户端漏掉变更
+	version := max(now.UnixMilli(), old.Version+1)
+	dbMeta := &cache.DbMeta{
+		BaseVersion: old.BaseVersion,
+		Version:     old.Version,
+		RefreshTime: now.UnixMilli(),
+		Tables:      make(map[string]*cache.TableMeta, len(tables)),
+		Dropped:     old.Dropped,
+	}
+	if dbMeta.Dropped == nil {
+		dbMeta.Dropped = make(map[string]int64)
+	}
+
+	for tableName, columns := range tables {
+		oldTable := old.Tables[tableName]
+		if oldTable != nil && slices.Equal(oldTable.Columns, columns) {
+			dbMeta.Tables[tableName] = oldTable
+			continue
+		}
+		dbMeta.Tables[tableName] = &cache.TableMeta{Version: version, Columns: columns}
+		dbMeta.Version = version
+		delete(dbMeta.Dropped, tableName)
+	}
+	for tableName := range old.Tables {
+		if _, ok := tables[tableName]; !ok {
+			dbMeta.Dropped[tableName] = version
+			dbMeta.Version = version
+		}
+	}
+
+	// 清理过期的删除记录，早于其删除版本的客户端需获取全量信息
+	expireVersion := now.Add(-dbMetaDroppedRetain).UnixMilli()
+	for tableName, droppedVersion := range dbMeta.Dropped {
+		if droppedVersion < expireVersion {
+			delete(dbMeta.Dropped, tableName)
+			dbMeta.BaseVersion = max(dbMeta.BaseVersion, droppedVersion)
+		}
+	}
+
+	return dbMeta
+}
+
+func (d *dbMetaCacheAppImpl) save(connId string, dbMeta *cache.DbMeta) {
+	if err := cache.SaveDbMeta(connId, dbMeta); err != nil {
+		logx.Warnf("保存库元数据缓存失败[%s]: %s", connId, err.Error())
+	}
+}
+
+// 加载所有表的字段提示信息，key = 表名，value = 字段提示信息
+func loadHintTables(dbConn *dbi.DbConn) (map[string][]string, error) {
+	dm := dbConn.GetDialect()
+	// 获取所有表
+	tables, err := dm.GetTables()
+	if err != nil {
+		return nil, err
+	}
+	tableNames := make([]string, 0)
+	for _, v := range tables {
+		tableNames = append(tableNames, v.TableName)
+	}
+	res := make(map[string][]string)
+
+	// 表为空，则直接返回
+	if len(tableNames) == 0 {
+		return res, nil
+	}
+
+	// 获取所有表下的所有列信息
+	columnMds, err := dm.GetColumns(tableNames...)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range columnMds {
+		columnName := fmt.Sprintf("%s  [%s]", v.ColumnName, v.ColumnType)
+		// 如果字段备注不为空，则加上备注信息
+		if v.ColumnComment != "" {
+			columnName = fmt.Sprintf("%s[%s]", columnName, v.ColumnComment)
+		}
+		res[v.TableName] = append(res[v.TableName], columnName)
+	}
+	// 无字段信息的表也需提示表名
+	for _, tableName := range tableNames {
+		if res[tableName] == nil {
+			res[tableName] = make([]string, 0)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,78 @@
+package application
+
+import (
+	"mayfly-go/internal/db/infrastructure/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_diffDbMeta(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	dbMeta := diffDbMeta(nil, map[string][]string{"t_user": {"id"}, "t_order": {"id"}}, now)
+	v1 := now.UnixMilli()
+	require.Equal(t, v1, dbMeta.BaseVersion)
+	require.Equal(t, v1, dbMeta.Version)
+	require.Equal(t, v1, dbMeta.Tables["t_user"].Version)
+
+	// 修改t_user字段，删除t_order，新增t_role
+	now = now.Add(time.Minute)
+	dbMeta = diffDbMeta(dbMeta, map[string][]string{"t_user": {"id", "name"}, "t_role": {"id"}}, now)
+	v2 := now.UnixMilli()
+	require.Equal(t, v1, dbMeta.BaseVersion)
+	require.Equal(t, v2, dbMeta.Version)
+	require.Equal(t, v2, dbMeta.Tables["t_user"].Version)
+	require.Equal(t, v2, dbMeta.Tables["t_role"].Version)
+	require.Equal(t, map[string]int64{"t_order": v2}, dbMeta.Dropped)
+
+	// 无变更则版本不变
+	dbMeta = diffDbMeta(dbMeta, map[string][]string{"t_user": {"id", "name"}, "t_role": {"id"}}, now.Add(time.Minute))
+	require.Equal(t, v2, dbMeta.Version)
+
+	// 时钟回拨时版本仍递增；重新创建的表移出删除记录
+	dbMeta = diffDbMeta(dbMeta, map[string][]string{"t_user": {"id", "name"}, "t_order": {"id"}}, now.Add(-time.Hour))
+	require.Equal(t, v2+1, dbMeta.Version)
+	require.Equal(t, map[string]int64{"t_role": v2 + 1}, dbMeta.Dropped)
+
+	// 过期的删除记录被清理，并提升可增量获取的最小版本
+	dbMeta = diffDbMeta(dbMeta, map[string][]string{"t_user": {"id", "name"}, "t_order": {"id"}}, now.Add(dbMetaDroppedRetain+time.Hour))
+	require.Empty(t, dbMeta.Dropped)
+	require.Equal(t, v2+1, dbMeta.BaseVersion)
+}
+
+func Test_newHintTablesChange(t *testing.T) {
+	dbMeta := &cache.DbMeta{
+		BaseVersion: 100,
+		Version:     300,
+		Tables: map[string]*cache.TableMeta{
+			"t_user":  {Version: 100, Columns: []string{"id"}},
+			"t_order": {Version: 300, Columns: []string{"id", "no"}},
+		},
+		Dropped: map[string]int64{"t_role": 200},
+	}
+
+	cases := []struct {
+		version int64
+		full    bool
+		tables  []string
+		dropped []string
+	}{
+		{0, true, []string{"t_order", "t_user"}, []string{}},
+		{50, true, []string{"t_order", "t_user"}, []string{}},
+		{100, false, []string{"t_order"}, []string{"t_role"}},
+		{200, false, []string{"t_order"}, []string{}},
+		{300, false, []string{}, []string{}},
+	}
+	for _, c := range cases {
+		res := newHintTablesChange(dbMeta, c.version)
+		require.Equal(t, int64(300), res.Version)
+		require.Equal(t, c.full, res.Full, c.version)
+		tables := make([]string, 0)
+		for tableName := range res.Tables {
+			tables = append(tables, tableName)
+		}
+		require.ElementsMatch(t, c.tables, tables, c.version)
+		require.ElementsMatch(t, c.dropped, res.Dropped, c.version)
+	}
+}
@@ -62,6 +62,11 @@ func (app *DbRestoreApp) Disable(ctx context.Context, jobId uint64) error {
 	return app.scheduler.DisableJob(ctx, entity.DbJobTypeRestore, jobId)
 }
 
+// Cancel 取消正在运行的恢复任务
+func (app *DbRestoreApp) Cancel(ctx context.Context, jobId uint64) error {
+	return app.scheduler.CancelJob(ctx, entity.DbJobTypeRestore, jobId)
+}
+
 // GetPageList 分页获取数据库恢复任务
 func (app *DbRestoreApp) GetPageList(condition *entity.DbJobQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
 	return app.restoreRepo.GetPageList(condition, pageParam, toEntity, orderBy...)
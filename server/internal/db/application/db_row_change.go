@@ -0,0 +1,290 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/jsonx"
+	"strings"
+)
+
+// DbRowChangeReq 表数据行修改请求，修改及删除均通过主键定位记录
+type DbRowChangeReq struct {
+	DbId   uint64
+	Db     string
+	Table  string
+	Remark string
+	DbConn *dbi.DbConn
+
+	Inserts []map[string]any // 新增的行，列名 -> 值
+	Updates []*DbRowUpdate   // 修改的行
+	Deletes []map[string]any // 删除行的主键值，主键列名 -> 值
+}
+
+// DbRowUpdate 单行修改信息
+type DbRowUpdate struct {
+	PrimaryKey map[string]any // 主键列名 -> 值
+	Values     map[string]any // 修改的列名 -> 新值
+}
+
+// 单条行修改对应的sql及执行记录
+type rowChange struct {
+	sql      string
+	pkWhere  string // 主键条件，新增时为空
+	sqlExec  *entity.DbSqlExec
+	affected int64
+}
+
+func (d *dbSqlExecAppImpl) SaveRowChanges(ctx context.Context, changeReq *DbRowChangeReq) (*DbSqlExecRes, error) {
+	if len(changeReq.Inserts)+len(changeReq.Updates)+len(changeReq.Deletes) == 0 {
+		return nil, errorx.NewBiz("不存在需要保存的数据")
+	}
+
+	dbConn := changeReq.DbConn
+	tableName := dbConn.Info.Type.RemoveQuote(changeReq.Table)
+	columns, err := dbConn.GetDialect().GetColumns(tableName)
+	if err != nil {
+		return nil, errorx.NewBiz("获取表列信息失败: %s", err.Error())
+	}
+	if len(columns) == 0 {
+		return nil, errorx.NewBiz("表[%s]不存在", tableName)
+	}
+	builder := newRowChangeBuilder(dbConn, tableName, columns)
+	if len(builder.primaryKeys) == 0 && len(changeReq.Updates)+len(changeReq.Deletes) > 0 {
+		return nil, errorx.NewBiz("表[%s]不存在主键, 不支持修改或删除数据", tableName)
+	}
+
+	execSqlReq := &DbSqlExecReq{
+		DbId:   changeReq.DbId,
+		Db:     changeReq.Db,
+		Remark: changeReq.Remark,
+		DbConn: dbConn,
+	}
+	changes := make([]*rowChange, 0)
+	newRowChange := func(sql, pkWhere string, execType int8) {
+		execSqlReq.Sql = sql
+		sqlExec := createSqlExecRecord(ctx, execSqlReq)
+		sqlExec.Table = tableName
+		sqlExec.Type = execType
+		changes = append(changes, &rowChange{sql: sql, pkWhere: pkWhere, sqlExec: sqlExec})
+	}
+
+	for _, values := range changeReq.Inserts {
+		sql, err := builder.insertSql(values)
+		if err != nil {
+			return nil, err
+		}
+		newRowChange(sql, "", entity.DbSqlExecTypeInsert)
+	}
+	for _, update := range changeReq.Updates {
+		pkWhere, err := builder.primaryKeyWhere(update.PrimaryKey)
+		if err != nil {
+			return nil, err
+		}
+		sets, err := builder.setClause(update.Values)
+		if err != nil {
+			return nil, err
+		}
+		newRowChange(fmt.Sprintf("UPDATE %s SET %s WHERE %s", builder.quoteTable, sets, pkWhere), pkWhere, entity.DbSqlExecTypeUpdate)
+	}
+	for _, pk := range changeReq.Deletes {
+		pkWhere, err := builder.primaryKeyWhere(pk)
+		if err != nil {
+			return nil, err
+		}
+		newRowChange(fmt.Sprintf("DELETE FROM %s WHERE %s", builder.quoteTable, pkWhere), pkWhere, entity.DbSqlExecTypeDelete)
+	}
+
+	// 需要审批的库，逐条提交审批，类型及表名已确定故无需解析sql
+	if d.needApproval(execSqlReq) {
+		var execResAll *DbSqlExecRes
+		for _, change := range changes {
+			execRes, err := d.submitApproval(ctx, nil, change.sqlExec)
+			if err != nil {
+				return nil, err
+			}
+			if execResAll == nil {
+				execResAll = execRes
+			} else {
+				execResAll.Merge(execRes)
+			}
+		}
+		return execResAll, nil
+	}
+
+	tx, err := dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// 在事务中锁定并记录修改、删除前的旧值，用于回滚，避免读取旧值后记录被并发修改
+	for _, change := range changes {
+		if change.pkWhere == "" {
+			continue
+		}
+		_, res, err := dbConn.TxQueryContext(ctx, tx, builder.dbType.StmtSelectForUpdate(builder.quoteTable, change.pkWhere))
+		if err != nil {
+			tx.Rollback()
+			return nil, errorx.NewBiz("查询旧值失败: %s", err.Error())
+		}
+		if len(res) == 0 {
+			tx.Rollback()
+			return nil, errorx.NewBiz("表[%s]中不存在[%s]的记录, 可能已被修改或删除", tableName, change.pkWhere)
+		}
+		change.sqlExec.OldValue = jsonx.ToStr(res)
+	}
+
+	for _, change := range changes {
+		affected, err := dbConn.TxExecContext(ctx, tx, change.sql)
+		if err != nil {
+			tx.Rollback()
+			return nil, errorx.NewBiz("[%s] -> 执行失败: %s", change.sql, err.Error())
+		}
+		if change.pkWhere != "" && affected == 0 {
+			tx.Rollback()
+			return nil, errorx.NewBiz("[%s] -> 未影响任何数据, 该记录可能已被修改或删除", change.sql)
+		}
+		change.affected = affected
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	res := make([]map[string]any, 0)
+	for _, change := range changes {
+		change.sqlExec.Res = fmt.Sprintf("rowsAffected: %d", change.affected)
+		d.saveSqlExecLog(false, change.sqlExec)
+
+		resData := make(map[string]any)
+		resData["rowsAffected"] = change.affected
+		resData["sql"] = change.sql
+		resData["result"] = "success"
+		res = append(res, resData)
+	}
+	return &DbSqlExecRes{
+		Columns: []*dbi.QueryColumn{
+			{Name: "sql", Type: "string"},
+			{Name: "rowsAffected", Type: "number"},
+			{Name: "result", Type: "string"},
+		},
+		Res: res,
+	}, nil
+}
+
+// 根据表列信息生成对应数据库的行修改sql
+type rowChangeBuilder struct {
+	dbType      dbi.DbType
+	dialect     dbi.Dialect
+	tableName   string
+	quoteTable  string
+	columns     []dbi.Column
+	primaryKeys []dbi.Column
+}
+
+func newRowChangeBuilder(dbConn *dbi.DbConn, tableName string, columns []dbi.Column) *rowChangeBuilder {
+	b := &rowChangeBuilder{
+		dbType:     dbConn.Info.Type,
+		dialect:    dbConn.GetDialect(),
+		tableName:  tableName,
+		quoteTable: QuoteTableName(dbConn.Info.Type, tableName),
+		columns:    columns,
+	}
+	for _, column := range columns {
+		if column.ColumnKey == "PRI" {
+			b.primaryKeys = append(b.primaryKeys, column)
+		}
+	}
+	return b
+}
+
+// 按表列顺序获取值中存在的列，值中存在表不存在的列则返回错误
+func (b *rowChangeBuilder) valueColumns(values map[string]any) ([]dbi.Column, error) {
+	for name := range values {
+		exist := false
+		for _, column := range b.columns {
+			if strings.EqualFold(column.ColumnName, name) {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			return nil, errorx.NewBiz("表[%s]不存在列[%s]", b.tableName, name)
+		}
+	}
+	columns := make([]dbi.Column, 0)
+	for _, column := range b.columns {
+		if _, ok := dbi.GetMapValue(values, column.ColumnName); ok {
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
+
+func (b *rowChangeBuilder) insertSql(values map[string]any) (string, error) {
+	columns, err := b.valueColumns(values)
+	if err != nil {
+		return "", err
+	}
+	if len(columns) == 0 {
+		return "", errorx.NewBiz("新增数据不能为空")
+	}
+	names := make([]string, 0, len(columns))
+	literals := make([]string, 0, len(columns))
+	for _, column := range columns {
+		value, _ := dbi.GetMapValue(values, column.ColumnName)
+		literal, err := b.toLiteral(column, value)
+		if err != nil {
+			return "", err
+		}
+		names = append(names, b.dbType.QuoteIdentifier(column.ColumnName))
+		literals = append(literals, literal)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", b.quoteTable, strings.Join(names, ", "), strings.Join(literals, ", ")), nil
+}
+
+func (b *rowChangeBuilder) setClause(values map[string]any) (string, error) {
+	columns, err := b.valueColumns(values)
+	if err != nil {
+		return "", err
+	}
+	if len(columns) == 0 {
+		return "", errorx.NewBiz("修改数据不能为空")
+	}
+	sets := make([]string, 0, len(columns))
+	for _, column := range columns {
+		value, _ := dbi.GetMapValue(values, column.ColumnName)
+		literal, err := b.toLiteral(column, value)
+		if err != nil {
+			return "", err
+		}
+		sets = append(sets, fmt.Sprintf("%s = %s", b.dbType.QuoteIdentifier(column.ColumnName), literal))
+	}
+	return strings.Join(sets, ", "), nil
+}
+
+// 生成主键条件，支持联合主键，需包含所有主键列的值
+func (b *rowChangeBuilder) primaryKeyWhere(pk map[string]any) (string, error) {
+	conds := make([]string, 0, len(b.primaryKeys))
+	for _, column := range b.primaryKeys {
+		value, ok := dbi.GetMapValue(pk, column.ColumnName)
+		if !ok || value == nil {
+			return "", errorx.NewBiz("缺少主键列[%s]的值", column.ColumnName)
+		}
+		literal, err := b.toLiteral(column, value)
+		if err != nil {
+			return "", err
+		}
+		conds = append(conds, fmt.Sprintf("%s = %s", b.dbType.QuoteIdentifier(column.ColumnName), literal))
+	}
+	return strings.Join(conds, " AND "), nil
+}
+
+// 根据列类型将值转为sql字面量
+func (b *rowChangeBuilder) toLiteral(column dbi.Column, value any) (string, error) {
+	literal, err := dbi.ColumnLiteral(b.dbType, b.dialect.GetDataType(column.ColumnType), value)
+	if err != nil {
+		return "", errorx.NewBiz("列[%s]: %s", column.ColumnName, err.Error())
+	}
+	return literal, nil
+}
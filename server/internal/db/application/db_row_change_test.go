@@ -0,0 +1,58 @@
+package application
+
+import (
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/dbm/mysql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRowChangeBuilder() *rowChangeBuilder {
+	return &rowChangeBuilder{
+		dbType:     dbi.DbTypeMysql,
+		dialect:    &mysql.MysqlDialect{},
+		tableName:  "t_order",
+		quoteTable: "`t_order`",
+		columns: []dbi.Column{
+			{ColumnName: "tenant_id", ColumnType: "int", ColumnKey: "PRI"},
+			{ColumnName: "id", ColumnType: "bigint", ColumnKey: "PRI"},
+			{ColumnName: "name", ColumnType: "varchar(32)"},
+			{ColumnName: "create_time", ColumnType: "datetime"},
+		},
+		primaryKeys: []dbi.Column{
+			{ColumnName: "tenant_id", ColumnType: "int", ColumnKey: "PRI"},
+			{ColumnName: "id", ColumnType: "bigint", ColumnKey: "PRI"},
+		},
+	}
+}
+
+func Test_rowChangeBuilderInsertSql(t *testing.T) {
+	b := newTestRowChangeBuilder()
+	sql, err := b.insertSql(map[string]any{"NAME": "it's", "id": 1, "tenant_id": "2", "create_time": "2024-01-02 03:04:05"})
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO `t_order` (`tenant_id`, `id`, `name`, `create_time`) VALUES (2, 1, 'it''s', '2024-01-02 03:04:05')", sql)
+
+	_, err = b.insertSql(map[string]any{"unknown": 1})
+	require.Error(t, err)
+	_, err = b.insertSql(map[string]any{})
+	require.Error(t, err)
+	_, err = b.insertSql(map[string]any{"id": "abc"})
+	require.Error(t, err)
+}
+
+func Test_rowChangeBuilderUpdate(t *testing.T) {
+	b := newTestRowChangeBuilder()
+	sets, err := b.setClause(map[string]any{"name": nil, "create_time": ""})
+	require.NoError(t, err)
+	require.Equal(t, "`name` = NULL, `create_time` = NULL", sets)
+
+	where, err := b.primaryKeyWhere(map[string]any{"ID": 1, "TENANT_ID": 2})
+	require.NoError(t, err)
+	require.Equal(t, "`tenant_id` = 2 AND `id` = 1", where)
+
+	_, err = b.primaryKeyWhere(map[string]any{"id": 1})
+	require.Error(t, err)
+	_, err = b.primaryKeyWhere(map[string]any{"id": 1, "tenant_id": nil})
+	require.Error(t, err)
+}
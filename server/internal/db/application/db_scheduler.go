@@ -2,14 +2,23 @@ package application
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"mayfly-go/internal/db/dbm"
 	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/domain/entity"
 	"mayfly-go/internal/db/domain/repository"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/pkg/contextx"
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/runner"
+	"mayfly-go/pkg/ws"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
@@ -18,6 +27,20 @@ const (
 	maxRunning = 8
 )
 
+// 数据库备份与恢复进度消息类型
+const DbJobProgressCategory = "dbJobProgress"
+
+// DbJobProgress 数据库备份与恢复进度消息
+type DbJobProgress struct {
+	*dbi.DbProgramProgress
+
+	JobId      uint64           `json:"jobId"`
+	JobType    entity.DbJobType `json:"jobType"`
+	DbName     string           `json:"dbName"`
+	Terminated bool             `json:"terminated"` // 是否已结束
+	Status     string           `json:"status"`     // 结束时的任务执行结果
+}
+
 type dbScheduler struct {
 	mutex              sync.Mutex
 	runner             *runner.Runner[entity.DbJob]
@@ -29,6 +52,7 @@ type dbScheduler struct {
 	binlogRepo         repository.DbBinlog
 	binlogHistoryRepo  repository.DbBinlogHistory
 	binlogTimes        map[uint64]time.Time
+	progressReceivers  map[entity.DbJobKey]uint64 // 任务 -> 接收下次执行进度的用户id
 }
 
 func newDbScheduler(repositories *repository.Repositories) (*dbScheduler, error) {
@@ -40,6 +64,7 @@ func newDbScheduler(repositories *repository.Repositories) (*dbScheduler, error)
 		restoreHistoryRepo: repositories.RestoreHistory,
 		binlogRepo:         repositories.Binlog,
 		binlogHistoryRepo:  repositories.BinlogHistory,
+		progressReceivers:  make(map[entity.DbJobKey]uint64),
 	}
 	scheduler.runner = runner.NewRunner[entity.DbJob](maxRunning, scheduler.runJob,
 		runner.WithScheduleJob[entity.DbJob](scheduler.scheduleJob),
@@ -97,11 +122,13 @@ func (s *dbScheduler) AddJob(ctx context.Context, saving bool, jobType entity.Db
 		for i := 0; i < reflectLen; i++ {
 			job := reflectValue.Index(i).Interface().(entity.DbJob)
 			job.SetJobType(jobType)
+			s.setProgressReceiver(ctx, job)
 			_ = s.runner.Add(ctx, job)
 		}
 	default:
 		job := jobs.(entity.DbJob)
 		job.SetJobType(jobType)
+		s.setProgressReceiver(ctx, job)
 		_ = s.runner.Add(ctx, job)
 	}
 	return nil
@@ -116,6 +143,7 @@ func (s *dbScheduler) RemoveJob(ctx context.Context, jobType entity.DbJobType, j
 		return err
 	}
 	_ = s.runner.Remove(ctx, entity.FormatJobKey(jobType, jobId))
+	delete(s.progressReceivers, entity.FormatJobKey(jobType, jobId))
 	return nil
 }
 
@@ -135,6 +163,7 @@ func (s *dbScheduler) EnableJob(ctx context.Context, jobType entity.DbJobType, j
 	if err := repo.UpdateEnabled(ctx, jobId, true); err != nil {
 		return err
 	}
+	s.setProgressReceiver(ctx, job)
 	_ = s.runner.Add(ctx, job)
 	return nil
 }
@@ -169,11 +198,101 @@ func (s *dbScheduler) StartJobNow(ctx context.Context, jobType entity.DbJobType,
 	if !job.IsEnabled() {
 		return errors.New("任务未启用")
 	}
+	s.setProgressReceiver(ctx, job)
 	_ = s.runner.StartNow(ctx, job)
 	return nil
 }
 
-func (s *dbScheduler) backupMysql(ctx context.Context, job entity.DbJob) error {
+// CancelJob 取消正在运行的任务，将终止正在运行的备份或恢复程序
+func (s *dbScheduler) CancelJob(ctx context.Context, jobType entity.DbJobType, jobId uint64) error {
+	if err := s.runner.Cancel(entity.FormatJobKey(jobType, jobId)); err != nil {
+		if errors.Is(err, runner.ErrJobNotRun) {
+			return errors.New("任务未在运行")
+		}
+		return err
+	}
+	return nil
+}
+
+// 将当前登录用户设置为任务下次执行进度的接收者，调用方需持有 s.mutex
+func (s *dbScheduler) setProgressReceiver(ctx context.Context, job entity.DbJob) {
+	if la := contextx.GetLoginAccount(ctx); la != nil {
+		s.progressReceivers[job.GetKey()] = la.Id
+	}
+}
+
+// 获取任务本次执行进度的接收者，未指定（如定时执行）则返回0，不推送进度
+func (s *dbScheduler) takeProgressReceiver(job entity.DbJob) uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if userId, ok := s.progressReceivers[job.GetKey()]; ok {
+		delete(s.progressReceivers, job.GetKey())
+		return userId
+	}
+	return 0
+}
+
+// 每秒向接收者推送一次任务进度，返回的函数用于停止推送并推送任务的执行结果
+func (s *dbScheduler) reportProgress(job entity.DbJob, progress *dbi.DbProgramProgress) func(result string) {
+	var title string
+	switch job.GetJobType() {
+	case entity.DbJobTypeBackup:
+		title = "数据库备份进度"
+	case entity.DbJobTypeRestore:
+		title = "数据库恢复进度"
+	default:
+		return func(string) {}
+	}
+	userId := s.takeProgressReceiver(job)
+	if userId == 0 {
+		return func(string) {}
+	}
+	send := func(terminated bool, result string) {
+		ws.SendJsonMsg(ws.UserId(userId), "", msgdto.InfoSysMsg(title, &DbJobProgress{
+			DbProgramProgress: progress.Snapshot(),
+			JobId:             job.GetJobBase().Id,
+			JobType:           job.GetJobType(),
+			DbName:            job.GetDbName(),
+			Terminated:        terminated,
+			Status:            result,
+		}).WithCategory(DbJobProgressCategory))
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				send(false, "")
+			}
+		}
+	}()
+	return func(result string) {
+		close(done)
+		wg.Wait()
+		send(true, result)
+	}
+}
+
+// 获取任务进度json，用于保存至备份或恢复历史
+func progressJson(ctx context.Context) string {
+	progress := dbi.GetProgress(ctx)
+	if progress == nil {
+		return ""
+	}
+	bytes, _ := json.Marshal(progress.Snapshot())
+	return string(bytes)
+}
+
+func (s *dbScheduler) backupDb(ctx context.Context, job entity.DbJob) error {
 	id, err := NewIncUUID()
 	if err != nil {
 		return err
@@ -185,11 +304,10 @@ func (s *dbScheduler) backupMysql(ctx context.Context, job entity.DbJob) error {
 		DbInstanceId: backup.DbInstanceId,
 		DbName:       backup.DbName,
 	}
-	conn, err := s.dbApp.GetDbConnByInstanceId(backup.DbInstanceId)
+	dbProgram, err := s.getDbProgram(backup.DbInstanceId, backup.DbName)
 	if err != nil {
 		return err
 	}
-	dbProgram := conn.GetDialect().GetDbProgram()
 	binlogInfo, err := dbProgram.Backup(ctx, history)
 	if err != nil {
 		return err
@@ -204,20 +322,117 @@ func (s *dbScheduler) backupMysql(ctx context.Context, job entity.DbJob) error {
 	history.BinlogFileName = binlogInfo.FileName
 	history.BinlogSequence = binlogInfo.Sequence
 	history.BinlogPosition = binlogInfo.Position
+	fileName, err := dbi.GetDbBackupFile(history.DbInstanceId, history.DbBackupId, history.Uuid)
+	if err != nil {
+		return errors.New(fmt.Sprintf("未找到备份文件: %v", err))
+	}
+	if history.FileSize, history.Checksum, err = fileChecksum(fileName); err != nil {
+		return err
+	}
+	history.FileName = filepath.Base(fileName)
+	bs, err := backup.GetStorage()
+	if err != nil {
+		_ = os.Remove(fileName)
+		return err
+	}
+	history.Storage = bs.RefString()
+	if err := dbi.UploadBackupFile(ctx, history, bs); err != nil {
+		_ = os.Remove(fileName)
+		return err
+	}
+	history.Progress = progressJson(ctx)
 
 	if err := s.backupHistoryRepo.Insert(ctx, history); err != nil {
 		return err
 	}
+	if err := s.cleanExpiredHistories(ctx, backup); err != nil {
+		logx.Errorf("根据保留策略清理数据库备份失败: %v", err)
+	}
 	return nil
 }
 
-func (s *dbScheduler) restoreMysql(ctx context.Context, job entity.DbJob) error {
+// 根据备份任务的保留策略删除过期的备份历史及备份文件，
+// 未完成的恢复任务所依赖的备份（指定的备份历史或按时间点恢复所需的基础备份）及早于最早 binlog 的最新备份不会被删除
+func (s *dbScheduler) cleanExpiredHistories(ctx context.Context, backup *entity.DbBackup) error {
+	retention, err := backup.GetRetention()
+	if err != nil || retention == nil {
+		return err
+	}
+	var histories []*entity.DbBackupHistory
+	if err := s.backupHistoryRepo.ListByCond(&entity.DbBackupHistory{DbBackupId: backup.Id}, &histories); err != nil {
+		return err
+	}
+	var restores []*entity.DbRestore
+	if err := s.restoreRepo.ListToDo(&restores); err != nil {
+		return err
+	}
+
+	protected := make(map[uint64]bool)
+	// 保留早于最早 binlog 的最新一次备份，保证已保留的 binlog 范围内均可按时间点恢复
+	earliestBinlog, ok, err := s.binlogHistoryRepo.GetEarliestHistory(backup.DbInstanceId)
+	if err != nil {
+		return err
+	}
+	if ok {
+		var base *entity.DbBackupHistory
+		for _, history := range histories {
+			if history.BinlogSequence <= earliestBinlog.Sequence && (base == nil || history.CreateTime.After(base.CreateTime)) {
+				base = history
+			}
+		}
+		if base != nil {
+			protected[base.Id] = true
+		}
+	}
+	for _, restore := range restores {
+		if restore.DbInstanceId != backup.DbInstanceId || restore.DbName != backup.DbName {
+			continue
+		}
+		if restore.DbBackupHistoryId > 0 {
+			protected[restore.DbBackupHistoryId] = true
+		}
+		if !restore.PointInTime.Valid {
+			continue
+		}
+		// 按时间点恢复需要该时间点之前最近的一次备份及之后的 binlog
+		var base *entity.DbBackupHistory
+		for _, history := range histories {
+			if !history.CreateTime.After(restore.PointInTime.Time) && (base == nil || history.CreateTime.After(base.CreateTime)) {
+				base = history
+			}
+		}
+		if base != nil {
+			protected[base.Id] = true
+		}
+	}
+
+	for _, history := range retention.SelectExpired(histories, protected, time.Now()) {
+		bs, err := s.getBackupHistoryStorage(history, backup)
+		if err != nil {
+			return err
+		}
+		if err := dbi.DeleteBackupFile(ctx, history, bs); err != nil {
+			return err
+		}
+		if err := s.backupHistoryRepo.DeleteById(ctx, history.Id); err != nil {
+			return err
+		}
+		logx.Infof("根据保留策略删除数据库备份: %s", history.Name)
+	}
+	return nil
+}
+
+func (s *dbScheduler) restoreDb(ctx context.Context, job entity.DbJob) error {
 	restore := job.(*entity.DbRestore)
-	conn, err := s.dbApp.GetDbConnByInstanceId(restore.DbInstanceId)
+	dbProgram, err := s.getDbProgram(restore.DbInstanceId, restore.DbName)
 	if err != nil {
 		return err
 	}
-	dbProgram := conn.GetDialect().GetDbProgram()
+	targetProgram, release, err := s.getTargetDbProgram(ctx, restore)
+	if err != nil {
+		return err
+	}
+	defer release()
 	if restore.PointInTime.Valid {
 		latestBinlogSequence, earliestBackupSequence := int64(-1), int64(-1)
 		binlogHistory, ok, err := s.binlogHistoryRepo.GetLatestHistory(restore.DbInstanceId)
@@ -243,11 +458,11 @@ func (s *dbScheduler) restoreMysql(ctx context.Context, job entity.DbJob) error
 		if err := s.binlogHistoryRepo.InsertWithBinlogFiles(ctx, restore.DbInstanceId, binlogFiles); err != nil {
 			return err
 		}
-		if err := s.restorePointInTime(ctx, dbProgram, restore); err != nil {
+		if err := s.restorePointInTime(ctx, dbProgram, targetProgram, restore); err != nil {
 			return err
 		}
 	} else {
-		if err := s.restoreBackupHistory(ctx, dbProgram, restore); err != nil {
+		if err := s.restoreBackupHistory(ctx, targetProgram, restore); err != nil {
 			return err
 		}
 	}
@@ -255,6 +470,7 @@ func (s *dbScheduler) restoreMysql(ctx context.Context, job entity.DbJob) error
 	history := &entity.DbRestoreHistory{
 		CreateTime:  time.Now(),
 		DbRestoreId: restore.Id,
+		Progress:    progressJson(ctx),
 	}
 	if err := s.restoreHistoryRepo.Insert(ctx, history); err != nil {
 		return err
@@ -269,22 +485,32 @@ func (s *dbScheduler) runJob(ctx context.Context, job entity.DbJob) {
 		return
 	}
 
+	progress := &dbi.DbProgramProgress{}
+	ctx = dbi.WithProgress(ctx, progress)
+	stopReport := s.reportProgress(job, progress)
+
 	var errRun error
 	switch typ := job.GetJobType(); typ {
 	case entity.DbJobTypeBackup:
-		errRun = s.backupMysql(ctx, job)
+		errRun = s.backupDb(ctx, job)
 	case entity.DbJobTypeRestore:
-		errRun = s.restoreMysql(ctx, job)
+		errRun = s.restoreDb(ctx, job)
 	case entity.DbJobTypeBinlog:
-		errRun = s.fetchBinlogMysql(ctx, job)
+		errRun = s.fetchBinlog(ctx, job)
 	default:
 		errRun = errors.New(fmt.Sprintf("无效的数据库任务类型: %v", typ))
 	}
 	status := entity.DbJobSuccess
 	if errRun != nil {
 		status = entity.DbJobFailed
+		if errors.Is(context.Cause(ctx), runner.ErrJobCanceled) {
+			status, errRun = entity.DbJobCanceled, nil
+		}
 	}
+	// 任务被取消后 ctx 已失效，仍需保存执行结果
+	ctx = context.WithoutCancel(ctx)
 	job.SetLastStatus(status, errRun)
+	stopReport(job.GetJobBase().LastResult)
 	if err := s.repo(job.GetJobType()).UpdateLastStatus(ctx, job); err != nil {
 		logx.Errorf("failed to update job status: %v", err)
 		return
@@ -326,7 +552,8 @@ func relatedToBinlog(typ entity.DbJobType) bool {
 	return typ == entity.DbJobTypeRestore || typ == entity.DbJobTypeBinlog
 }
 
-func (s *dbScheduler) restorePointInTime(ctx context.Context, program dbi.DbProgram, job *entity.DbRestore) error {
+// 按时间点恢复，program 为备份所在数据库的数据库程序，用于解析 binlog，targetProgram 为恢复目标数据库的数据库程序
+func (s *dbScheduler) restorePointInTime(ctx context.Context, program, targetProgram dbi.DbProgram, job *entity.DbRestore) error {
 	binlogHistory, err := s.binlogHistoryRepo.GetHistoryByTime(job.DbInstanceId, job.PointInTime.Time)
 	if err != nil {
 		return err
@@ -360,10 +587,10 @@ func (s *dbScheduler) restorePointInTime(ctx context.Context, program dbi.DbProg
 		TargetPosition:  target.Position,
 		TargetTime:      job.PointInTime.Time,
 	}
-	if err := program.RestoreBackupHistory(ctx, backupHistory.DbName, backupHistory.DbBackupId, backupHistory.Uuid); err != nil {
+	if err := s.restoreFromStorage(ctx, targetProgram, job.GetTargetDbName(), backupHistory); err != nil {
 		return err
 	}
-	return program.ReplayBinlog(ctx, job.DbName, job.DbName, restoreInfo)
+	return targetProgram.ReplayBinlog(ctx, job.DbName, job.GetTargetDbName(), restoreInfo)
 }
 
 func (s *dbScheduler) restoreBackupHistory(ctx context.Context, program dbi.DbProgram, job *entity.DbRestore) error {
@@ -371,10 +598,43 @@ func (s *dbScheduler) restoreBackupHistory(ctx context.Context, program dbi.DbPr
 	if err := s.backupHistoryRepo.GetById(backupHistory, job.DbBackupHistoryId); err != nil {
 		return err
 	}
-	return program.RestoreBackupHistory(ctx, backupHistory.DbName, backupHistory.DbBackupId, backupHistory.Uuid)
+	return s.restoreFromStorage(ctx, program, job.GetTargetDbName(), backupHistory)
+}
+
+// 从备份文件所在的存储流式读取备份文件恢复至 dbName 指定的数据库，不下载至本地
+func (s *dbScheduler) restoreFromStorage(ctx context.Context, program dbi.DbProgram, dbName string, backupHistory *entity.DbBackupHistory) error {
+	bs, err := s.getBackupHistoryStorage(backupHistory, nil)
+	if err != nil {
+		return err
+	}
+	openFile, err := dbi.NewBackupFileOpener(ctx, backupHistory, bs)
+	if err != nil {
+		return err
+	}
+	return program.RestoreBackupHistory(ctx, dbName, backupHistory, openFile)
+}
+
+// 获取备份文件所在的备份存储配置，备份历史中不保存 secret key，s3存储的 secret key 从所属备份任务的存储配置中获取，
+// backup 为 nil 时根据备份历史查询所属备份任务
+func (s *dbScheduler) getBackupHistoryStorage(history *entity.DbBackupHistory, backup *entity.DbBackup) (*entity.DbBackupStorage, error) {
+	bs, err := history.GetStorage()
+	if err != nil || bs.Type != entity.DbBackupStorageS3 {
+		return bs, err
+	}
+	if backup == nil {
+		backup = &entity.DbBackup{}
+		if err := s.backupRepo.GetById(backup, history.DbBackupId); err != nil {
+			return nil, errors.New(fmt.Sprintf("获取备份文件所属的备份任务失败: %v", err))
+		}
+	}
+	current, err := backup.GetStorage()
+	if err != nil {
+		return nil, err
+	}
+	return bs, bs.FillSecret(current)
 }
 
-func (s *dbScheduler) fetchBinlogMysql(ctx context.Context, backup entity.DbJob) error {
+func (s *dbScheduler) fetchBinlog(ctx context.Context, backup entity.DbJob) error {
 	instanceId := backup.GetJobBase().DbInstanceId
 	latestBinlogSequence, earliestBackupSequence := int64(-1), int64(-1)
 	binlogHistory, ok, err := s.binlogHistoryRepo.GetLatestHistory(instanceId)
@@ -398,9 +658,93 @@ func (s *dbScheduler) fetchBinlogMysql(ctx context.Context, backup entity.DbJob)
 		return err
 	}
 	dbProgram := conn.GetDialect().GetDbProgram()
+	if dbProgram == nil {
+		return nil
+	}
 	binlogFiles, err := dbProgram.FetchBinlogs(ctx, false, earliestBackupSequence, latestBinlogSequence)
 	if err == nil {
 		err = s.binlogHistoryRepo.InsertWithBinlogFiles(ctx, instanceId, binlogFiles)
 	}
 	return nil
 }
+
+// 获取数据库实例中指定数据库的连接
+func (s *dbScheduler) getDbConn(instanceId uint64, dbName string) (*dbi.DbConn, error) {
+	var dbs []*entity.Db
+	if err := s.dbApp.ListByCond(&entity.Db{InstanceId: instanceId}, &dbs, "id", "database"); err != nil {
+		return nil, err
+	}
+	for _, db := range dbs {
+		if strings.Contains(" "+db.Database+" ", " "+dbName+" ") {
+			return s.dbApp.GetDbConn(db.Id, dbName)
+		}
+	}
+	return nil, errors.New(fmt.Sprintf("数据库实例中未配置数据库: %s", dbName))
+}
+
+// 获取恢复目标数据库的数据库程序，目标数据库不存在时自动创建（数据库类型不支持创建数据库则返回错误），
+// 返回的释放函数用于关闭目标数据库连接
+func (s *dbScheduler) getTargetDbProgram(ctx context.Context, restore *entity.DbRestore) (dbi.DbProgram, func(), error) {
+	if restore.IsRestoreToSource() {
+		program, err := s.getDbProgram(restore.DbInstanceId, restore.DbName)
+		return program, func() {}, err
+	}
+
+	sourceConn, err := s.getDbConn(restore.DbInstanceId, restore.DbName)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := s.dbApp.GetDbConnByInstanceId(restore.GetTargetDbInstanceId())
+	if err != nil {
+		return nil, nil, err
+	}
+	dbType := conn.Info.Type
+	if dbType != sourceConn.Info.Type {
+		return nil, nil, errors.New(fmt.Sprintf("目标数据库实例类型 %s 与备份数据库类型 %s 不一致", dbType, sourceConn.Info.Type))
+	}
+
+	dbName := restore.GetTargetDbName()
+	// pgsql 等数据库名可带有 schema，如 db/schema
+	database, _, _ := strings.Cut(dbName, "/")
+	dbNames, err := conn.GetDialect().GetDbNames()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !slices.Contains(dbNames, database) {
+		stmt := dbType.StmtCreateDatabase(database)
+		if stmt == "" {
+			return nil, nil, errors.New(fmt.Sprintf("目标数据库 %s 不存在, 且数据库类型 %s 暂不支持自动创建数据库", database, dbType))
+		}
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return nil, nil, errors.New(fmt.Sprintf("创建目标数据库 %s 失败: %v", database, err))
+		}
+		logx.Infof("恢复数据库时创建目标数据库: %s", database)
+	}
+
+	targetInfo := *conn.Info
+	targetInfo.Id = 0
+	targetInfo.Database = dbName
+	targetConn, err := dbm.Conn(&targetInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+	program := targetConn.GetDialect().GetDbProgram()
+	if program == nil {
+		targetConn.Close()
+		return nil, nil, errors.New(fmt.Sprintf("数据库类型 %s 暂不支持备份与恢复", dbType))
+	}
+	return program, targetConn.Close, nil
+}
+
+// 获取数据库实例中指定数据库的数据库程序，用于备份与恢复该数据库
+func (s *dbScheduler) getDbProgram(instanceId uint64, dbName string) (dbi.DbProgram, error) {
+	conn, err := s.getDbConn(instanceId, dbName)
+	if err != nil {
+		return nil, err
+	}
+	dbProgram := conn.GetDialect().GetDbProgram()
+	if dbProgram == nil {
+		return nil, errors.New(fmt.Sprintf("数据库类型 %s 暂不支持备份与恢复", conn.Info.Type))
+	}
+	return dbProgram, nil
+}
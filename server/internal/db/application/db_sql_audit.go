@@ -0,0 +1,293 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"strings"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+)
+
+type SqlAuditRule interface {
+	base.App[*entity.SqlAuditRule]
+
+	// 分页获取sql审核规则
+	GetPageList(condition *entity.SqlAuditRuleQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	Save(ctx context.Context, rule *entity.SqlAuditRule) error
+
+	// NewAuditor 创建数据库的sql审核器，同一请求中的多条sql应共用同一审核器
+	NewAuditor(dbConn *dbi.DbConn) (*SqlAuditor, error)
+}
+
+// SqlAuditRes sql审核结果
+type SqlAuditRes struct {
+	Level    int8     `json:"level"`    // 命中规则中的最高风险等级，0表示未命中任何规则
+	Messages []string `json:"messages"` // 命中规则的提示信息
+}
+
+func (r *SqlAuditRes) add(level int8, msg string) {
+	r.Level = max(r.Level, level)
+	r.Messages = append(r.Messages, msg)
+}
+
+// NeedConfirm 是否需要用户确认后才能执行
+func (r *SqlAuditRes) NeedConfirm() bool {
+	return r != nil && r.Level == entity.SqlAuditLevelConfirm
+}
+
+// Check 校验sql是否可执行，禁止执行或需确认但未确认时返回错误
+func (r *SqlAuditRes) Check(sql string, confirmed bool) error {
+	if r == nil {
+		return nil
+	}
+	switch {
+	case r.Level == entity.SqlAuditLevelBlock:
+		return errorx.NewBiz("SQL[%s]未执行. 存在以下风险: %s", sql, strings.Join(r.Messages, "; "))
+	case r.Level == entity.SqlAuditLevelConfirm && !confirmed:
+		return errorx.NewBiz("SQL[%s]未执行. 存在以下风险, 需确认后执行: %s", sql, strings.Join(r.Messages, "; "))
+	}
+	return nil
+}
+
+type sqlAuditRuleAppImpl struct {
+	base.AppImpl[*entity.SqlAuditRule, repository.SqlAuditRule]
+}
+
+func (app *sqlAuditRuleAppImpl) InjectDbSqlAuditRuleRepo(repo repository.SqlAuditRule) {
+	app.Repo = repo
+}
+
+func (app *sqlAuditRuleAppImpl) GetPageList(condition *entity.SqlAuditRuleQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	return app.GetRepo().GetPageList(condition, pageParam, toEntity, orderBy...)
+}
+
+func (app *sqlAuditRuleAppImpl) Save(ctx context.Context, rule *entity.SqlAuditRule) error {
+	if _, ok := sqlAuditCheckers[rule.RuleType]; !ok {
+		return errorx.NewBiz("不支持的审核规则类型: %s", rule.RuleType)
+	}
+	if rule.Level < entity.SqlAuditLevelWarn || rule.Level > entity.SqlAuditLevelBlock {
+		return errorx.NewBiz("风险等级有误")
+	}
+	if rule.Threshold < 0 {
+		return errorx.NewBiz("表行数阈值不能小于0")
+	}
+	if rule.Id == 0 {
+		return app.Insert(ctx, rule)
+	}
+	return app.UpdateById(ctx, rule)
+}
+
+func (app *sqlAuditRuleAppImpl) NewAuditor(dbConn *dbi.DbConn) (*SqlAuditor, error) {
+	var rules []*entity.SqlAuditRule
+	if err := app.ListByCond(&entity.SqlAuditRule{Status: entity.SqlAuditRuleStatusEnable}, &rules); err != nil {
+		return nil, errorx.NewBiz("获取sql审核规则失败: %s", err.Error())
+	}
+	auditor := &SqlAuditor{dbConn: dbConn, results: make(map[string]*SqlAuditRes)}
+	for _, rule := range rules {
+		if matchTagPath(rule.TagPath, dbConn.Info.TagPath) {
+			auditor.rules = append(auditor.rules, rule)
+		}
+	}
+	return auditor, nil
+}
+
+// SqlAuditor sql审核器，缓存数据库匹配的审核规则、表统计信息及已审核sql的审核结果，
+// 同一请求中的多条sql共用，避免重复查询规则及表信息，且同一sql仅审核一次
+type SqlAuditor struct {
+	dbConn *dbi.DbConn
+	rules  []*entity.SqlAuditRule
+
+	tableRows map[string]int
+	results   map[string]*SqlAuditRes
+}
+
+// Audit 根据审核规则检查sql风险，stmt为nil表示sql解析失败
+func (a *SqlAuditor) Audit(ctx context.Context, sql string, stmt sqlparser.Statement) *SqlAuditRes {
+	if res, ok := a.results[sql]; ok {
+		return res
+	}
+	res := &SqlAuditRes{}
+	ac := &sqlAuditCtx{ctx: ctx, auditor: a, sql: sql, lowerSql: strings.ToLower(sql), stmt: stmt}
+	for _, rule := range a.rules {
+		if msg := sqlAuditCheckers[rule.RuleType](ac, rule); msg != "" {
+			res.add(rule.Level, fmt.Sprintf("[%s] %s", rule.Name, msg))
+		}
+	}
+	a.results[sql] = res
+	return res
+}
+
+// 获取表的行数（来自数据库统计信息，为估算值），获取失败则返回0
+func (a *SqlAuditor) getTableRows(table string) int {
+	if a.tableRows == nil {
+		a.tableRows = make(map[string]int)
+		tables, err := a.dbConn.GetDialect().GetTables()
+		if err != nil {
+			logx.Warnf("sql审核获取表信息失败: %s", err.Error())
+		}
+		for _, t := range tables {
+			a.tableRows[strings.ToLower(t.TableName)] = t.TableRows
+		}
+	}
+	return a.tableRows[strings.ToLower(table)]
+}
+
+// sqlAuditCtx 单条sql的审核上下文，缓存执行计划，避免多条规则重复查询
+type sqlAuditCtx struct {
+	ctx      context.Context
+	auditor  *SqlAuditor
+	sql      string
+	lowerSql string
+	stmt     sqlparser.Statement
+
+	fullScans map[string]int64
+}
+
+// 获取sql中行数达到阈值的表
+func (ac *sqlAuditCtx) getLargeTables(tables []string, threshold int64) []string {
+	largeTables := make([]string, 0)
+	for _, table := range tables {
+		if int64(ac.auditor.getTableRows(table)) >= threshold {
+			largeTables = append(largeTables, table)
+		}
+	}
+	return largeTables
+}
+
+// 获取执行计划中全表扫描的表及预估扫描行数
+func (ac *sqlAuditCtx) getFullScans() map[string]int64 {
+	if ac.fullScans == nil {
+		fullScans, err := explainFullScans(ac.ctx, ac.auditor.dbConn, ac.sql)
+		if err != nil {
+			logx.Warnf("sql审核获取执行计划失败: %s", err.Error())
+		}
+		ac.fullScans = fullScans
+		if ac.fullScans == nil {
+			ac.fullScans = make(map[string]int64)
+		}
+	}
+	return ac.fullScans
+}
+
+// sql审核检查函数，命中规则则返回提示信息
+type sqlAuditChecker func(ac *sqlAuditCtx, rule *entity.SqlAuditRule) string
+
+// 各审核规则类型对应的检查函数
+var sqlAuditCheckers = map[string]sqlAuditChecker{
+	entity.SqlAuditTypeNoWhere: func(ac *sqlAuditCtx, rule *entity.SqlAuditRule) string {
+		switch stmt := ac.stmt.(type) {
+		case *sqlparser.Update:
+			if stmt.Where == nil {
+				return "update语句未带where条件"
+			}
+		case *sqlparser.Delete:
+			if stmt.Where == nil {
+				return "delete语句未带where条件"
+			}
+		}
+		return ""
+	},
+	entity.SqlAuditTypeSelectStar: func(ac *sqlAuditCtx, rule *entity.SqlAuditRule) string {
+		stmt, ok := ac.stmt.(*sqlparser.Select)
+		if !ok {
+			return ""
+		}
+		hasStar := false
+		for _, expr := range stmt.SelectExprs {
+			if _, ok := expr.(*sqlparser.StarExpr); ok {
+				hasStar = true
+				break
+			}
+		}
+		if !hasStar {
+			return ""
+		}
+		if largeTables := ac.getLargeTables(getStmtTables(stmt), rule.Threshold); len(largeTables) > 0 {
+			return fmt.Sprintf("对表[%s]使用了select *", strings.Join(largeTables, ","))
+		}
+		return ""
+	},
+	entity.SqlAuditTypeDropTruncate: func(ac *sqlAuditCtx, rule *entity.SqlAuditRule) string {
+		switch ac.stmt.(type) {
+		case *sqlparser.DropTable, *sqlparser.DropView, *sqlparser.DropDatabase:
+			return "执行drop语句"
+		case *sqlparser.TruncateTable:
+			return "执行truncate语句"
+		case nil:
+			if strings.HasPrefix(ac.lowerSql, "drop ") {
+				return "执行drop语句"
+			}
+			if strings.HasPrefix(ac.lowerSql, "truncate ") {
+				return "执行truncate语句"
+			}
+		}
+		return ""
+	},
+	entity.SqlAuditTypeNoLimit: func(ac *sqlAuditCtx, rule *entity.SqlAuditRule) string {
+		switch stmt := ac.stmt.(type) {
+		case *sqlparser.Select:
+			if stmt.Limit == nil {
+				return "查询语句未带limit"
+			}
+		case nil:
+			if strings.HasPrefix(ac.lowerSql, "select") && !strings.Contains(ac.lowerSql, "limit") && !strings.Contains(ac.lowerSql, "rownum") {
+				return "查询语句未带limit"
+			}
+		}
+		return ""
+	},
+	entity.SqlAuditTypeFullScan: func(ac *sqlAuditCtx, rule *entity.SqlAuditRule) string {
+		switch ac.stmt.(type) {
+		case *sqlparser.Select, *sqlparser.Update, *sqlparser.Delete:
+		default:
+			return ""
+		}
+		tables := make([]string, 0)
+		for table, rows := range ac.getFullScans() {
+			if rows >= rule.Threshold {
+				tables = append(tables, table)
+			}
+		}
+		if len(tables) > 0 {
+			return fmt.Sprintf("对表[%s]进行了全表扫描", strings.Join(tables, ","))
+		}
+		return ""
+	},
+	entity.SqlAuditTypeDdlLargeTable: func(ac *sqlAuditCtx, rule *entity.SqlAuditRule) string {
+		stmt, ok := ac.stmt.(sqlparser.DDLStatement)
+		if !ok || stmt.GetAction() == sqlparser.CreateDDLAction {
+			return ""
+		}
+		tables := make([]string, 0)
+		for _, table := range stmt.AffectedTables() {
+			tables = append(tables, table.Name.String())
+		}
+		if largeTables := ac.getLargeTables(tables, rule.Threshold); len(largeTables) > 0 {
+			return fmt.Sprintf("对大表[%s]执行ddl", strings.Join(largeTables, ","))
+		}
+		return ""
+	},
+}
+
+// explainFullScans 通过执行计划获取全表扫描的表及预估扫描行数
+func explainFullScans(ctx context.Context, dbConn *dbi.DbConn, sql string) (map[string]int64, error) {
+	plan, err := dbConn.GetDialect().Explain(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	fullScans := make(map[string]int64)
+	plan.Walk(func(node *dbi.ExplainNode) {
+		if node.FullScan && node.Table != "" {
+			fullScans[node.Table] = max(fullScans[node.Table], node.Rows)
+		}
+	})
+	return fullScans, nil
+}
@@ -0,0 +1,77 @@
+package application
+
+import (
+	"context"
+	"mayfly-go/internal/db/domain/entity"
+	"testing"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSqlAuditor(rules ...*entity.SqlAuditRule) *SqlAuditor {
+	return &SqlAuditor{
+		rules:     rules,
+		tableRows: map[string]int{"t_order": 100000, "t_dict": 10},
+		results:   make(map[string]*SqlAuditRes),
+	}
+}
+
+func Test_sqlAuditCheckers(t *testing.T) {
+	tableName := func(name string) sqlparser.TableName {
+		return sqlparser.TableName{Name: sqlparser.NewIdentifierCS(name)}
+	}
+	cases := []struct {
+		ruleType  string
+		threshold int64
+		sql       string
+		stmt      sqlparser.Statement
+		hit       bool
+	}{
+		{entity.SqlAuditTypeNoWhere, 0, "update t_order set a = 1", &sqlparser.Update{}, true},
+		{entity.SqlAuditTypeNoWhere, 0, "delete from t_order where id = 1", &sqlparser.Delete{Where: &sqlparser.Where{}}, false},
+		{entity.SqlAuditTypeNoLimit, 0, "select * from t_order", &sqlparser.Select{}, true},
+		{entity.SqlAuditTypeNoLimit, 0, "select * from t_order limit 10", &sqlparser.Select{Limit: &sqlparser.Limit{}}, false},
+		{entity.SqlAuditTypeNoLimit, 0, "select * from t_order where rownum < 10", nil, false},
+		{entity.SqlAuditTypeDropTruncate, 0, "drop table t_order", &sqlparser.DropTable{}, true},
+		{entity.SqlAuditTypeDropTruncate, 0, "truncate t_order", nil, true},
+		{entity.SqlAuditTypeDropTruncate, 0, "select 1", &sqlparser.Select{}, false},
+		{entity.SqlAuditTypeDdlLargeTable, 1000, "alter table t_order add c int", &sqlparser.AlterTable{Table: tableName("t_order")}, true},
+		{entity.SqlAuditTypeDdlLargeTable, 1000, "alter table t_dict add c int", &sqlparser.AlterTable{Table: tableName("T_DICT")}, false},
+		{entity.SqlAuditTypeDdlLargeTable, 1000, "create table t_order (id int)", &sqlparser.CreateTable{Table: tableName("t_order")}, false},
+	}
+	for _, c := range cases {
+		auditor := newTestSqlAuditor(&entity.SqlAuditRule{Name: "rule", RuleType: c.ruleType, Level: entity.SqlAuditLevelWarn, Threshold: c.threshold})
+		res := auditor.Audit(context.Background(), c.sql, c.stmt)
+		require.Equal(t, c.hit, len(res.Messages) > 0, c.sql)
+	}
+}
+
+func Test_SqlAuditorAudit(t *testing.T) {
+	auditor := newTestSqlAuditor(
+		&entity.SqlAuditRule{Name: "no-where", RuleType: entity.SqlAuditTypeNoWhere, Level: entity.SqlAuditLevelConfirm},
+		&entity.SqlAuditRule{Name: "drop", RuleType: entity.SqlAuditTypeDropTruncate, Level: entity.SqlAuditLevelBlock},
+	)
+	ctx := context.Background()
+
+	res := auditor.Audit(ctx, "delete from t_order", &sqlparser.Delete{})
+	require.Equal(t, entity.SqlAuditLevelConfirm, res.Level)
+	require.Equal(t, []string{"[no-where] delete语句未带where条件"}, res.Messages)
+	require.True(t, res.NeedConfirm())
+	require.Error(t, res.Check("delete from t_order", false))
+	require.NoError(t, res.Check("delete from t_order", true))
+	// 同一sql仅审核一次，执行时复用审核结果
+	require.Same(t, res, auditor.Audit(ctx, "delete from t_order", &sqlparser.Delete{}))
+
+	res = auditor.Audit(ctx, "drop table t_order", &sqlparser.DropTable{})
+	require.Equal(t, entity.SqlAuditLevelBlock, res.Level)
+	require.Error(t, res.Check("drop table t_order", true))
+
+	res = auditor.Audit(ctx, "select 1", &sqlparser.Select{})
+	require.Equal(t, int8(0), res.Level)
+	require.NoError(t, res.Check("select 1", false))
+
+	var nilRes *SqlAuditRes
+	require.False(t, nilRes.NeedConfirm())
+	require.NoError(t, nilRes.Check("select 1", false))
+}
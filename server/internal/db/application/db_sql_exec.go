@@ -3,16 +3,27 @@ package application
 import (
 	"context"
 	"fmt"
+	"mayfly-go/internal/common/consts"
 	"mayfly-go/internal/db/config"
 	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/domain/entity"
 	"mayfly-go/internal/db/domain/repository"
+	sysapp "mayfly-go/internal/sys/application"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/contextx"
 	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/scheduler"
+	"mayfly-go/pkg/utils/anyx"
 	"mayfly-go/pkg/utils/jsonx"
+	"mayfly-go/pkg/utils/stringx"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/kanzihuang/vitess/go/vt/sqlparser"
 )
@@ -23,15 +34,21 @@ type DbSqlExecReq struct {
 	Sql    string
 	Remark string
 	DbConn *dbi.DbConn
+
+	ShowRawData    bool        // 是否返回未脱敏的原始数据，需调用方校验账号拥有查看原始数据的权限
+	AuditConfirmed bool        // 是否已确认sql审核中需确认的风险
+	Auditor        *SqlAuditor // sql审核器，为空则执行时创建，同一请求中的多条sql共用
 }
 
 type DbSqlExecRes struct {
-	Columns []*dbi.QueryColumn
-	Res     []map[string]any
+	Columns  []*dbi.QueryColumn
+	Res      []map[string]any
+	Warnings []string // sql审核警告信息
 }
 
 // 合并执行结果，主要用于执行多条sql使用
 func (d *DbSqlExecRes) Merge(execRes *DbSqlExecRes) {
+	d.Warnings = append(d.Warnings, execRes.Warnings...)
 	canMerge := len(d.Columns) == len(execRes.Columns)
 	if !canMerge {
 		return
@@ -49,23 +66,91 @@ type DbSqlExec interface {
 	// 执行sql
 	Exec(ctx context.Context, execSqlReq *DbSqlExecReq) (*DbSqlExecRes, error)
 
+	// 分页查询表数据，并对敏感字段进行脱敏
+	GetTableData(ctx context.Context, dbConn *dbi.DbConn, query *dbi.TableDataQuery, showRawData bool) (*dbi.TableDataRes, error)
+
+	// 注册执行中的sql，返回可取消且绑定了独占连接的上下文，sql执行结束后需调用返回的释放函数
+	RegisterRunningSql(ctx context.Context, execId string, dbConn *dbi.DbConn, sql string) (context.Context, func(), error)
+
+	// 取消执行中的sql
+	CancelRunningSql(ctx context.Context, execId string) error
+
+	// 获取指定账号执行中的sql列表
+	GetRunningSqls(accountId uint64) []*RunningSql
+
+	// 审批待执行的sql，审批通过则执行该sql
+	Approve(ctx context.Context, id uint64, pass bool, remark string) error
+
+	// 根据update、delete执行记录的旧值生成回滚sql
+	GenRollbackSql(ctx context.Context, id uint64) ([]string, error)
+
+	// 执行update、delete执行记录的回滚sql
+	Rollback(ctx context.Context, id uint64) (*DbSqlExecRes, error)
+
+	// 根据主键保存表数据的新增、修改及删除，所有修改在同一事务中执行并分别记录执行记录
+	SaveRowChanges(ctx context.Context, changeReq *DbRowChangeReq) (*DbSqlExecRes, error)
+
+	// 读取数据文件的前几行，并根据表头或列顺序生成默认的列映射
+	PreviewImportData(ctx context.Context, importReq *DbDataImportReq) (*DbDataImportPreview, error)
+
+	// 将csv、xlsx、jsonl文件数据按列映射在同一事务中批量导入至表，并推送导入进度
+	ImportData(ctx context.Context, importReq *DbDataImportReq) (*DbDataImportProgress, error)
+
 	// 根据条件删除sql执行记录
 	DeleteBy(ctx context.Context, condition *entity.DbSqlExec)
 
 	// 分页获取
 	GetPageList(condition *entity.DbSqlExecQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	// 定时将已过期的待审批sql执行记录置为审批过期
+	TimerExpireApproval()
 }
 
 type dbSqlExecAppImpl struct {
 	DbSqlExecRepo repository.DbSqlExec `inject:""`
+
+	DbApp   Db             `inject:""`
+	RoleApp sysapp.Role    `inject:""`
+	TeamApp tagapp.Team    `inject:""`
+	TagApp  tagapp.TagTree `inject:"TagTreeApp"`
+
+	DataMaskRuleApp DataMaskRule `inject:"DbDataMaskRuleApp"`
+	SqlAuditRuleApp SqlAuditRule `inject:"DbSqlAuditRuleApp"`
+	DbMetaCacheApp  DbMetaCache  `inject:""`
+
+	runningSqls sync.Map // 执行中的sql, execId -> *RunningSql
 }
 
+// 执行中的sql信息
+type RunningSql struct {
+	ExecId    string    `json:"execId"`
+	DbId      uint64    `json:"dbId"`
+	Db        string    `json:"db"`
+	Sql       string    `json:"sql"`
+	AccountId uint64    `json:"accountId"`
+	StartTime time.Time `json:"startTime"`
+
+	connId string // 执行sql的数据库连接id
+	dbConn *dbi.DbConn
+	cancel context.CancelFunc
+}
+
+// 执行update、delete时记录的最大旧值条数
+const maxOldValueCount = 200
+
+// sql执行记录中执行结果的最大长度（res字段为varchar(1000)）
+const maxSqlExecResLen = 1000
+
+// sql执行记录中sql的最大长度（sql字段为varchar(5000)）
+const maxSqlExecSqlLen = 5000
+
 func createSqlExecRecord(ctx context.Context, execSqlReq *DbSqlExecReq) *entity.DbSqlExec {
 	dbSqlExecRecord := new(entity.DbSqlExec)
 	dbSqlExecRecord.DbId = execSqlReq.DbId
 	dbSqlExecRecord.Db = execSqlReq.Db
 	dbSqlExecRecord.Sql = execSqlReq.Sql
 	dbSqlExecRecord.Remark = execSqlReq.Remark
+	dbSqlExecRecord.Status = entity.DbSqlExecStatusSuccess
 	dbSqlExecRecord.FillBaseInfo(model.IdGenTypeNone, contextx.GetLoginAccount(ctx))
 	return dbSqlExecRecord
 }
@@ -78,11 +163,25 @@ func (d *dbSqlExecAppImpl) Exec(ctx context.Context, execSqlReq *DbSqlExecReq) (
 	isSelect := false
 
 	stmt, err := sqlparser.Parse(sql)
+	if execSqlReq.Auditor == nil {
+		auditor, auditErr := d.SqlAuditRuleApp.NewAuditor(execSqlReq.DbConn)
+		if auditErr != nil {
+			return nil, auditErr
+		}
+		execSqlReq.Auditor = auditor
+	}
+	auditRes := execSqlReq.Auditor.Audit(ctx, sql, stmt)
+	if err := auditRes.Check(sql, execSqlReq.AuditConfirmed); err != nil {
+		return nil, err
+	}
+
 	if err != nil {
 		// 就算解析失败也执行sql，让数据库来判断错误。如果是查询sql则简单判断是否有limit分页参数信息（兼容pgsql）
 		// logx.Warnf("sqlparse解析sql[%s]失败: %s", sql, err.Error())
 		lowerSql := strings.ToLower(execSqlReq.Sql)
-		isSelect := strings.HasPrefix(lowerSql, "select")
+		dbType := execSqlReq.DbConn.Info.Type
+		// clickhouse的with子句仅用于查询
+		isSelect := strings.HasPrefix(lowerSql, "select") || (dbType == dbi.DbTypeClickhouse && strings.HasPrefix(lowerSql, "with"))
 		if isSelect {
 			// 如果配置为0，则不校验分页参数
 			maxCount := config.GetDbQueryMaxCount()
@@ -97,15 +196,27 @@ func (d *dbSqlExecAppImpl) Exec(ctx context.Context, execSqlReq *DbSqlExecReq) (
 			}
 		}
 		var execErr error
-		if isSelect || strings.HasPrefix(lowerSql, "show") {
+		if isSelect || isReadSqlPrefix(dbType, lowerSql) {
 			execRes, execErr = doRead(ctx, execSqlReq)
 		} else {
+			if d.needApproval(execSqlReq) {
+				return d.submitApproval(ctx, nil, dbSqlExecRecord)
+			}
 			execRes, execErr = doExec(ctx, execSqlReq.Sql, execSqlReq.DbConn)
 		}
 		if execErr != nil {
 			return nil, execErr
 		}
+		if isDdl(nil, lowerSql) {
+			d.DbMetaCacheApp.Invalidate(execSqlReq.DbConn)
+		}
+		if isSelect {
+			if err := d.maskQueryRes(ctx, nil, execSqlReq, execRes); err != nil {
+				return nil, err
+			}
+		}
 		d.saveSqlExecLog(isSelect, dbSqlExecRecord)
+		execRes.Warnings = auditRes.Messages
 		return execRes, nil
 	}
 
@@ -119,22 +230,151 @@ func (d *dbSqlExecAppImpl) Exec(ctx context.Context, execSqlReq *DbSqlExecReq) (
 	case *sqlparser.OtherRead:
 		isSelect = true
 		execRes, err = doRead(ctx, execSqlReq)
-	case *sqlparser.Update:
-		execRes, err = doUpdate(ctx, stmt, execSqlReq, dbSqlExecRecord)
-	case *sqlparser.Delete:
-		execRes, err = doDelete(ctx, stmt, execSqlReq, dbSqlExecRecord)
-	case *sqlparser.Insert:
-		execRes, err = doInsert(ctx, stmt, execSqlReq, dbSqlExecRecord)
 	default:
-		execRes, err = doExec(ctx, execSqlReq.Sql, execSqlReq.DbConn)
+		if d.needApproval(execSqlReq) {
+			return d.submitApproval(ctx, stmt, dbSqlExecRecord)
+		}
+		execRes, err = doWrite(ctx, stmt, execSqlReq, dbSqlExecRecord)
 	}
 	if err != nil {
 		return nil, err
 	}
+	if isDdl(stmt, sql) {
+		d.DbMetaCacheApp.Invalidate(execSqlReq.DbConn)
+	}
+	if isSelect {
+		if err := d.maskQueryRes(ctx, stmt, execSqlReq, execRes); err != nil {
+			return nil, err
+		}
+	}
 	d.saveSqlExecLog(isSelect, dbSqlExecRecord)
+	execRes.Warnings = auditRes.Messages
 	return execRes, nil
 }
 
+// 可能修改数据的关键字，用于无法解析的查询语句，如pgsql with子句中的delete ... returning、select into等
+var dataModifyKeywordRegexp = regexp.MustCompile(`\b(insert|update|delete|merge|into)\b`)
+
+// ParseSingleSelect 校验sql为单条select查询语句并返回解析后的语句。
+// 解析失败时（如部分数据库特有的语法）返回的语句为nil，此时要求以select或with开头，且不含可能修改数据的关键字
+func ParseSingleSelect(dbType dbi.DbType, sql string) (sqlparser.Statement, error) {
+	sqls, err := dbType.SplitSql(sql)
+	if err != nil {
+		return nil, errorx.NewBiz("sql解析错误: %s", err.Error())
+	}
+	if len(sqls) != 1 {
+		return nil, errorx.NewBiz("仅支持单条select查询语句")
+	}
+
+	stmt, err := sqlparser.Parse(sqls[0])
+	if err == nil {
+		switch stmt := stmt.(type) {
+		case *sqlparser.Select:
+			if stmt.Into == nil {
+				return stmt, nil
+			}
+		case *sqlparser.Union:
+			return stmt, nil
+		}
+		return nil, errorx.NewBiz("仅支持单条select查询语句")
+	}
+
+	lowerSql := strings.ToLower(sqls[0])
+	if !strings.HasPrefix(lowerSql, "select") && !strings.HasPrefix(lowerSql, "with") {
+		return nil, errorx.NewBiz("仅支持单条select查询语句")
+	}
+	if dataModifyKeywordRegexp.MatchString(lowerSql) {
+		return nil, errorx.NewBiz("查询语句中不能包含数据修改语句")
+	}
+	return nil, nil
+}
+
+// sql解析失败时，根据语句前缀判断是否为返回结果集的只读语句
+func isReadSqlPrefix(dbType dbi.DbType, lowerSql string) bool {
+	if strings.HasPrefix(lowerSql, "show") {
+		return true
+	}
+	// clickhouse的describe、explain、exists等只读语句无法被sql解析器识别
+	if dbType == dbi.DbTypeClickhouse {
+		for _, prefix := range []string{"desc", "explain", "exists"} {
+			if strings.HasPrefix(lowerSql, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 判断是否为ddl语句，sql解析失败时（stmt为nil）根据语句前缀判断
+func isDdl(stmt sqlparser.Statement, sql string) bool {
+	if stmt != nil {
+		_, ok := stmt.(sqlparser.DDLStatement)
+		return ok
+	}
+	lowerSql := strings.ToLower(strings.TrimSpace(sql))
+	for _, prefix := range []string{"create", "alter", "drop", "rename", "truncate", "comment"} {
+		if strings.HasPrefix(lowerSql, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// 对查询结果中的敏感字段进行脱敏，若返回未脱敏的原始数据则记录审计日志
+func (d *dbSqlExecAppImpl) maskQueryRes(ctx context.Context, stmt sqlparser.Statement, execSqlReq *DbSqlExecReq, execRes *DbSqlExecRes) error {
+	masker, err := d.DataMaskRuleApp.GetDataMasker(execSqlReq.DbConn, getStmtTables(stmt)...)
+	if err != nil {
+		return err
+	}
+	if err := masker.ResolveSelectMaskTypes(stmt, execRes.Columns); err != nil && !execSqlReq.ShowRawData {
+		return err
+	}
+	maskColumns := masker.MaskColumns(execRes.Columns)
+	if len(maskColumns) == 0 {
+		return nil
+	}
+	if execSqlReq.ShowRawData {
+		d.DataMaskRuleApp.SaveRawDataAudit(ctx, execSqlReq.DbConn, execSqlReq.Sql, maskColumns)
+		return nil
+	}
+	masker.MaskRows(execRes.Res)
+	return nil
+}
+
+func (d *dbSqlExecAppImpl) GetTableData(ctx context.Context, dbConn *dbi.DbConn, query *dbi.TableDataQuery, showRawData bool) (*dbi.TableDataRes, error) {
+	query.Table = dbConn.Info.Type.RemoveQuote(query.Table)
+	masker, err := d.DataMaskRuleApp.GetDataMasker(dbConn, query.Table)
+	if err != nil {
+		return nil, err
+	}
+	if !showRawData {
+		if err := masker.CheckTableDataQuery(query); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := dbConn.GetDialect().GetTableData(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	maskColumns := masker.MaskColumns(res.Columns)
+	if len(maskColumns) == 0 {
+		return res, nil
+	}
+	if showRawData {
+		d.DataMaskRuleApp.SaveRawDataAudit(ctx, dbConn, fmt.Sprintf("查看表[%s]数据", query.Table), maskColumns)
+		return res, nil
+	}
+	// 键集分页的主键为敏感字段时，下一页的主键值会泄露原始数据
+	for column := range res.NextKey {
+		if masker.GetMaskType(column) != "" {
+			return nil, errorx.NewBiz("主键[%s]为敏感字段, 不支持键集分页", column)
+		}
+	}
+	masker.MaskRows(res.Rows)
+	return res, nil
+}
+
 // 保存sql执行记录，如果是查询类则根据系统配置判断是否保存
 func (d *dbSqlExecAppImpl) saveSqlExecLog(isQuery bool, dbSqlExecRecord *entity.DbSqlExec) {
 	if !isQuery {
@@ -149,6 +389,520 @@ func (d *dbSqlExecAppImpl) saveSqlExecLog(isQuery bool, dbSqlExecRecord *entity.
 	}
 }
 
+func (d *dbSqlExecAppImpl) RegisterRunningSql(ctx context.Context, execId string, dbConn *dbi.DbConn, sql string) (context.Context, func(), error) {
+	ctx, cancel := context.WithCancel(ctx)
+	if execId == "" {
+		return ctx, cancel, nil
+	}
+
+	ctx, connId, releaseConn, err := dbConn.BindConn(ctx)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	runningSql := &RunningSql{
+		ExecId:    execId,
+		DbId:      dbConn.Info.Id,
+		Db:        dbConn.Info.Database,
+		Sql:       sql,
+		StartTime: time.Now(),
+		connId:    connId,
+		dbConn:    dbConn,
+		cancel:    cancel,
+	}
+	if la := contextx.GetLoginAccount(ctx); la != nil {
+		runningSql.AccountId = la.Id
+	}
+	// 使用LoadOrStore保证同一执行id并发注册时仅一个成功
+	if _, loaded := d.runningSqls.LoadOrStore(execId, runningSql); loaded {
+		cancel()
+		releaseConn()
+		return nil, nil, errorx.NewBiz("该执行id[%s]的sql正在执行中", execId)
+	}
+
+	return ctx, func() {
+		d.runningSqls.Delete(execId)
+		cancel()
+		releaseConn()
+	}, nil
+}
+
+func (d *dbSqlExecAppImpl) CancelRunningSql(ctx context.Context, execId string) error {
+	value, ok := d.runningSqls.Load(execId)
+	if !ok {
+		return errorx.NewBiz("该sql已执行结束或不存在")
+	}
+	runningSql := value.(*RunningSql)
+
+	la := contextx.GetLoginAccount(ctx)
+	if la.Id != runningSql.AccountId && la.Id != consts.AdminId {
+		return errorx.NewBiz("只能取消自己执行的sql")
+	}
+
+	// 先通知数据库取消执行，再取消上下文，防止仅断开连接而数据库仍在执行
+	if err := runningSql.dbConn.CancelQuery(runningSql.connId); err != nil {
+		// 仍取消上下文以断开连接，但数据库可能仍在执行该sql
+		runningSql.cancel()
+		return errorx.NewBiz("%s, 已断开连接, 数据库可能仍在执行该sql", err.Error())
+	}
+	runningSql.cancel()
+	return nil
+}
+
+func (d *dbSqlExecAppImpl) GetRunningSqls(accountId uint64) []*RunningSql {
+	runningSqls := make([]*RunningSql, 0)
+	d.runningSqls.Range(func(key, value any) bool {
+		if runningSql := value.(*RunningSql); runningSql.AccountId == accountId {
+			runningSqls = append(runningSqls, runningSql)
+		}
+		return true
+	})
+	return runningSqls
+}
+
+// 判断该数据库执行非查询sql是否需要审批
+func (d *dbSqlExecAppImpl) needApproval(execSqlReq *DbSqlExecReq) bool {
+	return config.GetDbSqlApproval().NeedApproval(execSqlReq.DbConn.Info.TagPath...)
+}
+
+// 保存为待审批的sql执行记录，审批通过后再执行
+func (d *dbSqlExecAppImpl) submitApproval(ctx context.Context, stmt sqlparser.Statement, dbSqlExecRecord *entity.DbSqlExec) (*DbSqlExecRes, error) {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Update:
+		if len(sqlparser.String(stmt.Where)) == 0 {
+			return nil, errorx.NewBiz("SQL[%s]未执行. 请完善 where 条件后再执行", dbSqlExecRecord.Sql)
+		}
+		dbSqlExecRecord.Table = strings.Split(sqlparser.String(stmt.TableExprs), " ")[0]
+		dbSqlExecRecord.Type = entity.DbSqlExecTypeUpdate
+	case *sqlparser.Delete:
+		if len(sqlparser.String(stmt.Where)) == 0 {
+			return nil, errorx.NewBiz("SQL[%s]未执行. 请完善 where 条件后再执行", dbSqlExecRecord.Sql)
+		}
+		dbSqlExecRecord.Table = strings.Split(sqlparser.String(stmt.TableExprs), " ")[0]
+		dbSqlExecRecord.Type = entity.DbSqlExecTypeDelete
+	case *sqlparser.Insert:
+		dbSqlExecRecord.Table = strings.Split(sqlparser.String(stmt.Table), " ")[0]
+		dbSqlExecRecord.Type = entity.DbSqlExecTypeInsert
+	}
+
+	expireTime := time.Now().Add(config.GetDbSqlApproval().ExpireTime)
+	dbSqlExecRecord.ExpireTime = &expireTime
+	dbSqlExecRecord.Status = entity.DbSqlExecStatusWait
+	if err := d.DbSqlExecRepo.Insert(ctx, dbSqlExecRecord); err != nil {
+		return nil, err
+	}
+
+	res := make([]map[string]any, 0)
+	resData := make(map[string]any)
+	resData["rowsAffected"] = 0
+	resData["sql"] = dbSqlExecRecord.Sql
+	resData["result"] = "已提交审批, 审批通过后执行"
+	res = append(res, resData)
+
+	return &DbSqlExecRes{
+		Columns: []*dbi.QueryColumn{
+			{Name: "sql", Type: "string"},
+			{Name: "rowsAffected", Type: "number"},
+			{Name: "result", Type: "string"},
+		},
+		Res: res,
+	}, nil
+}
+
+func (d *dbSqlExecAppImpl) Approve(ctx context.Context, id uint64, pass bool, remark string) error {
+	sqlExec := new(entity.DbSqlExec)
+	if err := d.DbSqlExecRepo.GetById(sqlExec, id); err != nil {
+		return errorx.NewBiz("sql执行记录不存在")
+	}
+	if sqlExec.Status != entity.DbSqlExecStatusWait {
+		return errorx.NewBiz("该sql执行记录非待审批状态")
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	if !d.isApprover(la.Id) {
+		return errorx.NewBiz("您不是该sql的审批人")
+	}
+	if sqlExec.CreatorId == la.Id {
+		return errorx.NewBiz("不可审批自己提交的sql")
+	}
+
+	// 先抢占待审批记录，防止并发审批导致sql被重复执行
+	claimed, err := d.DbSqlExecRepo.UpdateStatus(id, entity.DbSqlExecStatusWait, entity.DbSqlExecStatusApproving)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return errorx.NewBiz("该sql执行记录非待审批状态")
+	}
+
+	now := time.Now()
+	update := &entity.DbSqlExec{
+		ApproverId:    la.Id,
+		Approver:      la.Username,
+		ApproveTime:   &now,
+		ApproveRemark: remark,
+	}
+	update.Id = id
+
+	if sqlExec.ExpireTime != nil && sqlExec.ExpireTime.Before(now) {
+		update.Status = entity.DbSqlExecStatusExpired
+		d.updateApproveRes(ctx, update)
+		return errorx.NewBiz("该sql执行申请已过期")
+	}
+
+	if !pass {
+		update.Status = entity.DbSqlExecStatusReject
+		return d.updateApproveRes(ctx, update)
+	}
+
+	dbConn, err := d.DbApp.GetDbConn(sqlExec.DbId, sqlExec.Db)
+	if err != nil {
+		update.Status = entity.DbSqlExecStatusFail
+		update.Res = err.Error()
+		d.updateApproveRes(ctx, update)
+		return err
+	}
+	if sqlExec.RollbackId != 0 {
+		return d.approveRollback(ctx, sqlExec, dbConn, update)
+	}
+	execSqlReq := &DbSqlExecReq{
+		DbId:   sqlExec.DbId,
+		Db:     sqlExec.Db,
+		Sql:    sqlExec.Sql,
+		Remark: sqlExec.Remark,
+		DbConn: dbConn,
+	}
+	// 解析失败则直接交由数据库执行
+	stmt, _ := sqlparser.Parse(sqlExec.Sql)
+	execRes, err := doWrite(ctx, stmt, execSqlReq, update)
+	if err != nil {
+		update.Status = entity.DbSqlExecStatusFail
+		update.Res = err.Error()
+	} else {
+		update.Status = entity.DbSqlExecStatusSuccess
+		update.Res = jsonx.ToStr(execRes.Res)
+		if isDdl(stmt, sqlExec.Sql) {
+			d.DbMetaCacheApp.Invalidate(dbConn)
+		}
+	}
+	if updateErr := d.updateApproveRes(ctx, update); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// 审批通过后在同一事务中执行回滚脚本，执行成功后将原记录标记为已回滚
+func (d *dbSqlExecAppImpl) approveRollback(ctx context.Context, sqlExec *entity.DbSqlExec, dbConn *dbi.DbConn, update *entity.DbSqlExec) error {
+	rollbackSqls, err := dbConn.Info.Type.SplitSql(sqlExec.Sql)
+	if err == nil {
+		var rowsAffected int64
+		if rowsAffected, err = d.execRollbackSqls(ctx, sqlExec.RollbackId, dbConn, rollbackSqls); err == nil {
+			update.Status = entity.DbSqlExecStatusSuccess
+			update.Res = fmt.Sprintf("rowsAffected: %d", rowsAffected)
+		}
+	}
+	if err != nil {
+		update.Status = entity.DbSqlExecStatusFail
+		update.Res = err.Error()
+	}
+	if updateErr := d.updateApproveRes(ctx, update); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// 更新审批结果，执行结果过长则截断；更新失败时至少保证状态被更新，避免记录一直处于审批执行中
+func (d *dbSqlExecAppImpl) updateApproveRes(ctx context.Context, update *entity.DbSqlExec) error {
+	update.Res = stringx.TruncateStr(update.Res, maxSqlExecResLen)
+	err := d.DbSqlExecRepo.UpdateById(ctx, update)
+	if err == nil {
+		return nil
+	}
+	logx.Errorf("更新sql执行记录[%d]审批结果失败: %s", update.Id, err.Error())
+	if _, statusErr := d.DbSqlExecRepo.UpdateStatus(update.Id, entity.DbSqlExecStatusApproving, update.Status); statusErr != nil {
+		return statusErr
+	}
+	return err
+}
+
+func (d *dbSqlExecAppImpl) TimerExpireApproval() {
+	logx.Debug("开始定时处理过期的sql执行审批...")
+	scheduler.AddFun("@every 1m", func() {
+		if _, err := d.DbSqlExecRepo.ExpireApprovals(time.Now()); err != nil {
+			logx.Errorf("更新过期的sql执行审批失败: %s", err.Error())
+		}
+	})
+}
+
+// 判断账号是否为sql执行审批人（审批角色或审批团队成员）
+func (d *dbSqlExecAppImpl) isApprover(accountId uint64) bool {
+	if accountId == consts.AdminId {
+		return true
+	}
+
+	approval := config.GetDbSqlApproval()
+	if approval.ApproverTeamId != 0 && d.TeamApp.IsExistMember(approval.ApproverTeamId, accountId) {
+		return true
+	}
+	if approval.ApproverRoleId != 0 {
+		accountRoles, _ := d.RoleApp.GetAccountRoles(accountId)
+		for _, accountRole := range accountRoles {
+			if accountRole.RoleId == approval.ApproverRoleId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d *dbSqlExecAppImpl) GenRollbackSql(ctx context.Context, id uint64) ([]string, error) {
+	sqlExec, dbConn, err := d.getRollbackSqlExec(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rollbackSqls, _, err := genRollbackSql(sqlExec, dbConn)
+	return rollbackSqls, err
+}
+
+func (d *dbSqlExecAppImpl) Rollback(ctx context.Context, id uint64) (*DbSqlExecRes, error) {
+	sqlExec, dbConn, err := d.getRollbackSqlExec(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rollbackSqls, rowCount, err := genRollbackSql(sqlExec, dbConn)
+	if err != nil {
+		return nil, err
+	}
+	if rowCount >= maxOldValueCount {
+		return nil, errorx.NewBiz("旧值仅记录了前%d条数据, 不支持一键回滚, 请导出回滚sql后自行核对执行", maxOldValueCount)
+	}
+
+	execSqlReq := &DbSqlExecReq{
+		DbId:   sqlExec.DbId,
+		Db:     sqlExec.Db,
+		Sql:    strings.Join(rollbackSqls, ";\n") + ";",
+		Remark: fmt.Sprintf("回滚sql执行记录[%d]", id),
+		DbConn: dbConn,
+	}
+	// 需要审批的库，整个回滚脚本作为一条审批记录提交，审批通过后在同一事务中执行
+	if d.needApproval(execSqlReq) {
+		return d.submitRollbackApproval(ctx, sqlExec, execSqlReq)
+	}
+
+	rowsAffected, err := d.execRollbackSqls(ctx, id, dbConn, rollbackSqls)
+	if err != nil {
+		return nil, err
+	}
+
+	execSqlReq.Sql = stringx.TruncateStr(execSqlReq.Sql, maxSqlExecSqlLen)
+	dbSqlExecRecord := createSqlExecRecord(ctx, execSqlReq)
+	dbSqlExecRecord.Type = entity.DbSqlExecTypeOther
+	dbSqlExecRecord.Table = sqlExec.Table
+	dbSqlExecRecord.RollbackId = id
+	dbSqlExecRecord.Res = fmt.Sprintf("rowsAffected: %d", rowsAffected)
+	d.saveSqlExecLog(false, dbSqlExecRecord)
+
+	res := make([]map[string]any, 0)
+	resData := make(map[string]any)
+	resData["rowsAffected"] = rowsAffected
+	resData["sql"] = execSqlReq.Sql
+	resData["result"] = "success"
+	res = append(res, resData)
+	return &DbSqlExecRes{
+		Columns: []*dbi.QueryColumn{
+			{Name: "sql", Type: "string"},
+			{Name: "rowsAffected", Type: "number"},
+			{Name: "result", Type: "string"},
+		},
+		Res: res,
+	}, nil
+}
+
+// 提交回滚脚本审批，审批通过前原记录状态不变
+func (d *dbSqlExecAppImpl) submitRollbackApproval(ctx context.Context, sqlExec *entity.DbSqlExec, execSqlReq *DbSqlExecReq) (*DbSqlExecRes, error) {
+	if len(execSqlReq.Sql) > maxSqlExecSqlLen {
+		return nil, errorx.NewBiz("回滚sql过长, 不支持提交审批, 请导出回滚sql后自行核对执行")
+	}
+	for _, status := range []int8{entity.DbSqlExecStatusWait, entity.DbSqlExecStatusApproving} {
+		if d.DbSqlExecRepo.CountByCond(&entity.DbSqlExec{RollbackId: sqlExec.Id, Status: status}) > 0 {
+			return nil, errorx.NewBiz("该sql执行记录已提交回滚审批, 请勿重复提交")
+		}
+	}
+
+	dbSqlExecRecord := createSqlExecRecord(ctx, execSqlReq)
+	dbSqlExecRecord.Type = entity.DbSqlExecTypeOther
+	dbSqlExecRecord.Table = sqlExec.Table
+	dbSqlExecRecord.RollbackId = sqlExec.Id
+	return d.submitApproval(ctx, nil, dbSqlExecRecord)
+}
+
+// 在同一事务中执行回滚sql，执行成功后再将原记录标记为已回滚；并发回滚时仅一个可标记成功，其余回滚事务
+func (d *dbSqlExecAppImpl) execRollbackSqls(ctx context.Context, id uint64, dbConn *dbi.DbConn, rollbackSqls []string) (int64, error) {
+	tx, err := dbConn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	var rowsAffected int64
+	for _, rollbackSql := range rollbackSqls {
+		affected, err := dbConn.TxExecContext(ctx, tx, rollbackSql)
+		if err != nil {
+			tx.Rollback()
+			return 0, errorx.NewBiz("[%s] -> 执行失败: %s", rollbackSql, err.Error())
+		}
+		rowsAffected += affected
+	}
+
+	claimed, err := d.DbSqlExecRepo.UpdateStatus(id, entity.DbSqlExecStatusSuccess, entity.DbSqlExecStatusRolledBack)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if !claimed {
+		tx.Rollback()
+		return 0, errorx.NewBiz("该sql执行记录已回滚, 不可重复回滚")
+	}
+	if err := tx.Commit(); err != nil {
+		if _, err := d.DbSqlExecRepo.UpdateStatus(id, entity.DbSqlExecStatusRolledBack, entity.DbSqlExecStatusSuccess); err != nil {
+			logx.Errorf("还原sql执行记录[%d]状态失败: %s", id, err.Error())
+		}
+		return 0, err
+	}
+	return rowsAffected, nil
+}
+
+// 获取需要回滚的sql执行记录及对应的数据库连接
+func (d *dbSqlExecAppImpl) getRollbackSqlExec(ctx context.Context, id uint64) (*entity.DbSqlExec, *dbi.DbConn, error) {
+	sqlExec := new(entity.DbSqlExec)
+	if err := d.DbSqlExecRepo.GetById(sqlExec, id); err != nil {
+		return nil, nil, errorx.NewBiz("sql执行记录不存在")
+	}
+	if sqlExec.Type != entity.DbSqlExecTypeUpdate && sqlExec.Type != entity.DbSqlExecTypeDelete {
+		return nil, nil, errorx.NewBiz("仅支持回滚update、delete类型的sql")
+	}
+	if sqlExec.Status == entity.DbSqlExecStatusRolledBack {
+		return nil, nil, errorx.NewBiz("该sql执行记录已回滚, 不可重复回滚")
+	}
+	if sqlExec.Status != entity.DbSqlExecStatusSuccess {
+		return nil, nil, errorx.NewBiz("该sql未执行成功, 无需回滚")
+	}
+
+	dbConn, err := d.DbApp.GetDbConn(sqlExec.DbId, sqlExec.Db)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := d.TagApp.CanAccess(contextx.GetLoginAccount(ctx).Id, dbConn.Info.TagPath...); err != nil {
+		return nil, nil, err
+	}
+	return sqlExec, dbConn, nil
+}
+
+// 根据旧值生成回滚sql，返回回滚sql及旧值记录条数
+func genRollbackSql(sqlExec *entity.DbSqlExec, dbConn *dbi.DbConn) ([]string, int, error) {
+	oldValues, err := jsonx.To(sqlExec.OldValue, new([]map[string]any))
+	if err != nil {
+		return nil, 0, errorx.NewBiz("旧值数据有误, 无法生成回滚sql: %s", sqlExec.OldValue)
+	}
+	if len(*oldValues) == 0 {
+		return nil, 0, errorx.NewBiz("该sql执行记录不存在旧值数据")
+	}
+
+	dbType := dbConn.Info.Type
+	dialect := dbConn.GetDialect()
+	tableName := dbType.RemoveQuote(sqlExec.Table)
+	quoteTable := QuoteTableName(dbType, tableName)
+
+	columns, err := dialect.GetColumns(tableName)
+	if err != nil {
+		return nil, 0, errorx.NewBiz("获取表列信息失败: %s", err.Error())
+	}
+	// 需通过主键（含联合主键所有列）唯一定位记录，无主键表无法保证回滚的准确性
+	primaryKeys := dbi.GetPrimaryKeyColumns(columns)
+	if len(primaryKeys) == 0 {
+		return nil, 0, errorx.NewBiz("表[%s]不存在主键, 不支持生成回滚sql", tableName)
+	}
+	isPrimaryKey := func(columnName string) bool {
+		return slices.ContainsFunc(primaryKeys, func(pk string) bool { return strings.EqualFold(pk, columnName) })
+	}
+
+	rollbackSqls := make([]string, 0)
+	switch sqlExec.Type {
+	case entity.DbSqlExecTypeUpdate:
+		// 更新了主键列，则无法通过主键定位更新后的记录
+		if stmt, err := sqlparser.Parse(sqlExec.Sql); err == nil {
+			if update, ok := stmt.(*sqlparser.Update); ok {
+				for _, v := range update.Exprs {
+					if updateColumn := dbType.RemoveQuote(v.Name.Name.String()); isPrimaryKey(updateColumn) {
+						return nil, 0, errorx.NewBiz("该sql更新了主键列[%s], 无法生成回滚sql", updateColumn)
+					}
+				}
+			}
+		}
+
+		for _, oldValue := range *oldValues {
+			wheres := make([]string, 0, len(primaryKeys))
+			for _, pk := range primaryKeys {
+				pkValue, ok := dbi.GetMapValue(oldValue, pk)
+				if !ok {
+					return nil, 0, errorx.NewBiz("旧值中不存在主键[%s]的值", pk)
+				}
+				if pkValue == nil {
+					return nil, 0, errorx.NewBiz("旧值中主键[%s]的值为NULL, 无法定位记录", pk)
+				}
+				wheres = append(wheres, fmt.Sprintf("%s = %s", dbType.QuoteIdentifier(pk), toSqlLiteral(dbType, pkValue)))
+			}
+
+			sets := make([]string, 0)
+			for _, column := range columns {
+				if isPrimaryKey(column.ColumnName) {
+					continue
+				}
+				if value, ok := dbi.GetMapValue(oldValue, column.ColumnName); ok {
+					sets = append(sets, fmt.Sprintf("%s = %s", dbType.QuoteIdentifier(column.ColumnName), toSqlLiteral(dbType, value)))
+				}
+			}
+			if len(sets) == 0 {
+				continue
+			}
+			rollbackSqls = append(rollbackSqls, fmt.Sprintf("UPDATE %s SET %s WHERE %s", quoteTable, strings.Join(sets, ", "), strings.Join(wheres, " AND ")))
+		}
+	case entity.DbSqlExecTypeDelete:
+		for _, oldValue := range *oldValues {
+			insertColumns := make([]string, 0)
+			values := make([]string, 0)
+			for _, column := range columns {
+				if value, ok := dbi.GetMapValue(oldValue, column.ColumnName); ok {
+					insertColumns = append(insertColumns, dbType.QuoteIdentifier(column.ColumnName))
+					values = append(values, toSqlLiteral(dbType, value))
+				}
+			}
+			rollbackSqls = append(rollbackSqls, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteTable, strings.Join(insertColumns, ", "), strings.Join(values, ", ")))
+		}
+	}
+
+	return rollbackSqls, len(*oldValues), nil
+}
+
+// 将值转为对应数据库的sql字面量
+func toSqlLiteral(dbType dbi.DbType, value any) string {
+	if value == nil {
+		return "NULL"
+	}
+	if strValue, ok := value.(string); ok {
+		return dbType.QuoteLiteral(strValue)
+	}
+	return anyx.ToString(value)
+}
+
+// 表名可能带有库名或schema，如db.table，需分别添加引号
+func QuoteTableName(dbType dbi.DbType, tableName string) string {
+	names := strings.Split(tableName, ".")
+	for i, name := range names {
+		names[i] = dbType.QuoteIdentifier(name)
+	}
+	return strings.Join(names, ".")
+}
+
 func (d *dbSqlExecAppImpl) DeleteBy(ctx context.Context, condition *entity.DbSqlExec) {
 	d.DbSqlExecRepo.DeleteByCond(ctx, condition)
 }
@@ -183,6 +937,20 @@ func doSelect(ctx context.Context, selectStmt *sqlparser.Select, execSqlReq *DbS
 	return doRead(ctx, execSqlReq)
 }
 
+// 执行非查询类sql
+func doWrite(ctx context.Context, stmt sqlparser.Statement, execSqlReq *DbSqlExecReq, dbSqlExec *entity.DbSqlExec) (*DbSqlExecRes, error) {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Update:
+		return doUpdate(ctx, stmt, execSqlReq, dbSqlExec)
+	case *sqlparser.Delete:
+		return doDelete(ctx, stmt, execSqlReq, dbSqlExec)
+	case *sqlparser.Insert:
+		return doInsert(ctx, stmt, execSqlReq, dbSqlExec)
+	default:
+		return doExec(ctx, execSqlReq.Sql, execSqlReq.DbConn)
+	}
+}
+
 func doRead(ctx context.Context, execSqlReq *DbSqlExecReq) (*DbSqlExecRes, error) {
 	dbConn := execSqlReq.DbConn
 	sql := execSqlReq.Sql
@@ -213,15 +981,18 @@ func doUpdate(ctx context.Context, update *sqlparser.Update, execSqlReq *DbSqlEx
 		updateColumns = append(updateColumns, v.Name.Name.String())
 	}
 
-	// 获取表主键列名,排除使用别名
-	primaryKey, err := dbConn.GetDialect().GetPrimaryKey(tableName)
+	// 获取表主键列名（含联合主键的所有列），用于回滚时定位记录
+	columns, err := dbConn.GetDialect().GetColumns(dbConn.Info.Type.RemoveQuote(tableName))
 	if err != nil {
 		return nil, errorx.NewBiz("获取表主键信息失败")
 	}
+	for _, pk := range dbi.GetPrimaryKeyColumns(columns) {
+		updateColumns = append(updateColumns, dbConn.Info.Type.QuoteIdentifier(pk))
+	}
 
-	updateColumnsAndPrimaryKey := strings.Join(updateColumns, ",") + "," + primaryKey
+	updateColumnsAndPrimaryKey := strings.Join(updateColumns, ",")
 	// 查询要更新字段数据的旧值，以及主键值
-	selectSql := fmt.Sprintf("SELECT %s FROM %s %s LIMIT 200", updateColumnsAndPrimaryKey, tableStr, where)
+	selectSql := fmt.Sprintf("SELECT %s FROM %s %s LIMIT %d", updateColumnsAndPrimaryKey, tableStr, where, maxOldValueCount)
 	_, res, err := dbConn.QueryContext(ctx, selectSql)
 	if err == nil {
 		dbSqlExec.OldValue = jsonx.ToStr(res)
@@ -247,7 +1018,7 @@ func doDelete(ctx context.Context, delete *sqlparser.Delete, execSqlReq *DbSqlEx
 	}
 
 	// 查询删除数据
-	selectSql := fmt.Sprintf("SELECT * FROM %s %s LIMIT 200", tableStr, where)
+	selectSql := fmt.Sprintf("SELECT * FROM %s %s LIMIT %d", tableStr, where, maxOldValueCount)
 	_, res, _ := dbConn.QueryContext(ctx, selectSql)
 
 	dbSqlExec.OldValue = jsonx.ToStr(res)
@@ -0,0 +1,520 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mayfly-go/internal/db/config"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	msgapp "mayfly-go/internal/msg/application"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/stringx"
+	"mayfly-go/pkg/ws"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+)
+
+// 数据库迁移进度消息类型
+const DbTransferProgressCategory = "dbTransferProgress"
+
+// 默认每批次插入的数据条数
+const defaultTransferBatchSize = 1000
+
+// 单条批量插入语句的最大参数个数（pgsql最大支持65535个参数）
+const maxTransferBatchArgs = 60000
+
+// 数据库迁移进度消息
+type DbTransferProgress struct {
+	TaskId     uint64 `json:"taskId"`
+	TaskName   string `json:"taskName"`
+	TableName  string `json:"tableName"`  // 当前迁移的表
+	TableIndex int    `json:"tableIndex"` // 当前迁移的表序号
+	TableTotal int    `json:"tableTotal"` // 需迁移的表总数
+	Rows       int64  `json:"rows"`       // 当前表已迁移的数据条数
+	Terminated bool   `json:"terminated"` // 是否已结束
+	Err        string `json:"err"`
+}
+
+type DbTransferTask interface {
+	base.App[*entity.DbTransferTask]
+
+	// 分页获取数据库迁移任务
+	GetPageList(condition *entity.DbTransferTaskQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	Save(ctx context.Context, task *entity.DbTransferTask) error
+
+	Delete(ctx context.Context, id uint64) error
+
+	// 生成迁移至目标库的建表语句，用于预览表结构转换结果
+	GenCreateTableSql(ctx context.Context, id uint64) ([]string, error)
+
+	// 执行迁移任务，reset为true则忽略已迁移的表进度重新迁移。迁移进度通过ws推送至指定客户端
+	Run(ctx context.Context, id uint64, reset bool, clientId string) error
+
+	// 停止执行中的迁移任务
+	Stop(ctx context.Context, id uint64) error
+
+	// 重置运行中的任务状态，用于服务重启后恢复
+	ResetRunningState()
+}
+
+type dbTransferAppImpl struct {
+	base.AppImpl[*entity.DbTransferTask, repository.DbTransferTask]
+
+	DbApp  Db         `inject:""`
+	MsgApp msgapp.Msg `inject:""`
+
+	// 执行中的任务取消函数 key: taskId
+	runningTasks sync.Map
+}
+
+func (app *dbTransferAppImpl) InjectDbTransferTaskRepo(repo repository.DbTransferTask) {
+	app.Repo = repo
+}
+
+func (app *dbTransferAppImpl) GetPageList(condition *entity.DbTransferTaskQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	return app.GetRepo().GetTaskList(condition, pageParam, toEntity, orderBy...)
+}
+
+func (app *dbTransferAppImpl) Save(ctx context.Context, task *entity.DbTransferTask) error {
+	if task.Id == 0 {
+		task.RunningState = entity.DbTransferRunStateReady
+		return app.Insert(ctx, task)
+	}
+	if _, ok := app.runningTasks.Load(task.Id); ok {
+		return errorx.NewBiz("该任务正在执行中, 无法修改")
+	}
+	// 迁移配置变更后，原有迁移进度失效
+	task.Checkpoint = "{}"
+	return app.UpdateById(ctx, task)
+}
+
+func (app *dbTransferAppImpl) Delete(ctx context.Context, id uint64) error {
+	if _, ok := app.runningTasks.Load(id); ok {
+		return errorx.NewBiz("该任务正在执行中, 请先停止后再删除")
+	}
+	return app.DeleteById(ctx, id)
+}
+
+func (app *dbTransferAppImpl) GenCreateTableSql(ctx context.Context, id uint64) ([]string, error) {
+	task, err := app.GetById(new(entity.DbTransferTask), id)
+	if err != nil {
+		return nil, errorx.NewBiz("任务不存在")
+	}
+	srcConn, targetConn, err := app.getConns(task)
+	if err != nil {
+		return nil, err
+	}
+	tableMetas, err := dbi.GetTableMetas(srcConn, task.GetSrcTables()...)
+	if err != nil {
+		return nil, err
+	}
+
+	sqls := make([]string, 0)
+	for _, tm := range tableMetas {
+		createSqls, err := app.genCreateTableSql(srcConn, targetConn, tm)
+		if err != nil {
+			return nil, err
+		}
+		sqls = append(sqls, createSqls...)
+	}
+	return sqls, nil
+}
+
+func (app *dbTransferAppImpl) Run(ctx context.Context, id uint64, reset bool, clientId string) error {
+	task, err := app.GetById(new(entity.DbTransferTask), id)
+	if err != nil {
+		return errorx.NewBiz("任务不存在")
+	}
+	// 迁移时的建表、删表及数据写入无法逐条提交审批
+	_, targetConn, err := app.getConns(task)
+	if err != nil {
+		return err
+	}
+	if config.GetDbSqlApproval().NeedApproval(targetConn.Info.TagPath...) {
+		return errorx.NewBiz("目标库需审批后执行sql, 不支持数据迁移")
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	if _, loaded := app.runningTasks.LoadOrStore(id, cancel); loaded {
+		cancel()
+		return errorx.NewBiz("该任务正在执行中")
+	}
+
+	if reset {
+		task.Checkpoint = "{}"
+	}
+	updateTask := &entity.DbTransferTask{RunningState: entity.DbTransferRunStateRunning, Checkpoint: task.Checkpoint}
+	updateTask.Id = id
+	if err := app.UpdateById(ctx, updateTask); err != nil {
+		app.runningTasks.Delete(id)
+		cancel()
+		return err
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	logx.Infof("开始执行数据库迁移任务: %s", task.TaskName)
+	go app.doTransfer(runCtx, task, la, clientId)
+	return nil
+}
+
+func (app *dbTransferAppImpl) Stop(ctx context.Context, id uint64) error {
+	cancel, ok := app.runningTasks.Load(id)
+	if !ok {
+		return errorx.NewBiz("该任务未在执行中")
+	}
+	cancel.(context.CancelFunc)()
+	return nil
+}
+
+func (app *dbTransferAppImpl) ResetRunningState() {
+	cond := &entity.DbTransferTask{RunningState: entity.DbTransferRunStateRunning}
+	if err := app.Updates(context.Background(), cond, map[string]any{"running_state": entity.DbTransferRunStateReady}); err != nil {
+		logx.Errorf("重置数据库迁移任务状态失败: %s", err.Error())
+	}
+}
+
+func (app *dbTransferAppImpl) doTransfer(ctx context.Context, task *entity.DbTransferTask, la *model.LoginAccount, clientId string) {
+	checkpoint := task.GetCheckpoint()
+	progress := &DbTransferProgress{TaskId: task.Id, TaskName: task.TaskName}
+
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+		app.runningTasks.Delete(task.Id)
+		app.endTransfer(ctx, task, checkpoint, progress, la, clientId, err)
+	}()
+
+	srcConn, targetConn, err := app.getConns(task)
+	if err != nil {
+		return
+	}
+
+	tableMetas, err := dbi.GetTableMetas(srcConn, task.GetSrcTables()...)
+	if err != nil {
+		err = errorx.NewBiz("获取源库表信息失败: %s", err.Error())
+		return
+	}
+	targetTables, err := targetConn.GetDialect().GetTables()
+	if err != nil {
+		err = errorx.NewBiz("获取目标库表信息失败: %s", err.Error())
+		return
+	}
+	existTables := make(map[string]bool, len(targetTables))
+	for _, t := range targetTables {
+		existTables[strings.ToLower(t.TableName)] = true
+	}
+
+	progress.TableTotal = len(tableMetas)
+	for i, tm := range tableMetas {
+		tableName := tm.Table.TableName
+		progress.TableIndex = i + 1
+		progress.TableName = tableName
+		progress.Rows = 0
+		// 已迁移完成的表直接跳过，实现断点续迁
+		if checkpoint.IsDone(tableName) {
+			progress.Rows = checkpoint[tableName].Rows
+			continue
+		}
+		if ctx.Err() != nil {
+			err = errorx.NewBiz("该任务已被手动终止")
+			return
+		}
+
+		// 保留上次已提交的数据条数及主键值，以便从中断处继续迁移
+		var committedRows int64
+		var lastKey map[string]any
+		if tc := checkpoint[tableName]; tc != nil {
+			committedRows, lastKey = tc.Rows, tc.LastKey
+		}
+		checkpoint.Set(tableName, entity.DbTransferTableStatusRunning, committedRows, lastKey, "")
+		app.saveCheckpoint(task.Id, checkpoint)
+		app.sendProgress(la, clientId, progress)
+
+		rows, tableErr := app.transferTable(ctx, task, srcConn, targetConn, tm, existTables[strings.ToLower(tableName)], checkpoint, progress, la, clientId)
+		if tableErr != nil {
+			err = fmt.Errorf("表[%s]迁移失败: %s", tableName, tableErr.Error())
+			checkpoint.Set(tableName, entity.DbTransferTableStatusFail, rows, checkpoint[tableName].LastKey, tableErr.Error())
+			return
+		}
+		checkpoint.Set(tableName, entity.DbTransferTableStatusDone, rows, nil, "")
+		app.saveCheckpoint(task.Id, checkpoint)
+	}
+}
+
+// 迁移单表的结构及数据，返回已提交的数据条数
+func (app *dbTransferAppImpl) transferTable(ctx context.Context, task *entity.DbTransferTask, srcConn, targetConn *dbi.DbConn, tm *dbi.TableMeta, exist bool, checkpoint entity.DbTransferCheckpoint, progress *DbTransferProgress, la *model.LoginAccount, clientId string) (int64, error) {
+	tableName := tm.Table.TableName
+	targetType := targetConn.Info.Type
+	committedRows := checkpoint[tableName].Rows
+
+	if task.NeedStruct() {
+		if exist && task.TableStrategy == entity.DbTransferTableStrategyRecreate {
+			if _, err := targetConn.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", targetType.QuoteIdentifier(tableName))); err != nil {
+				return committedRows, fmt.Errorf("删除目标表失败: %s", err.Error())
+			}
+			exist = false
+		}
+		if !exist {
+			// 重新建表后，之前已提交的数据均已不存在
+			committedRows = 0
+			checkpoint.Set(tableName, entity.DbTransferTableStatusRunning, 0, nil, "")
+			createSqls, err := app.genCreateTableSql(srcConn, targetConn, tm)
+			if err != nil {
+				return 0, err
+			}
+			for _, createSql := range createSqls {
+				if _, err := targetConn.ExecContext(ctx, createSql); err != nil {
+					return 0, fmt.Errorf("创建目标表失败: [%s] -> %s", createSql, err.Error())
+				}
+			}
+		}
+	}
+
+	if !task.NeedData() {
+		return 0, nil
+	}
+	return app.transferData(ctx, task, srcConn, targetConn, tm, committedRows, checkpoint[tableName].LastKey, checkpoint, progress, la, clientId)
+}
+
+// 迁移单表数据，返回已提交的数据条数。
+// 存在主键时按主键排序查询并分批提交事务，中断后从最后提交数据的主键值之后继续迁移；
+// 无主键则无法确定已迁移的数据，单表数据在同一事务中插入，失败则回滚，重新执行时从该表开始迁移
+func (app *dbTransferAppImpl) transferData(ctx context.Context, task *entity.DbTransferTask, srcConn, targetConn *dbi.DbConn, tm *dbi.TableMeta, committedRows int64, lastKey map[string]any, checkpoint entity.DbTransferCheckpoint, progress *DbTransferProgress, la *model.LoginAccount, clientId string) (int64, error) {
+	tableName := tm.Table.TableName
+	srcDialect := srcConn.GetDialect()
+	targetDialect := targetConn.GetDialect()
+
+	columns := make([]string, 0, len(tm.Columns))
+	quoteColumns := make([]string, 0, len(tm.Columns))
+	for _, column := range tm.Columns {
+		columns = append(columns, column.ColumnName)
+		quoteColumns = append(quoteColumns, targetConn.Info.Type.QuoteIdentifier(column.ColumnName))
+	}
+	if len(columns) == 0 {
+		return 0, nil
+	}
+
+	batchSize := task.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultTransferBatchSize
+	}
+	batchSize = min(batchSize, maxTransferBatchArgs/len(columns))
+
+	primaryKeys := tm.GetPrimaryKeys()
+	batchCommit := len(primaryKeys) > 0
+	if !batchCommit || len(lastKey) == 0 {
+		committedRows, lastKey = 0, nil
+	}
+
+	tx, err := targetConn.Begin()
+	if err != nil {
+		return committedRows, fmt.Errorf("开启目标库事务失败: %s", err.Error())
+	}
+
+	var total int64
+	var dataTypes map[string]dbi.DataType
+	values := make([][]any, 0, batchSize)
+	lastSendTime := time.Now()
+
+	insertBatch := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		if _, err := targetDialect.BatchInsert(tx, tableName, quoteColumns, values, dbi.DuplicateStrategyNone); err != nil {
+			return err
+		}
+		total += int64(len(values))
+		lastRow := values[len(values)-1]
+		values = values[:0]
+
+		if batchCommit {
+			if err := tx.Commit(); err != nil {
+				return fmt.Errorf("目标库事务提交失败: %s", err.Error())
+			}
+			// 主键值统一转为字符串保存，避免json序列化后大整数丢失精度
+			batchLastKey := make(map[string]any, len(primaryKeys))
+			for _, pk := range primaryKeys {
+				if index := slices.IndexFunc(columns, func(column string) bool { return strings.EqualFold(column, pk) }); index >= 0 {
+					batchLastKey[pk] = anyx.ToString(lastRow[index])
+				}
+			}
+			committedRows, lastKey = total, batchLastKey
+			checkpoint.Set(tableName, entity.DbTransferTableStatusRunning, committedRows, lastKey, "")
+			app.saveCheckpoint(task.Id, checkpoint)
+
+			var err error
+			if tx, err = targetConn.Begin(); err != nil {
+				return fmt.Errorf("开启目标库事务失败: %s", err.Error())
+			}
+		}
+
+		progress.Rows = total
+		// 控制进度推送频率
+		if time.Since(lastSendTime) > time.Second {
+			app.sendProgress(la, clientId, progress)
+			lastSendTime = time.Now()
+		}
+		return nil
+	}
+
+	srcType := srcConn.Info.Type
+	selectSql := fmt.Sprintf("SELECT %s FROM %s", strings.Join(collx.ArrayMap(columns, srcType.QuoteIdentifier), ", "), srcType.QuoteIdentifier(tableName))
+	if batchCommit {
+		// 从最后提交数据的主键值之后继续查询
+		if len(lastKey) > 0 {
+			keysetCond, err := dbi.KeysetCond(srcType, srcDialect.GetDataType, tm.Columns, primaryKeys, lastKey)
+			if err != nil {
+				tx.Rollback()
+				return committedRows, err
+			}
+			selectSql += " WHERE " + keysetCond
+		}
+		selectSql += " ORDER BY " + strings.Join(collx.ArrayMap(primaryKeys, srcType.QuoteIdentifier), ", ")
+	}
+	total = committedRows
+	err = srcConn.WalkQueryRows(ctx, selectSql, func(row map[string]any, queryColumns []*dbi.QueryColumn) error {
+		if dataTypes == nil {
+			dataTypes = make(map[string]dbi.DataType, len(queryColumns))
+			for _, qc := range queryColumns {
+				dataTypes[qc.Name] = srcDialect.GetDataType(qc.Type)
+			}
+		}
+
+		rowValues := make([]any, 0, len(columns))
+		for _, column := range columns {
+			value := row[column]
+			// 时间类型统一格式，避免不同数据库间时间格式不兼容
+			if strValue, ok := value.(string); ok && strValue != "" {
+				switch dataType := dataTypes[column]; dataType {
+				case dbi.DataTypeDate, dbi.DataTypeTime, dbi.DataTypeDateTime:
+					value = srcDialect.FormatStrData(strValue, dataType)
+				}
+			}
+			rowValues = append(rowValues, value)
+		}
+		values = append(values, rowValues)
+
+		if len(values) >= batchSize {
+			return insertBatch()
+		}
+		return nil
+	})
+	if err == nil {
+		err = insertBatch()
+	}
+	if err != nil {
+		tx.Rollback()
+		if ctx.Err() != nil {
+			return committedRows, errors.New("该任务已被手动终止")
+		}
+		return committedRows, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return committedRows, fmt.Errorf("目标库事务提交失败: %s", err.Error())
+	}
+	progress.Rows = total
+	app.sendProgress(la, clientId, progress)
+	return total, nil
+}
+
+// 生成源表在目标库的建表语句，同类型数据库直接使用源表建表语句，否则转换字段类型后生成
+func (app *dbTransferAppImpl) genCreateTableSql(srcConn, targetConn *dbi.DbConn, tm *dbi.TableMeta) ([]string, error) {
+	srcType := srcConn.Info.Type
+	targetType := targetConn.Info.Type
+	if srcType == targetType {
+		ddl, err := srcConn.GetDialect().GetTableDDL(tm.Table.TableName)
+		if err != nil {
+			return nil, fmt.Errorf("获取源表建表语句失败: %s", err.Error())
+		}
+		// 源表建表语句可能包含多条语句（如pgsql的注释、索引语句）
+		stmts, err := sqlparser.SplitStatementToPieces(ddl, sqlparser.WithDialect(targetType.Dialect()))
+		if err != nil {
+			return nil, err
+		}
+		sqls := make([]string, 0, len(stmts))
+		for _, stmt := range stmts {
+			if stmt = stringx.TrimSpaceAndBr(stmt); stmt != "" {
+				sqls = append(sqls, strings.TrimSuffix(stmt, ";"))
+			}
+		}
+		return sqls, nil
+	}
+	return dbi.GenCreateTableSql(targetType, dbi.ConvTableMeta(srcType, srcConn.GetDialect(), tm, targetType)), nil
+}
+
+func (app *dbTransferAppImpl) getConns(task *entity.DbTransferTask) (*dbi.DbConn, *dbi.DbConn, error) {
+	srcConn, err := app.DbApp.GetDbConn(task.SrcDbId, task.SrcDbName)
+	if err != nil {
+		return nil, nil, errorx.NewBiz("连接源数据库失败: %s", err.Error())
+	}
+	targetConn, err := app.DbApp.GetDbConn(task.TargetDbId, task.TargetDbName)
+	if err != nil {
+		return nil, nil, errorx.NewBiz("连接目标数据库失败: %s", err.Error())
+	}
+	return srcConn, targetConn, nil
+}
+
+func (app *dbTransferAppImpl) saveCheckpoint(taskId uint64, checkpoint entity.DbTransferCheckpoint) {
+	task := &entity.DbTransferTask{Checkpoint: checkpoint.String()}
+	task.Id = taskId
+	if err := app.UpdateById(context.Background(), task); err != nil {
+		logx.Errorf("保存数据库迁移任务进度失败: %s", err.Error())
+	}
+}
+
+func (app *dbTransferAppImpl) endTransfer(ctx context.Context, task *entity.DbTransferTask, checkpoint entity.DbTransferCheckpoint, progress *DbTransferProgress, la *model.LoginAccount, clientId string, err error) {
+	updateTask := &entity.DbTransferTask{Checkpoint: checkpoint.String(), RunningState: entity.DbTransferRunStateReady}
+	updateTask.Id = task.Id
+
+	title := "数据库迁移成功"
+	var msg *msgdto.SysMsg
+	if err != nil {
+		title = "数据库迁移失败"
+		if ctx.Err() != nil {
+			updateTask.RunningState = entity.DbTransferRunStateStop
+		}
+		updateTask.RecentState = entity.DbTransferStateFail
+		updateTask.Res = stringx.TruncateStr(err.Error(), 1000)
+		progress.Err = updateTask.Res
+		msg = msgdto.ErrSysMsg(title, fmt.Sprintf("[%s]迁移失败: %s", task.TaskName, updateTask.Res))
+		logx.Errorf("数据库迁移任务[%s]执行失败: %s", task.TaskName, err.Error())
+	} else {
+		updateTask.RecentState = entity.DbTransferStateSuccess
+		updateTask.Res = fmt.Sprintf("迁移完成, 共迁移%d张表", progress.TableTotal)
+		msg = msgdto.SuccessSysMsg(title, fmt.Sprintf("[%s]%s", task.TaskName, updateTask.Res))
+		logx.Infof("数据库迁移任务[%s]执行完成", task.TaskName)
+	}
+	if updateErr := app.UpdateById(context.Background(), updateTask); updateErr != nil {
+		logx.Errorf("更新数据库迁移任务状态失败: %s", updateErr.Error())
+	}
+
+	progress.Terminated = true
+	app.sendProgress(la, clientId, progress)
+	if la != nil {
+		app.MsgApp.CreateAndSend(la, msg.WithClientId(clientId))
+	}
+}
+
+func (app *dbTransferAppImpl) sendProgress(la *model.LoginAccount, clientId string, progress *DbTransferProgress) {
+	if la == nil {
+		return
+	}
+	ws.SendJsonMsg(ws.UserId(la.Id), clientId, msgdto.InfoSysMsg("数据库迁移进度", progress).WithCategory(DbTransferProgressCategory))
+}
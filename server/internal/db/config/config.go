@@ -2,8 +2,11 @@ package config
 
 import (
 	sysapp "mayfly-go/internal/sys/application"
+	"mayfly-go/pkg/utils/conv"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 )
 
 const (
@@ -12,6 +15,9 @@ const (
 	ConfigKeyDbBackupRestore string = "DbBackupRestore" // 数据库备份
 	ConfigKeyDbMysqlBin      string = "MysqlBin"        // mysql可执行文件配置
 	ConfigKeyDbMariadbBin    string = "MariadbBin"      // mariadb可执行文件配置
+	ConfigKeyDbPgsqlBin      string = "PgsqlBin"        // postgresql可执行文件配置
+	ConfigKeyDbSqlApproval   string = "DbSqlApproval"   // 数据库sql执行审批配置
+	ConfigKeyDbExecTimeout   string = "DbExecTimeout"   // 数据库sql执行超时时间
 )
 
 // 获取数据库最大查询数量配置
@@ -19,11 +25,62 @@ func GetDbQueryMaxCount() int {
 	return sysapp.GetConfigApp().GetConfig(ConfigKeyDbQueryMaxCount).IntValue(200)
 }
 
+// 获取数据库sql执行超时时间配置，默认58秒（比前端请求超时时间稍微快一点，可以提示到前端）
+func GetDbExecTimeout() time.Duration {
+	timeout := sysapp.GetConfigApp().GetConfig(ConfigKeyDbExecTimeout).IntValue(58)
+	if timeout <= 0 {
+		timeout = 58
+	}
+	return time.Duration(timeout) * time.Second
+}
+
 // 获取数据库是否记录查询相关sql配置
 func GetDbSaveQuerySql() bool {
 	return sysapp.GetConfigApp().GetConfig(ConfigKeyDbSaveQuerySQL).BoolValue(false)
 }
 
+// sql执行审批配置
+type DbSqlApproval struct {
+	Enable         bool          // 是否启用
+	TagPaths       []string      // 需要审批的标签路径
+	ApproverRoleId uint64        // 审批角色id
+	ApproverTeamId uint64        // 审批团队id
+	ExpireTime     time.Duration // 审批过期时间
+}
+
+// 判断指定标签路径的数据库执行非查询sql是否需要审批
+func (a *DbSqlApproval) NeedApproval(tagPaths ...string) bool {
+	if !a.Enable {
+		return false
+	}
+	for _, tagPath := range tagPaths {
+		for _, approvalTagPath := range a.TagPaths {
+			if strings.HasPrefix(tagPath, approvalTagPath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 获取sql执行审批配置
+func GetDbSqlApproval() *DbSqlApproval {
+	c := sysapp.GetConfigApp().GetConfig(ConfigKeyDbSqlApproval)
+	jm := c.GetJsonMap()
+
+	dsa := new(DbSqlApproval)
+	dsa.Enable = c.ConvBool(jm["enable"], false)
+	for _, tagPath := range strings.Split(jm["tagPaths"], ",") {
+		if tagPath = strings.TrimSpace(tagPath); tagPath != "" {
+			dsa.TagPaths = append(dsa.TagPaths, tagPath)
+		}
+	}
+	dsa.ApproverRoleId = uint64(conv.Str2Int(jm["approverRoleId"], 0))
+	dsa.ApproverTeamId = uint64(conv.Str2Int(jm["approverTeamId"], 0))
+	dsa.ExpireTime = time.Duration(conv.Str2Int(jm["expireMinutes"], 1440)) * time.Minute
+	return dsa
+}
+
 type DbBackupRestore struct {
 	BackupPath string // 备份文件路径呢
 }
@@ -89,3 +146,42 @@ func GetMysqlBin(configKey string) *MysqlBin {
 
 	return mbc
 }
+
+// postgresql客户端可执行文件配置
+type PgsqlBin struct {
+	Path          string // 可执行文件路径
+	PgDumpPath    string // pg_dump可执行文件路径
+	PgRestorePath string // pg_restore可执行文件路径
+}
+
+// 获取postgresql可执行文件配置
+func GetPgsqlBin() *PgsqlBin {
+	c := sysapp.GetConfigApp().GetConfig(ConfigKeyDbPgsqlBin)
+	jm := c.GetJsonMap()
+
+	pbc := new(PgsqlBin)
+
+	path := jm["path"]
+	if path == "" {
+		path = "./db/postgres/bin"
+	}
+	pbc.Path = filepath.Join(path)
+
+	var extName string
+	if runtime.GOOS == "windows" {
+		extName = ".exe"
+	}
+	pgDumpPath := jm["pg_dump"]
+	if pgDumpPath == "" {
+		pgDumpPath = filepath.Join(path, "pg_dump"+extName)
+	}
+	pbc.PgDumpPath = filepath.Join(pgDumpPath)
+
+	pgRestorePath := jm["pg_restore"]
+	if pgRestorePath == "" {
+		pgRestorePath = filepath.Join(path, "pg_restore"+extName)
+	}
+	pbc.PgRestorePath = filepath.Join(pgRestorePath)
+
+	return pbc
+}
@@ -0,0 +1,243 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	CLICKHOUSE_META_FILE      = "metasql/clickhouse_meta.sql"
+	CLICKHOUSE_DBS            = "CLICKHOUSE_DBS"
+	CLICKHOUSE_TABLE_INFO_KEY = "CLICKHOUSE_TABLE_INFO"
+	CLICKHOUSE_INDEX_INFO_KEY = "CLICKHOUSE_INDEX_INFO"
+	CLICKHOUSE_COLUMN_MA_KEY  = "CLICKHOUSE_COLUMN_MA"
+)
+
+// 数值类型，如：UInt64、Nullable(Int32)、Float64、Decimal(10, 2)
+var chNumberTypeRegexp = regexp.MustCompile(`(?i)\b(u?int|float|decimal)\d*\b`)
+
+type ClickhouseDialect struct {
+	dc *dbi.DbConn
+}
+
+func (cd *ClickhouseDialect) GetDbServer() (*dbi.DbServer, error) {
+	_, res, err := cd.dc.Query("SELECT version() AS version")
+	if err != nil {
+		return nil, err
+	}
+	ds := &dbi.DbServer{
+		Version: anyx.ConvString(res[0]["version"]),
+	}
+	return ds, nil
+}
+
+func (cd *ClickhouseDialect) GetDbNames() ([]string, error) {
+	_, res, err := cd.dc.Query(dbi.GetLocalSql(CLICKHOUSE_META_FILE, CLICKHOUSE_DBS))
+	if err != nil {
+		return nil, err
+	}
+
+	databases := make([]string, 0)
+	for _, re := range res {
+		databases = append(databases, anyx.ConvString(re["dbname"]))
+	}
+	return databases, nil
+}
+
+// 获取表基础元信息, 如表名、表引擎及分区键等
+func (cd *ClickhouseDialect) GetTables() ([]dbi.Table, error) {
+	_, res, err := cd.dc.Query(dbi.GetLocalSql(CLICKHOUSE_META_FILE, CLICKHOUSE_TABLE_INFO_KEY))
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]dbi.Table, 0)
+	for _, re := range res {
+		tables = append(tables, dbi.Table{
+			TableName:    anyx.ConvString(re["tableName"]),
+			TableComment: anyx.ConvString(re["tableComment"]),
+			CreateTime:   anyx.ConvString(re["createTime"]),
+			TableRows:    anyx.ConvInt(re["tableRows"]),
+			DataLength:   anyx.ConvInt64(re["dataLength"]),
+			IndexLength:  anyx.ConvInt64(re["indexLength"]),
+			Extra: collx.M{
+				"engine":       anyx.ConvString(re["engine"]),
+				"partitionKey": anyx.ConvString(re["partitionKey"]),
+				"sortingKey":   anyx.ConvString(re["sortingKey"]),
+			},
+		})
+	}
+	return tables, nil
+}
+
+// 获取列元信息, 如列名等
+func (cd *ClickhouseDialect) GetColumns(tableNames ...string) ([]dbi.Column, error) {
+	dbType := cd.dc.Info.Type
+	tableName := strings.Join(collx.ArrayMap[string, string](tableNames, func(val string) string {
+		return dbType.QuoteLiteral(dbType.RemoveQuote(val))
+	}), ",")
+
+	_, res, err := cd.dc.Query(fmt.Sprintf(dbi.GetLocalSql(CLICKHOUSE_META_FILE, CLICKHOUSE_COLUMN_MA_KEY), tableName))
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]dbi.Column, 0)
+	for _, re := range res {
+		column := dbi.Column{
+			TableName:     anyx.ConvString(re["tableName"]),
+			ColumnName:    anyx.ConvString(re["columnName"]),
+			ColumnType:    anyx.ConvString(re["columnType"]),
+			ColumnComment: anyx.ConvString(re["columnComment"]),
+			Nullable:      anyx.ConvString(re["nullable"]),
+			ColumnKey:     anyx.ConvString(re["columnKey"]),
+			ColumnDefault: anyx.ConvString(re["columnDefault"]),
+			NumScale:      anyx.ConvString(re["numScale"]),
+		}
+		// MATERIALIZED、ALIAS等计算列
+		if defaultKind := anyx.ConvString(re["defaultKind"]); defaultKind != "" && defaultKind != "DEFAULT" {
+			column.Extra = collx.M{"defaultKind": defaultKind}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// 获取表主键字段名，不存在主键标识则默认第一个字段
+func (cd *ClickhouseDialect) GetPrimaryKey(tablename string) (string, error) {
+	columns, err := cd.GetColumns(tablename)
+	if err != nil {
+		return "", err
+	}
+	if len(columns) == 0 {
+		return "", errorx.NewBiz("[%s] 表不存在", tablename)
+	}
+
+	for _, v := range columns {
+		if v.ColumnKey == "PRI" {
+			return v.ColumnName, nil
+		}
+	}
+
+	return columns[0].ColumnName, nil
+}
+
+// 获取表索引信息，包含主键（排序键）及跳数索引
+func (cd *ClickhouseDialect) GetTableIndex(tableName string) ([]dbi.Index, error) {
+	_, res, err := cd.dc.Query(dbi.GetLocalSql(CLICKHOUSE_META_FILE, CLICKHOUSE_INDEX_INFO_KEY), tableName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	indexs := make([]dbi.Index, 0)
+	for _, re := range res {
+		indexs = append(indexs, dbi.Index{
+			IndexName:    anyx.ConvString(re["indexName"]),
+			ColumnName:   anyx.ConvString(re["columnName"]),
+			IndexType:    anyx.ConvString(re["indexType"]),
+			IndexComment: anyx.ConvString(re["indexComment"]),
+			NonUnique:    anyx.ConvInt(re["nonUnique"]),
+			SeqInIndex:   anyx.ConvInt(re["seqInIndex"]),
+		})
+	}
+	return indexs, nil
+}
+
+// 获取建表ddl
+func (cd *ClickhouseDialect) GetTableDDL(tableName string) (string, error) {
+	_, res, err := cd.dc.Query(fmt.Sprintf("SHOW CREATE TABLE %s", cd.dc.Info.Type.QuoteIdentifier(tableName)))
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "", errorx.NewBiz("[%s] 表不存在", tableName)
+	}
+	return anyx.ConvString(res[0]["statement"]) + ";", nil
+}
+
+// 根据表结构定义生成建表或修改表的sql
+func (cd *ClickhouseDialect) GenTableDesignSql(td *dbi.TableDefinition) ([]string, error) {
+	return dbi.GenTableDesignSql(cd.dc, td)
+}
+
+func (cd *ClickhouseDialect) WalkTableRecord(ctx context.Context, tableName string, walkFn dbi.WalkQueryRowsFunc) error {
+	return cd.dc.WalkQueryRows(ctx, fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
+}
+
+func (cd *ClickhouseDialect) GetTableData(ctx context.Context, query *dbi.TableDataQuery) (*dbi.TableDataRes, error) {
+	return dbi.QueryTableData(ctx, cd.dc, query, dbi.LimitOffsetPageSql)
+}
+
+func (cd *ClickhouseDialect) GetSchemas() ([]string, error) {
+	return nil, nil
+}
+
+// GetDbProgram 获取数据库程序模块，用于数据库备份与恢复
+func (cd *ClickhouseDialect) GetDbProgram() dbi.DbProgram {
+	return dbi.NewDbProgramLogical(cd.dc)
+}
+
+func (cd *ClickhouseDialect) GetDataType(dbColumnType string) dbi.DataType {
+	if chNumberTypeRegexp.MatchString(dbColumnType) {
+		return dbi.DataTypeNumber
+	}
+	// 日期时间类型，如：DateTime、DateTime64(3)
+	if regexp.MustCompile(`(?i)datetime`).MatchString(dbColumnType) {
+		return dbi.DataTypeDateTime
+	}
+	// 日期类型，如：Date、Date32
+	if regexp.MustCompile(`(?i)date`).MatchString(dbColumnType) {
+		return dbi.DataTypeDate
+	}
+	return dbi.DataTypeString
+}
+
+// BatchInsert 使用驱动的原生批量写入，数据在批次提交时以数据块的方式一次性发送。
+// clickhouse不支持事务，驱动中的事务仅用于界定批次，且同一连接上未提交的批次会被后续批次覆盖，
+// 故每次调用均在独立连接上写入并立即提交，tx仅为兼容接口。clickhouse无唯一约束，亦无需处理数据重复策略
+func (cd *ClickhouseDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any, duplicateStrategy dbi.DuplicateStrategy, keyColumns ...string) (int64, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+	batchTx, err := cd.dc.Begin()
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := batchTx.Prepare(fmt.Sprintf("INSERT INTO %s (%s)", cd.dc.Info.Type.QuoteIdentifier(tableName), strings.Join(columns, ",")))
+	if err != nil {
+		batchTx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, value := range values {
+		if _, err := stmt.Exec(value...); err != nil {
+			batchTx.Rollback()
+			return 0, err
+		}
+	}
+	if err := batchTx.Commit(); err != nil {
+		return 0, err
+	}
+	return int64(len(values)), nil
+}
+
+func (cd *ClickhouseDialect) FormatStrData(dbColumnValue string, dataType dbi.DataType) string {
+	switch dataType {
+	case dbi.DataTypeDateTime: // "2024-01-02T22:16:28+08:00"
+		res, _ := time.Parse(time.RFC3339, dbColumnValue)
+		return res.Format(time.DateTime)
+	case dbi.DataTypeDate: // "2024-01-02T00:00:00Z"
+		res, _ := time.Parse(time.RFC3339, dbColumnValue)
+		return res.Format(time.DateOnly)
+	}
+	return dbColumnValue
+}
@@ -0,0 +1,41 @@
+package clickhouse
+
+import (
+	"mayfly-go/internal/db/dbm/dbi"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseClickhouseExplain(t *testing.T) {
+	rows := parseClickhouseExplain([]string{
+		"Expression ((Projection + Before ORDER BY))",
+		"  Filter (WHERE)",
+		"    ReadFromMergeTree (default.t_user)",
+		"  Union",
+		"    ReadFromStorage (t_log)",
+	})
+	root := dbi.BuildExplainTree(rows)
+	require.Equal(t, "Expression", root.NodeType)
+	require.Equal(t, "(Projection + Before ORDER BY)", root.Extra)
+	require.Len(t, root.Children, 2)
+
+	read := root.Children[0].Children[0]
+	require.Equal(t, "ReadFromMergeTree", read.NodeType)
+	require.Equal(t, "t_user", read.Table)
+	require.False(t, read.FullScan)
+
+	read = root.Children[1].Children[0]
+	require.Equal(t, "t_log", read.Table)
+	require.True(t, read.FullScan)
+}
+
+func Test_GetDataType(t *testing.T) {
+	cd := &ClickhouseDialect{}
+	require.Equal(t, dbi.DataTypeNumber, cd.GetDataType("Nullable(UInt64)"))
+	require.Equal(t, dbi.DataTypeNumber, cd.GetDataType("Decimal(10, 2)"))
+	require.Equal(t, dbi.DataTypeDateTime, cd.GetDataType("DateTime64(3, 'Asia/Shanghai')"))
+	require.Equal(t, dbi.DataTypeDate, cd.GetDataType("Date32"))
+	require.Equal(t, dbi.DataTypeString, cd.GetDataType("LowCardinality(String)"))
+	require.Equal(t, dbi.DataTypeString, cd.GetDataType("Point"))
+}
@@ -0,0 +1,81 @@
+package clickhouse
+
+import (
+	"context"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"regexp"
+	"strings"
+)
+
+// 执行计划步骤行，如：ReadFromMergeTree (default.t_user)、Expression ((Projection + Before ORDER BY))，括号内为步骤描述
+var chExplainLineRegexp = regexp.MustCompile(`^(\s*)(\w+)(?:\s+\((.*)\))?\s*$`)
+
+// 读取表数据的步骤，如 ReadFromMergeTree (db.t_user)、ReadFromStorage (t_user)
+var chExplainReadRegexp = regexp.MustCompile(`^ReadFrom\w+$`)
+
+func (cd *ClickhouseDialect) Explain(ctx context.Context, sql string) (*dbi.ExplainNode, error) {
+	sql, err := dbi.CheckExplainSql(cd.dc.Info.Type, sql)
+	if err != nil {
+		return nil, err
+	}
+	_, res, err := cd.dc.QueryContext(ctx, "EXPLAIN "+sql)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, 0, len(res))
+	for _, re := range res {
+		lines = append(lines, anyx.ConvString(re["explain"]))
+	}
+	rows := parseClickhouseExplain(lines)
+	if len(rows) == 0 {
+		return nil, errorx.NewBiz("未获取到执行计划")
+	}
+	return dbi.BuildExplainTree(rows), nil
+}
+
+// parseClickhouseExplain 解析 EXPLAIN 返回的查询计划，根据步骤名前的缩进确定节点层级。
+// clickhouse计划中不含预估行数及成本，读表步骤的上层不存在过滤步骤时视为全表扫描
+func parseClickhouseExplain(lines []string) []dbi.ExplainRow {
+	rows := make([]dbi.ExplainRow, 0)
+	// 各层级的缩进、对应节点id及该层级（含上层）是否存在过滤步骤
+	indents := make([]int, 0)
+	ids := make([]int64, 0)
+	filtered := make([]bool, 0)
+	for _, line := range lines {
+		match := chExplainLineRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		indent := len(match[1])
+		for len(indents) > 0 && indents[len(indents)-1] >= indent {
+			indents = indents[:len(indents)-1]
+			ids = ids[:len(ids)-1]
+			filtered = filtered[:len(filtered)-1]
+		}
+
+		node := &dbi.ExplainNode{
+			NodeType: match[2],
+			Extra:    match[3],
+		}
+		var parentId int64
+		hasFilter := node.NodeType == "Filter"
+		if len(ids) > 0 {
+			parentId = ids[len(ids)-1]
+			hasFilter = hasFilter || filtered[len(filtered)-1]
+		}
+		if chExplainReadRegexp.MatchString(node.NodeType) {
+			// 描述为 库名.表名 或 表名
+			node.Table = node.Extra[strings.LastIndex(node.Extra, ".")+1:]
+			node.FullScan = !hasFilter
+		}
+
+		id := int64(len(rows) + 1)
+		rows = append(rows, dbi.ExplainRow{Id: id, ParentId: parentId, Node: node})
+		indents = append(indents, indent)
+		ids = append(ids, id)
+		filtered = append(filtered, hasFilter)
+	}
+	return rows
+}
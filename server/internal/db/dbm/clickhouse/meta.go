@@ -0,0 +1,76 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/utils/netx"
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+var (
+	meta dbi.Meta
+	once sync.Once
+)
+
+func GetMeta() dbi.Meta {
+	once.Do(func() {
+		meta = new(ClickhouseMeta)
+	})
+	return meta
+}
+
+type ClickhouseMeta struct {
+}
+
+func (cm *ClickhouseMeta) GetSqlDb(d *dbi.DbInfo) (*sql.DB, error) {
+	query := url.Values{}
+	query.Set("dial_timeout", "8s")
+	// 存在额外指定参数，则覆盖默认参数 -> 更多参数参考：https://github.com/ClickHouse/clickhouse-go#dsn
+	if d.Params != "" {
+		params, err := url.ParseQuery(d.Params)
+		if err != nil {
+			return nil, fmt.Errorf("连接参数格式错误: %s", err.Error())
+		}
+		for k, v := range params {
+			query[k] = v
+		}
+	}
+	dsn := &url.URL{
+		Scheme:   "clickhouse",
+		User:     url.UserPassword(d.Username, d.Password),
+		Host:     fmt.Sprintf("%s:%d", d.Host, d.Port),
+		Path:     "/" + d.Database,
+		RawQuery: query.Encode(),
+	}
+
+	opts, err := clickhouse.ParseDSN(dsn.String())
+	if err != nil {
+		return nil, err
+	}
+	// SSH Conect
+	if d.SshTunnelMachineId > 0 {
+		opts.DialContext = func(ctx context.Context, addr string) (net.Conn, error) {
+			sshTunnel, err := dbi.GetSshTunnel(d.SshTunnelMachineId)
+			if err != nil {
+				return nil, err
+			}
+			sshConn, err := sshTunnel.GetDialConn("tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			// 将ssh conn包装，否则会返回错误: ssh: tcpChan: deadline not supported
+			return &netx.WrapSshConn{Conn: sshConn}, nil
+		}
+	}
+	return clickhouse.OpenDB(opts), nil
+}
+
+func (cm *ClickhouseMeta) GetDialect(conn *dbi.DbConn) dbi.Dialect {
+	return &ClickhouseDialect{conn}
+}
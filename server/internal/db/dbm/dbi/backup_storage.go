@@ -0,0 +1,294 @@
+package dbi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mayfly-go/internal/db/config"
+	"mayfly-go/internal/db/domain/entity"
+	machineapp "mayfly-go/internal/machine/application"
+	"mayfly-go/pkg/logx"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+)
+
+// BackupStorage 数据库备份文件存储，备份程序始终将备份文件写入本地备份目录，
+// 备份完成后再上传至备份任务所选的存储，恢复时从存储中流式读取备份文件
+type BackupStorage interface {
+	// Upload 将本地文件上传至存储, name 为备份文件在存储中的相对路径
+	Upload(ctx context.Context, name string, localFile string) error
+
+	// Open 读取存储中的备份文件
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// Delete 删除存储中的备份文件
+	Delete(ctx context.Context, name string) error
+}
+
+// NewBackupStorage 根据备份存储配置创建备份存储
+func NewBackupStorage(bs *entity.DbBackupStorage) (BackupStorage, error) {
+	switch bs.Type {
+	case entity.DbBackupStorageLocal:
+		return &localBackupStorage{root: config.GetDbBackupRestore().BackupPath}, nil
+	case entity.DbBackupStorageSftp:
+		if bs.MachineId == 0 || bs.Path == "" {
+			return nil, errors.New("sftp备份存储需指定机器及目录")
+		}
+		return &sftpBackupStorage{machineId: bs.MachineId, dir: bs.Path}, nil
+	case entity.DbBackupStorageS3:
+		if err := bs.SecretDecrypt(); err != nil {
+			return nil, err
+		}
+		return NewS3BackupStorage(bs)
+	default:
+		return nil, errors.Errorf("不支持的备份存储类型: %s", bs.Type)
+	}
+}
+
+// 备份文件在存储中的相对路径，与本地备份目录结构一致
+func getBackupStorageName(history *entity.DbBackupHistory) string {
+	return path.Join(
+		fmt.Sprintf("instance-%d", history.DbInstanceId),
+		fmt.Sprintf("backup-%d", history.DbBackupId),
+		history.FileName)
+}
+
+// getBackupLocalFile 获取备份历史对应的本地备份文件路径
+func getBackupLocalFile(history *entity.DbBackupHistory) (string, error) {
+	if history.FileName == "" {
+		return GetDbBackupFile(history.DbInstanceId, history.DbBackupId, history.Uuid)
+	}
+	return filepath.Join(GetDbBackupDir(history.DbInstanceId, history.DbBackupId), history.FileName), nil
+}
+
+// UploadBackupFile 将本地备份文件上传至 bs 指定的存储，上传成功后删除本地备份文件
+func UploadBackupFile(ctx context.Context, history *entity.DbBackupHistory, bs *entity.DbBackupStorage) error {
+	if bs.IsLocal() {
+		return nil
+	}
+	storage, err := NewBackupStorage(bs)
+	if err != nil {
+		return err
+	}
+	localFile, err := getBackupLocalFile(history)
+	if err != nil {
+		return err
+	}
+	GetProgress(ctx).SetStage(ProgressStageUpload)
+	if err := storage.Upload(ctx, getBackupStorageName(history), localFile); err != nil {
+		return errors.Wrapf(err, "上传备份文件至%s存储失败", bs.Type)
+	}
+	return os.Remove(localFile)
+}
+
+// DownloadBackupFile 将备份文件从 bs 指定的存储读取至本地备份目录，返回本地备份文件路径及释放函数，释放时删除下载的本地文件
+func DownloadBackupFile(ctx context.Context, history *entity.DbBackupHistory, bs *entity.DbBackupStorage) (string, func(), error) {
+	localFile, err := getBackupLocalFile(history)
+	if err != nil {
+		return "", nil, err
+	}
+	if bs.IsLocal() {
+		return localFile, func() {}, nil
+	}
+
+	storage, err := NewBackupStorage(bs)
+	if err != nil {
+		return "", nil, err
+	}
+	reader, err := storage.Open(ctx, getBackupStorageName(history))
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "读取%s存储中的备份文件失败", bs.Type)
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(filepath.Dir(localFile), os.ModePerm); err != nil {
+		return "", nil, err
+	}
+	release := func() {
+		if err := os.Remove(localFile); err != nil && !os.IsNotExist(err) {
+			logx.Warnf("删除下载的备份文件失败: %v", err)
+		}
+	}
+	file, err := os.Create(localFile)
+	if err != nil {
+		return "", nil, err
+	}
+	progress := GetProgress(ctx)
+	progress.SetStage(ProgressStageDownload)
+	_, err = io.Copy(file, progress.Reader(&ctxReader{ctx: ctx, reader: reader}))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		release()
+		return "", nil, errors.Wrapf(err, "读取%s存储中的备份文件失败", bs.Type)
+	}
+	return localFile, release, nil
+}
+
+// BackupFileOpener 打开备份文件，恢复程序通过返回的 reader 流式读取备份文件，可多次打开
+type BackupFileOpener func() (io.ReadCloser, error)
+
+// NewBackupFileOpener 返回读取 bs 指定的存储中备份文件的 BackupFileOpener，非本地存储直接读取存储中的文件，不下载至本地
+func NewBackupFileOpener(ctx context.Context, history *entity.DbBackupHistory, bs *entity.DbBackupStorage) (BackupFileOpener, error) {
+	if bs.IsLocal() {
+		localFile, err := getBackupLocalFile(history)
+		if err != nil {
+			return nil, errors.Wrap(err, "打开备份文件失败")
+		}
+		return LocalBackupFileOpener(localFile), nil
+	}
+	storage, err := NewBackupStorage(bs)
+	if err != nil {
+		return nil, err
+	}
+	name := getBackupStorageName(history)
+	return func() (io.ReadCloser, error) {
+		reader, err := storage.Open(ctx, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "读取%s存储中的备份文件失败", bs.Type)
+		}
+		return &ctxReadCloser{ctxReader: ctxReader{ctx: ctx, reader: reader}, Closer: reader}, nil
+	}, nil
+}
+
+// LocalBackupFileOpener 返回读取本地备份文件的 BackupFileOpener
+func LocalBackupFileOpener(fileName string) BackupFileOpener {
+	return func() (io.ReadCloser, error) {
+		file, err := os.Open(fileName)
+		if err != nil {
+			return nil, errors.Wrap(err, "打开备份文件失败")
+		}
+		return file, nil
+	}
+}
+
+// DeleteBackupFile 删除 bs 指定的存储中的备份文件
+func DeleteBackupFile(ctx context.Context, history *entity.DbBackupHistory, bs *entity.DbBackupStorage) error {
+	if bs.IsLocal() {
+		localFile, err := getBackupLocalFile(history)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if err := os.Remove(localFile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	storage, err := NewBackupStorage(bs)
+	if err != nil {
+		return err
+	}
+	return storage.Delete(ctx, getBackupStorageName(history))
+}
+
+// localBackupStorage 本地磁盘存储
+type localBackupStorage struct {
+	root string
+}
+
+func (s *localBackupStorage) Upload(ctx context.Context, name string, localFile string) error {
+	fileName := filepath.Join(s.root, filepath.FromSlash(name))
+	if fileName == filepath.Clean(localFile) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+		return err
+	}
+	return os.Rename(localFile, fileName)
+}
+
+func (s *localBackupStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.root, filepath.FromSlash(name)))
+}
+
+func (s *localBackupStorage) Delete(ctx context.Context, name string) error {
+	return os.Remove(filepath.Join(s.root, filepath.FromSlash(name)))
+}
+
+// sftpBackupStorage 通过sftp将备份文件保存至已录入的机器
+type sftpBackupStorage struct {
+	machineId uint64
+	dir       string
+}
+
+func (s *sftpBackupStorage) getSftpCli() (*sftp.Client, error) {
+	cli, err := machineapp.GetMachineApp().GetCli(s.machineId)
+	if err != nil {
+		return nil, err
+	}
+	return cli.GetSftpCli()
+}
+
+func (s *sftpBackupStorage) Upload(ctx context.Context, name string, localFile string) error {
+	sftpCli, err := s.getSftpCli()
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(localFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	remoteFile := path.Join(s.dir, name)
+	if err := sftpCli.MkdirAll(path.Dir(remoteFile)); err != nil {
+		return err
+	}
+	// 先写入临时文件，上传完成后再改名，避免中断后留下不完整的备份文件
+	tmpFile := remoteFile + ".tmp"
+	remote, err := sftpCli.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+	_, err = remote.ReadFrom(&ctxReader{ctx: ctx, reader: file})
+	if closeErr := remote.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = sftpCli.Remove(tmpFile)
+		return err
+	}
+	return sftpCli.PosixRename(tmpFile, remoteFile)
+}
+
+func (s *sftpBackupStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	sftpCli, err := s.getSftpCli()
+	if err != nil {
+		return nil, err
+	}
+	return sftpCli.Open(path.Join(s.dir, name))
+}
+
+func (s *sftpBackupStorage) Delete(ctx context.Context, name string) error {
+	sftpCli, err := s.getSftpCli()
+	if err != nil {
+		return err
+	}
+	return sftpCli.Remove(path.Join(s.dir, name))
+}
+
+// ctxReader 读取前检查 context 是否已取消，用于中断不支持 context 的文件传输
+type ctxReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *ctxReader) Read(b []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(b)
+}
+
+type ctxReadCloser struct {
+	ctxReader
+	io.Closer
+}
@@ -0,0 +1,353 @@
+package dbi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mayfly-go/internal/db/domain/entity"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3AmzDateLayout = "20060102T150405Z"
+
+	s3PartSize     = 64 * 1024 * 1024 // 分片上传的默认分片大小
+	s3MaxPartCount = 10000            // S3协议限制的最大分片数
+)
+
+// s3BackupStorage 兼容S3协议的对象存储（如 AWS S3、MinIO），使用路径风格访问 {endpoint}/{bucket}/{key}，请求使用 AWS Signature V4 签名。
+// 不超过一个分片大小的备份文件使用单次 PUT 上传，更大的备份文件使用分片上传
+type s3BackupStorage struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	prefix    string
+	accessKey string
+	secretKey string
+	partSize  int64
+	client    *http.Client
+}
+
+func NewS3BackupStorage(bs *entity.DbBackupStorage) (BackupStorage, error) {
+	if bs.Endpoint == "" || bs.Bucket == "" || bs.AccessKey == "" || bs.SecretKey == "" {
+		return nil, errors.New("s3备份存储需指定服务地址、存储桶及访问密钥")
+	}
+	endpoint, err := url.Parse(bs.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, errors.Errorf("无效的s3服务地址: %s", bs.Endpoint)
+	}
+	region := bs.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &s3BackupStorage{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    bs.Bucket,
+		prefix:    strings.Trim(bs.Path, "/"),
+		accessKey: bs.AccessKey,
+		secretKey: bs.SecretKey,
+		partSize:  s3PartSize,
+		client:    http.DefaultClient,
+	}, nil
+}
+
+func (s *s3BackupStorage) objectUrl(name string) string {
+	u := *s.endpoint
+	u.Path = "/" + path.Join(strings.Trim(s.endpoint.Path, "/"), s.bucket, s.prefix, name)
+	return u.String()
+}
+
+func (s *s3BackupStorage) Upload(ctx context.Context, name string, localFile string) error {
+	file, err := os.Open(localFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	partSize := s.partSize
+	if stat.Size() <= partSize {
+		return s.putObject(ctx, name, io.NewSectionReader(file, 0, stat.Size()))
+	}
+	if minPartSize := (stat.Size() + s3MaxPartCount - 1) / s3MaxPartCount; partSize < minPartSize {
+		partSize = minPartSize
+	}
+	return s.multipartUpload(ctx, name, file, stat.Size(), partSize)
+}
+
+func (s *s3BackupStorage) putObject(ctx context.Context, name string, body *io.SectionReader) error {
+	payloadHash, err := sectionSha256(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectUrl(name), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = body.Size()
+	resp, err := s.do(req, payloadHash)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+type s3InitiateMultipartUploadResult struct {
+	UploadId string `xml:"UploadId"`
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type s3CompleteMultipartUpload struct {
+	XMLName xml.Name           `xml:"CompleteMultipartUpload"`
+	Parts   []*s3CompletedPart `xml:"Part"`
+}
+
+// multipartUpload 分片上传备份文件，任一分片上传失败则中止本次分片上传，避免存储中残留已上传的分片
+func (s *s3BackupStorage) multipartUpload(ctx context.Context, name string, file io.ReaderAt, size int64, partSize int64) error {
+	uploadId, err := s.createMultipartUpload(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	parts := make([]*s3CompletedPart, 0, (size+partSize-1)/partSize)
+	for offset := int64(0); offset < size; offset += partSize {
+		part := &s3CompletedPart{PartNumber: len(parts) + 1}
+		part.ETag, err = s.uploadPart(ctx, name, uploadId, part.PartNumber, io.NewSectionReader(file, offset, min(partSize, size-offset)))
+		if err != nil {
+			break
+		}
+		parts = append(parts, part)
+	}
+	if err == nil {
+		err = s.completeMultipartUpload(ctx, name, uploadId, parts)
+	}
+	if err != nil {
+		if abortErr := s.abortMultipartUpload(context.WithoutCancel(ctx), name, uploadId); abortErr != nil {
+			return errors.Wrapf(err, "中止分片上传失败: %v", abortErr)
+		}
+	}
+	return err
+}
+
+func (s *s3BackupStorage) createMultipartUpload(ctx context.Context, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.objectUrl(name)+"?uploads", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	result := &s3InitiateMultipartUploadResult{}
+	if err := xml.NewDecoder(resp.Body).Decode(result); err != nil {
+		return "", errors.Wrap(err, "解析s3分片上传响应失败")
+	}
+	if result.UploadId == "" {
+		return "", errors.New("s3分片上传未返回uploadId")
+	}
+	return result.UploadId, nil
+}
+
+func (s *s3BackupStorage) uploadPart(ctx context.Context, name string, uploadId string, partNumber int, body *io.SectionReader) (string, error) {
+	payloadHash, err := sectionSha256(body)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadId}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectUrl(name)+"?"+query.Encode(), body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = body.Size()
+	resp, err := s.do(req, payloadHash)
+	if err != nil {
+		return "", err
+	}
+	etag := resp.Header.Get("ETag")
+	if err := resp.Body.Close(); err != nil {
+		return "", err
+	}
+	if etag == "" {
+		return "", errors.Errorf("s3分片[%d]上传未返回ETag", partNumber)
+	}
+	return etag, nil
+}
+
+func (s *s3BackupStorage) completeMultipartUpload(ctx context.Context, name string, uploadId string, parts []*s3CompletedPart) error {
+	body, err := xml.Marshal(&s3CompleteMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+	query := url.Values{"uploadId": {uploadId}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.objectUrl(name)+"?"+query.Encode(), strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(body)
+	resp, err := s.do(req, hex.EncodeToString(hash[:]))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// 合并分片失败时S3仍可能返回200，需检查响应内容是否为错误信息
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return err
+	}
+	if strings.Contains(string(respBody), "<Error>") {
+		return errors.Errorf("s3合并分片失败: %s", string(respBody))
+	}
+	return nil
+}
+
+func (s *s3BackupStorage) abortMultipartUpload(ctx context.Context, name string, uploadId string) error {
+	query := url.Values{"uploadId": {uploadId}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectUrl(name)+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// sectionSha256 计算分段内容的 sha256 作为请求签名的 payload hash，计算后重置读取位置
+func sectionSha256(section *io.SectionReader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, section); err != nil {
+		return "", err
+	}
+	if _, err := section.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (s *s3BackupStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectUrl(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *s3BackupStorage) Delete(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectUrl(name), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// 签名并发送请求，响应状态码非 2xx 时返回错误
+func (s *s3BackupStorage) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	req.Header.Set("x-amz-date", time.Now().UTC().Format(s3AmzDateLayout))
+	s.sign(req, payloadHash)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.Errorf("s3请求失败: %s %s", resp.Status, string(body))
+	}
+	return resp, nil
+}
+
+var emptyPayloadHash = hex.EncodeToString(sha256.New().Sum(nil))
+
+// sign 使用 AWS Signature V4 对请求签名，签名包含 host 及请求中已设置的所有请求头，请求需已设置 x-amz-date 请求头
+func (s *s3BackupStorage) sign(req *http.Request, payloadHash string) {
+	amzDate := req.Header.Get("x-amz-date")
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", amzDate[:8], s.region)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	headerNames := make([]string, 0, len(headers))
+	for name := range headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	var canonicalHeaders strings.Builder
+	for _, name := range headerNames {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EncodePath(req.URL.Path),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(canonicalRequestHash[:])}, "\n")
+
+	signingKey := hmacSha256([]byte("AWS4"+s.secretKey), amzDate[:8])
+	signingKey = hmacSha256(signingKey, s.region)
+	signingKey = hmacSha256(signingKey, "s3")
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3EncodePath 按S3签名规范编码对象路径，仅保留非保留字符及路径分隔符
+func s3EncodePath(p string) string {
+	var sb strings.Builder
+	for _, b := range []byte(p) {
+		if b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || strings.IndexByte("-_.~/", b) >= 0 {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+	return sb.String()
+}
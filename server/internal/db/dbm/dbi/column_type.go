@@ -0,0 +1,437 @@
+package dbi
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 通用字段类型，作为不同数据库间字段类型转换的中间类型
+type commonType string
+
+const (
+	commonTypeTinyint   commonType = "tinyint"
+	commonTypeSmallint  commonType = "smallint"
+	commonTypeInt       commonType = "int"
+	commonTypeBigint    commonType = "bigint"
+	commonTypeDecimal   commonType = "decimal"
+	commonTypeFloat     commonType = "float"
+	commonTypeDouble    commonType = "double"
+	commonTypeBool      commonType = "bool"
+	commonTypeChar      commonType = "char"
+	commonTypeVarchar   commonType = "varchar"
+	commonTypeText      commonType = "text"
+	commonTypeBlob      commonType = "blob"
+	commonTypeDate      commonType = "date"
+	commonTypeTime      commonType = "time"
+	commonTypeDatetime  commonType = "datetime"
+	commonTypeTimestamp commonType = "timestamp"
+	commonTypeJson      commonType = "json"
+)
+
+// 各数据库字段类型名（小写，不含长度等参数）与通用字段类型的对应关系
+var commonTypeMapping = map[string]commonType{
+	"tinyint":                     commonTypeTinyint,
+	"smallint":                    commonTypeSmallint,
+	"int2":                        commonTypeSmallint,
+	"mediumint":                   commonTypeInt,
+	"int":                         commonTypeInt,
+	"integer":                     commonTypeInt,
+	"int4":                        commonTypeInt,
+	"serial":                      commonTypeInt,
+	"bigint":                      commonTypeBigint,
+	"int8":                        commonTypeBigint,
+	"bigserial":                   commonTypeBigint,
+	"decimal":                     commonTypeDecimal,
+	"numeric":                     commonTypeDecimal,
+	"number":                      commonTypeDecimal,
+	"dec":                         commonTypeDecimal,
+	"money":                       commonTypeDecimal,
+	"smallmoney":                  commonTypeDecimal,
+	"float":                       commonTypeFloat,
+	"real":                        commonTypeFloat,
+	"float4":                      commonTypeFloat,
+	"binary_float":                commonTypeFloat,
+	"double":                      commonTypeDouble,
+	"double precision":            commonTypeDouble,
+	"float8":                      commonTypeDouble,
+	"binary_double":               commonTypeDouble,
+	"bit":                         commonTypeBool,
+	"bool":                        commonTypeBool,
+	"boolean":                     commonTypeBool,
+	"char":                        commonTypeChar,
+	"character":                   commonTypeChar,
+	"bpchar":                      commonTypeChar,
+	"nchar":                       commonTypeChar,
+	"varchar":                     commonTypeVarchar,
+	"character varying":           commonTypeVarchar,
+	"varchar2":                    commonTypeVarchar,
+	"nvarchar":                    commonTypeVarchar,
+	"nvarchar2":                   commonTypeVarchar,
+	"text":                        commonTypeText,
+	"ntext":                       commonTypeText,
+	"xml":                         commonTypeText,
+	"tinytext":                    commonTypeText,
+	"mediumtext":                  commonTypeText,
+	"longtext":                    commonTypeText,
+	"clob":                        commonTypeText,
+	"nclob":                       commonTypeText,
+	"long":                        commonTypeText,
+	"blob":                        commonTypeBlob,
+	"tinyblob":                    commonTypeBlob,
+	"mediumblob":                  commonTypeBlob,
+	"longblob":                    commonTypeBlob,
+	"bytea":                       commonTypeBlob,
+	"binary":                      commonTypeBlob,
+	"varbinary":                   commonTypeBlob,
+	"raw":                         commonTypeBlob,
+	"long raw":                    commonTypeBlob,
+	"image":                       commonTypeBlob,
+	"date":                        commonTypeDate,
+	"time":                        commonTypeTime,
+	"timetz":                      commonTypeTime,
+	"datetime":                    commonTypeDatetime,
+	"datetime2":                   commonTypeDatetime,
+	"smalldatetime":               commonTypeDatetime,
+	"datetimeoffset":              commonTypeTimestamp,
+	"timestamp":                   commonTypeTimestamp,
+	"timestamptz":                 commonTypeTimestamp,
+	"timestamp without time zone": commonTypeTimestamp,
+	"timestamp with time zone":    commonTypeTimestamp,
+	"json":                        commonTypeJson,
+	"jsonb":                       commonTypeJson,
+}
+
+// 字段类型解析，如：varchar(64) -> varchar, [64]; decimal(10, 2) unsigned -> decimal, [10 2]; nvarchar(max) -> nvarchar, max
+var columnTypeRegexp = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9_ ]*?)\s*(?:\(([\d\s,]+|max)\))?(?:\s+unsigned)?(?:\s+zerofill)?$`)
+
+// 字段类型转换的中间结果
+type typeArgs struct {
+	typ       commonType
+	length    int // 字符长度或数值精度
+	scale     int // 小数位数
+	hasLength bool
+}
+
+// ConvColumnType 将源库字段类型转换为目标库对应的字段类型
+func ConvColumnType(srcType DbType, srcDialect Dialect, column Column, targetType DbType) string {
+	ta := parseColumnType(srcType, srcDialect, column)
+
+	switch targetType {
+	case DbTypeMysql, DbTypeMariadb:
+		return ta.toMysql()
+	case DbTypePostgres:
+		return ta.toPostgres()
+	case DbTypeOracle:
+		return ta.toOracle()
+	case DbTypeDM:
+		return ta.toDm()
+	case DbTypeSqlite:
+		return ta.toSqlite()
+	case DbTypeMssql:
+		return ta.toMssql()
+	default:
+		return column.ColumnType
+	}
+}
+
+func parseColumnType(srcType DbType, srcDialect Dialect, column Column) *typeArgs {
+	columnType := strings.TrimSpace(strings.ToLower(column.ColumnType))
+	ta := &typeArgs{}
+
+	var baseType string
+	maxLength := false
+	if matches := columnTypeRegexp.FindStringSubmatch(columnType); matches != nil {
+		baseType = strings.TrimSpace(matches[1])
+		maxLength = matches[2] == "max"
+		if matches[2] != "" && !maxLength {
+			args := strings.Split(matches[2], ",")
+			ta.length, _ = strconv.Atoi(strings.TrimSpace(args[0]))
+			ta.hasLength = true
+			if len(args) > 1 {
+				ta.scale, _ = strconv.Atoi(strings.TrimSpace(args[1]))
+			}
+		}
+	}
+	if !ta.hasLength || ta.scale == 0 {
+		if scale, err := strconv.Atoi(column.NumScale); err == nil {
+			ta.scale = scale
+		}
+	}
+
+	typ, ok := commonTypeMapping[baseType]
+	if !ok {
+		// 未知类型则根据数据类型大致判断
+		switch srcDialect.GetDataType(column.ColumnType) {
+		case DataTypeNumber:
+			typ = commonTypeDecimal
+		case DataTypeDate:
+			typ = commonTypeDate
+		case DataTypeTime:
+			typ = commonTypeTime
+		case DataTypeDateTime:
+			typ = commonTypeDatetime
+		default:
+			typ = commonTypeText
+		}
+	}
+
+	// 特殊类型处理
+	switch {
+	case maxLength && (typ == commonTypeChar || typ == commonTypeVarchar):
+		// sql server nvarchar(max)等大文本类型
+		typ = commonTypeText
+	case typ == commonTypeBool && ta.hasLength && ta.length > 1:
+		// mysql bit(n)
+		typ, ta.hasLength = commonTypeBigint, false
+	case typ == commonTypeDate && srcType == DbTypeOracle:
+		// oracle date类型包含时间
+		typ = commonTypeDatetime
+	case typ == commonTypeDecimal && srcType == DbTypeOracle && ta.hasLength && ta.scale == 0 && ta.length <= 18:
+		// oracle number(p)整数类型
+		switch {
+		case ta.length <= 4:
+			typ = commonTypeSmallint
+		case ta.length <= 9:
+			typ = commonTypeInt
+		default:
+			typ = commonTypeBigint
+		}
+		ta.hasLength = false
+	}
+	if typ != commonTypeDecimal && typ != commonTypeChar && typ != commonTypeVarchar {
+		ta.hasLength = false
+	}
+	ta.typ = typ
+	return ta
+}
+
+func (ta *typeArgs) decimalArgs() string {
+	if !ta.hasLength || ta.length == 0 {
+		// 未指定精度则使用最大精度
+		scale := ta.scale
+		if scale == 0 {
+			scale = 10
+		}
+		return fmt.Sprintf("(38,%d)", scale)
+	}
+	return fmt.Sprintf("(%d,%d)", ta.length, ta.scale)
+}
+
+func (ta *typeArgs) charLength(defaultLength int) int {
+	if !ta.hasLength || ta.length <= 0 {
+		return defaultLength
+	}
+	return ta.length
+}
+
+func (ta *typeArgs) toMysql() string {
+	switch ta.typ {
+	case commonTypeDecimal:
+		return "decimal" + ta.decimalArgs()
+	case commonTypeBool:
+		return "tinyint(1)"
+	case commonTypeChar:
+		return fmt.Sprintf("char(%d)", min(ta.charLength(1), 255))
+	case commonTypeVarchar:
+		length := ta.charLength(255)
+		if length > 16383 {
+			return "longtext"
+		}
+		return fmt.Sprintf("varchar(%d)", length)
+	case commonTypeText:
+		return "longtext"
+	case commonTypeBlob:
+		return "longblob"
+	case commonTypeTimestamp:
+		return "datetime"
+	default:
+		return string(ta.typ)
+	}
+}
+
+func (ta *typeArgs) toPostgres() string {
+	switch ta.typ {
+	case commonTypeTinyint, commonTypeSmallint:
+		return "int2"
+	case commonTypeInt:
+		return "int4"
+	case commonTypeBigint:
+		return "int8"
+	case commonTypeDecimal:
+		return "numeric" + ta.decimalArgs()
+	case commonTypeFloat:
+		return "float4"
+	case commonTypeDouble:
+		return "float8"
+	case commonTypeBool:
+		return "bool"
+	case commonTypeChar:
+		return fmt.Sprintf("bpchar(%d)", ta.charLength(1))
+	case commonTypeVarchar:
+		return fmt.Sprintf("varchar(%d)", ta.charLength(255))
+	case commonTypeBlob:
+		return "bytea"
+	case commonTypeDatetime, commonTypeTimestamp:
+		return "timestamp"
+	case commonTypeJson:
+		return "jsonb"
+	default:
+		return string(ta.typ)
+	}
+}
+
+func (ta *typeArgs) toOracle() string {
+	switch ta.typ {
+	case commonTypeTinyint:
+		return "NUMBER(3)"
+	case commonTypeSmallint:
+		return "NUMBER(5)"
+	case commonTypeInt:
+		return "NUMBER(10)"
+	case commonTypeBigint:
+		return "NUMBER(19)"
+	case commonTypeDecimal:
+		return "NUMBER" + ta.decimalArgs()
+	case commonTypeFloat:
+		return "BINARY_FLOAT"
+	case commonTypeDouble:
+		return "BINARY_DOUBLE"
+	case commonTypeBool:
+		return "NUMBER(1)"
+	case commonTypeChar:
+		return fmt.Sprintf("CHAR(%d)", min(ta.charLength(1), 2000))
+	case commonTypeVarchar:
+		length := ta.charLength(255)
+		if length > 4000 {
+			return "CLOB"
+		}
+		return fmt.Sprintf("VARCHAR2(%d)", length)
+	case commonTypeText, commonTypeJson:
+		return "CLOB"
+	case commonTypeBlob:
+		return "BLOB"
+	case commonTypeTime:
+		return "VARCHAR2(20)"
+	case commonTypeDate:
+		return "DATE"
+	default:
+		return "TIMESTAMP"
+	}
+}
+
+func (ta *typeArgs) toDm() string {
+	switch ta.typ {
+	case commonTypeDecimal:
+		return "DECIMAL" + ta.decimalArgs()
+	case commonTypeBool:
+		return "BIT"
+	case commonTypeChar:
+		return fmt.Sprintf("CHAR(%d)", ta.charLength(1))
+	case commonTypeVarchar:
+		length := ta.charLength(255)
+		if length > 8188 {
+			return "CLOB"
+		}
+		return fmt.Sprintf("VARCHAR(%d)", length)
+	case commonTypeText, commonTypeJson:
+		return "CLOB"
+	case commonTypeBlob:
+		return "BLOB"
+	default:
+		return strings.ToUpper(string(ta.typ))
+	}
+}
+
+func (ta *typeArgs) toSqlite() string {
+	switch ta.typ {
+	case commonTypeTinyint, commonTypeSmallint, commonTypeInt, commonTypeBigint, commonTypeBool:
+		return "integer"
+	case commonTypeDecimal:
+		return "numeric"
+	case commonTypeFloat, commonTypeDouble:
+		return "real"
+	case commonTypeBlob:
+		return "blob"
+	default:
+		return "text"
+	}
+}
+
+func (ta *typeArgs) toMssql() string {
+	switch ta.typ {
+	case commonTypeDecimal:
+		return "decimal" + ta.decimalArgs()
+	case commonTypeDouble:
+		return "float"
+	case commonTypeBool:
+		return "bit"
+	case commonTypeChar:
+		return fmt.Sprintf("nchar(%d)", min(ta.charLength(1), 4000))
+	case commonTypeVarchar:
+		length := ta.charLength(255)
+		if length > 4000 {
+			return "nvarchar(max)"
+		}
+		return fmt.Sprintf("nvarchar(%d)", length)
+	case commonTypeText, commonTypeJson:
+		return "nvarchar(max)"
+	case commonTypeBlob:
+		return "varbinary(max)"
+	case commonTypeDatetime:
+		return "datetime2"
+	case commonTypeTimestamp:
+		// sql server的timestamp为行版本号，并非时间类型
+		return "datetimeoffset"
+	default:
+		return string(ta.typ)
+	}
+}
+
+// 默认值中的类型转换，如pgsql的 'abc'::character varying
+var defaultValueCastRegexp = regexp.MustCompile(`^(.+?)::[a-zA-Z ]+(\[\])?$`)
+
+// ConvColumnDefault 将源库字段默认值转换为目标库可用的默认值，无法转换的默认值（如函数、序列等）则忽略
+func ConvColumnDefault(column Column) string {
+	value := strings.TrimSpace(column.ColumnDefault)
+	if value == "" || strings.EqualFold(value, "NULL") {
+		return ""
+	}
+	if matches := defaultValueCastRegexp.FindStringSubmatch(value); matches != nil {
+		value = matches[1]
+	}
+	// sql server的unicode字符串，如：N'abc'
+	if strings.HasPrefix(value, "N'") {
+		value = value[1:]
+	}
+	upperValue := strings.ToUpper(value)
+	if strings.HasPrefix(upperValue, "CURRENT_TIMESTAMP") || upperValue == "NOW()" || upperValue == "GETDATE()" || upperValue == "SYSDATE" || upperValue == "SYSTIMESTAMP" {
+		return "CURRENT_TIMESTAMP"
+	}
+	// 函数调用（如序列nextval）不做转换
+	if strings.Contains(value, "(") {
+		return ""
+	}
+	return value
+}
+
+// ConvTableMeta 将源库表结构转换为目标库类型的表结构，用于在目标库创建表
+func ConvTableMeta(srcType DbType, srcDialect Dialect, tm *TableMeta, targetType DbType) *TableMeta {
+	columns := make([]Column, 0, len(tm.Columns))
+	for _, column := range tm.Columns {
+		column.ColumnType = ConvColumnType(srcType, srcDialect, column, targetType)
+		column.ColumnDefault = ConvColumnDefault(column)
+		columns = append(columns, column)
+	}
+	return &TableMeta{
+		Table:   tm.Table,
+		Columns: columns,
+		Indexs:  tm.Indexs,
+	}
+}
+
+// GenCreateTableSql 生成指定类型数据库的建表语句（包含表、字段备注及索引等语句）
+func GenCreateTableSql(dbType DbType, tm *TableMeta) []string {
+	return genCreateTableSql(dbType, tm)
+}
@@ -0,0 +1,46 @@
+package dbi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ConvColumnType(t *testing.T) {
+	tests := []struct {
+		srcType    DbType
+		column     Column
+		targetType DbType
+		want       string
+	}{
+		{DbTypeMysql, Column{ColumnType: "varchar(64)"}, DbTypePostgres, "varchar(64)"},
+		{DbTypeMysql, Column{ColumnType: "bigint(20) unsigned"}, DbTypePostgres, "int8"},
+		{DbTypeMysql, Column{ColumnType: "decimal(10,2)", NumScale: "2"}, DbTypeOracle, "NUMBER(10,2)"},
+		{DbTypeMysql, Column{ColumnType: "datetime"}, DbTypePostgres, "timestamp"},
+		{DbTypeMysql, Column{ColumnType: "longtext"}, DbTypeDM, "CLOB"},
+		{DbTypeMysql, Column{ColumnType: "tinyint(1)"}, DbTypeDM, "TINYINT"},
+		{DbTypePostgres, Column{ColumnType: "int4"}, DbTypeMysql, "int"},
+		{DbTypePostgres, Column{ColumnType: "numeric", NumScale: ""}, DbTypeMysql, "decimal(38,10)"},
+		{DbTypePostgres, Column{ColumnType: "timestamptz"}, DbTypeMysql, "datetime"},
+		{DbTypePostgres, Column{ColumnType: "bytea"}, DbTypeMysql, "longblob"},
+		{DbTypeOracle, Column{ColumnType: "NUMBER(10)", NumScale: "0"}, DbTypeMysql, "bigint"},
+		{DbTypeOracle, Column{ColumnType: "DATE"}, DbTypeMysql, "datetime"},
+		{DbTypeOracle, Column{ColumnType: "VARCHAR2(100)"}, DbTypeMysql, "varchar(100)"},
+		{DbTypeMssql, Column{ColumnType: "nvarchar(max)"}, DbTypeMysql, "longtext"},
+		{DbTypeMssql, Column{ColumnType: "datetime2(7)"}, DbTypePostgres, "timestamp"},
+		{DbTypeMysql, Column{ColumnType: "varchar(64)"}, DbTypeMssql, "nvarchar(64)"},
+		{DbTypePostgres, Column{ColumnType: "timestamptz"}, DbTypeMssql, "datetimeoffset"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, ConvColumnType(tt.srcType, nil, tt.column, tt.targetType), tt.column.ColumnType)
+	}
+}
+
+func Test_ConvColumnDefault(t *testing.T) {
+	require.Equal(t, "'abc'", ConvColumnDefault(Column{ColumnDefault: "'abc'::character varying"}))
+	require.Equal(t, "", ConvColumnDefault(Column{ColumnDefault: "nextval('t_user_id_seq'::regclass)"}))
+	require.Equal(t, "CURRENT_TIMESTAMP", ConvColumnDefault(Column{ColumnDefault: "CURRENT_TIMESTAMP(3)"}))
+	require.Equal(t, "0", ConvColumnDefault(Column{ColumnDefault: "0"}))
+	require.Equal(t, "CURRENT_TIMESTAMP", ConvColumnDefault(Column{ColumnDefault: "getdate()"}))
+	require.Equal(t, "'abc'", ConvColumnDefault(Column{ColumnDefault: "N'abc'"}))
+}
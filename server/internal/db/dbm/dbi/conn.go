@@ -1,8 +1,11 @@
 package dbi
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"mayfly-go/internal/machine/mcm"
 	"mayfly-go/pkg/errorx"
@@ -10,6 +13,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // 游标遍历查询结果集处理函数
@@ -20,9 +24,19 @@ type DbConn struct {
 	Id   string
 	Info *DbInfo
 
-	db *sql.DB
+	db       *sql.DB
+	bindConn *sql.Conn // 不为空则所有sql均在该独占连接上执行
 }
 
+// sql执行器，*sql.DB与*sql.Conn均实现了该接口
+type sqlExecutor interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// 上下文中绑定的独占连接key
+type connCtxKey struct{}
+
 // 执行数据库查询返回的列信息
 type QueryColumn struct {
 	Name string `json:"name"` // 列名
@@ -56,6 +70,26 @@ func (d *DbConn) QueryContext(ctx context.Context, querySql string, args ...any)
 	return queryColumns, result, nil
 }
 
+// 事务中执行查询，若tx == nil，则不使用事务
+func (d *DbConn) TxQueryContext(ctx context.Context, tx *sql.Tx, querySql string, args ...any) ([]*QueryColumn, []map[string]any, error) {
+	if tx == nil {
+		return d.QueryContext(ctx, querySql, args...)
+	}
+	result := make([]map[string]any, 0, 16)
+	var queryColumns []*QueryColumn
+	err := walkQueryRows(ctx, tx, querySql, nil, func(row map[string]any, columns []*QueryColumn) error {
+		if len(queryColumns) == 0 {
+			queryColumns = columns
+		}
+		result = append(result, row)
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, nil, wrapSqlError(err)
+	}
+	return queryColumns, result, nil
+}
+
 // 将查询结果映射至struct，可具体参考sqlx库
 func (d *DbConn) Query2Struct(execSql string, dest any) error {
 	rows, err := d.db.Query(execSql)
@@ -74,7 +108,12 @@ func (d *DbConn) Query2Struct(execSql string, dest any) error {
 
 // 游标方式遍历查询结果集, walkFn返回error不为nil, 则跳出遍历
 func (d *DbConn) WalkQueryRows(ctx context.Context, querySql string, walkFn WalkQueryRowsFunc, args ...any) error {
-	return walkQueryRows(ctx, d.db, querySql, walkFn, args...)
+	return walkQueryRows(ctx, d.getExecutor(ctx), querySql, nil, walkFn, args...)
+}
+
+// 游标方式遍历查询结果集，遍历前先以查询列调用columnsFn（结果集为空时也会调用），如用于导出时写入表头
+func (d *DbConn) WalkQueryRowsWithColumns(ctx context.Context, querySql string, columnsFn func(columns []*QueryColumn) error, walkFn WalkQueryRowsFunc, args ...any) error {
+	return walkQueryRows(ctx, d.getExecutor(ctx), querySql, columnsFn, walkFn, args...)
 }
 
 // 执行 update, insert, delete，建表等sql
@@ -103,7 +142,7 @@ func (d *DbConn) TxExecContext(ctx context.Context, tx *sql.Tx, execSql string,
 	if tx != nil {
 		res, err = tx.ExecContext(ctx, execSql, args...)
 	} else {
-		res, err = d.db.ExecContext(ctx, execSql, args...)
+		res, err = d.getExecutor(ctx).ExecContext(ctx, execSql, args...)
 	}
 
 	if err != nil {
@@ -117,6 +156,64 @@ func (d *DbConn) Begin() (*sql.Tx, error) {
 	return d.db.Begin()
 }
 
+// 获取一个独占连接并绑定至上下文，使用返回的上下文执行的sql都将在该连接上执行，主要用于取消执行中的sql
+// 依次返回 绑定连接后的上下文，连接id(不支持获取则为空)，释放连接函数，错误
+func (d *DbConn) BindConn(ctx context.Context) (context.Context, string, func(), error) {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return ctx, "", nil, wrapSqlError(err)
+	}
+
+	var connId string
+	if stmt := d.Info.Type.StmtConnectionId(); stmt != "" {
+		if err := conn.QueryRowContext(ctx, stmt).Scan(&connId); err != nil {
+			logx.Warnf("获取数据库[%s]连接id失败: %s", d.Id, err.Error())
+		}
+	}
+
+	return context.WithValue(ctx, connCtxKey{}, conn), connId, func() { conn.Close() }, nil
+}
+
+// 丢弃上下文绑定的独占连接，释放后该连接将被关闭而不会放回连接池，用于会话状态无法还原等场景
+func (d *DbConn) DiscardBindConn(ctx context.Context) {
+	if conn, ok := ctx.Value(connCtxKey{}).(*sql.Conn); ok {
+		conn.Raw(func(driverConn any) error { return driver.ErrBadConn })
+	}
+}
+
+// 取消指定连接id正在执行的sql，数据库类型不支持（如oracle）或未获取到连接id则返回错误
+func (d *DbConn) CancelQuery(connId string) error {
+	if connId == "" && d.Info.Type.StmtConnectionId() != "" {
+		return errorx.NewBiz("未获取到执行sql的连接id, 无法取消")
+	}
+	stmt := d.Info.Type.StmtCancelQuery(connId)
+	if stmt == "" {
+		return errorx.NewBiz("数据库类型 %s 暂不支持取消执行中的sql", d.Info.Type)
+	}
+	_, err := d.db.Exec(stmt)
+	return wrapSqlError(err)
+}
+
+// WithBindConn 返回在上下文绑定的独占连接上执行sql的连接副本，用于 dialect 等不接收上下文的方法在该连接上执行，如在快照事务中获取表信息
+func (d *DbConn) WithBindConn(ctx context.Context) *DbConn {
+	conn, ok := ctx.Value(connCtxKey{}).(*sql.Conn)
+	if !ok {
+		return d
+	}
+	return &DbConn{Id: d.Id, Info: d.Info, db: d.db, bindConn: conn}
+}
+
+// 获取sql执行器，若上下文或当前连接绑定了独占连接则使用该连接
+func (d *DbConn) getExecutor(ctx context.Context) sqlExecutor {
+	if conn, ok := ctx.Value(connCtxKey{}).(*sql.Conn); ok {
+		return conn
+	}
+	if d.bindConn != nil {
+		return d.bindConn
+	}
+	return d.db
+}
+
 // 获取数据库dialect实现接口
 func (d *DbConn) GetDialect() Dialect {
 	return d.Info.Meta.GetDialect(d)
@@ -137,7 +234,7 @@ func (d *DbConn) Close() {
 }
 
 // 游标方式遍历查询rows, walkFn error不为nil, 则跳出遍历
-func walkQueryRows(ctx context.Context, db *sql.DB, selectSql string, walkFn WalkQueryRowsFunc, args ...any) error {
+func walkQueryRows(ctx context.Context, db sqlExecutor, selectSql string, columnsFn func(columns []*QueryColumn) error, walkFn WalkQueryRowsFunc, args ...any) error {
 	rows, err := db.QueryContext(ctx, selectSql, args...)
 
 	if err != nil {
@@ -161,12 +258,17 @@ func walkQueryRows(ctx context.Context, db *sql.DB, selectSql string, walkFn Wal
 	// 这里表示一行填充数据
 	scans := make([]any, lenCols)
 	// 这里表示一行所有列的值，用[]byte表示
-	values := make([][]byte, lenCols)
+	values := make([]bytesValue, lenCols)
 	for k, colType := range colTypes {
 		cols[k] = &QueryColumn{Name: colType.Name(), Type: colType.DatabaseTypeName()}
 		// 这里scans引用values，把数据填充到[]byte里
 		scans[k] = &values[k]
 	}
+	if columnsFn != nil {
+		if err := columnsFn(cols); err != nil {
+			return err
+		}
+	}
 
 	for rows.Next() {
 		// 不Scan也会导致等待，该链接实际处于未工作的状态，然后也会导致连接数迅速达到最大
@@ -188,6 +290,49 @@ func walkQueryRows(ctx context.Context, db *sql.DB, selectSql string, walkFn Wal
 	return nil
 }
 
+// 以[]byte接收的列值，兼容驱动返回的数组、map等复合类型值（如clickhouse的Array、Map），复合类型转为json
+type bytesValue []byte
+
+func (b *bytesValue) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*b = nil
+	case []byte:
+		*b = bytes.Clone(v)
+	case string:
+		*b = []byte(v)
+	case time.Time:
+		*b = []byte(v.Format(time.RFC3339Nano))
+	default:
+		rv := reflect.ValueOf(src)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			*b = strconv.AppendInt(nil, rv.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			*b = strconv.AppendUint(nil, rv.Uint(), 10)
+		case reflect.Float32:
+			*b = strconv.AppendFloat(nil, rv.Float(), 'g', -1, 32)
+		case reflect.Float64:
+			*b = strconv.AppendFloat(nil, rv.Float(), 'g', -1, 64)
+		case reflect.Bool:
+			*b = strconv.AppendBool(nil, rv.Bool())
+		case reflect.String:
+			*b = []byte(rv.String())
+		default:
+			if stringer, ok := src.(fmt.Stringer); ok {
+				*b = []byte(stringer.String())
+				return nil
+			}
+			data, err := json.Marshal(src)
+			if err != nil {
+				return err
+			}
+			*b = data
+		}
+	}
+	return nil
+}
+
 // 将查询的值转为对应列类型的实际值，不全部转为字符串
 func valueConvert(data []byte, colType *sql.ColumnType) any {
 	if data == nil {
@@ -198,9 +343,21 @@ func valueConvert(data []byte, colType *sql.ColumnType) any {
 
 	// 如果类型是bit，则直接返回第一个字节即可
 	if strings.Contains(colDatabaseTypeName, "bit") {
+		// sql server驱动的bit值为bool类型
+		switch string(data) {
+		case "true":
+			return uint8(1)
+		case "false":
+			return uint8(0)
+		}
 		return data[0]
 	}
 
+	// sql server的uniqueidentifier为16字节，前三段为小端序
+	if colDatabaseTypeName == "uniqueidentifier" && len(data) == 16 {
+		return fmt.Sprintf("%X-%X-%X-%X-%X", []byte{data[3], data[2], data[1], data[0]}, []byte{data[5], data[4]}, []byte{data[7], data[6]}, data[8:10], data[10:])
+	}
+
 	// 这里把[]byte数据转成string
 	stringV := string(data)
 	if stringV == "" {
@@ -0,0 +1,522 @@
+package dbi
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 数据导入支持的文件类型
+const (
+	DataFileTypeCsv   = "csv"
+	DataFileTypeXlsx  = "xlsx"
+	DataFileTypeJsonl = "jsonl"
+)
+
+// 根据文件名后缀获取数据文件类型
+func GetDataFileType(filename string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".csv":
+		return DataFileTypeCsv, nil
+	case ".xlsx":
+		return DataFileTypeXlsx, nil
+	case ".jsonl", ".ndjson":
+		return DataFileTypeJsonl, nil
+	default:
+		return "", errorx.NewBiz("不支持的文件类型[%s], 仅支持csv、xlsx、jsonl文件", ext)
+	}
+}
+
+// DataFileReader 数据文件的行读取器
+type DataFileReader interface {
+	// Read 读取下一行数据，值为nil表示null，读取完毕返回io.EOF，单行格式错误返回*DataFileRowError。
+	// jsonl文件首行返回首个对象的字段名作为表头，后续行按表头顺序返回对应的值
+	Read() ([]any, error)
+}
+
+// DataFileRowError 数据文件中单行的格式错误，可跳过该行继续读取
+type DataFileRowError struct {
+	Err error
+}
+
+func (e *DataFileRowError) Error() string {
+	return e.Err.Error()
+}
+
+// 是否为单行数据格式错误
+func IsDataFileRowError(err error) bool {
+	var rowErr *DataFileRowError
+	return errors.As(err, &rowErr)
+}
+
+// NewDataFileReader 创建数据文件读取器，xlsx仅读取第一个工作表
+func NewDataFileReader(fileType string, r io.ReaderAt, size int64) (DataFileReader, error) {
+	switch fileType {
+	case DataFileTypeCsv:
+		return newCsvReader(io.NewSectionReader(r, 0, size)), nil
+	case DataFileTypeXlsx:
+		return newXlsxReader(r, size)
+	case DataFileTypeJsonl:
+		return &jsonlReader{scanner: newLineScanner(io.NewSectionReader(r, 0, size))}, nil
+	default:
+		return nil, errorx.NewBiz("不支持的文件类型[%s]", fileType)
+	}
+}
+
+// DetectDataFileHeader 判断数据文件首行是否为表头，即各列均不为空、不为数字且不重复
+func DetectDataFileHeader(fileType string, firstRow []any) bool {
+	if fileType == DataFileTypeJsonl {
+		return true
+	}
+	if len(firstRow) == 0 {
+		return false
+	}
+	names := make(map[string]struct{}, len(firstRow))
+	for _, value := range firstRow {
+		name, ok := value.(string)
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return false
+		}
+		if _, err := strconv.ParseFloat(name, 64); err == nil {
+			return false
+		}
+		if _, ok := names[strings.ToLower(name)]; ok {
+			return false
+		}
+		names[strings.ToLower(name)] = struct{}{}
+	}
+	return true
+}
+
+// 导入数据时支持的时间格式
+var importTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	time.DateTime,
+	"2006-01-02 15:04",
+	time.DateOnly,
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	time.TimeOnly,
+	"15:04",
+}
+
+// ConvertImportValue 根据字段数据类型转换导入文件中的值。
+// 非字符串类型的空值转为null，时间日期统一转为 2006-01-02 15:04:05 等格式，xlsx中以数字存储的日期亦会转换
+func ConvertImportValue(dataType DataType, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if b, ok := value.(bool); ok {
+		if dataType == DataTypeNumber {
+			if b {
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	strValue := anyx.ToString(value)
+	if dataType == DataTypeString || dataType == "" {
+		return strValue, nil
+	}
+
+	strValue = strings.TrimSpace(strValue)
+	if strValue == "" {
+		return nil, nil
+	}
+	switch dataType {
+	case DataTypeNumber:
+		if _, err := strconv.ParseFloat(strValue, 64); err != nil {
+			return nil, errorx.NewBiz("值[%s]不是有效的数字", strValue)
+		}
+		return strValue, nil
+	case DataTypeDate, DataTypeTime, DataTypeDateTime:
+		t, err := parseImportTime(strValue)
+		if err != nil {
+			return nil, err
+		}
+		switch dataType {
+		case DataTypeDate:
+			return t.Format(time.DateOnly), nil
+		case DataTypeTime:
+			return t.Format(time.TimeOnly), nil
+		default:
+			return t.Format(time.DateTime), nil
+		}
+	}
+	return strValue, nil
+}
+
+// xlsx日期的最大值，即9999-12-31
+const maxExcelDateSerial = 2958466
+
+func parseImportTime(value string) (time.Time, error) {
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	// xlsx中的日期以1899-12-30起的天数存储，小数部分为时间
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial >= 0 && serial < maxExcelDateSerial {
+		days := math.Floor(serial)
+		seconds := math.Round((serial - days) * 86400)
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.Local).AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second), nil
+	}
+	return time.Time{}, errorx.NewBiz("值[%s]不是有效的时间格式", value)
+}
+
+// ------------------------- csv -------------------------
+
+type csvReader struct {
+	reader *csv.Reader
+	first  bool
+}
+
+func newCsvReader(r io.Reader) *csvReader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return &csvReader{reader: reader, first: true}
+}
+
+func (cr *csvReader) Read() ([]any, error) {
+	record, err := cr.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &DataFileRowError{Err: err}
+		}
+		return nil, err
+	}
+	row := make([]any, len(record))
+	for i, v := range record {
+		row[i] = v
+	}
+	// 去除utf-8 bom
+	if cr.first && len(record) > 0 {
+		row[0] = strings.TrimPrefix(record[0], "\ufeff")
+	}
+	cr.first = false
+	return row, nil
+}
+
+// ------------------------- jsonl -------------------------
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	header  []string
+	// 首行对象的值，表头返回后再返回
+	firstValues []any
+}
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	// 单行最大16M
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return scanner
+}
+
+func (jr *jsonlReader) Read() ([]any, error) {
+	if jr.firstValues != nil {
+		values := jr.firstValues
+		jr.firstValues = nil
+		return values, nil
+	}
+
+	line, err := jr.nextLine()
+	if err != nil {
+		return nil, err
+	}
+	if jr.header == nil {
+		// 首行为表头，格式错误则无法继续读取
+		keys, obj, err := decodeOrderedObject(line)
+		if err != nil {
+			return nil, err
+		}
+		jr.header = keys
+		jr.firstValues = jr.toRow(obj)
+		row := make([]any, len(keys))
+		for i, key := range keys {
+			row[i] = key
+		}
+		return row, nil
+	}
+
+	obj := make(map[string]any)
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, &DataFileRowError{Err: errorx.NewBiz("json解析失败: %s", err.Error())}
+	}
+	return jr.toRow(obj), nil
+}
+
+func (jr *jsonlReader) nextLine() ([]byte, error) {
+	for jr.scanner.Scan() {
+		line := bytes.TrimSpace(jr.scanner.Bytes())
+		line = bytes.TrimPrefix(line, []byte("\ufeff"))
+		if len(line) > 0 {
+			return line, nil
+		}
+	}
+	if err := jr.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// 按表头顺序获取对象的值，嵌套的对象或数组转为json字符串
+func (jr *jsonlReader) toRow(obj map[string]any) []any {
+	row := make([]any, len(jr.header))
+	for i, key := range jr.header {
+		switch v := obj[key].(type) {
+		case map[string]any, []any:
+			b, _ := json.Marshal(v)
+			row[i] = string(b)
+		default:
+			row[i] = v
+		}
+	}
+	return row
+}
+
+// 解析json对象，并返回按声明顺序的字段名
+func decodeOrderedObject(line []byte) ([]string, map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if t, err := decoder.Token(); err != nil || t != json.Delim('{') {
+		return nil, nil, errorx.NewBiz("jsonl文件每行需为json对象")
+	}
+	keys := make([]string, 0)
+	obj := make(map[string]any)
+	for decoder.More() {
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, nil, errorx.NewBiz("json解析失败: %s", err.Error())
+		}
+		key := t.(string)
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, errorx.NewBiz("json解析失败: %s", err.Error())
+		}
+		if _, ok := obj[key]; !ok {
+			keys = append(keys, key)
+		}
+		obj[key] = value
+	}
+	return keys, obj, nil
+}
+
+// ------------------------- xlsx -------------------------
+
+type xlsxReader struct {
+	sheet         io.ReadCloser
+	decoder       *xml.Decoder
+	sharedStrings []string
+}
+
+func newXlsxReader(r io.ReaderAt, size int64) (*xlsxReader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errorx.NewBiz("xlsx文件解析失败: %s", err.Error())
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sharedStrings, err := readXlsxSharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, err
+	}
+	sheetFile := files[getXlsxFirstSheetPath(files)]
+	if sheetFile == nil {
+		return nil, errorx.NewBiz("xlsx文件中不存在工作表")
+	}
+	sheet, err := sheetFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxReader{sheet: sheet, decoder: xml.NewDecoder(sheet), sharedStrings: sharedStrings}, nil
+}
+
+func (xr *xlsxReader) Read() ([]any, error) {
+	for {
+		token, err := xr.decoder.Token()
+		if err != nil {
+			xr.sheet.Close()
+			return nil, err
+		}
+		if se, ok := token.(xml.StartElement); ok && se.Name.Local == "row" {
+			return xr.readRow()
+		}
+	}
+}
+
+// xlsx单元格
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		Text string   `xml:"t"`
+		Runs []string `xml:"r>t"`
+	} `xml:"is"`
+}
+
+func (xr *xlsxReader) readRow() ([]any, error) {
+	row := make([]any, 0)
+	for {
+		token, err := xr.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "c" {
+				continue
+			}
+			cell := new(xlsxCell)
+			if err := xr.decoder.DecodeElement(cell, &t); err != nil {
+				return nil, err
+			}
+			// 空单元格不会写入文件，需根据单元格位置补齐
+			if idx := xlsxColumnIndex(cell.Ref); idx >= 0 {
+				for len(row) < idx {
+					row = append(row, "")
+				}
+			}
+			row = append(row, xr.cellValue(cell))
+		case xml.EndElement:
+			if t.Name.Local == "row" {
+				return row, nil
+			}
+		}
+	}
+}
+
+func (xr *xlsxReader) cellValue(cell *xlsxCell) any {
+	switch cell.Type {
+	case "s":
+		idx, err := strconv.Atoi(cell.Value)
+		if err != nil || idx < 0 || idx >= len(xr.sharedStrings) {
+			return ""
+		}
+		return xr.sharedStrings[idx]
+	case "inlineStr":
+		if len(cell.Inline.Runs) > 0 {
+			return strings.Join(cell.Inline.Runs, "")
+		}
+		return cell.Inline.Text
+	case "b":
+		return cell.Value == "1"
+	default:
+		return cell.Value
+	}
+}
+
+// 根据单元格引用获取列序号（从0开始），如：A1 -> 0、AB3 -> 27
+func xlsxColumnIndex(ref string) int {
+	idx := 0
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		idx = idx*26 + int(c-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return idx - 1
+}
+
+func readXlsxSharedStrings(f *zip.File) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var sst struct {
+		Items []struct {
+			Text string   `xml:"t"`
+			Runs []string `xml:"r>t"`
+		} `xml:"si"`
+	}
+	if err := xml.NewDecoder(rc).Decode(&sst); err != nil {
+		return nil, errorx.NewBiz("xlsx共享字符串解析失败: %s", err.Error())
+	}
+	res := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		if len(item.Runs) > 0 {
+			res[i] = strings.Join(item.Runs, "")
+		} else {
+			res[i] = item.Text
+		}
+	}
+	return res, nil
+}
+
+// 获取第一个工作表的文件路径
+func getXlsxFirstSheetPath(files map[string]*zip.File) string {
+	const defaultPath = "xl/worksheets/sheet1.xml"
+	var workbook struct {
+		Sheets []struct {
+			Id string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			Id     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if decodeZipXml(files["xl/workbook.xml"], &workbook) != nil || len(workbook.Sheets) == 0 {
+		return defaultPath
+	}
+	if decodeZipXml(files["xl/_rels/workbook.xml.rels"], &rels) != nil {
+		return defaultPath
+	}
+	for _, rel := range rels.Relationships {
+		if rel.Id != workbook.Sheets[0].Id {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return defaultPath
+}
+
+func decodeZipXml(f *zip.File, v any) error {
+	if f == nil {
+		return io.EOF
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
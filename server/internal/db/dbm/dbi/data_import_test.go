@@ -0,0 +1,124 @@
+package dbi
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readAllDataFile(t *testing.T, fileType string, data []byte) ([][]any, error) {
+	reader, err := NewDataFileReader(fileType, bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	rows := make([][]any, 0)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+func Test_csvReader(t *testing.T) {
+	rows, err := readAllDataFile(t, DataFileTypeCsv, []byte("\ufeffid,name\n1,\"a,b\"\n2\n"))
+	require.NoError(t, err)
+	require.Equal(t, [][]any{{"id", "name"}, {"1", "a,b"}, {"2"}}, rows)
+	require.True(t, DetectDataFileHeader(DataFileTypeCsv, rows[0]))
+	require.False(t, DetectDataFileHeader(DataFileTypeCsv, rows[1]))
+}
+
+func Test_jsonlReader(t *testing.T) {
+	data := `{"id": 1, "name": "a", "tags": ["x"]}
+
+{"name": "b", "id": 2, "extra": true}
+not json
+{"id": 3}`
+	reader, err := NewDataFileReader(DataFileTypeJsonl, strings.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var rows [][]any
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if IsDataFileRowError(err) {
+			rows = append(rows, nil)
+			continue
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+	require.Len(t, rows, 5)
+	require.Equal(t, []any{"id", "name", "tags"}, rows[0])
+	require.Equal(t, json.Number("1"), rows[1][0])
+	require.Equal(t, `["x"]`, rows[1][2])
+	require.Equal(t, "b", rows[2][1])
+	require.Nil(t, rows[2][2])
+	require.Nil(t, rows[3])
+	require.Nil(t, rows[4][1])
+}
+
+func Test_xlsxReader(t *testing.T) {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	files := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="data" sheetId="1" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>id</t></si><si><t>name</t></si><si><r><t>he</t></r><r><t>llo</t></r></si></sst>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>ok</t></is></c></row>` +
+			`<row r="2"><c r="A2"><v>1</v></c><c r="C2" t="b"><v>1</v></c></row>` +
+			`<row r="3"><c r="B3" t="s"><v>2</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	rows, err := readAllDataFile(t, DataFileTypeXlsx, buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, [][]any{{"id", "name", "ok"}, {"1", "", true}, {"", "hello"}}, rows)
+}
+
+func Test_ConvertImportValue(t *testing.T) {
+	cases := []struct {
+		dataType DataType
+		value    any
+		want     any
+		err      bool
+	}{
+		{DataTypeString, "", "", false},
+		{DataTypeNumber, "", nil, false},
+		{DataTypeNumber, " 12.5 ", "12.5", false},
+		{DataTypeNumber, true, 1, false},
+		{DataTypeNumber, "abc", nil, true},
+		{DataTypeDateTime, "2024-01-02T03:04:05Z", "2024-01-02 03:04:05", false},
+		{DataTypeDateTime, "2024/01/02 03:04", "2024-01-02 03:04:00", false},
+		{DataTypeDate, "45293", "2024-01-02", false},
+		{DataTypeDateTime, "45293.5", "2024-01-02 12:00:00", false},
+		{DataTypeTime, "12:30", "12:30:00", false},
+		{DataTypeDate, "abc", nil, true},
+	}
+	for _, c := range cases {
+		got, err := ConvertImportValue(c.dataType, c.value)
+		if c.err {
+			require.Error(t, err, c.value)
+			continue
+		}
+		require.NoError(t, err, c.value)
+		require.Equal(t, c.want, got, c.value)
+	}
+}
@@ -2,7 +2,10 @@ package dbi
 
 import (
 	"context"
+	"fmt"
+	"mayfly-go/internal/db/config"
 	"mayfly-go/internal/db/domain/entity"
+	"os"
 	"path/filepath"
 	"time"
 )
@@ -14,7 +17,8 @@ type DbProgram interface {
 
 	ReplayBinlog(ctx context.Context, originalDatabase, targetDatabase string, restoreInfo *RestoreInfo) error
 
-	RestoreBackupHistory(ctx context.Context, dbName string, dbBackupId uint64, dbBackupHistoryUuid string) error
+	// RestoreBackupHistory 将备份恢复至 dbName 指定的数据库，备份可来自其他数据库实例，备份文件通过 openFile 流式读取
+	RestoreBackupHistory(ctx context.Context, dbName string, backupHistory *entity.DbBackupHistory, openFile BackupFileOpener) error
 
 	GetBinlogEventPositionAtOrAfterTime(ctx context.Context, binlogName string, targetTime time.Time) (position int64, parseErr error)
 }
@@ -34,3 +38,23 @@ func (ri *RestoreInfo) GetBinlogPaths(binlogDir string) []string {
 	}
 	return files
 }
+
+// GetDbBackupDir 获取数据库备份任务的备份文件目录
+func GetDbBackupDir(instanceId, backupId uint64) string {
+	return filepath.Join(
+		config.GetDbBackupRestore().BackupPath,
+		fmt.Sprintf("instance-%d", instanceId),
+		fmt.Sprintf("backup-%d", backupId))
+}
+
+// GetDbBackupFile 获取备份历史对应的备份文件路径，不同备份程序生成的备份文件扩展名不同
+func GetDbBackupFile(instanceId, backupId uint64, uuid string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(GetDbBackupDir(instanceId, backupId), uuid+".*"))
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", os.ErrNotExist
+	}
+	return files[0], nil
+}
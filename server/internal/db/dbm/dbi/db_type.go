@@ -11,12 +11,14 @@ import (
 type DbType string
 
 const (
-	DbTypeMysql    DbType = "mysql"
-	DbTypeMariadb  DbType = "mariadb"
-	DbTypePostgres DbType = "postgres"
-	DbTypeDM       DbType = "dm"
-	DbTypeOracle   DbType = "oracle"
-	DbTypeSqlite   DbType = "sqlite"
+	DbTypeMysql      DbType = "mysql"
+	DbTypeMariadb    DbType = "mariadb"
+	DbTypePostgres   DbType = "postgres"
+	DbTypeDM         DbType = "dm"
+	DbTypeOracle     DbType = "oracle"
+	DbTypeSqlite     DbType = "sqlite"
+	DbTypeMssql      DbType = "mssql"
+	DbTypeClickhouse DbType = "clickhouse"
 )
 
 func ToDbType(dbType string) DbType {
@@ -40,10 +42,13 @@ func (dbType DbType) Equal(typ string) bool {
 // byte, the result will be truncated immediately before it.
 func (dbType DbType) QuoteIdentifier(name string) string {
 	switch dbType {
-	case DbTypeMysql, DbTypeMariadb:
+	case DbTypeMysql, DbTypeMariadb, DbTypeClickhouse:
 		return quoteIdentifier(name, "`")
 	case DbTypePostgres:
 		return quoteIdentifier(name, `"`)
+	case DbTypeMssql:
+		// sql server使用中括号包裹标识符，标识符中的 ] 需转义为 ]]
+		return "[" + quoteIdentifier(name, "]")[1:]
 	default:
 		return quoteIdentifier(name, `"`)
 	}
@@ -51,10 +56,12 @@ func (dbType DbType) QuoteIdentifier(name string) string {
 
 func (dbType DbType) RemoveQuote(name string) string {
 	switch dbType {
-	case DbTypeMysql, DbTypeMariadb:
+	case DbTypeMysql, DbTypeMariadb, DbTypeClickhouse:
 		return removeQuote(name, "`")
 	case DbTypePostgres:
 		return removeQuote(name, `"`)
+	case DbTypeMssql:
+		return removeQuote(removeQuote(name, "["), "]")
 	default:
 		return removeQuote(name, `"`)
 	}
@@ -62,17 +69,28 @@ func (dbType DbType) RemoveQuote(name string) string {
 
 func (dbType DbType) QuoteLiteral(literal string) string {
 	switch dbType {
-	case DbTypeMysql, DbTypeMariadb:
+	case DbTypeMysql, DbTypeMariadb, DbTypeClickhouse:
 		literal = strings.ReplaceAll(literal, `\`, `\\`)
 		literal = strings.ReplaceAll(literal, `'`, `''`)
 		return "'" + literal + "'"
 	case DbTypePostgres:
 		return pq.QuoteLiteral(literal)
+	case DbTypeMssql:
+		// sql server不转义反斜杠，使用N前缀以支持unicode字符
+		return "N'" + strings.ReplaceAll(literal, `'`, `''`) + "'"
 	default:
 		return pq.QuoteLiteral(literal)
 	}
 }
 
+// 日期时间类型值的sql字面量，oracle需通过to_timestamp将字符串转换为日期
+func (dbType DbType) QuoteDateTimeLiteral(value string) string {
+	if dbType == DbTypeOracle {
+		return fmt.Sprintf("TO_TIMESTAMP(%s, 'YYYY-MM-DD HH24:MI:SS.FF')", dbType.QuoteLiteral(value))
+	}
+	return dbType.QuoteLiteral(value)
+}
+
 func (dbType DbType) MetaDbName() string {
 	switch dbType {
 	case DbTypeMysql, DbTypeMariadb:
@@ -81,6 +99,8 @@ func (dbType DbType) MetaDbName() string {
 		return "postgres"
 	case DbTypeDM:
 		return ""
+	case DbTypeMssql:
+		return "master"
 	default:
 		return ""
 	}
@@ -88,7 +108,8 @@ func (dbType DbType) MetaDbName() string {
 
 func (dbType DbType) Dialect() sqlparser.Dialect {
 	switch dbType {
-	case DbTypeMysql, DbTypeMariadb:
+	case DbTypeMysql, DbTypeMariadb, DbTypeClickhouse:
+		// clickhouse的标识符与字符串转义规则与mysql一致
 		return sqlparser.MysqlDialect{}
 	case DbTypePostgres:
 		return sqlparser.PostgresDialect{}
@@ -97,6 +118,62 @@ func (dbType DbType) Dialect() sqlparser.Dialect {
 	}
 }
 
+// SplitSql 将多条sql拆分为单条语句。clickhouse等部分语法无法被sql解析器识别，拆分失败时退化为按分号拆分
+func (dbType DbType) SplitSql(sql string) ([]string, error) {
+	sqls, err := sqlparser.SplitStatementToPieces(sql, sqlparser.WithDialect(dbType.Dialect()))
+	if err != nil && dbType == DbTypeClickhouse {
+		return splitBySemicolon(sql), nil
+	}
+	return sqls, err
+}
+
+// 按分号拆分sql，忽略引号及注释中的分号
+func splitBySemicolon(sql string) []string {
+	sqls := make([]string, 0)
+	appendSql := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			sqls = append(sqls, s)
+		}
+	}
+
+	start := 0
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && strings.HasPrefix(sql[i:], "--"), c == '#':
+			if end := strings.IndexByte(sql[i:], '\n'); end > -1 {
+				i += end
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end > -1 {
+				i += end + 3
+			} else {
+				i = len(sql)
+			}
+		case c == ';':
+			appendSql(sql[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(sql) {
+		appendSql(sql[start:])
+	}
+	return sqls
+}
+
 func quoteIdentifier(name, quoter string) string {
 	end := strings.IndexRune(name, 0)
 	if end > -1 {
@@ -110,6 +187,7 @@ func removeQuote(name, quoter string) string {
 	return strings.ReplaceAll(name, quoter, "")
 }
 
+// 开启或关闭当前会话外键检查的sql，不支持则返回空
 func (dbType DbType) StmtSetForeignKeyChecks(check bool) string {
 	switch dbType {
 	case DbTypeMysql, DbTypeMariadb:
@@ -121,14 +199,22 @@ func (dbType DbType) StmtSetForeignKeyChecks(check bool) string {
 	case DbTypePostgres:
 		// not currently supported postgres
 		return ""
+	case DbTypeSqlite:
+		// 事务中无法修改 foreign_keys，故延迟至事务提交时再检查外键
+		if check {
+			return ""
+		} else {
+			return "PRAGMA defer_foreign_keys = ON;\n"
+		}
 	default:
+		// sql server、oracle、达梦等不支持会话级关闭外键检查
 		return ""
 	}
 }
 
 func (dbType DbType) StmtUseDatabase(dbName string) string {
 	switch dbType {
-	case DbTypeMysql, DbTypeMariadb:
+	case DbTypeMysql, DbTypeMariadb, DbTypeMssql, DbTypeClickhouse:
 		return fmt.Sprintf("USE %s;\n", dbType.QuoteIdentifier(dbName))
 	case DbTypePostgres:
 		// not currently supported postgres
@@ -137,3 +223,108 @@ func (dbType DbType) StmtUseDatabase(dbName string) string {
 		return ""
 	}
 }
+
+// 获取当前连接id的sql，用于取消执行中的sql
+func (dbType DbType) StmtConnectionId() string {
+	switch dbType {
+	case DbTypeMysql, DbTypeMariadb:
+		return "SELECT CONNECTION_ID()"
+	case DbTypePostgres:
+		return "SELECT pg_backend_pid()"
+	case DbTypeDM:
+		return "SELECT SESSID()"
+	case DbTypeMssql:
+		return "SELECT @@SPID"
+	default:
+		return ""
+	}
+}
+
+// 取消指定连接id正在执行的sql
+func (dbType DbType) StmtCancelQuery(connId string) string {
+	if connId == "" {
+		return ""
+	}
+	switch dbType {
+	case DbTypeMysql, DbTypeMariadb:
+		return fmt.Sprintf("KILL QUERY %s", connId)
+	case DbTypePostgres:
+		return fmt.Sprintf("SELECT pg_cancel_backend(%s)", connId)
+	case DbTypeDM:
+		return fmt.Sprintf("SP_CANCEL_SESSION_OPERATION(%s)", connId)
+	case DbTypeMssql:
+		// sql server无法只取消语句，KILL会终止该会话
+		return fmt.Sprintf("KILL %s", connId)
+	default:
+		return ""
+	}
+}
+
+// 开启只读一致性快照事务的sql，用于逻辑备份时读取一致的数据
+func (dbType DbType) StmtStartSnapshot() string {
+	switch dbType {
+	case DbTypeMysql, DbTypeMariadb:
+		return "START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY"
+	case DbTypePostgres:
+		return "BEGIN TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY"
+	case DbTypeOracle, DbTypeDM:
+		return "SET TRANSACTION READ ONLY"
+	case DbTypeSqlite:
+		return "BEGIN"
+	default:
+		return ""
+	}
+}
+
+// 开启事务的sql，仅支持ddl语句在事务中执行并可回滚的数据库，mysql、oracle、达梦等执行ddl会隐式提交事务故返回空
+func (dbType DbType) StmtBeginTransaction() string {
+	switch dbType {
+	case DbTypePostgres, DbTypeSqlite:
+		return "BEGIN"
+	case DbTypeMssql:
+		return "BEGIN TRANSACTION"
+	default:
+		return ""
+	}
+}
+
+// 删除表（若存在）的sql，oracle不支持 IF EXISTS
+func (dbType DbType) StmtDropTableIfExists(quotedTable string) string {
+	if dbType == DbTypeOracle {
+		return fmt.Sprintf("DROP TABLE %s", quotedTable)
+	}
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s", quotedTable)
+}
+
+// 查询并锁定满足条件的行的sql，需在事务中执行，用于修改前读取旧值。
+// sqlite写事务串行执行、clickhouse不支持事务，故使用普通查询
+func (dbType DbType) StmtSelectForUpdate(quotedTable string, where string) string {
+	switch dbType {
+	case DbTypeMysql, DbTypeMariadb, DbTypePostgres, DbTypeOracle, DbTypeDM:
+		return fmt.Sprintf("SELECT * FROM %s WHERE %s FOR UPDATE", quotedTable, where)
+	case DbTypeMssql:
+		return fmt.Sprintf("SELECT * FROM %s WITH (UPDLOCK, ROWLOCK) WHERE %s", quotedTable, where)
+	default:
+		return fmt.Sprintf("SELECT * FROM %s WHERE %s", quotedTable, where)
+	}
+}
+
+// 创建数据库的sql，不支持则返回空
+func (dbType DbType) StmtCreateDatabase(dbName string) string {
+	switch dbType {
+	case DbTypeMysql, DbTypeMariadb, DbTypePostgres, DbTypeMssql, DbTypeClickhouse:
+		return fmt.Sprintf("CREATE DATABASE %s", dbType.QuoteIdentifier(dbName))
+	default:
+		return ""
+	}
+}
+
+// 删除数据库的sql，不支持则返回空
+func (dbType DbType) StmtDropDatabase(dbName string) string {
+	switch dbType {
+	case DbTypeMysql, DbTypeMariadb, DbTypePostgres, DbTypeMssql, DbTypeClickhouse:
+		return fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbType.QuoteIdentifier(dbName))
+	default:
+		return ""
+	}
+}
@@ -42,6 +42,11 @@ func Test_QuoteLiteral(t *testing.T) {
 			sql:    "a\u00A0b",
 			want:   "'a\u00A0b'",
 		},
+		{
+			dbType: DbTypeMssql,
+			sql:    "\\a'b",
+			want:   "N'\\a''b'",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(string(tt.dbType)+"_"+tt.sql, func(t *testing.T) {
@@ -81,3 +86,62 @@ func Test_quoteIdentifier(t *testing.T) {
 		})
 	}
 }
+
+func Test_StmtCancelQuery(t *testing.T) {
+	tests := []struct {
+		dbType DbType
+		connId string
+		want   string
+	}{
+		{
+			dbType: DbTypeMysql,
+			connId: "12",
+			want:   "KILL QUERY 12",
+		},
+		{
+			dbType: DbTypePostgres,
+			connId: "12",
+			want:   "SELECT pg_cancel_backend(12)",
+		},
+		{
+			dbType: DbTypeMysql,
+			connId: "",
+			want:   "",
+		},
+		{
+			dbType: DbTypeSqlite,
+			connId: "12",
+			want:   "",
+		},
+		{
+			dbType: DbTypeMssql,
+			connId: "52",
+			want:   "KILL 52",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dbType)+"_"+tt.connId, func(t *testing.T) {
+			got := tt.dbType.StmtCancelQuery(tt.connId)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_MssqlQuoteIdentifier(t *testing.T) {
+	require.Equal(t, "[t_user]", DbTypeMssql.QuoteIdentifier("t_user"))
+	require.Equal(t, "[a]]b]", DbTypeMssql.QuoteIdentifier("a]b"))
+	require.Equal(t, "t_user", DbTypeMssql.RemoveQuote("[t_user]"))
+}
+
+func Test_splitBySemicolon(t *testing.T) {
+	sqls := splitBySemicolon("SELECT 'a;b', `c;d` FROM t FINAL; -- x;y\nINSERT INTO t VALUES ('\\';'); /* ; */ OPTIMIZE TABLE t;")
+	require.Equal(t, []string{"SELECT 'a;b', `c;d` FROM t FINAL", "-- x;y\nINSERT INTO t VALUES ('\\';')", "/* ; */ OPTIMIZE TABLE t"}, sqls)
+	require.Empty(t, splitBySemicolon(" ; \n"))
+}
+
+func Test_StmtSelectForUpdate(t *testing.T) {
+	require.Equal(t, "SELECT * FROM `t` WHERE `id` = 1 FOR UPDATE", DbTypeMysql.StmtSelectForUpdate("`t`", "`id` = 1"))
+	require.Equal(t, `SELECT * FROM "T" WHERE "ID" = 1 FOR UPDATE`, DbTypeOracle.StmtSelectForUpdate(`"T"`, `"ID" = 1`))
+	require.Equal(t, "SELECT * FROM [t] WITH (UPDLOCK, ROWLOCK) WHERE [id] = 1", DbTypeMssql.StmtSelectForUpdate("[t]", "[id] = 1"))
+	require.Equal(t, `SELECT * FROM "t" WHERE "id" = 1`, DbTypeSqlite.StmtSelectForUpdate(`"t"`, `"id" = 1`))
+}
@@ -1,6 +1,7 @@
 package dbi
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"strings"
@@ -28,12 +29,13 @@ type DbServer struct {
 
 // 表信息
 type Table struct {
-	TableName    string `json:"tableName"`    // 表名
-	TableComment string `json:"tableComment"` // 表备注
-	CreateTime   string `json:"createTime"`   // 创建时间
-	TableRows    int    `json:"tableRows"`
-	DataLength   int64  `json:"dataLength"`
-	IndexLength  int64  `json:"indexLength"`
+	TableName    string  `json:"tableName"`    // 表名
+	TableComment string  `json:"tableComment"` // 表备注
+	CreateTime   string  `json:"createTime"`   // 创建时间
+	TableRows    int     `json:"tableRows"`
+	DataLength   int64   `json:"dataLength"`
+	IndexLength  int64   `json:"indexLength"`
+	Extra        collx.M `json:"extra"` // 其他额外信息，如clickhouse表引擎、分区键等
 }
 
 // 表的列信息
@@ -49,6 +51,17 @@ type Column struct {
 	Extra         collx.M `json:"extra"`         // 其他额外信息
 }
 
+// 获取所有主键列名（包含联合主键的所有列），表不存在主键则返回空
+func GetPrimaryKeyColumns(columns []Column) []string {
+	pks := make([]string, 0)
+	for _, column := range columns {
+		if column.ColumnKey == "PRI" {
+			pks = append(pks, column.ColumnName)
+		}
+	}
+	return pks
+}
+
 // 表索引信息
 type Index struct {
 	IndexName    string `json:"indexName"`    // 索引名
@@ -83,20 +96,36 @@ type Dialect interface {
 	// 获取建表ddl
 	GetTableDDL(tableName string) (string, error)
 
-	// WalkTableRecord 遍历指定表的数据
-	WalkTableRecord(tableName string, walkFn WalkQueryRowsFunc) error
+	// GenTableDesignSql 根据表结构定义生成sql，表不存在则生成建表语句，否则生成与现有表结构比对后的最小修改语句
+	GenTableDesignSql(td *TableDefinition) ([]string, error)
+
+	// WalkTableRecord 遍历指定表的数据，若ctx绑定了独占连接则在该连接上查询
+	WalkTableRecord(ctx context.Context, tableName string, walkFn WalkQueryRowsFunc) error
+
+	// GetTableData 根据过滤条件、排序分页查询表数据，支持主键键集分页
+	GetTableData(ctx context.Context, query *TableDataQuery) (*TableDataRes, error)
 
 	GetSchemas() ([]string, error)
 
-	// GetDbProgram 获取数据库程序模块，用于数据库备份与恢复
+	// GetDbProgram 获取数据库程序模块，用于数据库备份与恢复，不支持备份与恢复则返回nil
 	GetDbProgram() DbProgram
 
-	// 批量保存数据
-	BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any) (int64, error)
+	// 批量保存数据，columns为已quote的字段名。
+	// duplicateStrategy为数据重复时的处理策略，keyColumns为判断数据重复的字段（已quote），为空则使用表主键（mysql则根据主键及唯一索引判断）
+	BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any, duplicateStrategy DuplicateStrategy, keyColumns ...string) (int64, error)
 
 	GetDataType(dbColumnType string) DataType
 
 	FormatStrData(dbColumnValue string, dataType DataType) string
+
+	// Explain 获取sql的执行计划，并统一转换为树形结构，若ctx绑定了独占连接则在该连接上执行
+	Explain(ctx context.Context, sql string) (*ExplainNode, error)
+}
+
+// TableDDLPreparer 获取建表ddl前需执行写操作（如pgsql创建辅助函数）的方言可实现该接口，
+// 逻辑备份时在只读快照事务开启前调用，之后获取ddl不再执行写操作
+type TableDDLPreparer interface {
+	PrepareTableDDL() error
 }
 
 // ------------------------- 元数据sql操作 -------------------------
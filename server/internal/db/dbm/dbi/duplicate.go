@@ -0,0 +1,120 @@
+package dbi
+
+import (
+	"fmt"
+	"strings"
+
+	"mayfly-go/pkg/utils/collx"
+)
+
+// 批量插入时数据重复（主键或唯一索引冲突）的处理策略
+type DuplicateStrategy int8
+
+const (
+	DuplicateStrategyNone   DuplicateStrategy = -1 // 不处理，数据重复则报错
+	DuplicateStrategyIgnore DuplicateStrategy = 1  // 忽略重复数据
+	DuplicateStrategyUpdate DuplicateStrategy = 2  // 以新数据更新重复数据
+)
+
+// 是否需要处理重复数据
+func (ds DuplicateStrategy) NeedHandle() bool {
+	return ds == DuplicateStrategyIgnore || ds == DuplicateStrategyUpdate
+}
+
+// 获取判断数据重复的字段，未指定则默认使用表主键（含联合主键），无主键则使用唯一索引，均不存在则返回错误
+func GetDuplicateKeyColumns(dialect Dialect, dbType DbType, tableName string, keyColumns []string) ([]string, error) {
+	if len(keyColumns) > 0 {
+		return keyColumns, nil
+	}
+	columns, err := dialect.GetColumns(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("获取表[%s]字段失败: %s", tableName, err.Error())
+	}
+	indexs, err := dialect.GetTableIndex(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("获取表[%s]索引失败: %s", tableName, err.Error())
+	}
+	keys := getDuplicateKeys(columns, indexs)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("表[%s]不存在主键或唯一索引, 无法判断数据是否重复", tableName)
+	}
+	return collx.ArrayMap(keys, dbType.QuoteIdentifier), nil
+}
+
+// 获取可判断数据重复的字段，优先使用主键，其次使用第一个唯一索引
+func getDuplicateKeys(columns []Column, indexs []Index) []string {
+	if pks := (&TableMeta{Columns: columns, Indexs: indexs}).GetPrimaryKeys(); len(pks) > 0 {
+		return pks
+	}
+	for _, index := range indexs {
+		if index.NonUnique == 0 && index.ColumnName != "" {
+			return collx.ArrayMap(strings.Split(index.ColumnName, ","), strings.TrimSpace)
+		}
+	}
+	return nil
+}
+
+// 获取需要更新的字段，即非重复判断字段
+func getUpdateColumns(columns []string, keyColumns []string) []string {
+	return collx.ArrayRemoveFunc(columns, func(column string) bool {
+		return collx.ArrayContains(keyColumns, column)
+	})
+}
+
+// 生成 ON CONFLICT 子句（pgsql、sqlite），如：ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"
+func GenOnConflictSql(duplicateStrategy DuplicateStrategy, columns []string, keyColumns []string) string {
+	switch duplicateStrategy {
+	case DuplicateStrategyIgnore:
+		if len(keyColumns) == 0 {
+			return " ON CONFLICT DO NOTHING"
+		}
+		return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(keyColumns, ", "))
+	case DuplicateStrategyUpdate:
+		updateColumns := getUpdateColumns(columns, keyColumns)
+		if len(updateColumns) == 0 {
+			return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(keyColumns, ", "))
+		}
+		sets := collx.ArrayMap(updateColumns, func(column string) string {
+			return fmt.Sprintf("%s = EXCLUDED.%s", column, column)
+		})
+		return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keyColumns, ", "), strings.Join(sets, ", "))
+	default:
+		return ""
+	}
+}
+
+// 生成单行数据的 MERGE 语句（oracle、达梦），placeholders为各字段值的占位符
+//
+//	MERGE INTO "t" T USING (SELECT ? "id", ? "name" FROM DUAL) S ON (T."id" = S."id")
+//	WHEN MATCHED THEN UPDATE SET T."name" = S."name"
+//	WHEN NOT MATCHED THEN INSERT ("id", "name") VALUES (S."id", S."name")
+func GenMergeSql(duplicateStrategy DuplicateStrategy, quoteTableName string, columns []string, keyColumns []string, placeholders []string) string {
+	return genMergeSql(duplicateStrategy, quoteTableName, columns, keyColumns, placeholders, " FROM DUAL")
+}
+
+// 生成单行数据的 MERGE 语句（sql server），sql server查询无需FROM DUAL，且MERGE语句必须以分号结尾
+func GenMssqlMergeSql(duplicateStrategy DuplicateStrategy, quoteTableName string, columns []string, keyColumns []string, placeholders []string) string {
+	return genMergeSql(duplicateStrategy, quoteTableName, columns, keyColumns, placeholders, "") + ";"
+}
+
+func genMergeSql(duplicateStrategy DuplicateStrategy, quoteTableName string, columns []string, keyColumns []string, placeholders []string, fromDual string) string {
+	selects := make([]string, 0, len(columns))
+	for i, column := range columns {
+		selects = append(selects, fmt.Sprintf("%s %s", placeholders[i], column))
+	}
+	ons := collx.ArrayMap(keyColumns, func(column string) string {
+		return fmt.Sprintf("T.%s = S.%s", column, column)
+	})
+	insertValues := collx.ArrayMap(columns, func(column string) string {
+		return "S." + column
+	})
+
+	sql := fmt.Sprintf("MERGE INTO %s T USING (SELECT %s%s) S ON (%s)", quoteTableName, strings.Join(selects, ", "), fromDual, strings.Join(ons, " AND "))
+	if updateColumns := getUpdateColumns(columns, keyColumns); duplicateStrategy == DuplicateStrategyUpdate && len(updateColumns) > 0 {
+		sets := collx.ArrayMap(updateColumns, func(column string) string {
+			return fmt.Sprintf("T.%s = S.%s", column, column)
+		})
+		sql += " WHEN MATCHED THEN UPDATE SET " + strings.Join(sets, ", ")
+	}
+	return sql + fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)", strings.Join(columns, ", "), strings.Join(insertValues, ", "))
+}
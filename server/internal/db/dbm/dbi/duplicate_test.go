@@ -0,0 +1,40 @@
+package dbi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getDuplicateKeys(t *testing.T) {
+	columns := []Column{{ColumnName: "id"}, {ColumnName: "code"}, {ColumnName: "name"}}
+	uniqueIndex := Index{IndexName: "uk_code_name", ColumnName: "code, name", NonUnique: 0}
+	normalIndex := Index{IndexName: "idx_name", ColumnName: "name", NonUnique: 1}
+
+	require.Equal(t, []string{"tenant_id", "id"}, getDuplicateKeys(columns, []Index{{IndexName: "PRIMARY", ColumnName: "tenant_id,id"}, uniqueIndex}))
+	require.Equal(t, []string{"id"}, getDuplicateKeys([]Column{{ColumnName: "id", ColumnKey: "PRI"}}, []Index{uniqueIndex}))
+	require.Equal(t, []string{"code", "name"}, getDuplicateKeys(columns, []Index{normalIndex, uniqueIndex}))
+	require.Empty(t, getDuplicateKeys(columns, []Index{normalIndex}))
+}
+
+func Test_GenOnConflictSql(t *testing.T) {
+	columns := []string{`"id"`, `"name"`, `"age"`}
+	keyColumns := []string{`"id"`}
+
+	require.Equal(t, "", GenOnConflictSql(DuplicateStrategyNone, columns, keyColumns))
+	require.Equal(t, ` ON CONFLICT ("id") DO NOTHING`, GenOnConflictSql(DuplicateStrategyIgnore, columns, keyColumns))
+	require.Equal(t, ` ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "age" = EXCLUDED."age"`, GenOnConflictSql(DuplicateStrategyUpdate, columns, keyColumns))
+}
+
+func Test_GenMergeSql(t *testing.T) {
+	columns := []string{`"ID"`, `"NAME"`}
+	keyColumns := []string{`"ID"`}
+	placeholders := []string{":1", ":2"}
+
+	require.Equal(t, `MERGE INTO "T_USER" T USING (SELECT :1 "ID", :2 "NAME" FROM DUAL) S ON (T."ID" = S."ID") WHEN MATCHED THEN UPDATE SET T."NAME" = S."NAME" WHEN NOT MATCHED THEN INSERT ("ID", "NAME") VALUES (S."ID", S."NAME")`,
+		GenMergeSql(DuplicateStrategyUpdate, `"T_USER"`, columns, keyColumns, placeholders))
+	require.Equal(t, `MERGE INTO "T_USER" T USING (SELECT :1 "ID", :2 "NAME" FROM DUAL) S ON (T."ID" = S."ID") WHEN NOT MATCHED THEN INSERT ("ID", "NAME") VALUES (S."ID", S."NAME")`,
+		GenMergeSql(DuplicateStrategyIgnore, `"T_USER"`, columns, keyColumns, placeholders))
+	require.Equal(t, `MERGE INTO [t_user] T USING (SELECT @p1 [id], @p2 [name]) S ON (T.[id] = S.[id]) WHEN NOT MATCHED THEN INSERT ([id], [name]) VALUES (S.[id], S.[name]);`,
+		GenMssqlMergeSql(DuplicateStrategyIgnore, "[t_user]", []string{"[id]", "[name]"}, []string{"[id]"}, []string{"@p1", "@p2"}))
+}
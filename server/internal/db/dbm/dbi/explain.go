@@ -0,0 +1,104 @@
+package dbi
+
+import (
+	"mayfly-go/pkg/errorx"
+	"strings"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+)
+
+// ExplainNode 执行计划节点，各数据库的执行计划统一转换为该树形结构
+type ExplainNode struct {
+	NodeType string         `json:"nodeType"` // 节点类型，为各数据库原始的操作名，如 ALL、Seq Scan、TABLE ACCESS FULL
+	Table    string         `json:"table"`    // 访问的表
+	Index    string         `json:"index"`    // 使用的索引
+	Rows     int64          `json:"rows"`     // 预估行数，为0表示未知
+	Cost     float64        `json:"cost"`     // 预估成本，为0表示未知
+	FullScan bool           `json:"fullScan"` // 是否为全表扫描
+	Extra    string         `json:"extra"`    // 其他信息，如过滤条件
+	Children []*ExplainNode `json:"children"`
+}
+
+// Walk 深度优先遍历执行计划的所有节点
+func (n *ExplainNode) Walk(walkFn func(node *ExplainNode)) {
+	if n == nil {
+		return
+	}
+	walkFn(n)
+	for _, child := range n.Children {
+		child.Walk(walkFn)
+	}
+}
+
+// NewExplainRoot 多个顶层节点时使用统一的根节点包装，只有一个则直接返回该节点
+func NewExplainRoot(nodes []*ExplainNode) *ExplainNode {
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	return &ExplainNode{NodeType: "QUERY PLAN", Children: nodes}
+}
+
+// ExplainRow 带父节点id的执行计划节点，用于将按行返回的执行计划（如oracle plan_table）组装为树
+type ExplainRow struct {
+	Id       int64
+	ParentId int64
+	Node     *ExplainNode
+}
+
+// BuildExplainTree 根据父节点id将执行计划行组装为树，父节点不存在的行作为顶层节点
+func BuildExplainTree(rows []ExplainRow) *ExplainNode {
+	nodes := make(map[int64]*ExplainNode, len(rows))
+	for _, row := range rows {
+		nodes[row.Id] = row.Node
+	}
+	roots := make([]*ExplainNode, 0)
+	for _, row := range rows {
+		if parent, ok := nodes[row.ParentId]; ok && row.ParentId != row.Id {
+			parent.Children = append(parent.Children, row.Node)
+		} else {
+			roots = append(roots, row.Node)
+		}
+	}
+	return NewExplainRoot(roots)
+}
+
+// TrimExplainSql 去除待分析sql前后的空白及结尾的分号
+func TrimExplainSql(sql string) string {
+	return strings.TrimRight(strings.TrimSpace(sql), "; \t\r\n")
+}
+
+// 解析失败时允许分析的语句前缀
+var explainSqlPrefixes = []string{"select", "with", "insert", "update", "delete", "replace", "merge"}
+
+// CheckExplainSql 校验待分析的sql只能为单条查询或增删改语句，并返回去除结尾分号后的sql。
+// 部分数据库驱动（如lib/pq）支持一次执行多条语句，拼接至EXPLAIN后会直接执行后续语句，故需拒绝多语句
+func CheckExplainSql(dbType DbType, sql string) (string, error) {
+	sqls, err := dbType.SplitSql(sql)
+	if err != nil {
+		return "", errorx.NewBiz("sql解析失败: %s", err.Error())
+	}
+	if len(sqls) != 1 {
+		return "", errorx.NewBiz("仅支持分析单条sql语句")
+	}
+	sql = TrimExplainSql(sqls[0])
+	if sql == "" {
+		return "", errorx.NewBiz("sql不能为空")
+	}
+
+	if stmt, err := sqlparser.Parse(sql); err == nil && stmt != nil {
+		switch stmt.(type) {
+		case *sqlparser.Select, *sqlparser.Union, *sqlparser.Insert, *sqlparser.Update, *sqlparser.Delete:
+			return sql, nil
+		default:
+			return "", errorx.NewBiz("仅支持分析select、insert、update、delete语句")
+		}
+	}
+	// 数据库特有语法解析失败时，根据语句前缀判断
+	lowerSql := strings.ToLower(sql)
+	for _, prefix := range explainSqlPrefixes {
+		if strings.HasPrefix(lowerSql, prefix) {
+			return sql, nil
+		}
+	}
+	return "", errorx.NewBiz("仅支持分析select、insert、update、delete语句")
+}
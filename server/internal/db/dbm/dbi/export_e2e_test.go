@@ -0,0 +1,13 @@
+//go:build e2e
+
+package dbi
+
+import (
+	"bufio"
+	"context"
+)
+
+// LogicalDump 导出 dump 方法，用于外部测试包的集成测试
+func LogicalDump(ctx context.Context, svc *DbProgramLogical, writer *bufio.Writer, dbName string) error {
+	return svc.dump(ctx, writer, dbName)
+}
@@ -0,0 +1,347 @@
+package dbi
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kanzihuang/vitess/go/vt/sqlparser"
+	"github.com/pkg/errors"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/stringx"
+)
+
+var _ DbProgram = (*DbProgramLogical)(nil)
+
+// DbProgramLogical 内置的逻辑备份与恢复程序，不依赖数据库客户端可执行文件。
+// 通过 GetTableDDL 与 WalkTableRecord 在只读快照事务中导出表结构及数据，并以 gzip 压缩的 sql 文件保存，不支持按时间点恢复
+type DbProgramLogical struct {
+	dbConn *DbConn
+}
+
+func NewDbProgramLogical(dbConn *DbConn) *DbProgramLogical {
+	return &DbProgramLogical{
+		dbConn: dbConn,
+	}
+}
+
+// IsBinAvailable 判断可执行文件是否可用，不可用时可使用逻辑备份程序代替
+func IsBinAvailable(binPath string) bool {
+	_, err := exec.LookPath(binPath)
+	return err == nil
+}
+
+func (svc *DbProgramLogical) getBackupFile(instanceId, backupId uint64, uuid string) string {
+	return filepath.Join(GetDbBackupDir(instanceId, backupId), fmt.Sprintf("%s.sql.gz", uuid))
+}
+
+// IsLogicalBackup 判断备份历史是否为逻辑备份，备份文件可能已上传至其他存储，优先根据备份文件名判断
+func (svc *DbProgramLogical) IsLogicalBackup(backupHistory *entity.DbBackupHistory) bool {
+	if backupHistory.FileName != "" {
+		return strings.HasSuffix(backupHistory.FileName, ".sql.gz")
+	}
+	_, err := os.Stat(svc.getBackupFile(backupHistory.DbInstanceId, backupHistory.DbBackupId, backupHistory.Uuid))
+	return err == nil
+}
+
+func (svc *DbProgramLogical) Backup(ctx context.Context, backupHistory *entity.DbBackupHistory) (*entity.BinlogInfo, error) {
+	fileName := svc.getBackupFile(backupHistory.DbInstanceId, backupHistory.DbBackupId, backupHistory.Uuid)
+	if err := os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+		return nil, err
+	}
+	tmpFile := filepath.Join(filepath.Dir(fileName), "backup.logical.tmp")
+	defer func() {
+		_ = os.Remove(tmpFile)
+	}()
+
+	file, err := os.Create(tmpFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "创建备份文件失败")
+	}
+	progress := GetProgress(ctx)
+	progress.SetStage(ProgressStageBackup)
+	gzipWriter := gzip.NewWriter(progress.Writer(file))
+	writer := bufio.NewWriter(gzipWriter)
+	err = svc.dump(ctx, writer, backupHistory.DbName)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = gzipWriter.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logx.Errorf("逻辑备份数据库失败: %v", err)
+		return nil, errors.Wrap(err, "逻辑备份数据库失败")
+	}
+
+	if err := os.Rename(tmpFile, fileName); err != nil {
+		return nil, errors.Wrap(err, "备份文件改名失败")
+	}
+	return &entity.BinlogInfo{}, nil
+}
+
+// 在只读快照事务中导出数据库所有表的结构及数据
+func (svc *DbProgramLogical) dump(ctx context.Context, writer *bufio.Writer, dbName string) error {
+	dbType := svc.dbConn.Info.Type
+	ctx, _, release, err := svc.dbConn.BindConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// 表信息及表结构同样需在快照事务所在的连接上获取，保证与导出的数据一致
+	dialect := svc.dbConn.WithBindConn(ctx).GetDialect()
+	// 获取ddl需执行写操作（如pgsql创建辅助函数）的，需在只读事务开启前完成
+	if preparer, ok := dialect.(TableDDLPreparer); ok {
+		if err := preparer.PrepareTableDDL(); err != nil {
+			return errors.Wrap(err, "准备获取表结构失败")
+		}
+	}
+	if stmt := dbType.StmtStartSnapshot(); stmt != "" {
+		if _, err := svc.dbConn.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrap(err, "开启快照事务失败")
+		}
+		defer func() {
+			_, _ = svc.dbConn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+		}()
+	}
+
+	tables, err := dialect.GetTables()
+	if err != nil {
+		return errors.Wrap(err, "获取表信息失败")
+	}
+	progress := GetProgress(ctx)
+	progress.SetTableTotal(len(tables))
+
+	writer.WriteString("-- ----------------------------\n")
+	writer.WriteString("-- 备份平台: mayfly-go\n")
+	writer.WriteString(fmt.Sprintf("-- 备份时间: %s\n", time.Now().Format(time.DateTime)))
+	writer.WriteString(fmt.Sprintf("-- 备份数据库: %s\n", dbName))
+	writer.WriteString(fmt.Sprintf("-- 数据库类型: %s\n", dbType))
+	writer.WriteString("-- ----------------------------\n\n")
+	writer.WriteString(dbType.StmtSetForeignKeyChecks(false))
+
+	for _, table := range tables {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		progress.StartTable(table.TableName)
+		quotedTable := dbType.QuoteIdentifier(table.TableName)
+		ddl, err := dialect.GetTableDDL(table.TableName)
+		if err != nil {
+			return errors.Wrapf(err, "获取表[%s]ddl失败", table.TableName)
+		}
+		writer.WriteString(fmt.Sprintf("\n-- 表结构: %s\n", table.TableName))
+		writer.WriteString(dbType.StmtDropTableIfExists(quotedTable) + ";\n")
+		writer.WriteString(strings.TrimSuffix(strings.TrimSpace(ddl), ";") + ";\n")
+
+		writer.WriteString(fmt.Sprintf("\n-- 表记录: %s\n", table.TableName))
+		var insertPrefix string
+		err = dialect.WalkTableRecord(ctx, table.TableName, func(row map[string]any, columns []*QueryColumn) error {
+			if insertPrefix == "" {
+				quotedColumns := make([]string, 0, len(columns))
+				for _, column := range columns {
+					quotedColumns = append(quotedColumns, dbType.QuoteIdentifier(column.Name))
+				}
+				insertPrefix = fmt.Sprintf("INSERT INTO %s (%s) VALUES (", quotedTable, strings.Join(quotedColumns, ", "))
+			}
+			values := make([]string, 0, len(columns))
+			for _, column := range columns {
+				values = append(values, formatLiteral(dbType, column.Type, row[column.Name]))
+			}
+			_, err := writer.WriteString(insertPrefix + strings.Join(values, ", ") + ");\n")
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "导出表[%s]数据失败", table.TableName)
+		}
+	}
+	progress.FinishTables()
+	writer.WriteString(dbType.StmtSetForeignKeyChecks(true))
+	return nil
+}
+
+// RestoreBackupHistory 依次执行逻辑备份文件中的sql语句恢复数据库。
+// 执行前先完整读取并解析备份文件，避免备份文件损坏时在删除已有表后才失败，备份文件通过 openFile 流式读取两次；
+// pgsql、sql server、sqlite在事务中恢复，失败时回滚，mysql、oracle、达梦执行ddl会隐式提交事务，恢复失败时已执行的语句无法回滚
+func (svc *DbProgramLogical) RestoreBackupHistory(ctx context.Context, dbName string, backupHistory *entity.DbBackupHistory, openFile BackupFileOpener) error {
+	dbType := svc.dbConn.Info.Type
+	if err := walkBackupSql(ctx, dbType, openFile, nil, func(sql string) error { return nil }); err != nil {
+		return err
+	}
+
+	// 使用独占连接执行，保证会话级设置（如关闭外键检查）及事务对后续语句生效
+	ctx, _, release, err := svc.dbConn.BindConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	inTx := false
+	if stmt := dbType.StmtBeginTransaction(); stmt != "" {
+		if _, err := svc.dbConn.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrap(err, "开启事务失败")
+		}
+		inTx = true
+	}
+	if dbType == DbTypePostgres {
+		if err := svc.disablePgsqlTriggers(ctx); err != nil {
+			if inTx {
+				_, _ = svc.dbConn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+			}
+			return err
+		}
+	}
+
+	progress := GetProgress(ctx)
+	progress.SetStage(ProgressStageRestore)
+	err = walkBackupSql(ctx, dbType, openFile, progress.Reader, func(sql string) error {
+		if fields := strings.Fields(sql); len(fields) > 2 && strings.EqualFold(fields[0], "CREATE") && strings.EqualFold(fields[1], "TABLE") {
+			tableName, _, _ := strings.Cut(fields[2], "(")
+			progress.StartTable(dbType.RemoveQuote(tableName))
+		}
+		if _, err := svc.dbConn.ExecContext(ctx, sql); err != nil {
+			// oracle 不支持 DROP TABLE IF EXISTS，表不存在时忽略删除失败
+			if dbType == DbTypeOracle && strings.HasPrefix(strings.ToUpper(sql), "DROP TABLE") {
+				logx.Warnf("恢复数据库[%s]时删除表失败: %s", dbName, err.Error())
+				return nil
+			}
+			return errors.Wrapf(err, "执行sql失败: %s", stringx.TruncateStr(sql, 200))
+		}
+		return nil
+	})
+	if inTx {
+		if err != nil {
+			_, _ = svc.dbConn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+			return err
+		}
+		if _, err := svc.dbConn.ExecContext(ctx, "COMMIT"); err != nil {
+			return errors.Wrap(err, "提交事务失败")
+		}
+	}
+	if err != nil {
+		return err
+	}
+	progress.FinishTables()
+	return nil
+}
+
+// pgsql 外键通过系统触发器实现，超级用户可在当前事务内切换为 replica 模式以跳过外键检查，事务结束后自动恢复；
+// 非超级用户无权修改该参数，按备份文件中的表顺序恢复
+func (svc *DbProgramLogical) disablePgsqlTriggers(ctx context.Context) error {
+	_, res, err := svc.dbConn.QueryContext(ctx, "SELECT CASE WHEN rolsuper THEN 1 ELSE 0 END AS is_super FROM pg_roles WHERE rolname = current_user")
+	if err != nil {
+		return errors.Wrap(err, "查询当前用户是否为超级用户失败")
+	}
+	if len(res) == 0 || anyx.ConvInt(res[0]["is_super"]) != 1 {
+		return nil
+	}
+	if _, err := svc.dbConn.ExecContext(ctx, "SET LOCAL session_replication_role = replica"); err != nil {
+		return errors.Wrap(err, "关闭外键检查失败")
+	}
+	return nil
+}
+
+// 依次读取逻辑备份文件中的sql语句，wrapReader 不为空则使用其包装文件读取（如统计读取进度）
+func walkBackupSql(ctx context.Context, dbType DbType, openFile BackupFileOpener, wrapReader func(io.Reader) io.Reader, fn func(sql string) error) error {
+	file, err := openFile()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	var reader io.Reader = file
+	if wrapReader != nil {
+		reader = wrapReader(file)
+	}
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return errors.Wrap(err, "读取备份文件失败")
+	}
+	defer func() {
+		_ = gzipReader.Close()
+	}()
+
+	tokenizer := sqlparser.NewReaderTokenizer(gzipReader, sqlparser.WithCacheInBuffer(), sqlparser.WithDialect(dbType.Dialect()))
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		sql, err := sqlparser.SplitNext(tokenizer)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "解析备份文件失败")
+		}
+		if strings.TrimSpace(sql) == "" {
+			continue
+		}
+		if err := fn(sql); err != nil {
+			return err
+		}
+	}
+}
+
+func (svc *DbProgramLogical) FetchBinlogs(ctx context.Context, downloadLatestBinlogFile bool, earliestBackupSequence, latestBinlogSequence int64) ([]*entity.BinlogFile, error) {
+	return nil, nil
+}
+
+func (svc *DbProgramLogical) ReplayBinlog(ctx context.Context, originalDatabase, targetDatabase string, restoreInfo *RestoreInfo) error {
+	return errors.New("逻辑备份不支持按时间点恢复")
+}
+
+func (svc *DbProgramLogical) GetBinlogEventPositionAtOrAfterTime(ctx context.Context, binlogName string, targetTime time.Time) (position int64, parseErr error) {
+	return 0, errors.New("逻辑备份不支持按时间点恢复")
+}
+
+// 二进制列类型，查询结果中二进制列的值与字符串一样以string返回，需根据列类型区分
+var binaryColumnTypeRegexp = regexp.MustCompile(`(?i)blob|binary|bytea|image|^(long )?raw$`)
+
+// 将字段值转为sql字面量，columnType 为查询结果的列类型
+func formatLiteral(dbType DbType, columnType string, value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return formatBinaryLiteral(dbType, v)
+	case string:
+		if binaryColumnTypeRegexp.MatchString(columnType) {
+			return formatBinaryLiteral(dbType, []byte(v))
+		}
+		return dbType.QuoteLiteral(v)
+	default:
+		return anyx.ToString(v)
+	}
+}
+
+// 将二进制值转为对应数据库的十六进制字面量
+func formatBinaryLiteral(dbType DbType, value []byte) string {
+	hexValue := hex.EncodeToString(value)
+	switch dbType {
+	case DbTypePostgres:
+		return fmt.Sprintf("decode('%s', 'hex')", hexValue)
+	case DbTypeOracle, DbTypeDM:
+		return fmt.Sprintf("HEXTORAW('%s')", hexValue)
+	case DbTypeMssql:
+		return "0x" + hexValue
+	case DbTypeClickhouse:
+		return fmt.Sprintf("unhex('%s')", hexValue)
+	default:
+		return fmt.Sprintf("X'%s'", hexValue)
+	}
+}
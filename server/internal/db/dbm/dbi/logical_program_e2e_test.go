@@ -0,0 +1,60 @@
+//go:build e2e
+
+package dbi_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/dbm/postgres"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const dbNameLogicalDumpTest = "mayfly_logical_dump_test"
+
+func newPgsqlTestConn(t *testing.T, database string) *dbi.DbConn {
+	dbInfo := &dbi.DbInfo{
+		Type:     dbi.DbTypePostgres,
+		Host:     "localhost",
+		Port:     5432,
+		Username: "test",
+		Password: "test",
+		Database: database,
+	}
+	dbConn, err := dbInfo.Conn(postgres.GetMeta())
+	require.NoError(t, err)
+	return dbConn
+}
+
+// 在只读快照事务中导出pgsql数据库，获取建表ddl所需的函数需在事务开启前创建
+func TestDbProgramLogical_DumpPgsql(t *testing.T) {
+	adminConn := newPgsqlTestConn(t, "postgres")
+	defer adminConn.Close()
+	dropDb := fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbNameLogicalDumpTest)
+	_, err := adminConn.Exec(dropDb)
+	require.NoError(t, err)
+	_, err = adminConn.Exec(fmt.Sprintf("CREATE DATABASE %s", dbNameLogicalDumpTest))
+	require.NoError(t, err)
+	defer adminConn.Exec(dropDb)
+
+	dbConn := newPgsqlTestConn(t, dbNameLogicalDumpTest)
+	_, err = dbConn.Exec("CREATE TABLE t_dump (id INT PRIMARY KEY, name VARCHAR(32), data BYTEA)")
+	require.NoError(t, err)
+	_, err = dbConn.Exec("INSERT INTO t_dump VALUES (1, 'it''s', '\\x0027'), (2, NULL, NULL)")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	require.NoError(t, dbi.LogicalDump(context.Background(), dbi.NewDbProgramLogical(dbConn), writer, dbNameLogicalDumpTest))
+	require.NoError(t, writer.Flush())
+	dbConn.Close()
+
+	out := buf.String()
+	require.Contains(t, out, "CREATE TABLE")
+	require.Contains(t, out, `INSERT INTO "t_dump" ("id", "name", "data") VALUES (1, 'it''s', decode('0027', 'hex'));`)
+	require.Contains(t, out, `INSERT INTO "t_dump" ("id", "name", "data") VALUES (2, NULL, NULL);`)
+}
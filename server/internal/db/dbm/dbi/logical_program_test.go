@@ -0,0 +1,63 @@
+package dbi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_formatLiteral(t *testing.T) {
+	tests := []struct {
+		dbType     DbType
+		columnType string
+		value      any
+		want       string
+	}{
+		{DbTypeMysql, "INT", nil, "NULL"},
+		{DbTypeMysql, "INT", 12, "12"},
+		{DbTypeMysql, "DOUBLE", 1.5, "1.5"},
+		{DbTypeMysql, "VARCHAR", "it's", `'it''s'`},
+		{DbTypeOracle, "VARCHAR2", "it's", `'it''s'`},
+		{DbTypeMysql, "BLOB", "\x00'", `X'0027'`},
+		{DbTypeMysql, "VARBINARY", []byte{0, 0xff}, `X'00ff'`},
+		{DbTypePostgres, "BYTEA", "\x00'", `decode('0027', 'hex')`},
+		{DbTypeOracle, "RAW", "ab", `HEXTORAW('6162')`},
+		{DbTypeDM, "BLOB", "ab", `HEXTORAW('6162')`},
+		{DbTypeMssql, "IMAGE", "ab", `0x6162`},
+		{DbTypeSqlite, "BLOB", "", `X''`},
+		{DbTypeOracle, "VARCHAR2", "raw", `'raw'`},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, formatLiteral(tt.dbType, tt.columnType, tt.value), tt.columnType)
+	}
+}
+
+func TestDbType_StmtSetForeignKeyChecks(t *testing.T) {
+	require.Equal(t, "SET FOREIGN_KEY_CHECKS = 0;\n", DbTypeMysql.StmtSetForeignKeyChecks(false))
+	require.Equal(t, "", DbTypePostgres.StmtSetForeignKeyChecks(false))
+	require.Equal(t, "", DbTypeOracle.StmtSetForeignKeyChecks(false))
+}
+
+func TestDbType_StmtBeginTransaction(t *testing.T) {
+	require.Equal(t, "BEGIN", DbTypePostgres.StmtBeginTransaction())
+	require.Equal(t, "BEGIN TRANSACTION", DbTypeMssql.StmtBeginTransaction())
+	require.Equal(t, "", DbTypeMysql.StmtBeginTransaction())
+	require.Equal(t, "", DbTypeOracle.StmtBeginTransaction())
+}
+
+func TestDbConn_WithBindConn(t *testing.T) {
+	dbConn := &DbConn{Id: "1"}
+	require.Same(t, dbConn, dbConn.WithBindConn(context.Background()))
+
+	conn := &sql.Conn{}
+	bound := dbConn.WithBindConn(context.WithValue(context.Background(), connCtxKey{}, conn))
+	require.NotSame(t, dbConn, bound)
+	require.Same(t, conn, bound.getExecutor(context.Background()))
+}
+
+func TestDbType_StmtDropTableIfExists(t *testing.T) {
+	require.Equal(t, `DROP TABLE IF EXISTS "t"`, DbTypePostgres.StmtDropTableIfExists(`"t"`))
+	require.Equal(t, `DROP TABLE "t"`, DbTypeOracle.StmtDropTableIfExists(`"t"`))
+}
@@ -0,0 +1,75 @@
+--CLICKHOUSE_DBS 数据库名信息
+SELECT
+  name AS dbname
+FROM
+  system.databases
+WHERE
+  name NOT IN ('system', 'information_schema', 'INFORMATION_SCHEMA')
+ORDER BY name
+---------------------------------------
+--CLICKHOUSE_TABLE_INFO 表详细信息
+SELECT
+  name AS tableName,
+  comment AS tableComment,
+  toString(metadata_modification_time) AS createTime,
+  ifNull(total_rows, 0) AS tableRows,
+  ifNull(total_bytes, 0) AS dataLength,
+  0 AS indexLength,
+  engine,
+  partition_key AS partitionKey,
+  sorting_key AS sortingKey
+FROM
+  system.tables
+WHERE
+  database = currentDatabase()
+  AND is_temporary = 0
+  AND engine != 'View'
+ORDER BY name
+---------------------------------------
+--CLICKHOUSE_INDEX_INFO 索引信息（主键及跳数索引）
+SELECT
+  'PRIMARY' AS indexName,
+  primary_key AS columnName,
+  'PRIMARY KEY' AS indexType,
+  1 AS nonUnique,
+  1 AS seqInIndex,
+  '' AS indexComment
+FROM
+  system.tables
+WHERE
+  database = currentDatabase()
+  AND name = ?
+  AND primary_key != ''
+UNION ALL
+SELECT
+  name AS indexName,
+  expr AS columnName,
+  type AS indexType,
+  1 AS nonUnique,
+  1 AS seqInIndex,
+  concat('GRANULARITY ', toString(granularity)) AS indexComment
+FROM
+  system.data_skipping_indices
+WHERE
+  database = currentDatabase()
+  AND table = ?
+---------------------------------------
+--CLICKHOUSE_COLUMN_MA 列信息元数据
+SELECT
+  table AS tableName,
+  name AS columnName,
+  type AS columnType,
+  if(default_kind = 'DEFAULT', default_expression, '') AS columnDefault,
+  comment AS columnComment,
+  if(is_in_primary_key, 'PRI', '') AS columnKey,
+  if(startsWith(type, 'Nullable('), 'YES', 'NO') AS nullable,
+  numeric_scale AS numScale,
+  default_kind AS defaultKind
+FROM
+  system.columns
+WHERE
+  database = currentDatabase()
+  AND table IN (%s)
+ORDER BY
+  table,
+  position
@@ -0,0 +1,121 @@
+--MSSQL_DB_SCHEMAS 库schemas
+SELECT
+    s.name AS schemaName
+FROM
+    sys.schemas s
+WHERE
+    HAS_PERMS_BY_NAME(s.name, 'SCHEMA', 'SELECT') = 1
+    AND s.name NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest')
+    AND s.name NOT LIKE 'db[_]%'
+ORDER BY
+    s.name
+---------------------------------------
+--MSSQL_TABLE_INFO 表详细信息
+SELECT
+    t.name AS tableName,
+    CAST(ep.value AS NVARCHAR(4000)) AS tableComment,
+    CONVERT(VARCHAR(19), t.create_date, 120) AS createTime,
+    ISNULL(r.rowCount, 0) AS tableRows,
+    ISNULL(s.dataLength, 0) AS dataLength,
+    ISNULL(s.indexLength, 0) AS indexLength
+FROM
+    sys.tables t
+LEFT JOIN sys.extended_properties ep ON
+    ep.major_id = t.object_id
+    AND ep.minor_id = 0
+    AND ep.class = 1
+    AND ep.name = 'MS_Description'
+LEFT JOIN (
+    SELECT object_id, CAST(SUM(rows) AS BIGINT) AS rowCount
+    FROM sys.partitions
+    WHERE index_id IN (0, 1)
+    GROUP BY object_id
+) r ON r.object_id = t.object_id
+LEFT JOIN (
+    SELECT
+        p.object_id,
+        CAST(SUM(CASE WHEN p.index_id IN (0, 1) THEN au.used_pages ELSE 0 END) AS BIGINT) * 8192 AS dataLength,
+        CAST(SUM(CASE WHEN p.index_id > 1 THEN au.used_pages ELSE 0 END) AS BIGINT) * 8192 AS indexLength
+    FROM sys.partitions p
+    JOIN sys.allocation_units au ON au.container_id = p.partition_id
+    GROUP BY p.object_id
+) s ON s.object_id = t.object_id
+WHERE
+    t.schema_id = SCHEMA_ID()
+    AND t.is_ms_shipped = 0
+ORDER BY
+    t.name
+---------------------------------------
+--MSSQL_INDEX_INFO 表索引信息
+SELECT
+    i.name AS indexName,
+    c.name AS columnName,
+    i.type_desc AS indexType,
+    CASE WHEN i.is_unique = 1 THEN 0 ELSE 1 END AS nonUnique,
+    i.is_primary_key AS isPrimaryKey,
+    ic.key_ordinal AS seqInIndex,
+    '' AS indexComment
+FROM
+    sys.indexes i
+JOIN sys.index_columns ic ON
+    ic.object_id = i.object_id
+    AND ic.index_id = i.index_id
+JOIN sys.columns c ON
+    c.object_id = ic.object_id
+    AND c.column_id = ic.column_id
+WHERE
+    i.object_id = OBJECT_ID(QUOTENAME(SCHEMA_NAME()) + '.' + QUOTENAME('%s'))
+    AND i.name IS NOT NULL
+    AND ic.is_included_column = 0
+ORDER BY
+    i.name,
+    ic.key_ordinal
+---------------------------------------
+--MSSQL_COLUMN_MA 表列信息
+SELECT
+    t.name AS tableName,
+    c.name AS columnName,
+    CASE
+        WHEN ty.name IN ('varchar', 'char', 'varbinary', 'binary')
+            THEN ty.name + '(' + CASE WHEN c.max_length = -1 THEN 'max' ELSE CAST(c.max_length AS VARCHAR(10)) END + ')'
+        WHEN ty.name IN ('nvarchar', 'nchar')
+            THEN ty.name + '(' + CASE WHEN c.max_length = -1 THEN 'max' ELSE CAST(c.max_length / 2 AS VARCHAR(10)) END + ')'
+        WHEN ty.name IN ('decimal', 'numeric')
+            THEN ty.name + '(' + CAST(c.precision AS VARCHAR(10)) + ',' + CAST(c.scale AS VARCHAR(10)) + ')'
+        WHEN ty.name IN ('datetime2', 'time', 'datetimeoffset')
+            THEN ty.name + '(' + CAST(c.scale AS VARCHAR(10)) + ')'
+        ELSE ty.name
+    END AS columnType,
+    CAST(ep.value AS NVARCHAR(4000)) AS columnComment,
+    CASE WHEN pk.column_id IS NOT NULL THEN 'PRI' ELSE '' END AS columnKey,
+    OBJECT_DEFINITION(c.default_object_id) AS columnDefault,
+    CASE WHEN c.is_nullable = 1 THEN 'YES' ELSE 'NO' END AS nullable,
+    c.scale AS numScale,
+    CASE
+        WHEN c.is_identity = 1
+            THEN CONCAT('IDENTITY(', IDENT_SEED(QUOTENAME(SCHEMA_NAME(t.schema_id)) + '.' + QUOTENAME(t.name)), ',', IDENT_INCR(QUOTENAME(SCHEMA_NAME(t.schema_id)) + '.' + QUOTENAME(t.name)), ')')
+        ELSE ''
+    END AS identityDef
+FROM
+    sys.columns c
+JOIN sys.tables t ON
+    t.object_id = c.object_id
+JOIN sys.types ty ON
+    ty.user_type_id = c.user_type_id
+LEFT JOIN sys.extended_properties ep ON
+    ep.major_id = c.object_id
+    AND ep.minor_id = c.column_id
+    AND ep.class = 1
+    AND ep.name = 'MS_Description'
+LEFT JOIN (
+    SELECT ic.object_id, ic.column_id
+    FROM sys.indexes i
+    JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+    WHERE i.is_primary_key = 1
+) pk ON pk.object_id = c.object_id AND pk.column_id = c.column_id
+WHERE
+    t.schema_id = SCHEMA_ID()
+    AND t.name IN (%s)
+ORDER BY
+    t.name,
+    c.column_id
@@ -0,0 +1,172 @@
+package dbi
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+const (
+	ProgressStageBackup   = "备份"
+	ProgressStageUpload   = "上传备份文件"
+	ProgressStageDownload = "读取备份文件"
+	ProgressStageRestore  = "恢复"
+	ProgressStageBinlog   = "回放binlog"
+)
+
+// DbProgramProgress 数据库备份与恢复进度，由数据库程序在执行过程中更新，所有方法均可在 nil 上调用
+type DbProgramProgress struct {
+	mutex sync.Mutex
+
+	Stage           string `json:"stage"`           // 当前阶段
+	Bytes           int64  `json:"bytes"`           // 当前阶段已写入或读取的字节数
+	TableName       string `json:"tableName"`       // 当前处理的表
+	Tables          int    `json:"tables"`          // 已处理的表数
+	TableTotal      int    `json:"tableTotal"`      // 表总数，为0表示未知
+	BinlogFiles     int    `json:"binlogFiles"`     // 已回放的binlog文件数
+	BinlogFileTotal int    `json:"binlogFileTotal"` // 需回放的binlog文件总数
+}
+
+type progressCtxKey struct{}
+
+// WithProgress 将进度绑定至context，数据库程序通过 GetProgress 获取并更新进度
+func WithProgress(ctx context.Context, progress *DbProgramProgress) context.Context {
+	return context.WithValue(ctx, progressCtxKey{}, progress)
+}
+
+// GetProgress 获取context绑定的进度，未绑定则返回nil
+func GetProgress(ctx context.Context) *DbProgramProgress {
+	progress, _ := ctx.Value(progressCtxKey{}).(*DbProgramProgress)
+	return progress
+}
+
+// SetStage 进入新的阶段，并重置已读写的字节数
+func (p *DbProgramProgress) SetStage(stage string) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.Stage = stage
+	p.Bytes = 0
+}
+
+func (p *DbProgramProgress) AddBytes(n int64) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.Bytes += n
+}
+
+func (p *DbProgramProgress) SetTableTotal(total int) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.TableTotal = total
+	p.Tables = 0
+}
+
+// StartTable 开始处理表，上一张表视为已处理完成
+func (p *DbProgramProgress) StartTable(tableName string) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.TableName != "" {
+		p.Tables++
+	}
+	p.TableName = tableName
+}
+
+// FinishTables 所有表处理完成
+func (p *DbProgramProgress) FinishTables() {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.TableName != "" {
+		p.Tables++
+		p.TableName = ""
+	}
+}
+
+func (p *DbProgramProgress) SetBinlogFileTotal(total int) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.BinlogFileTotal = total
+	p.BinlogFiles = 0
+}
+
+func (p *DbProgramProgress) SetBinlogFiles(n int) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.BinlogFiles = min(n, p.BinlogFileTotal)
+}
+
+// Snapshot 获取当前进度的副本
+func (p *DbProgramProgress) Snapshot() *DbProgramProgress {
+	if p == nil {
+		return nil
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return &DbProgramProgress{
+		Stage:           p.Stage,
+		Bytes:           p.Bytes,
+		TableName:       p.TableName,
+		Tables:          p.Tables,
+		TableTotal:      p.TableTotal,
+		BinlogFiles:     p.BinlogFiles,
+		BinlogFileTotal: p.BinlogFileTotal,
+	}
+}
+
+// Writer 包装 writer，统计写入的字节数
+func (p *DbProgramProgress) Writer(writer io.Writer) io.Writer {
+	if p == nil {
+		return writer
+	}
+	return &progressWriter{writer: writer, progress: p}
+}
+
+// Reader 包装 reader，统计读取的字节数
+func (p *DbProgramProgress) Reader(reader io.Reader) io.Reader {
+	if p == nil {
+		return reader
+	}
+	return &progressReader{reader: reader, progress: p}
+}
+
+type progressWriter struct {
+	writer   io.Writer
+	progress *DbProgramProgress
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	n, err := w.writer.Write(b)
+	w.progress.AddBytes(int64(n))
+	return n, err
+}
+
+type progressReader struct {
+	reader   io.Reader
+	progress *DbProgramProgress
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.reader.Read(b)
+	r.progress.AddBytes(int64(n))
+	return n, err
+}
@@ -0,0 +1,529 @@
+package dbi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+)
+
+// 表结构元信息，用于表结构比对
+type TableMeta struct {
+	Table   Table    `json:"table"`
+	Columns []Column `json:"columns"`
+	Indexs  []Index  `json:"indexs"`
+	Ddl     string   `json:"ddl"` // 源库建表语句，仅源库与目标库类型一致时存在
+}
+
+// 字段差异
+type ColumnDiff struct {
+	Source Column `json:"source"` // 源库字段信息
+	Target Column `json:"target"` // 目标库字段信息
+}
+
+// 单表结构差异
+type TableDiff struct {
+	TableName     string       `json:"tableName"`
+	AddColumns    []Column     `json:"addColumns"`    // 目标表需新增的字段
+	DropColumns   []Column     `json:"dropColumns"`   // 目标表需删除的字段
+	ModifyColumns []ColumnDiff `json:"modifyColumns"` // 目标表需修改的字段
+	AddIndexs     []Index      `json:"addIndexs"`     // 目标表需新增的索引
+	DropIndexs    []Index      `json:"dropIndexs"`    // 目标表需删除的索引
+}
+
+func (td *TableDiff) IsEmpty() bool {
+	return len(td.AddColumns) == 0 && len(td.DropColumns) == 0 && len(td.ModifyColumns) == 0 && len(td.AddIndexs) == 0 && len(td.DropIndexs) == 0
+}
+
+// 两个库的表结构差异，以源库为准同步至目标库
+type SchemaDiff struct {
+	AddTables   []*TableMeta `json:"addTables"`   // 目标库需新增的表
+	DropTables  []string     `json:"dropTables"`  // 目标库多余的表
+	AlterTables []*TableDiff `json:"alterTables"` // 表结构不一致的表
+}
+
+func (sd *SchemaDiff) IsEmpty() bool {
+	return len(sd.AddTables) == 0 && len(sd.DropTables) == 0 && len(sd.AlterTables) == 0
+}
+
+// 比对源库与目标库的表结构，tableNames为空则比对所有表
+func CompareSchema(src, target *DbConn, tableNames ...string) (*SchemaDiff, error) {
+	srcMetas, err := GetTableMetas(src, tableNames...)
+	if err != nil {
+		return nil, fmt.Errorf("获取源库表结构失败: %s", err.Error())
+	}
+	targetMetas, err := GetTableMetas(target, tableNames...)
+	if err != nil {
+		return nil, fmt.Errorf("获取目标库表结构失败: %s", err.Error())
+	}
+
+	diff := DiffTableMetas(srcMetas, targetMetas)
+	// 类型一致则直接使用源库的建表语句
+	if src.Info.Type == target.Info.Type {
+		for _, tm := range diff.AddTables {
+			ddl, err := src.GetDialect().GetTableDDL(tm.Table.TableName)
+			if err != nil {
+				return nil, fmt.Errorf("获取源表[%s]建表语句失败: %s", tm.Table.TableName, err.Error())
+			}
+			tm.Ddl = ddl
+		}
+	}
+	return diff, nil
+}
+
+// GetTableMetas 获取库中指定表的表结构信息，tableNames为空则获取所有表
+func GetTableMetas(dbConn *DbConn, tableNames ...string) ([]*TableMeta, error) {
+	dialect := dbConn.GetDialect()
+	tables, err := dialect.GetTables()
+	if err != nil {
+		return nil, err
+	}
+	if len(tableNames) > 0 {
+		tables = collx.ArrayRemoveFunc(tables, func(t Table) bool {
+			return !collx.ArrayContains(tableNames, t.TableName)
+		})
+	}
+	if len(tables) == 0 {
+		return []*TableMeta{}, nil
+	}
+
+	tableMetas := make([]*TableMeta, 0, len(tables))
+	tableMetaMap := make(map[string]*TableMeta, len(tables))
+	names := make([]string, 0, len(tables))
+	for _, t := range tables {
+		tm := &TableMeta{Table: t}
+		tableMetas = append(tableMetas, tm)
+		tableMetaMap[t.TableName] = tm
+		names = append(names, t.TableName)
+	}
+
+	columns, err := dialect.GetColumns(names...)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range columns {
+		if tm := tableMetaMap[column.TableName]; tm != nil {
+			tm.Columns = append(tm.Columns, column)
+		}
+	}
+
+	for _, tm := range tableMetas {
+		indexs, err := dialect.GetTableIndex(tm.Table.TableName)
+		if err != nil {
+			return nil, err
+		}
+		tm.Indexs = indexs
+	}
+	return tableMetas, nil
+}
+
+// 比对表结构信息，返回目标表结构同步为源表结构所需的差异
+func DiffTableMetas(srcMetas, targetMetas []*TableMeta) *SchemaDiff {
+	diff := &SchemaDiff{
+		AddTables:   make([]*TableMeta, 0),
+		DropTables:  make([]string, 0),
+		AlterTables: make([]*TableDiff, 0),
+	}
+
+	targetMap := collx.ArrayToMap(targetMetas, func(tm *TableMeta) string {
+		return strings.ToLower(tm.Table.TableName)
+	})
+	srcMap := collx.ArrayToMap(srcMetas, func(tm *TableMeta) string {
+		return strings.ToLower(tm.Table.TableName)
+	})
+
+	for _, srcMeta := range srcMetas {
+		targetMeta := targetMap[strings.ToLower(srcMeta.Table.TableName)]
+		if targetMeta == nil {
+			diff.AddTables = append(diff.AddTables, srcMeta)
+			continue
+		}
+		if td := diffTable(srcMeta, targetMeta); !td.IsEmpty() {
+			diff.AlterTables = append(diff.AlterTables, td)
+		}
+	}
+
+	for _, targetMeta := range targetMetas {
+		if srcMap[strings.ToLower(targetMeta.Table.TableName)] == nil {
+			diff.DropTables = append(diff.DropTables, targetMeta.Table.TableName)
+		}
+	}
+	return diff
+}
+
+func diffTable(srcMeta, targetMeta *TableMeta) *TableDiff {
+	td := &TableDiff{TableName: targetMeta.Table.TableName}
+
+	targetColumns := collx.ArrayToMap(targetMeta.Columns, func(c Column) string {
+		return strings.ToLower(c.ColumnName)
+	})
+	srcColumns := collx.ArrayToMap(srcMeta.Columns, func(c Column) string {
+		return strings.ToLower(c.ColumnName)
+	})
+	for _, srcColumn := range srcMeta.Columns {
+		targetColumn, ok := targetColumns[strings.ToLower(srcColumn.ColumnName)]
+		if !ok {
+			td.AddColumns = append(td.AddColumns, srcColumn)
+			continue
+		}
+		if !columnEqual(srcColumn, targetColumn) {
+			td.ModifyColumns = append(td.ModifyColumns, ColumnDiff{Source: srcColumn, Target: targetColumn})
+		}
+	}
+	for _, targetColumn := range targetMeta.Columns {
+		if _, ok := srcColumns[strings.ToLower(targetColumn.ColumnName)]; !ok {
+			td.DropColumns = append(td.DropColumns, targetColumn)
+		}
+	}
+
+	// 主键索引通过字段信息体现，不参与索引比对
+	srcIndexs := collx.ArrayRemoveFunc(srcMeta.Indexs, isPrimaryIndex)
+	targetIndexs := collx.ArrayRemoveFunc(targetMeta.Indexs, isPrimaryIndex)
+	targetIndexMap := collx.ArrayToMap(targetIndexs, func(i Index) string {
+		return strings.ToLower(i.IndexName)
+	})
+	srcIndexMap := collx.ArrayToMap(srcIndexs, func(i Index) string {
+		return strings.ToLower(i.IndexName)
+	})
+	for _, srcIndex := range srcIndexs {
+		targetIndex, ok := targetIndexMap[strings.ToLower(srcIndex.IndexName)]
+		if ok && indexEqual(srcIndex, targetIndex) {
+			continue
+		}
+		// 索引不一致则先删除再新增
+		if ok {
+			td.DropIndexs = append(td.DropIndexs, targetIndex)
+		}
+		td.AddIndexs = append(td.AddIndexs, srcIndex)
+	}
+	for _, targetIndex := range targetIndexs {
+		if _, ok := srcIndexMap[strings.ToLower(targetIndex.IndexName)]; !ok {
+			td.DropIndexs = append(td.DropIndexs, targetIndex)
+		}
+	}
+	return td
+}
+
+func columnEqual(src, target Column) bool {
+	return strings.EqualFold(src.ColumnType, target.ColumnType) &&
+		isNullable(src) == isNullable(target) &&
+		src.ColumnDefault == target.ColumnDefault &&
+		src.ColumnComment == target.ColumnComment &&
+		// 仅两者均包含额外信息时比对自增标识（不同类型数据库间比对时忽略）
+		(src.Extra == nil || target.Extra == nil || IsAutoIncrement(src) == IsAutoIncrement(target))
+}
+
+func indexEqual(src, target Index) bool {
+	return strings.EqualFold(strings.ReplaceAll(src.ColumnName, " ", ""), strings.ReplaceAll(target.ColumnName, " ", "")) &&
+		src.NonUnique == target.NonUnique
+}
+
+func isNullable(column Column) bool {
+	return !strings.EqualFold(column.Nullable, "NO")
+}
+
+// 字段是否自增，目前仅mysql的字段信息包含自增标识
+// 是否为自增列
+func IsAutoIncrement(column Column) bool {
+	return strings.Contains(strings.ToLower(anyx.ConvString(column.Extra["extra"])), "auto_increment")
+}
+
+func isPrimaryIndex(index Index) bool {
+	name := strings.ToLower(index.IndexName)
+	// mysql为PRIMARY，pgsql为xxx_pkey，sql server默认为PK__xxx
+	return name == "primary" || strings.HasSuffix(name, "_pkey") || strings.HasPrefix(name, "pk_")
+}
+
+// 生成将目标库表结构同步为源库表结构的sql语句，语句以目标库类型的方言生成，不含结尾分号
+func (sd *SchemaDiff) GenSql(targetType DbType, dropTable bool) []string {
+	sqls := make([]string, 0)
+	for _, tm := range sd.AddTables {
+		sqls = append(sqls, genCreateTableSql(targetType, tm)...)
+	}
+	for _, td := range sd.AlterTables {
+		sqls = append(sqls, genAlterTableSql(targetType, td)...)
+	}
+	if dropTable {
+		for _, tableName := range sd.DropTables {
+			sqls = append(sqls, fmt.Sprintf("DROP TABLE %s", targetType.QuoteIdentifier(tableName)))
+		}
+	}
+	return sqls
+}
+
+func genCreateTableSql(dbType DbType, tm *TableMeta) []string {
+	if tm.Ddl != "" {
+		return []string{strings.TrimSuffix(strings.TrimSpace(tm.Ddl), ";")}
+	}
+
+	tableName := tm.Table.TableName
+	quoteTable := dbType.QuoteIdentifier(tableName)
+	lines := make([]string, 0, len(tm.Columns)+1)
+	pks := make([]string, 0)
+	for _, column := range tm.Columns {
+		lines = append(lines, "  "+genColumnDefinition(dbType, column, true))
+		if column.ColumnKey == "PRI" {
+			pks = append(pks, dbType.QuoteIdentifier(column.ColumnName))
+		}
+	}
+	if len(pks) > 0 && dbType != DbTypeClickhouse {
+		lines = append(lines, fmt.Sprintf("  PRIMARY KEY (%s)", strings.Join(pks, ", ")))
+	}
+
+	createSql := fmt.Sprintf("CREATE TABLE %s (\n%s\n)", quoteTable, strings.Join(lines, ",\n"))
+	if isMysqlType(dbType) && tm.Table.TableComment != "" {
+		createSql += " COMMENT = " + dbType.QuoteLiteral(tm.Table.TableComment)
+	}
+	if dbType == DbTypeClickhouse {
+		// clickhouse需指定表引擎，MergeTree的主键默认与排序键一致
+		orderBy := "tuple()"
+		if len(pks) > 0 {
+			orderBy = fmt.Sprintf("(%s)", strings.Join(pks, ", "))
+		}
+		createSql += " ENGINE = MergeTree ORDER BY " + orderBy
+		if tm.Table.TableComment != "" {
+			createSql += " COMMENT " + dbType.QuoteLiteral(tm.Table.TableComment)
+		}
+	}
+	sqls := []string{createSql}
+
+	if supportCommentOn(dbType) {
+		if tm.Table.TableComment != "" {
+			sqls = append(sqls, fmt.Sprintf("COMMENT ON TABLE %s IS %s", quoteTable, dbType.QuoteLiteral(tm.Table.TableComment)))
+		}
+		for _, column := range tm.Columns {
+			if column.ColumnComment != "" {
+				sqls = append(sqls, genColumnCommentSql(dbType, tableName, column))
+			}
+		}
+	}
+
+	for _, index := range tm.Indexs {
+		if !isPrimaryIndex(index) {
+			sqls = append(sqls, genCreateIndexSql(dbType, tableName, index))
+		}
+	}
+	return sqls
+}
+
+func genAlterTableSql(dbType DbType, td *TableDiff) []string {
+	sqls := make([]string, 0)
+	tableName := td.TableName
+	quoteTable := dbType.QuoteIdentifier(tableName)
+	supportComment := supportCommentOn(dbType)
+
+	for _, index := range td.DropIndexs {
+		sqls = append(sqls, genDropIndexSql(dbType, tableName, index))
+	}
+
+	for _, column := range td.AddColumns {
+		addKeyword := "ADD COLUMN"
+		if dbType == DbTypeOracle || dbType == DbTypeDM || dbType == DbTypeMssql {
+			addKeyword = "ADD"
+		}
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s %s %s", quoteTable, addKeyword, genColumnDefinition(dbType, column, true)))
+		if supportComment && column.ColumnComment != "" {
+			sqls = append(sqls, genColumnCommentSql(dbType, tableName, column))
+		}
+	}
+
+	for _, cd := range td.ModifyColumns {
+		sqls = append(sqls, genModifyColumnSql(dbType, tableName, cd)...)
+	}
+
+	for _, column := range td.DropColumns {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quoteTable, dbType.QuoteIdentifier(column.ColumnName)))
+	}
+
+	for _, index := range td.AddIndexs {
+		sqls = append(sqls, genCreateIndexSql(dbType, tableName, index))
+	}
+	return sqls
+}
+
+func genModifyColumnSql(dbType DbType, tableName string, cd ColumnDiff) []string {
+	quoteTable := dbType.QuoteIdentifier(tableName)
+	src, target := cd.Source, cd.Target
+	quoteColumn := dbType.QuoteIdentifier(target.ColumnName)
+
+	switch dbType {
+	case DbTypeMysql, DbTypeMariadb, DbTypeClickhouse:
+		return []string{fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", quoteTable, genColumnDefinition(dbType, src, true))}
+	case DbTypeMssql:
+		// sql server的默认值为约束，需按约束名删除后重建，此处只同步类型及可空性
+		definition := fmt.Sprintf("%s %s", quoteColumn, src.ColumnType)
+		if isNullable(src) {
+			definition += " NULL"
+		} else {
+			definition += " NOT NULL"
+		}
+		sqls := make([]string, 0)
+		if !strings.EqualFold(src.ColumnType, target.ColumnType) || isNullable(src) != isNullable(target) {
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", quoteTable, definition))
+		}
+		if src.ColumnDefault != target.ColumnDefault {
+			sqls = append(sqls, fmt.Sprintf("-- 请手动修改表[%s]字段[%s]的默认值为: %s", tableName, target.ColumnName, src.ColumnDefault))
+		}
+		return sqls
+	case DbTypeSqlite:
+		return []string{fmt.Sprintf("-- sqlite不支持修改字段, 请手动重建表[%s]字段[%s]", tableName, target.ColumnName)}
+	case DbTypePostgres:
+		sqls := make([]string, 0)
+		if !strings.EqualFold(src.ColumnType, target.ColumnType) {
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s", quoteTable, quoteColumn, src.ColumnType))
+		}
+		if isNullable(src) != isNullable(target) {
+			action := "SET NOT NULL"
+			if isNullable(src) {
+				action = "DROP NOT NULL"
+			}
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s", quoteTable, quoteColumn, action))
+		}
+		if src.ColumnDefault != target.ColumnDefault {
+			if src.ColumnDefault == "" {
+				sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT", quoteTable, quoteColumn))
+			} else {
+				sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s", quoteTable, quoteColumn, formatDefaultValue(dbType, src.ColumnDefault)))
+			}
+		}
+		if src.ColumnComment != target.ColumnComment {
+			sqls = append(sqls, genColumnCommentSql(dbType, tableName, src))
+		}
+		return sqls
+	default:
+		// oracle、达梦修改可空性时需显式指定，可空性一致时指定会报错
+		sqls := make([]string, 0)
+		definition := fmt.Sprintf("%s %s", quoteColumn, src.ColumnType)
+		if src.ColumnDefault != target.ColumnDefault {
+			if src.ColumnDefault == "" {
+				definition += " DEFAULT NULL"
+			} else {
+				definition += " DEFAULT " + formatDefaultValue(dbType, src.ColumnDefault)
+			}
+		}
+		if isNullable(src) != isNullable(target) {
+			if isNullable(src) {
+				definition += " NULL"
+			} else {
+				definition += " NOT NULL"
+			}
+		}
+		if !strings.EqualFold(src.ColumnType, target.ColumnType) || src.ColumnDefault != target.ColumnDefault || isNullable(src) != isNullable(target) {
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s MODIFY %s", quoteTable, definition))
+		}
+		if src.ColumnComment != target.ColumnComment {
+			sqls = append(sqls, genColumnCommentSql(dbType, tableName, src))
+		}
+		return sqls
+	}
+}
+
+// 生成字段定义，如：`name` varchar(32) NOT NULL DEFAULT ” COMMENT '名称'
+func genColumnDefinition(dbType DbType, column Column, withComment bool) string {
+	definition := fmt.Sprintf("%s %s", dbType.QuoteIdentifier(column.ColumnName), column.ColumnType)
+	if column.ColumnDefault != "" {
+		definition += " DEFAULT " + formatDefaultValue(dbType, column.ColumnDefault)
+	}
+	if !isNullable(column) {
+		definition += " NOT NULL"
+	}
+	if isMysqlType(dbType) && IsAutoIncrement(column) {
+		definition += " AUTO_INCREMENT"
+	}
+	if withComment && (isMysqlType(dbType) || dbType == DbTypeClickhouse) && column.ColumnComment != "" {
+		definition += " COMMENT " + dbType.QuoteLiteral(column.ColumnComment)
+	}
+	return definition
+}
+
+func genColumnCommentSql(dbType DbType, tableName string, column Column) string {
+	return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s", dbType.QuoteIdentifier(tableName), dbType.QuoteIdentifier(column.ColumnName), dbType.QuoteLiteral(column.ColumnComment))
+}
+
+func genCreateIndexSql(dbType DbType, tableName string, index Index) string {
+	unique := ""
+	if index.NonUnique == 0 {
+		unique = "UNIQUE "
+	}
+	columns := collx.ArrayMap(strings.Split(index.ColumnName, ","), func(c string) string {
+		return dbType.QuoteIdentifier(strings.TrimSpace(c))
+	})
+	if dbType == DbTypeClickhouse {
+		// clickhouse仅支持跳数索引，非clickhouse的索引类型则使用minmax
+		indexType := index.IndexType
+		if !clickhouseIndexTypeRegexp.MatchString(indexType) {
+			indexType = "minmax"
+		}
+		return fmt.Sprintf("ALTER TABLE %s ADD INDEX %s (%s) TYPE %s GRANULARITY 1", dbType.QuoteIdentifier(tableName), dbType.QuoteIdentifier(index.IndexName), strings.Join(columns, ", "), indexType)
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique, dbType.QuoteIdentifier(index.IndexName), dbType.QuoteIdentifier(tableName), strings.Join(columns, ", "))
+}
+
+func genDropIndexSql(dbType DbType, tableName string, index Index) string {
+	if dbType == DbTypeClickhouse {
+		return fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", dbType.QuoteIdentifier(tableName), dbType.QuoteIdentifier(index.IndexName))
+	}
+	if isMysqlType(dbType) || dbType == DbTypeMssql {
+		return fmt.Sprintf("DROP INDEX %s ON %s", dbType.QuoteIdentifier(index.IndexName), dbType.QuoteIdentifier(tableName))
+	}
+	return fmt.Sprintf("DROP INDEX %s", dbType.QuoteIdentifier(index.IndexName))
+}
+
+// clickhouse跳数索引类型，如：minmax、set(100)、bloom_filter(0.01)
+var clickhouseIndexTypeRegexp = regexp.MustCompile(`^(minmax|set|bloom_filter|ngrambf_v1|tokenbf_v1)(\([\d.,\s]*\))?$`)
+
+// 可原样输出的默认值：数值、NULL、布尔值、已被引号包裹的字符串（内部单引号须成对转义，不可含反斜杠）及允许的函数调用，均可带类型转换。
+// 函数仅允许常见的时间、uuid、序列函数，参数只能为数值或字符串字面量
+var (
+	defaultLiteralPattern   = `(-?\d+(\.\d+)?|N?'([^'\\]|'')*'|NULL|TRUE|FALSE)`
+	defaultFuncPattern      = `(CURRENT_TIMESTAMP|CURRENT_DATE|CURRENT_TIME|LOCALTIMESTAMP|LOCALTIME|SYSDATE|SYSTIMESTAMP|NOW|GETDATE|GETUTCDATE|SYSDATETIME|TODAY|UUID|NEWID|GEN_RANDOM_UUID|UUID_GENERATE_V4|GENERATEUUIDV4|NEXTVAL|DATETIME|DATE)`
+	defaultArgsPattern      = `(\(\s*(` + defaultLiteralPattern + `(::[A-Z_][A-Z0-9_ ]*)?(\s*,\s*` + defaultLiteralPattern + `(::[A-Z_][A-Z0-9_ ]*)?)*)?\s*\))?`
+	defaultCastPattern      = `(::[A-Z_][A-Z0-9_ ]*(\(\d+(,\s*\d+)?\))?)?`
+	rawDefaultValueRegexp   = regexp.MustCompile(`^(` + defaultLiteralPattern + `|` + defaultFuncPattern + defaultArgsPattern + `)` + defaultCastPattern + `$`)
+	defaultOuterParenRegexp = regexp.MustCompile(`^\((.*)\)$`)
+)
+
+// 格式化默认值，可原样输出的默认值（见rawDefaultValueRegexp）直接输出，其他均作为字符串字面量
+func formatDefaultValue(dbType DbType, value string) string {
+	// mssql的默认值会被括号包裹，如：((0))、(getdate())
+	check := strings.TrimSpace(value)
+	for {
+		m := defaultOuterParenRegexp.FindStringSubmatch(check)
+		if m == nil {
+			break
+		}
+		check = strings.TrimSpace(m[1])
+	}
+	if rawDefaultValueRegexp.MatchString(upperOutsideQuote(check)) {
+		return value
+	}
+	return dbType.QuoteLiteral(value)
+}
+
+// 将单引号外的字符转为大写，单引号内的字符串字面量保持不变
+func upperOutsideQuote(s string) string {
+	var sb strings.Builder
+	inQuote := false
+	for _, r := range s {
+		if r == '\'' {
+			inQuote = !inQuote
+		}
+		if inQuote {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteString(strings.ToUpper(string(r)))
+		}
+	}
+	return sb.String()
+}
+
+// 是否支持 COMMENT ON 语句设置表及字段注释
+func supportCommentOn(dbType DbType) bool {
+	return !isMysqlType(dbType) && dbType != DbTypeSqlite && dbType != DbTypeMssql && dbType != DbTypeClickhouse
+}
+
+func isMysqlType(dbType DbType) bool {
+	return dbType == DbTypeMysql || dbType == DbTypeMariadb
+}
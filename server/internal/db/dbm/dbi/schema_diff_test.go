@@ -0,0 +1,86 @@
+package dbi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DiffTableMetas(t *testing.T) {
+	srcMetas := []*TableMeta{
+		{
+			Table: Table{TableName: "t_user"},
+			Columns: []Column{
+				{TableName: "t_user", ColumnName: "id", ColumnType: "bigint", ColumnKey: "PRI", Nullable: "NO"},
+				{TableName: "t_user", ColumnName: "name", ColumnType: "varchar(64)", Nullable: "NO", ColumnComment: "名称"},
+				{TableName: "t_user", ColumnName: "age", ColumnType: "int", Nullable: "YES", ColumnDefault: "0"},
+			},
+			Indexs: []Index{
+				{IndexName: "PRIMARY", ColumnName: "id"},
+				{IndexName: "idx_name", ColumnName: "name", NonUnique: 0},
+			},
+		},
+		{
+			Table:   Table{TableName: "t_role"},
+			Columns: []Column{{TableName: "t_role", ColumnName: "id", ColumnType: "bigint", ColumnKey: "PRI", Nullable: "NO"}},
+		},
+	}
+	targetMetas := []*TableMeta{
+		{
+			Table: Table{TableName: "t_user"},
+			Columns: []Column{
+				{TableName: "t_user", ColumnName: "id", ColumnType: "bigint", ColumnKey: "PRI", Nullable: "NO"},
+				{TableName: "t_user", ColumnName: "name", ColumnType: "varchar(32)", Nullable: "NO", ColumnComment: "名称"},
+				{TableName: "t_user", ColumnName: "remark", ColumnType: "varchar(255)", Nullable: "YES"},
+			},
+			Indexs: []Index{
+				{IndexName: "PRIMARY", ColumnName: "id"},
+				{IndexName: "idx_name", ColumnName: "name", NonUnique: 1},
+			},
+		},
+		{
+			Table: Table{TableName: "t_log"},
+		},
+	}
+
+	diff := DiffTableMetas(srcMetas, targetMetas)
+	require.Len(t, diff.AddTables, 1)
+	require.Equal(t, "t_role", diff.AddTables[0].Table.TableName)
+	require.Equal(t, []string{"t_log"}, diff.DropTables)
+	require.Len(t, diff.AlterTables, 1)
+
+	td := diff.AlterTables[0]
+	require.Equal(t, "age", td.AddColumns[0].ColumnName)
+	require.Equal(t, "remark", td.DropColumns[0].ColumnName)
+	require.Equal(t, "varchar(64)", td.ModifyColumns[0].Source.ColumnType)
+	require.Equal(t, "idx_name", td.DropIndexs[0].IndexName)
+	require.Equal(t, "idx_name", td.AddIndexs[0].IndexName)
+
+	require.Equal(t, []string{
+		"CREATE TABLE `t_role` (\n  `id` bigint NOT NULL,\n  PRIMARY KEY (`id`)\n)",
+		"DROP INDEX `idx_name` ON `t_user`",
+		"ALTER TABLE `t_user` ADD COLUMN `age` int DEFAULT 0",
+		"ALTER TABLE `t_user` MODIFY COLUMN `name` varchar(64) NOT NULL COMMENT '名称'",
+		"ALTER TABLE `t_user` DROP COLUMN `remark`",
+		"CREATE UNIQUE INDEX `idx_name` ON `t_user` (`name`)",
+		"DROP TABLE `t_log`",
+	}, diff.GenSql(DbTypeMysql, true))
+
+	require.Equal(t, []string{
+		`CREATE TABLE "t_role" (` + "\n" + `  "id" bigint NOT NULL,` + "\n" + `  PRIMARY KEY ("id")` + "\n)",
+		`DROP INDEX "idx_name"`,
+		`ALTER TABLE "t_user" ADD COLUMN "age" int DEFAULT 0`,
+		`ALTER TABLE "t_user" ALTER COLUMN "name" TYPE varchar(64)`,
+		`ALTER TABLE "t_user" DROP COLUMN "remark"`,
+		`CREATE UNIQUE INDEX "idx_name" ON "t_user" ("name")`,
+	}, diff.GenSql(DbTypePostgres, false))
+
+	require.Equal(t, []string{
+		"CREATE TABLE `t_role` (\n  `id` bigint NOT NULL\n) ENGINE = MergeTree ORDER BY (`id`)",
+		"ALTER TABLE `t_user` DROP INDEX `idx_name`",
+		"ALTER TABLE `t_user` ADD COLUMN `age` int DEFAULT 0",
+		"ALTER TABLE `t_user` MODIFY COLUMN `name` varchar(64) NOT NULL COMMENT '名称'",
+		"ALTER TABLE `t_user` DROP COLUMN `remark`",
+		"ALTER TABLE `t_user` ADD INDEX `idx_name` (`name`) TYPE minmax GRANULARITY 1",
+	}, diff.GenSql(DbTypeClickhouse, false))
+}
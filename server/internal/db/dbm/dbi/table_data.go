@@ -0,0 +1,380 @@
+package dbi
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 表数据查询条件支持的操作符
+const (
+	TableDataOpEq        = "="
+	TableDataOpNe        = "!="
+	TableDataOpGt        = ">"
+	TableDataOpGe        = ">="
+	TableDataOpLt        = "<"
+	TableDataOpLe        = "<="
+	TableDataOpLike      = "like"
+	TableDataOpNotLike   = "not like"
+	TableDataOpIn        = "in"
+	TableDataOpNotIn     = "not in"
+	TableDataOpIsNull    = "is null"
+	TableDataOpIsNotNull = "is not null"
+)
+
+// TableDataCond 表数据过滤条件，多个条件之间为and关系
+type TableDataCond struct {
+	Column string `json:"column"`
+	Op     string `json:"op"`
+	Value  any    `json:"value"` // in、not in 时为数组，is null、is not null 时忽略
+}
+
+// TableDataOrder 表数据排序
+type TableDataOrder struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc"`
+}
+
+// TableDataQuery 表数据分页查询参数
+type TableDataQuery struct {
+	Table    string
+	Conds    []*TableDataCond
+	Orders   []*TableDataOrder // 为空则按主键排序，保证分页结果稳定
+	PageNum  int
+	PageSize int
+	Count    bool // 是否统计总记录数
+
+	// 是否使用主键键集分页，适用于大表的顺序翻页。开启后按主键升序查询AfterKey之后的数据，忽略PageNum及Orders
+	Keyset   bool
+	AfterKey map[string]any // 上一页最后一行的主键值，为空则查询第一页
+}
+
+// TableDataRes 表数据分页查询结果
+type TableDataRes struct {
+	Columns []*QueryColumn   `json:"columns"`
+	Rows    []map[string]any `json:"rows"`
+	Total   int64            `json:"total"`   // 总记录数，未统计则为-1
+	NextKey map[string]any   `json:"nextKey"` // 键集分页时下一页的AfterKey，没有更多数据则为nil
+}
+
+// PageSqlFunc 为查询sql添加分页，offset从0开始
+type PageSqlFunc func(selectSql string, offset, limit int) string
+
+// LimitOffsetPageSql 使用 LIMIT OFFSET 分页，适用于mysql、postgres、dm、sqlite等
+func LimitOffsetPageSql(selectSql string, offset, limit int) string {
+	return fmt.Sprintf("%s LIMIT %d OFFSET %d", selectSql, limit, offset)
+}
+
+// TableDataSql 根据查询参数生成的表数据sql
+type TableDataSql struct {
+	SelectSql   string   // 未分页的查询sql
+	CountSql    string   // 统计总记录数的sql
+	PrimaryKeys []string // 表主键列名
+	Offset      int
+	Limit       int
+}
+
+// BuildTableDataSql 根据表列信息校验查询参数并生成查询sql，列名及值均经过quote处理
+func BuildTableDataSql(dbType DbType, dataTypeFunc func(columnType string) DataType, columns []Column, query *TableDataQuery) (*TableDataSql, error) {
+	if len(columns) == 0 {
+		return nil, errorx.NewBiz("表[%s]不存在", query.Table)
+	}
+	columnMap := make(map[string]Column, len(columns))
+	primaryKeys := make([]Column, 0)
+	for _, column := range columns {
+		columnMap[strings.ToLower(column.ColumnName)] = column
+		if column.ColumnKey == "PRI" {
+			primaryKeys = append(primaryKeys, column)
+		}
+	}
+	getColumn := func(name string) (Column, error) {
+		if column, ok := columnMap[strings.ToLower(name)]; ok {
+			return column, nil
+		}
+		return Column{}, errorx.NewBiz("表[%s]不存在列[%s]", query.Table, name)
+	}
+	literal := func(column Column, value any) (string, error) {
+		res, err := ColumnLiteral(dbType, dataTypeFunc(column.ColumnType), value)
+		if err != nil {
+			return "", errorx.NewBiz("列[%s]: %s", column.ColumnName, err.Error())
+		}
+		return res, nil
+	}
+
+	wheres := make([]string, 0)
+	for _, cond := range query.Conds {
+		column, err := getColumn(cond.Column)
+		if err != nil {
+			return nil, err
+		}
+		where, err := buildTableDataCond(dbType, dbType.QuoteIdentifier(column.ColumnName), cond, func(value any) (string, error) { return literal(column, value) })
+		if err != nil {
+			return nil, err
+		}
+		wheres = append(wheres, where)
+	}
+
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = 25
+	}
+	offset := 0
+	orders := make([]string, 0)
+	if query.Keyset {
+		if len(primaryKeys) == 0 {
+			return nil, errorx.NewBiz("表[%s]不存在主键, 不支持键集分页", query.Table)
+		}
+		for _, pk := range primaryKeys {
+			orders = append(orders, dbType.QuoteIdentifier(pk.ColumnName))
+		}
+		if len(query.AfterKey) > 0 {
+			keysetWhere, err := buildKeysetCond(dbType, primaryKeys, query.AfterKey, literal)
+			if err != nil {
+				return nil, err
+			}
+			wheres = append(wheres, keysetWhere)
+		}
+	} else {
+		if query.PageNum > 1 {
+			offset = (query.PageNum - 1) * pageSize
+		}
+		for _, order := range query.Orders {
+			column, err := getColumn(order.Column)
+			if err != nil {
+				return nil, err
+			}
+			orderStr := dbType.QuoteIdentifier(column.ColumnName)
+			if order.Desc {
+				orderStr += " DESC"
+			}
+			orders = append(orders, orderStr)
+		}
+		if len(orders) == 0 {
+			for _, pk := range primaryKeys {
+				orders = append(orders, dbType.QuoteIdentifier(pk.ColumnName))
+			}
+		}
+	}
+
+	from := "FROM " + dbType.QuoteIdentifier(query.Table)
+	if len(wheres) > 0 {
+		from += " WHERE " + strings.Join(wheres, " AND ")
+	}
+	selectSql := "SELECT * " + from
+	if len(orders) > 0 {
+		selectSql += " ORDER BY " + strings.Join(orders, ", ")
+	}
+
+	pkNames := make([]string, 0, len(primaryKeys))
+	for _, pk := range primaryKeys {
+		pkNames = append(pkNames, pk.ColumnName)
+	}
+	return &TableDataSql{
+		SelectSql:   selectSql,
+		CountSql:    "SELECT COUNT(*) " + from,
+		PrimaryKeys: pkNames,
+		Offset:      offset,
+		Limit:       pageSize,
+	}, nil
+}
+
+func buildTableDataCond(dbType DbType, quoteColumn string, cond *TableDataCond, literal func(value any) (string, error)) (string, error) {
+	op := strings.ToLower(strings.Join(strings.Fields(cond.Op), " "))
+	switch op {
+	case TableDataOpIsNull, TableDataOpIsNotNull:
+		return fmt.Sprintf("%s %s", quoteColumn, strings.ToUpper(op)), nil
+	case TableDataOpIn, TableDataOpNotIn:
+		values, ok := cond.Value.([]any)
+		if !ok || len(values) == 0 {
+			return "", errorx.NewBiz("%s 条件的值需为非空数组", op)
+		}
+		literals := make([]string, 0, len(values))
+		for _, value := range values {
+			l, err := literal(value)
+			if err != nil {
+				return "", err
+			}
+			literals = append(literals, l)
+		}
+		return fmt.Sprintf("%s %s (%s)", quoteColumn, strings.ToUpper(op), strings.Join(literals, ", ")), nil
+	case TableDataOpEq, TableDataOpNe, TableDataOpGt, TableDataOpGe, TableDataOpLt, TableDataOpLe:
+		if cond.Value == nil {
+			return "", errorx.NewBiz("%s 条件的值不能为空", op)
+		}
+		l, err := literal(cond.Value)
+		if err != nil {
+			return "", err
+		}
+		if op == TableDataOpNe {
+			op = "<>"
+		}
+		return fmt.Sprintf("%s %s %s", quoteColumn, op, l), nil
+	case TableDataOpLike, TableDataOpNotLike:
+		// like 条件的值统一作为字符串
+		return fmt.Sprintf("%s %s %s", quoteColumn, strings.ToUpper(op), toStrLiteral(dbType, cond.Value)), nil
+	}
+	return "", errorx.NewBiz("不支持的条件操作符: %s", cond.Op)
+}
+
+// 生成键集分页条件，联合主键时展开为 (a > ?) OR (a = ? AND b > ?) 形式，兼容不支持行值比较的数据库
+func buildKeysetCond(dbType DbType, primaryKeys []Column, afterKey map[string]any, literal func(column Column, value any) (string, error)) (string, error) {
+	literals := make([]string, 0, len(primaryKeys))
+	for _, pk := range primaryKeys {
+		value, ok := GetMapValue(afterKey, pk.ColumnName)
+		if !ok || value == nil {
+			return "", errorx.NewBiz("缺少主键列[%s]的值", pk.ColumnName)
+		}
+		l, err := literal(pk, value)
+		if err != nil {
+			return "", err
+		}
+		literals = append(literals, l)
+	}
+
+	ors := make([]string, 0, len(primaryKeys))
+	for i, pk := range primaryKeys {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, fmt.Sprintf("%s = %s", dbType.QuoteIdentifier(primaryKeys[j].ColumnName), literals[j]))
+		}
+		ands = append(ands, fmt.Sprintf("%s > %s", dbType.QuoteIdentifier(pk.ColumnName), literals[i]))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	if len(ors) == 1 {
+		return ors[0], nil
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", nil
+}
+
+// KeysetCond 生成查询主键值大于afterKey的数据的条件，用于按主键顺序分批读取表数据
+func KeysetCond(dbType DbType, dataTypeFunc func(columnType string) DataType, columns []Column, primaryKeys []string, afterKey map[string]any) (string, error) {
+	pkColumns := make([]Column, 0, len(primaryKeys))
+	for _, pk := range primaryKeys {
+		index := slices.IndexFunc(columns, func(column Column) bool { return strings.EqualFold(column.ColumnName, pk) })
+		if index < 0 {
+			return "", errorx.NewBiz("不存在主键列[%s]", pk)
+		}
+		pkColumns = append(pkColumns, columns[index])
+	}
+	return buildKeysetCond(dbType, pkColumns, afterKey, func(column Column, value any) (string, error) {
+		return ColumnLiteral(dbType, dataTypeFunc(column.ColumnType), value)
+	})
+}
+
+// QueryTableData 分页查询表数据，pageSqlFunc为各数据库的分页sql实现
+func QueryTableData(ctx context.Context, dc *DbConn, query *TableDataQuery, pageSqlFunc PageSqlFunc) (*TableDataRes, error) {
+	dialect := dc.GetDialect()
+	columns, err := dialect.GetColumns(query.Table)
+	if err != nil {
+		return nil, err
+	}
+	tds, err := BuildTableDataSql(dc.Info.Type, dialect.GetDataType, columns, query)
+	if err != nil {
+		return nil, err
+	}
+
+	queryColumns, rows, err := dc.QueryContext(ctx, pageSqlFunc(tds.SelectSql, tds.Offset, tds.Limit))
+	if err != nil {
+		return nil, err
+	}
+	res := &TableDataRes{Columns: queryColumns, Rows: rows, Total: -1}
+
+	if query.Keyset && len(rows) == tds.Limit {
+		lastRow := rows[len(rows)-1]
+		res.NextKey = make(map[string]any, len(tds.PrimaryKeys))
+		for _, pk := range tds.PrimaryKeys {
+			value, _ := GetMapValue(lastRow, pk)
+			res.NextKey[pk] = value
+		}
+	}
+
+	if query.Count {
+		_, countRes, err := dc.QueryContext(ctx, tds.CountSql)
+		if err != nil {
+			return nil, err
+		}
+		if len(countRes) > 0 {
+			for _, v := range countRes[0] {
+				res.Total = anyx.ConvInt64(v)
+			}
+		}
+	}
+	return res, nil
+}
+
+// ColumnLiteral 根据列的数据类型将值转为sql字面量
+func ColumnLiteral(dbType DbType, dataType DataType, value any) (string, error) {
+	if value == nil {
+		return "NULL", nil
+	}
+	if v, ok := value.(bool); ok {
+		return boolLiteral(dbType, dataType, v), nil
+	}
+	if v, ok := value.([]byte); ok {
+		value = string(v)
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer, reflect.Func, reflect.Chan, reflect.Interface:
+		return "", errorx.NewBiz("值[%v]不是有效的字段值", value)
+	}
+
+	switch dataType {
+	case DataTypeNumber:
+		strValue := anyx.ToString(value)
+		if strValue == "" {
+			return "NULL", nil
+		}
+		if _, err := strconv.ParseFloat(strValue, 64); err != nil {
+			return "", errorx.NewBiz("值[%s]不是有效的数字", strValue)
+		}
+		return strValue, nil
+	case DataTypeDate, DataTypeDateTime:
+		if strValue, ok := value.(string); ok {
+			if strValue == "" {
+				return "NULL", nil
+			}
+			return dbType.QuoteDateTimeLiteral(strValue), nil
+		}
+	}
+	if _, ok := value.(string); ok {
+		return toStrLiteral(dbType, value), nil
+	}
+	return anyx.ToString(value), nil
+}
+
+// 布尔值字面量，数值列及不支持 TRUE/FALSE 的数据库（sql server、oracle、达梦）使用 1/0
+func boolLiteral(dbType DbType, dataType DataType, value bool) string {
+	switch {
+	case dataType != DataTypeNumber && (dbType == DbTypePostgres || dbType == DbTypeSqlite || dbType == DbTypeClickhouse || dbType == DbTypeMysql || dbType == DbTypeMariadb):
+		if value {
+			return "TRUE"
+		}
+		return "FALSE"
+	case value:
+		return "1"
+	default:
+		return "0"
+	}
+}
+
+func toStrLiteral(dbType DbType, value any) string {
+	return dbType.QuoteLiteral(anyx.ToString(value))
+}
+
+// GetMapValue 获取map中指定列的值，列名忽略大小写（如oracle、dm返回的列名为大写）
+func GetMapValue(m map[string]any, columnName string) (any, bool) {
+	if value, ok := m[columnName]; ok {
+		return value, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, columnName) {
+			return v, true
+		}
+	}
+	return nil, false
+}
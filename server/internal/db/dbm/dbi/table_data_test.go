@@ -0,0 +1,147 @@
+package dbi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func tableDataTestDataType(columnType string) DataType {
+	if strings.Contains(columnType, "int") {
+		return DataTypeNumber
+	}
+	if strings.Contains(columnType, "date") {
+		return DataTypeDateTime
+	}
+	return DataTypeString
+}
+
+func Test_BuildTableDataSql(t *testing.T) {
+	columns := []Column{
+		{ColumnName: "id", ColumnType: "bigint", ColumnKey: "PRI"},
+		{ColumnName: "name", ColumnType: "varchar(64)"},
+		{ColumnName: "create_time", ColumnType: "datetime"},
+	}
+
+	tds, err := BuildTableDataSql(DbTypeMysql, tableDataTestDataType, columns, &TableDataQuery{
+		Table: "t_user",
+		Conds: []*TableDataCond{
+			{Column: "NAME", Op: "like", Value: "a'%"},
+			{Column: "id", Op: "in", Value: []any{1, "2"}},
+			{Column: "create_time", Op: "is  not null"},
+		},
+		Orders:   []*TableDataOrder{{Column: "create_time", Desc: true}},
+		PageNum:  3,
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM `t_user` WHERE `name` LIKE 'a''%' AND `id` IN (1, 2) AND `create_time` IS NOT NULL ORDER BY `create_time` DESC", tds.SelectSql)
+	require.Equal(t, "SELECT COUNT(*) FROM `t_user` WHERE `name` LIKE 'a''%' AND `id` IN (1, 2) AND `create_time` IS NOT NULL", tds.CountSql)
+	require.Equal(t, 20, tds.Offset)
+	require.Equal(t, 10, tds.Limit)
+	require.Equal(t, "SELECT 1 LIMIT 10 OFFSET 20", LimitOffsetPageSql("SELECT 1", tds.Offset, tds.Limit))
+
+	// 未指定排序则按主键排序
+	tds, err = BuildTableDataSql(DbTypePostgres, tableDataTestDataType, columns, &TableDataQuery{Table: "t_user"})
+	require.NoError(t, err)
+	require.Equal(t, `SELECT * FROM "t_user" ORDER BY "id"`, tds.SelectSql)
+	require.Equal(t, 0, tds.Offset)
+
+	_, err = BuildTableDataSql(DbTypeMysql, tableDataTestDataType, columns, &TableDataQuery{
+		Table: "t_user",
+		Conds: []*TableDataCond{{Column: "id", Op: "=", Value: "1 or 1=1"}},
+	})
+	require.Error(t, err)
+
+	_, err = BuildTableDataSql(DbTypeMysql, tableDataTestDataType, columns, &TableDataQuery{
+		Table: "t_user",
+		Conds: []*TableDataCond{{Column: "id;drop", Op: "="}},
+	})
+	require.Error(t, err)
+
+	_, err = BuildTableDataSql(DbTypeMysql, tableDataTestDataType, columns, &TableDataQuery{
+		Table: "t_user",
+		Conds: []*TableDataCond{{Column: "id", Op: "= 1 or", Value: 1}},
+	})
+	require.Error(t, err)
+}
+
+func Test_BuildTableDataSqlKeyset(t *testing.T) {
+	columns := []Column{
+		{ColumnName: "tenant_id", ColumnType: "int", ColumnKey: "PRI"},
+		{ColumnName: "code", ColumnType: "varchar(32)", ColumnKey: "PRI"},
+		{ColumnName: "name", ColumnType: "varchar(64)"},
+	}
+
+	tds, err := BuildTableDataSql(DbTypeOracle, tableDataTestDataType, columns, &TableDataQuery{
+		Table:    "T_DICT",
+		Orders:   []*TableDataOrder{{Column: "name"}},
+		PageNum:  5,
+		PageSize: 100,
+		Keyset:   true,
+		AfterKey: map[string]any{"TENANT_ID": 1, "CODE": "a"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `SELECT * FROM "T_DICT" WHERE (("tenant_id" > 1) OR ("tenant_id" = 1 AND "code" > 'a')) ORDER BY "tenant_id", "code"`, tds.SelectSql)
+	require.Equal(t, 0, tds.Offset)
+	require.Equal(t, []string{"tenant_id", "code"}, tds.PrimaryKeys)
+
+	_, err = BuildTableDataSql(DbTypeOracle, tableDataTestDataType, columns, &TableDataQuery{
+		Table:    "T_DICT",
+		Keyset:   true,
+		AfterKey: map[string]any{"tenant_id": 1},
+	})
+	require.Error(t, err)
+
+	_, err = BuildTableDataSql(DbTypeMysql, tableDataTestDataType, columns[2:], &TableDataQuery{Table: "t", Keyset: true})
+	require.Error(t, err)
+}
+
+func Test_KeysetCond(t *testing.T) {
+	columns := []Column{
+		{ColumnName: "id", ColumnType: "bigint"},
+		{ColumnName: "name", ColumnType: "varchar(64)"},
+	}
+	// 主键值以字符串保存时仍按数字生成条件
+	cond, err := KeysetCond(DbTypeMysql, tableDataTestDataType, columns, []string{"id"}, map[string]any{"id": "9007199254740993"})
+	require.NoError(t, err)
+	require.Equal(t, "(`id` > 9007199254740993)", cond)
+
+	_, err = KeysetCond(DbTypeMysql, tableDataTestDataType, columns, []string{"code"}, map[string]any{"code": "a"})
+	require.Error(t, err)
+}
+
+func Test_ColumnLiteral(t *testing.T) {
+	tests := []struct {
+		dbType   DbType
+		dataType DataType
+		value    any
+		want     string
+		err      bool
+	}{
+		{DbTypeMysql, DataTypeString, nil, "NULL", false},
+		{DbTypeMysql, DataTypeNumber, "12", "12", false},
+		{DbTypeMysql, DataTypeNumber, "", "NULL", false},
+		{DbTypeMysql, DataTypeNumber, "1a", "", true},
+		{DbTypeMysql, DataTypeString, "it's", "'it''s'", false},
+		{DbTypeMysql, DataTypeDateTime, "", "NULL", false},
+		{DbTypeMysql, DataTypeNumber, true, "1", false},
+		{DbTypePostgres, DataTypeString, false, "FALSE", false},
+		{DbTypePostgres, DataTypeNumber, false, "0", false},
+		{DbTypeMssql, DataTypeString, true, "1", false},
+		{DbTypeOracle, DataTypeString, false, "0", false},
+		{DbTypeMysql, DataTypeString, []byte("ab"), "'ab'", false},
+		{DbTypeMysql, DataTypeString, map[string]any{"a": 1}, "", true},
+		{DbTypeMysql, DataTypeString, []any{1, 2}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ColumnLiteral(tt.dbType, tt.dataType, tt.value)
+		if tt.err {
+			require.Error(t, err, tt.value)
+			continue
+		}
+		require.NoError(t, err, tt.value)
+		require.Equal(t, tt.want, got, tt.value)
+	}
+}
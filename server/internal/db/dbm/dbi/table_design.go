@@ -0,0 +1,274 @@
+package dbi
+
+import (
+	"fmt"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/collx"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// 表结构定义，用于可视化设计表（新建或修改表）
+type TableDefinition struct {
+	TableName    string              `json:"tableName"`    // 表名
+	TableComment string              `json:"tableComment"` // 表备注
+	Columns      []*ColumnDefinition `json:"columns"`      // 字段，按声明顺序
+	PrimaryKeys  []string            `json:"primaryKeys"`  // 主键字段名
+	Indexs       []*IndexDefinition  `json:"indexs"`       // 索引，不包含主键索引
+}
+
+// 字段定义
+type ColumnDefinition struct {
+	Name          string `json:"name"`          // 字段名
+	OldName       string `json:"oldName"`       // 原字段名，不为空且与字段名不一致时重命名字段
+	Type          string `json:"type"`          // 类型，如：varchar、decimal，也可为包含长度的完整类型，如：varchar(32)
+	Length        int    `json:"length"`        // 长度，为0则不指定
+	Scale         int    `json:"scale"`         // 小数位数，长度不为0时有效
+	Nullable      bool   `json:"nullable"`      // 是否可为null，主键字段不可为null
+	Default       string `json:"default"`       // 默认值，数值及常用时间、uuid等函数原样输出，其他作为字符串字面量
+	Comment       string `json:"comment"`       // 备注
+	AutoIncrement bool   `json:"autoIncrement"` // 是否自增，目前仅mysql有效
+}
+
+// 索引定义
+type IndexDefinition struct {
+	Name    string   `json:"name"`    // 索引名
+	Columns []string `json:"columns"` // 索引字段
+	Unique  bool     `json:"unique"`  // 是否唯一索引
+	Type    string   `json:"type"`    // 索引类型，目前仅clickhouse跳数索引有效，如：minmax
+	Comment string   `json:"comment"` // 备注
+}
+
+// GenTableDesignSql 根据表结构定义生成sql，表不存在则生成建表语句，否则与现有表结构比对生成最小的修改语句。
+// 语句不含结尾分号，无法自动执行的操作以 -- 开头的注释语句提示
+func GenTableDesignSql(dc *DbConn, td *TableDefinition) ([]string, error) {
+	if err := td.validate(); err != nil {
+		return nil, err
+	}
+	existMetas, err := GetTableMetas(dc, td.TableName)
+	if err != nil {
+		return nil, err
+	}
+	var existMeta *TableMeta
+	if len(existMetas) > 0 {
+		existMeta = existMetas[0]
+	}
+	return genTableDesignSql(dc.Info.Type, td, existMeta)
+}
+
+// 根据表结构定义及现有表结构生成sql，existMeta为nil则生成建表语句
+func genTableDesignSql(dbType DbType, td *TableDefinition, existMeta *TableMeta) ([]string, error) {
+	newMeta := td.toTableMeta()
+	if existMeta == nil {
+		return genCreateTableSql(dbType, newMeta), nil
+	}
+
+	// 表名以数据库中的为准（大小写可能不一致）
+	tableName := existMeta.Table.TableName
+	newMeta.Table.TableName = tableName
+	for i := range newMeta.Columns {
+		newMeta.Columns[i].TableName = tableName
+	}
+
+	sqls := make([]string, 0)
+	// 重命名字段，并同步修改现有表结构信息，避免比对时生成删除再新增字段的语句
+	for _, column := range td.Columns {
+		if column.OldName == "" || column.OldName == column.Name {
+			continue
+		}
+		idx := slices.IndexFunc(existMeta.Columns, func(c Column) bool { return strings.EqualFold(c.ColumnName, column.OldName) })
+		if idx == -1 {
+			return nil, errorx.NewBiz("表[%s]不存在字段[%s]", tableName, column.OldName)
+		}
+		sqls = append(sqls, genRenameColumnSql(dbType, tableName, existMeta.Columns[idx].ColumnName, column.Name))
+		existMeta.Columns[idx].ColumnName = column.Name
+		for i, index := range existMeta.Indexs {
+			indexColumns := collx.ArrayMap(strings.Split(index.ColumnName, ","), func(c string) string {
+				if strings.EqualFold(strings.TrimSpace(c), column.OldName) {
+					return column.Name
+				}
+				return strings.TrimSpace(c)
+			})
+			existMeta.Indexs[i].ColumnName = strings.Join(indexColumns, ",")
+		}
+	}
+
+	existPks := existMeta.GetPrimaryKeys()
+	pkChanged := !slices.EqualFunc(existPks, td.PrimaryKeys, strings.EqualFold)
+	if pkChanged && len(existPks) > 0 {
+		sqls = append(sqls, genDropPrimaryKeySql(dbType, existMeta))
+	}
+
+	sqls = append(sqls, genAlterTableSql(dbType, diffTable(newMeta, existMeta))...)
+
+	if pkChanged && len(td.PrimaryKeys) > 0 {
+		sqls = append(sqls, genAddPrimaryKeySql(dbType, tableName, td.PrimaryKeys))
+	}
+	if td.TableComment != existMeta.Table.TableComment {
+		sqls = append(sqls, genTableCommentSql(dbType, tableName, td.TableComment))
+	}
+	return sqls, nil
+}
+
+func (td *TableDefinition) validate() error {
+	if td.TableName == "" {
+		return errorx.NewBiz("表名不能为空")
+	}
+	if len(td.Columns) == 0 {
+		return errorx.NewBiz("表[%s]至少需要一个字段", td.TableName)
+	}
+	columnNames := make([]string, 0, len(td.Columns))
+	for _, column := range td.Columns {
+		if column.Name == "" || column.Type == "" {
+			return errorx.NewBiz("字段名及类型不能为空")
+		}
+		if !columnDefinitionTypeRegexp.MatchString(column.columnType()) {
+			return errorx.NewBiz("字段[%s]的类型[%s]不合法", column.Name, column.columnType())
+		}
+		if slices.ContainsFunc(columnNames, func(c string) bool { return strings.EqualFold(c, column.Name) }) {
+			return errorx.NewBiz("字段[%s]重复", column.Name)
+		}
+		columnNames = append(columnNames, column.Name)
+	}
+	hasColumn := func(name string) bool {
+		return slices.ContainsFunc(columnNames, func(c string) bool { return strings.EqualFold(c, name) })
+	}
+	for _, pk := range td.PrimaryKeys {
+		if !hasColumn(pk) {
+			return errorx.NewBiz("主键字段[%s]不存在", pk)
+		}
+	}
+	for _, index := range td.Indexs {
+		if index.Name == "" || len(index.Columns) == 0 {
+			return errorx.NewBiz("索引名及索引字段不能为空")
+		}
+		for _, column := range index.Columns {
+			if !hasColumn(column) {
+				return errorx.NewBiz("索引[%s]的字段[%s]不存在", index.Name, column)
+			}
+		}
+	}
+	return nil
+}
+
+// 转换为表结构元信息，以复用表结构比对及sql生成逻辑
+func (td *TableDefinition) toTableMeta() *TableMeta {
+	tm := &TableMeta{
+		Table:   Table{TableName: td.TableName, TableComment: td.TableComment},
+		Columns: make([]Column, 0, len(td.Columns)),
+		Indexs:  make([]Index, 0, len(td.Indexs)),
+	}
+	for _, cd := range td.Columns {
+		column := Column{
+			TableName:     td.TableName,
+			ColumnName:    cd.Name,
+			ColumnType:    cd.columnType(),
+			ColumnComment: cd.Comment,
+			ColumnDefault: cd.Default,
+			Nullable:      "YES",
+		}
+		if slices.ContainsFunc(td.PrimaryKeys, func(pk string) bool { return strings.EqualFold(pk, cd.Name) }) {
+			column.ColumnKey = "PRI"
+			column.Nullable = "NO"
+		} else if !cd.Nullable {
+			column.Nullable = "NO"
+		}
+		column.Extra = collx.M{"extra": ""}
+		if cd.AutoIncrement {
+			column.Extra["extra"] = "auto_increment"
+		}
+		tm.Columns = append(tm.Columns, column)
+	}
+	for _, id := range td.Indexs {
+		index := Index{
+			IndexName:    id.Name,
+			ColumnName:   strings.Join(id.Columns, ","),
+			IndexType:    id.Type,
+			IndexComment: id.Comment,
+			NonUnique:    1,
+		}
+		if id.Unique {
+			index.NonUnique = 0
+		}
+		tm.Indexs = append(tm.Indexs, index)
+	}
+	return tm
+}
+
+// 字段类型仅允许类型名及可选的长度、小数位数，如：varchar(32)、decimal(10, 2)、double precision
+var columnDefinitionTypeRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9 _]*(\(\d+(,\s*\d+)?\))?$`)
+
+// 完整字段类型，如：varchar(32)、decimal(10,2)
+func (cd *ColumnDefinition) columnType() string {
+	if cd.Length <= 0 || strings.Contains(cd.Type, "(") {
+		return cd.Type
+	}
+	if cd.Scale > 0 {
+		return fmt.Sprintf("%s(%d,%d)", cd.Type, cd.Length, cd.Scale)
+	}
+	return fmt.Sprintf("%s(%d)", cd.Type, cd.Length)
+}
+
+// 获取表的主键字段，优先使用主键索引（pgsql的columnKey不能准确标识主键）
+func (tm *TableMeta) GetPrimaryKeys() []string {
+	for _, index := range tm.Indexs {
+		if isPrimaryIndex(index) {
+			return collx.ArrayMap(strings.Split(index.ColumnName, ","), strings.TrimSpace)
+		}
+	}
+	pks := make([]string, 0)
+	for _, column := range tm.Columns {
+		if column.ColumnKey == "PRI" {
+			pks = append(pks, column.ColumnName)
+		}
+	}
+	return pks
+}
+
+func genRenameColumnSql(dbType DbType, tableName, oldName, newName string) string {
+	if dbType == DbTypeMssql {
+		return fmt.Sprintf("EXEC sp_rename %s, %s, 'COLUMN'", dbType.QuoteLiteral(tableName+"."+oldName), dbType.QuoteLiteral(newName))
+	}
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", dbType.QuoteIdentifier(tableName), dbType.QuoteIdentifier(oldName), dbType.QuoteIdentifier(newName))
+}
+
+func genDropPrimaryKeySql(dbType DbType, tm *TableMeta) string {
+	tableName := tm.Table.TableName
+	switch dbType {
+	case DbTypeSqlite, DbTypeClickhouse:
+		return fmt.Sprintf("-- %s不支持修改主键, 请手动重建表[%s]", dbType, tableName)
+	case DbTypePostgres, DbTypeMssql:
+		// 需根据主键约束名删除
+		for _, index := range tm.Indexs {
+			if isPrimaryIndex(index) {
+				return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", dbType.QuoteIdentifier(tableName), dbType.QuoteIdentifier(index.IndexName))
+			}
+		}
+		return fmt.Sprintf("-- 未找到表[%s]的主键约束, 请手动删除原主键", tableName)
+	default:
+		return fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY", dbType.QuoteIdentifier(tableName))
+	}
+}
+
+func genAddPrimaryKeySql(dbType DbType, tableName string, pks []string) string {
+	if dbType == DbTypeSqlite || dbType == DbTypeClickhouse {
+		return fmt.Sprintf("-- %s不支持修改主键, 请手动重建表[%s]", dbType, tableName)
+	}
+	columns := collx.ArrayMap(pks, func(pk string) string { return dbType.QuoteIdentifier(pk) })
+	return fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s)", dbType.QuoteIdentifier(tableName), strings.Join(columns, ", "))
+}
+
+func genTableCommentSql(dbType DbType, tableName, comment string) string {
+	quoteTable := dbType.QuoteIdentifier(tableName)
+	switch {
+	case isMysqlType(dbType):
+		return fmt.Sprintf("ALTER TABLE %s COMMENT = %s", quoteTable, dbType.QuoteLiteral(comment))
+	case dbType == DbTypeClickhouse:
+		return fmt.Sprintf("ALTER TABLE %s MODIFY COMMENT %s", quoteTable, dbType.QuoteLiteral(comment))
+	case supportCommentOn(dbType):
+		return fmt.Sprintf("COMMENT ON TABLE %s IS %s", quoteTable, dbType.QuoteLiteral(comment))
+	default:
+		return fmt.Sprintf("-- %s不支持修改表备注, 请手动修改表[%s]备注为: %s", dbType, tableName, comment)
+	}
+}
@@ -0,0 +1,117 @@
+package dbi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_genTableDesignSql(t *testing.T) {
+	td := &TableDefinition{
+		TableName:    "t_user",
+		TableComment: "用户",
+		Columns: []*ColumnDefinition{
+			{Name: "id", Type: "bigint", AutoIncrement: true},
+			{Name: "name", Type: "varchar", Length: 32, Default: "abc", Comment: "名称"},
+		},
+		PrimaryKeys: []string{"id"},
+		Indexs:      []*IndexDefinition{{Name: "idx_name", Columns: []string{"name"}, Unique: true}},
+	}
+	require.NoError(t, td.validate())
+	sqls, err := genTableDesignSql(DbTypeMysql, td, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"CREATE TABLE `t_user` (\n  `id` bigint NOT NULL AUTO_INCREMENT,\n  `name` varchar(32) DEFAULT 'abc' NOT NULL COMMENT '名称',\n  PRIMARY KEY (`id`)\n) COMMENT = '用户'",
+		"CREATE UNIQUE INDEX `idx_name` ON `t_user` (`name`)",
+	}, sqls)
+
+	existMeta := &TableMeta{
+		Table: Table{TableName: "t_user"},
+		Columns: []Column{
+			{TableName: "t_user", ColumnName: "id", ColumnType: "bigint", Nullable: "NO"},
+			{TableName: "t_user", ColumnName: "name", ColumnType: "varchar(32)", Nullable: "YES"},
+			{TableName: "t_user", ColumnName: "remark", ColumnType: "varchar(255)", Nullable: "YES"},
+		},
+		Indexs: []Index{
+			{IndexName: "t_user_pkey", ColumnName: "id", NonUnique: 0},
+			{IndexName: "idx_name", ColumnName: "name", NonUnique: 1},
+		},
+	}
+	td = &TableDefinition{
+		TableName:    "t_user",
+		TableComment: "用户",
+		Columns: []*ColumnDefinition{
+			{Name: "id", Type: "bigint"},
+			{Name: "user_name", OldName: "name", Type: "varchar", Length: 64, Comment: "名称"},
+			{Name: "age", Type: "int", Default: "0", Nullable: true},
+		},
+		PrimaryKeys: []string{"id", "user_name"},
+		Indexs:      []*IndexDefinition{{Name: "idx_name", Columns: []string{"user_name"}}},
+	}
+	require.NoError(t, td.validate())
+	sqls, err = genTableDesignSql(DbTypePostgres, td, existMeta)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`ALTER TABLE "t_user" RENAME COLUMN "name" TO "user_name"`,
+		`ALTER TABLE "t_user" DROP CONSTRAINT "t_user_pkey"`,
+		`ALTER TABLE "t_user" ADD COLUMN "age" int DEFAULT 0`,
+		`ALTER TABLE "t_user" ALTER COLUMN "user_name" TYPE varchar(64)`,
+		`ALTER TABLE "t_user" ALTER COLUMN "user_name" SET NOT NULL`,
+		`COMMENT ON COLUMN "t_user"."user_name" IS '名称'`,
+		`ALTER TABLE "t_user" DROP COLUMN "remark"`,
+		`ALTER TABLE "t_user" ADD PRIMARY KEY ("id", "user_name")`,
+		`COMMENT ON TABLE "t_user" IS '用户'`,
+	}, sqls)
+
+	td.PrimaryKeys = []string{"uid"}
+	require.Error(t, td.validate())
+}
+
+func Test_TableDefinitionValidateType(t *testing.T) {
+	cases := []struct {
+		column *ColumnDefinition
+		valid  bool
+	}{
+		{&ColumnDefinition{Name: "a", Type: "varchar", Length: 32}, true},
+		{&ColumnDefinition{Name: "a", Type: "decimal(10, 2)"}, true},
+		{&ColumnDefinition{Name: "a", Type: "double precision"}, true},
+		{&ColumnDefinition{Name: "a", Type: "int; DROP TABLE t_user"}, false},
+		{&ColumnDefinition{Name: "a", Type: "varchar(32) DEFAULT 'x'"}, false},
+		{&ColumnDefinition{Name: "a", Type: "int -- "}, false},
+	}
+	for _, c := range cases {
+		td := &TableDefinition{TableName: "t_user", Columns: []*ColumnDefinition{c.column}}
+		if c.valid {
+			require.NoError(t, td.validate(), c.column.Type)
+		} else {
+			require.Error(t, td.validate(), c.column.Type)
+		}
+	}
+}
+
+func Test_formatDefaultValue(t *testing.T) {
+	cases := []struct {
+		dbType DbType
+		value  string
+		want   string
+	}{
+		{DbTypeMysql, "0", "0"},
+		{DbTypeMysql, "-1.5", "-1.5"},
+		{DbTypeMysql, "abc", "'abc'"},
+		{DbTypeMysql, "CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP"},
+		{DbTypeMysql, "current_timestamp(3)", "current_timestamp(3)"},
+		{DbTypeMysql, "now()", "now()"},
+		{DbTypeMysql, "foo()", "'foo()'"},
+		{DbTypeMysql, "'a'); DROP TABLE t_user; --", `'''a''); DROP TABLE t_user; --'`},
+		{DbTypeMysql, `'a\'`, `'''a\\'''`},
+		{DbTypeMysql, "now(); DROP TABLE t_user", "'now(); DROP TABLE t_user'"},
+		{DbTypePostgres, "nextval('t_user_id_seq'::regclass)", "nextval('t_user_id_seq'::regclass)"},
+		{DbTypePostgres, "'abc'::character varying", "'abc'::character varying"},
+		{DbTypeMssql, "((0))", "((0))"},
+		{DbTypeMssql, "(getdate())", "(getdate())"},
+		{DbTypeMssql, "(1) + (2)", "N'(1) + (2)'"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, formatDefaultValue(c.dbType, c.value), c.value)
+	}
+}
@@ -3,8 +3,10 @@ package dbm
 import (
 	"fmt"
 	"mayfly-go/internal/common/consts"
+	"mayfly-go/internal/db/dbm/clickhouse"
 	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/dbm/dm"
+	"mayfly-go/internal/db/dbm/mssql"
 	"mayfly-go/internal/db/dbm/mysql"
 	"mayfly-go/internal/db/dbm/oracle"
 	"mayfly-go/internal/db/dbm/postgres"
@@ -51,6 +53,10 @@ func getDbMetaByType(dt dbi.DbType) dbi.Meta {
 		return oracle.GetMeta()
 	case dbi.DbTypeSqlite:
 		return sqlite.GetMeta()
+	case dbi.DbTypeMssql:
+		return mssql.GetMeta()
+	case dbi.DbTypeClickhouse:
+		return clickhouse.GetMeta()
 	default:
 		panic(fmt.Sprintf("invalid database type: %s", dt))
 	}
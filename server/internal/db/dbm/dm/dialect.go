@@ -230,8 +230,17 @@ func (dd *DMDialect) GetTableDDL(tableName string) (string, error) {
 	return builder.String(), nil
 }
 
-func (dd *DMDialect) WalkTableRecord(tableName string, walkFn dbi.WalkQueryRowsFunc) error {
-	return dd.dc.WalkQueryRows(context.Background(), fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
+// 根据表结构定义生成建表或修改表的sql
+func (dd *DMDialect) GenTableDesignSql(td *dbi.TableDefinition) ([]string, error) {
+	return dbi.GenTableDesignSql(dd.dc, td)
+}
+
+func (dd *DMDialect) WalkTableRecord(ctx context.Context, tableName string, walkFn dbi.WalkQueryRowsFunc) error {
+	return dd.dc.WalkQueryRows(ctx, fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
+}
+
+func (dd *DMDialect) GetTableData(ctx context.Context, query *dbi.TableDataQuery) (*dbi.TableDataRes, error) {
+	return dbi.QueryTableData(ctx, dd.dc, query, dbi.LimitOffsetPageSql)
 }
 
 // 获取DM当前连接的库可访问的schemaNames
@@ -250,7 +259,7 @@ func (dd *DMDialect) GetSchemas() ([]string, error) {
 
 // GetDbProgram 获取数据库程序模块，用于数据库备份与恢复
 func (dd *DMDialect) GetDbProgram() dbi.DbProgram {
-	panic("implement me")
+	return dbi.NewDbProgramLogical(dd.dc)
 }
 
 func (dd *DMDialect) GetDataType(dbColumnType string) dbi.DataType {
@@ -272,7 +281,7 @@ func (dd *DMDialect) GetDataType(dbColumnType string) dbi.DataType {
 	return dbi.DataTypeString
 }
 
-func (dd *DMDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any) (int64, error) {
+func (dd *DMDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any, duplicateStrategy dbi.DuplicateStrategy, keyColumns ...string) (int64, error) {
 	// 执行批量insert sql
 	// insert into "table_name" ("column1", "column2", ...) values (value1, value2, ...)
 
@@ -282,7 +291,18 @@ func (dd *DMDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string,
 	// 去除最后一个逗号，占位符由括号包裹
 	placeholder := fmt.Sprintf("(%s)", strings.TrimSuffix(repeated, ","))
 
-	sqlTemp := fmt.Sprintf("insert into %s (%s) values %s", dd.dc.Info.Type.QuoteIdentifier(tableName), strings.Join(columns, ","), placeholder)
+	quoteTableName := dd.dc.Info.Type.QuoteIdentifier(tableName)
+	sqlTemp := fmt.Sprintf("insert into %s (%s) values %s", quoteTableName, strings.Join(columns, ","), placeholder)
+	// 数据重复时通过 MERGE 语句忽略或更新
+	if duplicateStrategy.NeedHandle() {
+		var err error
+		keyColumns, err = dbi.GetDuplicateKeyColumns(dd, dd.dc.Info.Type, tableName, keyColumns)
+		if err != nil {
+			return 0, err
+		}
+		placeholders := strings.Split(strings.Repeat("?", len(columns)), "")
+		sqlTemp = dbi.GenMergeSql(duplicateStrategy, quoteTableName, columns, keyColumns, placeholders)
+	}
 	effRows := 0
 	for _, value := range values {
 		// 达梦数据库只能一条条的执行insert
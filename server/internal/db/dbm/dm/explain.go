@@ -0,0 +1,88 @@
+package dm
+
+import (
+	"context"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 执行计划行，如：#CSCN2: [1, 100, 30]; INDEX33555496(T1)，中括号内依次为成本、预估行数、行宽
+var dmExplainLineRegexp = regexp.MustCompile(`#(\w+):\s*\[(\d+),\s*(\d+),\s*(\d+)\]\s*;?\s*(.*)$`)
+
+// 扫描、检索类操作，如 CSCN2、SSEK2、BLKUP2，其操作对象为 INDEX33555496(T1)，括号外为索引名，括号内为表名
+var dmExplainScanOpRegexp = regexp.MustCompile(`^(CSCN|CSEK|SSCN|SSEK|BLKUP)`)
+var dmExplainObjectRegexp = regexp.MustCompile(`^(\w+)\((\w+)\)`)
+
+// DM执行计划中的全表扫描操作（聚集索引全扫描）
+const dmFullScanOp = "CSCN2"
+
+func (dd *DMDialect) Explain(ctx context.Context, sql string) (*dbi.ExplainNode, error) {
+	sql, err := dbi.CheckExplainSql(dd.dc.Info.Type, sql)
+	if err != nil {
+		return nil, err
+	}
+	_, res, err := dd.dc.QueryContext(ctx, "EXPLAIN "+sql)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, 0)
+	for _, re := range res {
+		for _, v := range re {
+			lines = append(lines, strings.Split(anyx.ToString(v), "\n")...)
+		}
+	}
+	rows := parseDmExplain(lines)
+	if len(rows) == 0 {
+		return nil, errorx.NewBiz("未获取到执行计划")
+	}
+	return dbi.BuildExplainTree(rows), nil
+}
+
+// parseDmExplain 解析 EXPLAIN 返回的文本执行计划，根据操作符前的缩进确定节点层级
+func parseDmExplain(lines []string) []dbi.ExplainRow {
+	rows := make([]dbi.ExplainRow, 0)
+	// 各层级的缩进及对应节点id
+	indents := make([]int, 0)
+	ids := make([]int64, 0)
+	for _, line := range lines {
+		match := dmExplainLineRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		indent := strings.Index(line, "#")
+		for len(indents) > 0 && indents[len(indents)-1] >= indent {
+			indents = indents[:len(indents)-1]
+			ids = ids[:len(ids)-1]
+		}
+
+		cost, _ := strconv.ParseFloat(match[2], 64)
+		estimatedRows, _ := strconv.ParseInt(match[3], 10, 64)
+		node := &dbi.ExplainNode{
+			NodeType: match[1],
+			Rows:     estimatedRows,
+			Cost:     cost,
+			FullScan: match[1] == dmFullScanOp,
+			Extra:    strings.TrimSpace(match[5]),
+		}
+		if dmExplainScanOpRegexp.MatchString(node.NodeType) {
+			if object := dmExplainObjectRegexp.FindStringSubmatch(node.Extra); object != nil {
+				node.Index = object[1]
+				node.Table = object[2]
+			}
+		}
+
+		id := int64(len(rows) + 1)
+		var parentId int64
+		if len(ids) > 0 {
+			parentId = ids[len(ids)-1]
+		}
+		rows = append(rows, dbi.ExplainRow{Id: id, ParentId: parentId, Node: node})
+		indents = append(indents, indent)
+		ids = append(ids, id)
+	}
+	return rows
+}
@@ -0,0 +1,434 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	MSSQL_META_FILE      = "metasql/mssql_meta.sql"
+	MSSQL_DB_SCHEMAS     = "MSSQL_DB_SCHEMAS"
+	MSSQL_TABLE_INFO_KEY = "MSSQL_TABLE_INFO"
+	MSSQL_INDEX_INFO_KEY = "MSSQL_INDEX_INFO"
+	MSSQL_COLUMN_MA_KEY  = "MSSQL_COLUMN_MA"
+)
+
+// sql server单条语句最多2100个参数，单个VALUES最多1000行
+const (
+	mssqlMaxParams     = 2000
+	mssqlMaxInsertRows = 1000
+)
+
+type MssqlDialect struct {
+	dc *dbi.DbConn
+}
+
+func (md *MssqlDialect) GetDbServer() (*dbi.DbServer, error) {
+	_, res, err := md.dc.Query("SELECT CAST(SERVERPROPERTY('ProductVersion') AS VARCHAR(128)) AS version, CAST(SERVERPROPERTY('Edition') AS VARCHAR(128)) AS edition")
+	if err != nil {
+		return nil, err
+	}
+	ds := &dbi.DbServer{
+		Version: anyx.ConvString(res[0]["version"]),
+		Extra:   collx.M{"edition": anyx.ConvString(res[0]["edition"])},
+	}
+	return ds, nil
+}
+
+func (md *MssqlDialect) GetDbNames() ([]string, error) {
+	_, res, err := md.dc.Query("SELECT name AS dbname FROM sys.databases WHERE HAS_DBACCESS(name) = 1 ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+
+	databases := make([]string, 0)
+	for _, re := range res {
+		databases = append(databases, anyx.ConvString(re["dbname"]))
+	}
+	return databases, nil
+}
+
+// 获取表基础元信息, 如表名等
+func (md *MssqlDialect) GetTables() ([]dbi.Table, error) {
+	_, res, err := md.dc.Query(dbi.GetLocalSql(MSSQL_META_FILE, MSSQL_TABLE_INFO_KEY))
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]dbi.Table, 0)
+	for _, re := range res {
+		tables = append(tables, dbi.Table{
+			TableName:    anyx.ConvString(re["tableName"]),
+			TableComment: anyx.ConvString(re["tableComment"]),
+			CreateTime:   anyx.ConvString(re["createTime"]),
+			TableRows:    anyx.ConvInt(re["tableRows"]),
+			DataLength:   anyx.ConvInt64(re["dataLength"]),
+			IndexLength:  anyx.ConvInt64(re["indexLength"]),
+		})
+	}
+	return tables, nil
+}
+
+// 获取列元信息, 如列名等
+func (md *MssqlDialect) GetColumns(tableNames ...string) ([]dbi.Column, error) {
+	dbType := md.dc.Info.Type
+	tableName := strings.Join(collx.ArrayMap[string, string](tableNames, func(val string) string {
+		return dbType.QuoteLiteral(dbType.RemoveQuote(val))
+	}), ",")
+
+	_, res, err := md.dc.Query(fmt.Sprintf(dbi.GetLocalSql(MSSQL_META_FILE, MSSQL_COLUMN_MA_KEY), tableName))
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]dbi.Column, 0)
+	for _, re := range res {
+		column := dbi.Column{
+			TableName:     anyx.ConvString(re["tableName"]),
+			ColumnName:    anyx.ConvString(re["columnName"]),
+			ColumnType:    anyx.ConvString(re["columnType"]),
+			ColumnComment: anyx.ConvString(re["columnComment"]),
+			Nullable:      anyx.ConvString(re["nullable"]),
+			ColumnKey:     anyx.ConvString(re["columnKey"]),
+			ColumnDefault: trimDefaultParens(anyx.ConvString(re["columnDefault"])),
+			NumScale:      anyx.ConvString(re["numScale"]),
+		}
+		if identity := anyx.ConvString(re["identityDef"]); identity != "" {
+			column.Extra = collx.M{"identity": identity}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// 去除默认值定义外层的括号，如：((0)) -> 0, (N'abc') -> N'abc', (getdate()) -> getdate()
+func trimDefaultParens(value string) string {
+	for len(value) >= 2 && value[0] == '(' && value[len(value)-1] == ')' {
+		// 确认首尾括号为一对，如 (a)+(b) 则不能去除
+		depth := 0
+		paired := true
+		for i, c := range value {
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				depth--
+			}
+			if depth == 0 && i < len(value)-1 {
+				paired = false
+				break
+			}
+		}
+		if !paired {
+			break
+		}
+		value = value[1 : len(value)-1]
+	}
+	return value
+}
+
+func (md *MssqlDialect) GetPrimaryKey(tablename string) (string, error) {
+	columns, err := md.GetColumns(tablename)
+	if err != nil {
+		return "", err
+	}
+	if len(columns) == 0 {
+		return "", errorx.NewBiz("[%s] 表不存在", tablename)
+	}
+	for _, v := range columns {
+		if v.ColumnKey == "PRI" {
+			return v.ColumnName, nil
+		}
+	}
+
+	return columns[0].ColumnName, nil
+}
+
+// 获取表索引信息
+func (md *MssqlDialect) GetTableIndex(tableName string) ([]dbi.Index, error) {
+	indexs, _, err := md.getTableIndex(tableName)
+	return indexs, err
+}
+
+// 获取表索引信息，同时返回主键索引名
+func (md *MssqlDialect) getTableIndex(tableName string) ([]dbi.Index, string, error) {
+	_, res, err := md.dc.Query(fmt.Sprintf(dbi.GetLocalSql(MSSQL_META_FILE, MSSQL_INDEX_INFO_KEY), strings.ReplaceAll(tableName, "'", "''")))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 把查询结果以索引名分组，索引字段以逗号连接，索引字段已根据名称和顺序排序
+	result := make([]dbi.Index, 0)
+	var pkName string
+	for _, re := range res {
+		indexName := anyx.ConvString(re["indexName"])
+		if anyx.ConvInt(re["isPrimaryKey"]) == 1 {
+			pkName = indexName
+		}
+		columnName := anyx.ConvString(re["columnName"])
+		if i := len(result) - 1; i >= 0 && result[i].IndexName == indexName {
+			result[i].ColumnName = result[i].ColumnName + "," + columnName
+			continue
+		}
+		result = append(result, dbi.Index{
+			IndexName:    indexName,
+			ColumnName:   columnName,
+			IndexType:    anyx.ConvString(re["indexType"]),
+			IndexComment: anyx.ConvString(re["indexComment"]),
+			NonUnique:    anyx.ConvInt(re["nonUnique"]),
+			SeqInIndex:   anyx.ConvInt(re["seqInIndex"]),
+		})
+	}
+	return result, pkName, nil
+}
+
+// 获取建表ddl，sql server没有直接获取ddl的语句，根据列、索引及注释信息拼接
+func (md *MssqlDialect) GetTableDDL(tableName string) (string, error) {
+	dbType := md.dc.Info.Type
+	columns, err := md.GetColumns(tableName)
+	if err != nil {
+		return "", err
+	}
+	if len(columns) == 0 {
+		return "", errorx.NewBiz("[%s] 表不存在", tableName)
+	}
+	indexs, pkName, err := md.getTableIndex(tableName)
+	if err != nil {
+		return "", err
+	}
+	// 表及字段注释使用扩展属性 MS_Description 保存
+	_, tableRes, err := md.dc.Query(fmt.Sprintf(`SELECT SCHEMA_NAME() AS schemaName, (SELECT CAST(value AS NVARCHAR(4000)) FROM sys.extended_properties
+		WHERE major_id = OBJECT_ID(QUOTENAME(SCHEMA_NAME()) + '.' + QUOTENAME(%s)) AND minor_id = 0 AND class = 1 AND name = 'MS_Description') AS tableComment`, dbType.QuoteLiteral(tableName)))
+	if err != nil {
+		return "", err
+	}
+	schemaName := anyx.ConvString(tableRes[0]["schemaName"])
+	tableComment := anyx.ConvString(tableRes[0]["tableComment"])
+	quoteTable := dbType.QuoteIdentifier(tableName)
+
+	lines := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		definition := fmt.Sprintf("  %s %s", dbType.QuoteIdentifier(column.ColumnName), column.ColumnType)
+		if identity := anyx.ConvString(column.Extra["identity"]); identity != "" {
+			definition += " " + identity
+		}
+		if column.ColumnDefault != "" {
+			definition += " DEFAULT " + column.ColumnDefault
+		}
+		if column.Nullable == "NO" {
+			definition += " NOT NULL"
+		} else {
+			definition += " NULL"
+		}
+		lines = append(lines, definition)
+	}
+	for _, index := range indexs {
+		if index.IndexName == pkName {
+			lines = append(lines, fmt.Sprintf("  CONSTRAINT %s PRIMARY KEY (%s)", dbType.QuoteIdentifier(pkName), quoteColumnNames(dbType, index.ColumnName)))
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("CREATE TABLE %s (\n%s\n);", quoteTable, strings.Join(lines, ",\n")))
+
+	for _, index := range indexs {
+		if index.IndexName == pkName {
+			continue
+		}
+		unique := ""
+		if index.NonUnique == 0 {
+			unique = "UNIQUE "
+		}
+		builder.WriteString(fmt.Sprintf("\nCREATE %sINDEX %s ON %s (%s);", unique, dbType.QuoteIdentifier(index.IndexName), quoteTable, quoteColumnNames(dbType, index.ColumnName)))
+	}
+
+	if tableComment != "" {
+		builder.WriteString(fmt.Sprintf("\nEXEC sp_addextendedproperty 'MS_Description', %s, 'SCHEMA', %s, 'TABLE', %s;",
+			dbType.QuoteLiteral(tableComment), dbType.QuoteLiteral(schemaName), dbType.QuoteLiteral(tableName)))
+	}
+	for _, column := range columns {
+		if column.ColumnComment != "" {
+			builder.WriteString(fmt.Sprintf("\nEXEC sp_addextendedproperty 'MS_Description', %s, 'SCHEMA', %s, 'TABLE', %s, 'COLUMN', %s;",
+				dbType.QuoteLiteral(column.ColumnComment), dbType.QuoteLiteral(schemaName), dbType.QuoteLiteral(tableName), dbType.QuoteLiteral(column.ColumnName)))
+		}
+	}
+	return builder.String(), nil
+}
+
+// 将逗号连接的列名逐个quote
+func quoteColumnNames(dbType dbi.DbType, columnNames string) string {
+	return strings.Join(collx.ArrayMap(strings.Split(columnNames, ","), func(c string) string {
+		return dbType.QuoteIdentifier(strings.TrimSpace(c))
+	}), ", ")
+}
+
+// 根据表结构定义生成建表或修改表的sql
+func (md *MssqlDialect) GenTableDesignSql(td *dbi.TableDefinition) ([]string, error) {
+	return dbi.GenTableDesignSql(md.dc, td)
+}
+
+func (md *MssqlDialect) WalkTableRecord(ctx context.Context, tableName string, walkFn dbi.WalkQueryRowsFunc) error {
+	return md.dc.WalkQueryRows(ctx, fmt.Sprintf("SELECT * FROM %s", md.dc.Info.Type.QuoteIdentifier(tableName)), walkFn)
+}
+
+func (md *MssqlDialect) GetTableData(ctx context.Context, query *dbi.TableDataQuery) (*dbi.TableDataRes, error) {
+	return dbi.QueryTableData(ctx, md.dc, query, mssqlPageSql)
+}
+
+// sql server分页，首页使用TOP，其余使用 OFFSET FETCH（sql server 2012及以上），OFFSET FETCH必须指定ORDER BY
+func mssqlPageSql(selectSql string, offset, limit int) string {
+	if offset == 0 {
+		return fmt.Sprintf("SELECT TOP %d %s", limit, strings.TrimPrefix(selectSql, "SELECT "))
+	}
+	if !strings.Contains(selectSql, " ORDER BY ") {
+		selectSql += " ORDER BY (SELECT NULL)"
+	}
+	return fmt.Sprintf("%s OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", selectSql, offset, limit)
+}
+
+// 获取当前连接的库可访问的schemaNames
+func (md *MssqlDialect) GetSchemas() ([]string, error) {
+	_, res, err := md.dc.Query(dbi.GetLocalSql(MSSQL_META_FILE, MSSQL_DB_SCHEMAS))
+	if err != nil {
+		return nil, err
+	}
+	schemaNames := make([]string, 0)
+	for _, re := range res {
+		schemaNames = append(schemaNames, anyx.ConvString(re["schemaName"]))
+	}
+	return schemaNames, nil
+}
+
+// GetDbProgram 获取数据库程序模块，用于数据库备份与恢复
+func (md *MssqlDialect) GetDbProgram() dbi.DbProgram {
+	return dbi.NewDbProgramLogical(md.dc)
+}
+
+func (md *MssqlDialect) GetDataType(dbColumnType string) dbi.DataType {
+	if regexp.MustCompile(`(?i)int|float|real|numeric|decimal|money|bit`).MatchString(dbColumnType) {
+		return dbi.DataTypeNumber
+	}
+	// 日期时间类型
+	if regexp.MustCompile(`(?i)datetime|smalldatetime`).MatchString(dbColumnType) {
+		return dbi.DataTypeDateTime
+	}
+	// 日期类型
+	if regexp.MustCompile(`(?i)date`).MatchString(dbColumnType) {
+		return dbi.DataTypeDate
+	}
+	// 时间类型，timestamp为行版本号，不属于时间类型
+	if regexp.MustCompile(`(?i)^time`).MatchString(dbColumnType) && !strings.EqualFold(dbColumnType, "timestamp") {
+		return dbi.DataTypeTime
+	}
+	return dbi.DataTypeString
+}
+
+func (md *MssqlDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any, duplicateStrategy dbi.DuplicateStrategy, keyColumns ...string) (int64, error) {
+	if len(values) <= 0 {
+		return 0, nil
+	}
+	dbType := md.dc.Info.Type
+	quoteTableName := dbType.QuoteIdentifier(tableName)
+
+	// 插入自增列的值需开启 IDENTITY_INSERT，该设置只对当前会话生效
+	identityInsert, err := md.hasIdentityColumn(tableName, columns)
+	if err != nil {
+		return 0, err
+	}
+	if identityInsert {
+		if _, err := md.dc.TxExec(tx, fmt.Sprintf("SET IDENTITY_INSERT %s ON", quoteTableName)); err != nil {
+			return 0, err
+		}
+		defer md.dc.TxExec(tx, fmt.Sprintf("SET IDENTITY_INSERT %s OFF", quoteTableName))
+	}
+
+	// 数据重复时通过 MERGE 语句逐条忽略或更新
+	if duplicateStrategy.NeedHandle() {
+		keyColumns, err = dbi.GetDuplicateKeyColumns(md, dbType, tableName, keyColumns)
+		if err != nil {
+			return 0, err
+		}
+		effRows := int64(0)
+		for _, value := range values {
+			placeholders := make([]string, 0, len(columns))
+			for i := range value {
+				placeholders = append(placeholders, fmt.Sprintf("@p%d", i+1))
+			}
+			res, err := md.dc.TxExec(tx, dbi.GenMssqlMergeSql(duplicateStrategy, quoteTableName, columns, keyColumns, placeholders), value...)
+			if err != nil {
+				return effRows, err
+			}
+			effRows += res
+		}
+		return effRows, nil
+	}
+
+	// 受参数个数及VALUES行数限制，分批插入
+	batchSize := min(mssqlMaxInsertRows, max(mssqlMaxParams/len(columns), 1))
+	effRows := int64(0)
+	for start := 0; start < len(values); start += batchSize {
+		batchValues := values[start:min(start+batchSize, len(values))]
+
+		// 构建占位符字符串 "(@p1, @p2, @p3), (@p4, @p5, @p6), ..." 用于指定参数
+		args := make([]any, 0, len(batchValues)*len(columns))
+		placeholders := make([]string, 0, len(batchValues))
+		for _, value := range batchValues {
+			placeholder := make([]string, 0, len(value))
+			for _, v := range value {
+				args = append(args, v)
+				placeholder = append(placeholder, fmt.Sprintf("@p%d", len(args)))
+			}
+			placeholders = append(placeholders, "("+strings.Join(placeholder, ", ")+")")
+		}
+
+		sqlStr := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", quoteTableName, strings.Join(columns, ","), strings.Join(placeholders, ", "))
+		res, err := md.dc.TxExec(tx, sqlStr, args...)
+		if err != nil {
+			return effRows, err
+		}
+		effRows += res
+	}
+	return effRows, nil
+}
+
+// 插入的列中是否包含自增列，columns为已quote的字段名
+func (md *MssqlDialect) hasIdentityColumn(tableName string, columns []string) (bool, error) {
+	tableColumns, err := md.GetColumns(tableName)
+	if err != nil {
+		return false, err
+	}
+	dbType := md.dc.Info.Type
+	for _, column := range tableColumns {
+		if anyx.ConvString(column.Extra["identity"]) == "" {
+			continue
+		}
+		for _, c := range columns {
+			if strings.EqualFold(dbType.RemoveQuote(c), column.ColumnName) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (md *MssqlDialect) FormatStrData(dbColumnValue string, dataType dbi.DataType) string {
+	switch dataType {
+	case dbi.DataTypeDateTime: // "2024-01-02T22:16:28.547Z"
+		res, _ := time.Parse(time.RFC3339, dbColumnValue)
+		return res.Format(time.DateTime)
+	case dbi.DataTypeDate: // "2024-01-02T00:00:00Z"
+		res, _ := time.Parse(time.RFC3339, dbColumnValue)
+		return res.Format(time.DateOnly)
+	case dbi.DataTypeTime: // "0001-01-01T22:16:28.5475Z"
+		res, _ := time.Parse(time.RFC3339, dbColumnValue)
+		return res.Format(time.TimeOnly)
+	}
+	return dbColumnValue
+}
@@ -0,0 +1,34 @@
+package mssql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_trimDefaultParens(t *testing.T) {
+	require.Equal(t, "0", trimDefaultParens("((0))"))
+	require.Equal(t, "N'abc'", trimDefaultParens("(N'abc')"))
+	require.Equal(t, "getdate()", trimDefaultParens("(getdate())"))
+	require.Equal(t, "(1)+(2)", trimDefaultParens("((1)+(2))"))
+	require.Equal(t, "", trimDefaultParens(""))
+}
+
+func Test_mssqlPageSql(t *testing.T) {
+	require.Equal(t, "SELECT TOP 10 * FROM [t] ORDER BY [id]", mssqlPageSql("SELECT * FROM [t] ORDER BY [id]", 0, 10))
+	require.Equal(t, "SELECT * FROM [t] ORDER BY [id] OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", mssqlPageSql("SELECT * FROM [t] ORDER BY [id]", 20, 10))
+	require.Equal(t, "SELECT * FROM [t] ORDER BY (SELECT NULL) OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", mssqlPageSql("SELECT * FROM [t]", 20, 10))
+}
+
+func Test_mssqlExplainNode(t *testing.T) {
+	node := mssqlExplainNode(map[string]any{
+		"PhysicalOp":       "Clustered Index Scan",
+		"Argument":         "OBJECT:([test].[dbo].[t_user].[PK_t_user]), WHERE:([test].[dbo].[t_user].[name]=N'a')",
+		"EstimateRows":     float64(12.5),
+		"TotalSubtreeCost": float64(0.0032),
+	})
+	require.Equal(t, "t_user", node.Table)
+	require.Equal(t, "PK_t_user", node.Index)
+	require.Equal(t, int64(12), node.Rows)
+	require.True(t, node.FullScan)
+}
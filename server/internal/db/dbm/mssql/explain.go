@@ -0,0 +1,79 @@
+package mssql
+
+import (
+	"context"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"regexp"
+	"strconv"
+)
+
+// 操作对象，如：OBJECT:([db].[dbo].[t_user].[PK_t_user])、OBJECT:([db].[dbo].[t_user])
+var mssqlExplainObjectRegexp = regexp.MustCompile(`OBJECT:\(\[[^\]]*\]\.\[[^\]]*\]\.\[([^\]]+)\](?:\.\[([^\]]+)\])?`)
+
+// 全表扫描操作（堆表扫描及聚集索引全扫描）
+var mssqlFullScanOps = []string{"Table Scan", "Clustered Index Scan"}
+
+func (md *MssqlDialect) Explain(ctx context.Context, sql string) (*dbi.ExplainNode, error) {
+	sql, err := dbi.CheckExplainSql(md.dc.Info.Type, sql)
+	if err != nil {
+		return nil, err
+	}
+	// SHOWPLAN_ALL只对当前会话生效，且需单独执行，故在独占连接上开启后再获取执行计划
+	ctx, _, release, err := md.dc.BindConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if _, err := md.dc.ExecContext(ctx, "SET SHOWPLAN_ALL ON"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// 关闭失败的连接仍处于SHOWPLAN_ALL模式，放回连接池后执行的sql都只会返回执行计划，故直接丢弃该连接
+		if _, err := md.dc.ExecContext(context.WithoutCancel(ctx), "SET SHOWPLAN_ALL OFF"); err != nil {
+			logx.Warnf("关闭SHOWPLAN_ALL失败, 丢弃该连接: %s", err.Error())
+			md.dc.DiscardBindConn(ctx)
+		}
+	}()
+
+	_, res, err := md.dc.QueryContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]dbi.ExplainRow, 0, len(res))
+	for _, re := range res {
+		// 语句行的Type为SELECT等语句类型，操作行为PLAN_ROW
+		if anyx.ConvString(re["Type"]) != "PLAN_ROW" {
+			continue
+		}
+		rows = append(rows, dbi.ExplainRow{
+			Id:       anyx.ConvInt64(re["NodeId"]),
+			ParentId: anyx.ConvInt64(re["Parent"]),
+			Node:     mssqlExplainNode(re),
+		})
+	}
+	if len(rows) == 0 {
+		return nil, errorx.NewBiz("未获取到执行计划")
+	}
+	return dbi.BuildExplainTree(rows), nil
+}
+
+func mssqlExplainNode(re map[string]any) *dbi.ExplainNode {
+	argument := anyx.ConvString(re["Argument"])
+	node := &dbi.ExplainNode{
+		NodeType: anyx.ConvString(re["PhysicalOp"]),
+		Extra:    argument,
+	}
+	estimateRows, _ := strconv.ParseFloat(anyx.ToString(re["EstimateRows"]), 64)
+	node.Rows = int64(estimateRows)
+	node.Cost, _ = strconv.ParseFloat(anyx.ToString(re["TotalSubtreeCost"]), 64)
+	if match := mssqlExplainObjectRegexp.FindStringSubmatch(argument); match != nil {
+		node.Table = match[1]
+		node.Index = match[2]
+	}
+	node.FullScan = collx.ArrayContains(mssqlFullScanOps, node.NodeType)
+	return node
+}
@@ -0,0 +1,96 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/utils/netx"
+	"net"
+	"net/url"
+	"sync"
+
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+var (
+	meta dbi.Meta
+	once sync.Once
+)
+
+func GetMeta() dbi.Meta {
+	once.Do(func() {
+		meta = new(MssqlMeta)
+	})
+	return meta
+}
+
+type MssqlMeta struct {
+}
+
+func (md *MssqlMeta) GetSqlDb(d *dbi.DbInfo) (*sql.DB, error) {
+	query := url.Values{}
+	query.Set("encrypt", "disable")
+	query.Set("connection timeout", "8")
+	if d.Database != "" {
+		query.Set("database", d.Database)
+	}
+	// 存在额外指定参数，则覆盖默认参数
+	if d.Params != "" {
+		params, err := url.ParseQuery(d.Params)
+		if err != nil {
+			return nil, fmt.Errorf("连接参数格式错误: %s", err.Error())
+		}
+		for k, v := range params {
+			// 已指定库则以指定的库为准
+			if k == "database" && d.Database != "" {
+				continue
+			}
+			query[k] = v
+		}
+	}
+	dsn := &url.URL{
+		Scheme:   "sqlserver",
+		User:     url.UserPassword(d.Username, d.Password),
+		Host:     fmt.Sprintf("%s:%d", d.Host, d.Port),
+		RawQuery: query.Encode(),
+	}
+
+	connector, err := mssql.NewConnector(dsn.String())
+	if err != nil {
+		return nil, err
+	}
+	// SSH Conect
+	if d.SshTunnelMachineId > 0 {
+		connector.Dialer = &MssqlSshDialer{sshTunnelMachineId: d.SshTunnelMachineId, host: d.Host}
+	}
+	return sql.OpenDB(connector), nil
+}
+
+func (md *MssqlMeta) GetDialect(conn *dbi.DbConn) dbi.Dialect {
+	return &MssqlDialect{conn}
+}
+
+// 通过ssh隧道连接sql server
+type MssqlSshDialer struct {
+	sshTunnelMachineId int
+	host               string
+}
+
+func (sd *MssqlSshDialer) DialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	sshTunnel, err := dbi.GetSshTunnel(sd.sshTunnelMachineId)
+	if err != nil {
+		return nil, err
+	}
+	sshConn, err := sshTunnel.GetDialConn("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	// 将ssh conn包装，否则会返回错误: ssh: tcpChan: deadline not supported
+	return &netx.WrapSshConn{Conn: sshConn}, nil
+}
+
+// HostName 实现mssql.HostDialer，使驱动直接使用host:port拨号，而非先在本地解析域名（ssh隧道时host可能仅在远端可解析）
+func (sd *MssqlSshDialer) HostName() string {
+	return sd.host
+}
@@ -93,6 +93,7 @@ func (md *MysqlDialect) GetColumns(tableNames ...string) ([]dbi.Column, error) {
 			ColumnKey:     anyx.ConvString(re["columnKey"]),
 			ColumnDefault: anyx.ConvString(re["columnDefault"]),
 			NumScale:      anyx.ConvString(re["numScale"]),
+			Extra:         collx.M{"extra": anyx.ConvString(re["extra"])},
 		})
 	}
 	return columns, nil
@@ -163,8 +164,17 @@ func (md *MysqlDialect) GetTableDDL(tableName string) (string, error) {
 	return anyx.ConvString(res[0]["Create Table"]) + ";", nil
 }
 
-func (md *MysqlDialect) WalkTableRecord(tableName string, walkFn dbi.WalkQueryRowsFunc) error {
-	return md.dc.WalkQueryRows(context.Background(), fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
+// 根据表结构定义生成建表或修改表的sql
+func (md *MysqlDialect) GenTableDesignSql(td *dbi.TableDefinition) ([]string, error) {
+	return dbi.GenTableDesignSql(md.dc, td)
+}
+
+func (md *MysqlDialect) WalkTableRecord(ctx context.Context, tableName string, walkFn dbi.WalkQueryRowsFunc) error {
+	return md.dc.WalkQueryRows(ctx, fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
+}
+
+func (md *MysqlDialect) GetTableData(ctx context.Context, query *dbi.TableDataQuery) (*dbi.TableDataRes, error) {
+	return dbi.QueryTableData(ctx, md.dc, query, dbi.LimitOffsetPageSql)
 }
 
 func (md *MysqlDialect) GetSchemas() ([]string, error) {
@@ -195,7 +205,7 @@ func (md *MysqlDialect) GetDataType(dbColumnType string) dbi.DataType {
 	return dbi.DataTypeString
 }
 
-func (md *MysqlDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any) (int64, error) {
+func (md *MysqlDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any, duplicateStrategy dbi.DuplicateStrategy, keyColumns ...string) (int64, error) {
 	// 生成占位符字符串：如：(?,?)
 	// 重复字符串并用逗号连接
 	repeated := strings.Repeat("?,", len(columns))
@@ -210,7 +220,27 @@ func (md *MysqlDialect) BatchInsert(tx *sql.Tx, tableName string, columns []stri
 	// 去除最后一个逗号
 	placeholder = strings.TrimSuffix(repeated, ",")
 
-	sqlStr := fmt.Sprintf("insert into %s (%s) values %s", md.dc.Info.Type.QuoteIdentifier(tableName), strings.Join(columns, ","), placeholder)
+	insertKeyword := "insert"
+	duplicateSql := ""
+	switch duplicateStrategy {
+	case dbi.DuplicateStrategyIgnore:
+		insertKeyword = "insert ignore"
+	case dbi.DuplicateStrategyUpdate:
+		// mysql根据主键及唯一索引判断数据是否重复，重复则更新非keyColumns字段
+		updates := make([]string, 0)
+		for _, column := range columns {
+			if !collx.ArrayContains(keyColumns, column) {
+				updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", column, column))
+			}
+		}
+		if len(updates) > 0 {
+			duplicateSql = " on duplicate key update " + strings.Join(updates, ", ")
+		} else {
+			insertKeyword = "insert ignore"
+		}
+	}
+
+	sqlStr := fmt.Sprintf("%s into %s (%s) values %s%s", insertKeyword, md.dc.Info.Type.QuoteIdentifier(tableName), strings.Join(columns, ","), placeholder, duplicateSql)
 	// 执行批量insert sql
 	// 把二维数组转为一维数组
 	var args []any
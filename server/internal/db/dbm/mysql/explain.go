@@ -0,0 +1,123 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"sort"
+	"strconv"
+)
+
+// 执行计划json中非计划结构的对象或数组字段
+var mysqlExplainIgnoreKeys = map[string]bool{
+	"cost_info":       true,
+	"used_columns":    true,
+	"possible_keys":   true,
+	"used_key_parts":  true,
+	"ref":             true,
+	"partitions":      true,
+	"r_loops":         true,
+	"r_total_time_ms": true,
+}
+
+func (md *MysqlDialect) Explain(ctx context.Context, sql string) (*dbi.ExplainNode, error) {
+	sql, err := dbi.CheckExplainSql(md.dc.Info.Type, sql)
+	if err != nil {
+		return nil, err
+	}
+	_, res, err := md.dc.QueryContext(ctx, "EXPLAIN FORMAT=JSON "+sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, errorx.NewBiz("未获取到执行计划")
+	}
+	for _, v := range res[0] {
+		return parseMysqlExplain(anyx.ToString(v))
+	}
+	return nil, errorx.NewBiz("未获取到执行计划")
+}
+
+// parseMysqlExplain 解析 EXPLAIN FORMAT=JSON 的结果（兼容mariadb），query_block、nested_loop等结构作为节点，table作为叶子节点
+func parseMysqlExplain(planJson string) (*dbi.ExplainNode, error) {
+	var plan map[string]any
+	if err := json.Unmarshal([]byte(planJson), &plan); err != nil {
+		return nil, errorx.NewBiz("解析执行计划失败: %s", err.Error())
+	}
+	return dbi.NewExplainRoot(mysqlExplainNodes(plan)), nil
+}
+
+// 获取对象或数组中的所有执行计划节点
+func mysqlExplainNodes(value any) []*dbi.ExplainNode {
+	var nodes []*dbi.ExplainNode
+	switch value := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if mysqlExplainIgnoreKeys[key] {
+				continue
+			}
+			switch v := value[key].(type) {
+			case map[string]any:
+				nodes = append(nodes, mysqlExplainNode(key, v))
+			case []any:
+				// 如 nested_loop、query_specifications，数组元素为同级节点
+				node := &dbi.ExplainNode{NodeType: key, Children: mysqlExplainNodes(v)}
+				if len(node.Children) > 0 {
+					nodes = append(nodes, node)
+				}
+			}
+		}
+	case []any:
+		for _, v := range value {
+			nodes = append(nodes, mysqlExplainNodes(v)...)
+		}
+	}
+	return nodes
+}
+
+func mysqlExplainNode(key string, value map[string]any) *dbi.ExplainNode {
+	node := &dbi.ExplainNode{NodeType: key}
+	costInfo, _ := value["cost_info"].(map[string]any)
+	if key == "table" {
+		node.NodeType = anyx.ToString(value["access_type"])
+		node.Table = anyx.ToString(value["table_name"])
+		node.Index = anyx.ToString(value["key"])
+		node.FullScan = node.NodeType == "ALL"
+		node.Extra = anyx.ToString(value["attached_condition"])
+		if rows, ok := value["rows_examined_per_scan"]; ok {
+			node.Rows = toInt64(rows)
+		} else {
+			node.Rows = toInt64(value["rows"])
+		}
+		if costInfo != nil {
+			node.Cost = toFloat64(costInfo["prefix_cost"])
+		}
+	} else if costInfo != nil {
+		node.Cost = toFloat64(costInfo["query_cost"])
+	}
+	node.Children = mysqlExplainNodes(value)
+	return node
+}
+
+// json中的数值可能为数字或字符串，如 "query_cost": "1.20"
+func toFloat64(value any) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
+
+func toInt64(value any) int64 {
+	return int64(toFloat64(value))
+}
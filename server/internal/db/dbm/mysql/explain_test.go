@@ -0,0 +1,54 @@
+package mysql
+
+import (
+	"mayfly-go/internal/db/dbm/dbi"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseMysqlExplain(t *testing.T) {
+	planJson := `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "12.50"},
+    "nested_loop": [
+      {
+        "table": {
+          "table_name": "u",
+          "access_type": "ALL",
+          "rows_examined_per_scan": 100,
+          "cost_info": {"read_cost": "1.00", "eval_cost": "10.00", "prefix_cost": "11.00"},
+          "used_columns": ["id", "name"],
+          "attached_condition": "(u.name = 'a')"
+        }
+      },
+      {
+        "table": {
+          "table_name": "r",
+          "access_type": "eq_ref",
+          "possible_keys": ["PRIMARY"],
+          "key": "PRIMARY",
+          "rows_examined_per_scan": 1,
+          "cost_info": {"prefix_cost": "12.50"}
+        }
+      }
+    ]
+  }
+}`
+	got, err := parseMysqlExplain(planJson)
+	require.NoError(t, err)
+	require.Equal(t, &dbi.ExplainNode{
+		NodeType: "query_block",
+		Cost:     12.5,
+		Children: []*dbi.ExplainNode{
+			{
+				NodeType: "nested_loop",
+				Children: []*dbi.ExplainNode{
+					{NodeType: "ALL", Table: "u", Rows: 100, Cost: 11, FullScan: true, Extra: "(u.name = 'a')"},
+					{NodeType: "eq_ref", Table: "r", Index: "PRIMARY", Rows: 1, Cost: 12.5},
+				},
+			},
+		},
+	}, got)
+}
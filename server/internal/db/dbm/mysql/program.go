@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
@@ -77,6 +78,11 @@ func (svc *DbProgramMysql) GetBinlogFilePath(fileName string) string {
 }
 
 func (svc *DbProgramMysql) Backup(ctx context.Context, backupHistory *entity.DbBackupHistory) (*entity.BinlogInfo, error) {
+	if !dbi.IsBinAvailable(svc.getMysqlBin().MysqldumpPath) {
+		logx.Warnf("未找到 mysqldump 可执行文件, 使用内置逻辑备份: %s", backupHistory.DbName)
+		return dbi.NewDbProgramLogical(svc.dbConn).Backup(ctx, backupHistory)
+	}
+
 	dir := svc.getDbBackupDir(backupHistory.DbInstanceId, backupHistory.DbBackupId)
 	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 		return nil, err
@@ -93,18 +99,34 @@ func (svc *DbProgramMysql) Backup(ctx context.Context, backupHistory *entity.DbB
 		"--user", dbInfo.Username,
 		"--password=" + dbInfo.Password,
 		"--add-drop-database",
-		"--result-file", tmpFile,
 		"--single-transaction",
 		"--master-data=2",
 		"--databases", backupHistory.DbName,
 	}
 
+	file, err := os.Create(tmpFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "创建备份文件失败")
+	}
+	progress := dbi.GetProgress(ctx)
+	progress.SetStage(dbi.ProgressStageBackup)
+	if tables, err := svc.dbConn.GetDialect().GetTables(); err == nil {
+		progress.SetTableTotal(len(tables))
+	}
+
 	cmd := exec.CommandContext(ctx, svc.getMysqlBin().MysqldumpPath, args...)
+	// 通过标准输出写入备份文件，以便统计备份进度
+	cmd.Stdout = io.MultiWriter(progress.Writer(file), newDumpTableWatcher(progress))
 	logx.Debugf("backup database using mysqldump binary: %s", cmd.String())
-	if err := runCmd(cmd); err != nil {
+	err = runCmd(cmd)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
 		logx.Errorf("运行 mysqldump 程序失败: %v", err)
 		return nil, errors.Wrap(err, "运行 mysqldump 程序失败")
 	}
+	progress.FinishTables()
 
 	logx.Debugf("Checking dumped file stat: %s", tmpFile)
 	if _, err := os.Stat(tmpFile); err != nil {
@@ -128,7 +150,11 @@ func (svc *DbProgramMysql) Backup(ctx context.Context, backupHistory *entity.DbB
 	return binlogInfo, nil
 }
 
-func (svc *DbProgramMysql) RestoreBackupHistory(ctx context.Context, dbName string, dbBackupId uint64, dbBackupHistoryUuid string) error {
+func (svc *DbProgramMysql) RestoreBackupHistory(ctx context.Context, dbName string, backupHistory *entity.DbBackupHistory, openFile dbi.BackupFileOpener) error {
+	if logical := dbi.NewDbProgramLogical(svc.dbConn); logical.IsLogicalBackup(backupHistory) {
+		return logical.RestoreBackupHistory(ctx, dbName, backupHistory, openFile)
+	}
+
 	dbInfo := svc.dbInfo()
 	args := []string{
 		"--host", dbInfo.Host,
@@ -138,23 +164,30 @@ func (svc *DbProgramMysql) RestoreBackupHistory(ctx context.Context, dbName stri
 		"--password=" + dbInfo.Password,
 	}
 
-	fileName := filepath.Join(svc.getDbBackupDir(svc.dbInfo().InstanceId, dbBackupId),
-		fmt.Sprintf("%v.sql", dbBackupHistoryUuid))
-	file, err := os.Open(fileName)
+	file, err := openFile()
 	if err != nil {
-		return errors.Wrap(err, "打开备份文件失败")
+		return err
 	}
 	defer func() {
 		_ = file.Close()
 	}()
 
+	progress := dbi.GetProgress(ctx)
+	progress.SetStage(dbi.ProgressStageRestore)
+	// 备份文件中包含切换至原数据库的语句，需过滤掉才能恢复至 --database 指定的数据库
+	stdin := filterDatabaseStmts(io.TeeReader(progress.Reader(file), newDumpTableWatcher(progress)))
+	defer func() {
+		_ = stdin.Close()
+	}()
+
 	cmd := exec.CommandContext(ctx, svc.getMysqlBin().MysqlPath, args...)
-	cmd.Stdin = file
+	cmd.Stdin = stdin
 	logx.Debug("恢复数据库: ", cmd.String())
 	if err := runCmd(cmd); err != nil {
 		logx.Errorf("运行 mysql 程序失败: %v", err)
 		return errors.Wrap(err, "运行 mysql 程序失败")
 	}
+	progress.FinishTables()
 	return nil
 }
 
@@ -384,6 +417,88 @@ func (svc *DbProgramMysql) GetSortedBinlogFilesOnServer(_ context.Context) ([]*e
 	return sortBinlogFiles(binlogFiles), nil
 }
 
+// mysqldump --databases 生成的删除、创建及切换数据库的语句前缀
+var databaseStmtPrefixes = []string{"/*!40000 DROP DATABASE", "CREATE DATABASE ", "USE `"}
+
+// 过滤备份文件中删除、创建及切换数据库的语句
+func filterDatabaseStmts(reader io.Reader) *io.PipeReader {
+	pr, pw := io.Pipe()
+	go func() {
+		br := bufio.NewReader(reader)
+		for {
+			line, err := br.ReadString('\n')
+			if len(line) > 0 && !isDatabaseStmt(line) {
+				if _, err := pw.Write([]byte(line)); err != nil {
+					return
+				}
+			}
+			if err == io.EOF {
+				_ = pw.Close()
+				return
+			}
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
+
+// mysqldump 在每张表的表结构前输出的注释
+const dumpTableStructurePrefix = "-- Table structure for table `"
+
+// 根据 mysqldump 输出的表结构注释统计已处理的表
+func newDumpTableWatcher(progress *dbi.DbProgramProgress) io.Writer {
+	return newLineWatcher(func(line string) {
+		if tableName, ok := strings.CutPrefix(line, dumpTableStructurePrefix); ok {
+			progress.StartTable(strings.TrimSuffix(strings.TrimSpace(tableName), "`"))
+		}
+	})
+}
+
+// lineWatcher 逐行检查写入的内容，仅保留每行的前 maxLineWatchSize 个字节用于检查
+type lineWatcher struct {
+	line   []byte
+	onLine func(line string)
+}
+
+const maxLineWatchSize = 256
+
+func newLineWatcher(onLine func(line string)) *lineWatcher {
+	return &lineWatcher{onLine: onLine}
+}
+
+func (w *lineWatcher) Write(b []byte) (int, error) {
+	n := len(b)
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		chunk := b
+		if i >= 0 {
+			chunk = b[:i]
+		}
+		if remain := maxLineWatchSize - len(w.line); remain > 0 {
+			w.line = append(w.line, chunk[:min(len(chunk), remain)]...)
+		}
+		if i < 0 {
+			break
+		}
+		w.onLine(string(w.line))
+		w.line = w.line[:0]
+		b = b[i+1:]
+	}
+	return n, nil
+}
+
+func isDatabaseStmt(line string) bool {
+	for _, prefix := range databaseStmtPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 var regexpBinlogInfo = regexp.MustCompile("CHANGE MASTER TO MASTER_LOG_FILE='([^.]+).([0-9]+)', MASTER_LOG_POS=([0-9]+);")
 
 func readBinlogInfoFromBackup(reader io.Reader) (*entity.BinlogInfo, error) {
@@ -525,7 +640,8 @@ func (svc *DbProgramMysql) ReplayBinlog(ctx context.Context, originalDatabase, t
 	}
 
 	dbInfo := svc.dbInfo()
-	mysqlbinlogArgs = append(mysqlbinlogArgs, restoreInfo.GetBinlogPaths(svc.getBinlogDir(dbInfo.InstanceId))...)
+	// binlog 文件保存在备份所在数据库实例的 binlog 目录中
+	mysqlbinlogArgs = append(mysqlbinlogArgs, restoreInfo.GetBinlogPaths(svc.getBinlogDir(restoreInfo.BackupHistory.DbInstanceId))...)
 
 	mysqlArgs := []string{
 		"--host", dbInfo.Host,
@@ -562,7 +678,17 @@ func (svc *DbProgramMysql) ReplayBinlog(ctx context.Context, originalDatabase, t
 	mysqlbinlogCmd.Stderr = &mysqlbinlogErr
 	mysqlCmd.Stderr = &mysqlErr
 	mysqlCmd.Stdout = os.Stdout
-	mysqlCmd.Stdin = mysqlRead
+	// 根据 mysqlbinlog 输出的 Rotate 事件统计已回放的 binlog 文件数
+	progress := dbi.GetProgress(ctx)
+	progress.SetStage(dbi.ProgressStageBinlog)
+	progress.SetBinlogFileTotal(len(restoreInfo.BinlogHistories))
+	rotated := 0
+	mysqlCmd.Stdin = io.TeeReader(progress.Reader(mysqlRead), newLineWatcher(func(line string) {
+		if strings.HasPrefix(line, "#") && strings.Contains(line, "Rotate to ") {
+			rotated++
+			progress.SetBinlogFiles(rotated)
+		}
+	}))
 
 	if err := mysqlbinlogCmd.Start(); err != nil {
 		return errors.Wrap(err, "启动 mysqlbinlog 程序失败")
@@ -582,6 +708,7 @@ func (svc *DbProgramMysql) ReplayBinlog(ctx context.Context, originalDatabase, t
 	if err := mysqlCmd.Wait(); err != nil {
 		return errors.Errorf("运行 mysql 程序失败: %s", mysqlErr.String())
 	}
+	progress.SetBinlogFiles(len(restoreInfo.BinlogHistories))
 
 	return nil
 }
@@ -631,7 +758,9 @@ func (svc *DbProgramMysql) CheckBinlogRowFormat(ctx context.Context) error {
 
 func runCmd(cmd *exec.Cmd) error {
 	var stderr strings.Builder
-	cmd.Stdout = os.Stdout
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
 	cmd.Stderr = &stderr
 	if err := cmd.Start(); err != nil {
 		return err
@@ -217,7 +217,9 @@ func (s *DbInstanceSuite) testReplayBinlog(backupHistory *entity.DbBackupHistory
 
 func (s *DbInstanceSuite) testRestore(backupHistory *entity.DbBackupHistory) {
 	require := s.Require()
-	err := s.instanceSvc.RestoreBackupHistory(context.Background(), backupHistory.DbName, backupHistory.DbBackupId, backupHistory.Uuid)
+	fileName, err := dbi.GetDbBackupFile(backupHistory.DbInstanceId, backupHistory.DbBackupId, backupHistory.Uuid)
+	require.NoError(err)
+	err = s.instanceSvc.RestoreBackupHistory(context.Background(), backupHistory.DbName, backupHistory, dbi.LocalBackupFileOpener(fileName))
 	require.NoError(err)
 }
 
@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"io"
+	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/domain/entity"
 	"strings"
 	"testing"
@@ -24,3 +26,33 @@ func Test_readBinlogInfoFromBackup(t *testing.T) {
 		Position: 379,
 	}, got)
 }
+
+func Test_filterDatabaseStmts(t *testing.T) {
+	text := "/*!40000 DROP DATABASE IF EXISTS `db`*/;\n" +
+		"\n" +
+		"CREATE DATABASE /*!32312 IF NOT EXISTS*/ `db` /*!40100 DEFAULT CHARACTER SET utf8mb4 */;\n" +
+		"\n" +
+		"USE `db`;\n" +
+		"INSERT INTO `t` VALUES (1,'USE `db`');\n" +
+		"UNLOCK TABLES;"
+	got, err := io.ReadAll(filterDatabaseStmts(strings.NewReader(text)))
+	require.NoError(t, err)
+	require.Equal(t, "\n\nINSERT INTO `t` VALUES (1,'USE `db`');\nUNLOCK TABLES;", string(got))
+}
+
+func Test_newDumpTableWatcher(t *testing.T) {
+	progress := &dbi.DbProgramProgress{}
+	progress.SetTableTotal(2)
+	watcher := newDumpTableWatcher(progress)
+	text := "--\n-- Table structure for table `t1`\n--\nCREATE TABLE `t1` (id int);\n" +
+		"--\n-- Table structure for table `t2`\n--\nCREATE TABLE `t2` (id int);\n"
+	// 分多次写入，模拟一行被拆分至多次写入的情况
+	for _, chunk := range []string{text[:20], text[20:50], text[50:]} {
+		_, err := watcher.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+	require.Equal(t, "t2", progress.TableName)
+	require.Equal(t, 1, progress.Tables)
+	progress.FinishTables()
+	require.Equal(t, 2, progress.Tables)
+}
@@ -234,8 +234,13 @@ func (od *OracleDialect) GetTableDDL(tableName string) (string, error) {
 	return builder.String(), nil
 }
 
-func (od *OracleDialect) WalkTableRecord(tableName string, walkFn dbi.WalkQueryRowsFunc) error {
-	return od.dc.WalkQueryRows(context.Background(), fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
+// 根据表结构定义生成建表或修改表的sql
+func (od *OracleDialect) GenTableDesignSql(td *dbi.TableDefinition) ([]string, error) {
+	return dbi.GenTableDesignSql(od.dc, td)
+}
+
+func (od *OracleDialect) WalkTableRecord(ctx context.Context, tableName string, walkFn dbi.WalkQueryRowsFunc) error {
+	return od.dc.WalkQueryRows(ctx, fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
 }
 
 // 获取DM当前连接的库可访问的schemaNames
@@ -254,7 +259,7 @@ func (od *OracleDialect) GetSchemas() ([]string, error) {
 
 // GetDbProgram 获取数据库程序模块，用于数据库备份与恢复
 func (od *OracleDialect) GetDbProgram() dbi.DbProgram {
-	panic("implement me")
+	return dbi.NewDbProgramLogical(od.dc)
 }
 
 func (od *OracleDialect) GetDataType(dbColumnType string) dbi.DataType {
@@ -276,7 +281,7 @@ func (od *OracleDialect) GetDataType(dbColumnType string) dbi.DataType {
 	return dbi.DataTypeString
 }
 
-func (od *OracleDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any) (int64, error) {
+func (od *OracleDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any, duplicateStrategy dbi.DuplicateStrategy, keyColumns ...string) (int64, error) {
 	//INSERT ALL
 	//INTO my_table(field_1,field_2) VALUES (value_1,value_2)
 	//INTO my_table(field_1,field_2) VALUES (value_3,value_4)
@@ -287,6 +292,29 @@ func (od *OracleDialect) BatchInsert(tx *sql.Tx, tableName string, columns []str
 		return 0, nil
 	}
 
+	quoteTableName := od.dc.Info.Type.QuoteIdentifier(tableName)
+	// 数据重复时通过 MERGE 语句逐条忽略或更新
+	if duplicateStrategy.NeedHandle() {
+		var err error
+		keyColumns, err = dbi.GetDuplicateKeyColumns(od, od.dc.Info.Type, tableName, keyColumns)
+		if err != nil {
+			return 0, err
+		}
+		effRows := int64(0)
+		for _, value := range values {
+			placeholders := make([]string, 0, len(columns))
+			for i, arg := range value {
+				placeholders = append(placeholders, oraclePlaceholder(i+1, arg))
+			}
+			res, err := od.dc.TxExec(tx, dbi.GenMergeSql(duplicateStrategy, quoteTableName, columns, keyColumns, placeholders), value...)
+			if err != nil {
+				return effRows, err
+			}
+			effRows += res
+		}
+		return effRows, nil
+	}
+
 	// 把二维数组转为一维数组
 	var args []any
 	for _, v := range values {
@@ -301,23 +329,9 @@ func (od *OracleDialect) BatchInsert(tx *sql.Tx, tableName string, columns []str
 	for i := 0; i < len(args); i += len(columns) {
 		var placeholder []string
 		for j := 0; j < len(columns); j++ {
-			// 判断字符串数据格式是时间"2023-06-25 10:40:10"  占位符需要变成 to_date(:x, 'fmt')
-			if reflect.TypeOf(args[i+j]) == reflect.TypeOf("") {
-				if regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`).MatchString(args[i+j].(string)) {
-					placeholder = append(placeholder, fmt.Sprintf("to_date(:%d, 'yyyy-mm-dd hh24:mi:ss')", i+j+1))
-				} else if regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`).MatchString(args[i+j].(string)) {
-					// 只有年月日的数据，oracle会自动补零时分秒，如：2024-01-02: to_date('2024-01-02','yyyy-mm-dd') 输出：2024-01-02 00:00:00
-					placeholder = append(placeholder, fmt.Sprintf("to_date(:%d, 'yyyy-mm-dd')", i+j+1))
-				} else if regexp.MustCompile(`^\d{2}:\d{2}:\d{2}$`).MatchString(args[i+j].(string)) {
-					// 只有时间的数据，oracle会拼接当前月份的年月日，如当前月份是2024-01: to_date('13:23:11','hh24:mi:ss') 输出：2024-01-01 13:23:11
-					placeholder = append(placeholder, fmt.Sprintf("to_date(:%d, 'hh24:mi:ss')", i+j+1))
-				}
-				continue
-			}
-
-			placeholder = append(placeholder, fmt.Sprintf(":%d", i+j+1))
+			placeholder = append(placeholder, oraclePlaceholder(i+j+1, args[i+j]))
 		}
-		sqlArr = append(sqlArr, fmt.Sprintf("INTO %s (%s) VALUES (%s)", od.dc.Info.Type.QuoteIdentifier(tableName), strings.Join(columns, ","), strings.Join(placeholder, ",")))
+		sqlArr = append(sqlArr, fmt.Sprintf("INTO %s (%s) VALUES (%s)", quoteTableName, strings.Join(columns, ","), strings.Join(placeholder, ",")))
 	}
 	sqlArr = append(sqlArr, "SELECT 1 FROM DUAL")
 
@@ -326,6 +340,23 @@ func (od *OracleDialect) BatchInsert(tx *sql.Tx, tableName string, columns []str
 	return res, err
 }
 
+// 获取指定序号参数的占位符，oracle的占位符是:1,:2,:3....
+func oraclePlaceholder(index int, arg any) string {
+	// 判断字符串数据格式是时间"2023-06-25 10:40:10"  占位符需要变成 to_date(:x, 'fmt')
+	if reflect.TypeOf(arg) == reflect.TypeOf("") {
+		if regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`).MatchString(arg.(string)) {
+			return fmt.Sprintf("to_date(:%d, 'yyyy-mm-dd hh24:mi:ss')", index)
+		} else if regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`).MatchString(arg.(string)) {
+			// 只有年月日的数据，oracle会自动补零时分秒，如：2024-01-02: to_date('2024-01-02','yyyy-mm-dd') 输出：2024-01-02 00:00:00
+			return fmt.Sprintf("to_date(:%d, 'yyyy-mm-dd')", index)
+		} else if regexp.MustCompile(`^\d{2}:\d{2}:\d{2}$`).MatchString(arg.(string)) {
+			// 只有时间的数据，oracle会拼接当前月份的年月日，如当前月份是2024-01: to_date('13:23:11','hh24:mi:ss') 输出：2024-01-01 13:23:11
+			return fmt.Sprintf("to_date(:%d, 'hh24:mi:ss')", index)
+		}
+	}
+	return fmt.Sprintf(":%d", index)
+}
+
 func (od *OracleDialect) FormatStrData(dbColumnValue string, dataType dbi.DataType) string {
 	switch dataType {
 	case dbi.DataTypeDateTime: // "2024-01-02T22:08:22.275697+08:00"
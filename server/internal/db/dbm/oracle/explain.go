@@ -0,0 +1,83 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/stringx"
+	"strings"
+)
+
+// Explain 使用 EXPLAIN PLAN 将执行计划写入 PLAN_TABLE 后读取，即 DBMS_XPLAN.DISPLAY 所展示的计划数据。
+// PLAN_TABLE 为会话级临时表，故需在同一连接上执行
+func (od *OracleDialect) Explain(ctx context.Context, sql string) (*dbi.ExplainNode, error) {
+	sql, err := dbi.CheckExplainSql(od.dc.Info.Type, sql)
+	if err != nil {
+		return nil, err
+	}
+	ctx, _, release, err := od.dc.BindConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	statementId := "mayfly_" + stringx.Rand(16)
+	if _, err := od.dc.ExecContext(ctx, fmt.Sprintf("EXPLAIN PLAN SET STATEMENT_ID = '%s' FOR %s", statementId, sql)); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if _, err := od.dc.ExecContext(ctx, fmt.Sprintf("DELETE FROM PLAN_TABLE WHERE STATEMENT_ID = '%s'", statementId)); err != nil {
+			logx.Warnf("清除oracle执行计划数据失败: %s", err.Error())
+		}
+	}()
+
+	_, res, err := od.dc.QueryContext(ctx, fmt.Sprintf(`SELECT ID, PARENT_ID, OPERATION, OPTIONS, OBJECT_NAME, CARDINALITY, COST, ACCESS_PREDICATES, FILTER_PREDICATES
+	FROM PLAN_TABLE WHERE STATEMENT_ID = '%s' ORDER BY ID`, statementId))
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, errorx.NewBiz("未获取到执行计划")
+	}
+	return dbi.BuildExplainTree(oracleExplainRows(res)), nil
+}
+
+// 将 PLAN_TABLE 的记录转换为执行计划行
+func oracleExplainRows(res []map[string]any) []dbi.ExplainRow {
+	rows := make([]dbi.ExplainRow, 0, len(res))
+	for _, re := range res {
+		operation := anyx.ToString(re["OPERATION"])
+		options := anyx.ToString(re["OPTIONS"])
+		objectName := anyx.ToString(re["OBJECT_NAME"])
+		node := &dbi.ExplainNode{
+			NodeType: strings.TrimSpace(operation + " " + options),
+			Rows:     anyx.ConvInt64(re["CARDINALITY"]),
+			Cost:     float64(anyx.ConvInt64(re["COST"])),
+			FullScan: operation == "TABLE ACCESS" && options == "FULL",
+		}
+		if strings.HasPrefix(operation, "INDEX") {
+			node.Index = objectName
+		} else {
+			node.Table = objectName
+		}
+
+		extras := make([]string, 0)
+		if access := anyx.ToString(re["ACCESS_PREDICATES"]); access != "" {
+			extras = append(extras, "access: "+access)
+		}
+		if filter := anyx.ToString(re["FILTER_PREDICATES"]); filter != "" {
+			extras = append(extras, "filter: "+filter)
+		}
+		node.Extra = strings.Join(extras, "; ")
+
+		rows = append(rows, dbi.ExplainRow{
+			Id:       anyx.ConvInt64(re["ID"]),
+			ParentId: anyx.ConvInt64(re["PARENT_ID"]),
+			Node:     node,
+		})
+	}
+	return rows
+}
@@ -0,0 +1,60 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/logx"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ROWNUM分页时附加的行号列，查询后需从结果中移除
+const oracleRowNumColumn = "MAYFLY_RN__"
+
+// 各连接是否支持 OFFSET FETCH 分页（oracle 12c及以上），dbConnId -> bool
+var oracleOffsetFetchSupports sync.Map
+
+func (od *OracleDialect) GetTableData(ctx context.Context, query *dbi.TableDataQuery) (*dbi.TableDataRes, error) {
+	if od.supportOffsetFetch() {
+		return dbi.QueryTableData(ctx, od.dc, query, func(selectSql string, offset, limit int) string {
+			return fmt.Sprintf("%s OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", selectSql, offset, limit)
+		})
+	}
+
+	res, err := dbi.QueryTableData(ctx, od.dc, query, func(selectSql string, offset, limit int) string {
+		return fmt.Sprintf("SELECT * FROM (SELECT t__.*, ROWNUM AS %s FROM (%s) t__ WHERE ROWNUM <= %d) WHERE %s > %d",
+			oracleRowNumColumn, selectSql, offset+limit, oracleRowNumColumn, offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]*dbi.QueryColumn, 0, len(res.Columns))
+	for _, column := range res.Columns {
+		if column.Name != oracleRowNumColumn {
+			columns = append(columns, column)
+		}
+	}
+	res.Columns = columns
+	for _, row := range res.Rows {
+		delete(row, oracleRowNumColumn)
+	}
+	return res, nil
+}
+
+// 根据数据库版本判断是否支持 OFFSET FETCH，获取版本失败则使用ROWNUM分页
+func (od *OracleDialect) supportOffsetFetch() bool {
+	if support, ok := oracleOffsetFetchSupports.Load(od.dc.Id); ok {
+		return support.(bool)
+	}
+	support := false
+	if server, err := od.GetDbServer(); err != nil {
+		logx.Warnf("获取oracle版本失败, 使用ROWNUM分页: %s", err.Error())
+	} else {
+		major, _ := strconv.Atoi(strings.Split(server.Version, ".")[0])
+		support = major >= 12
+	}
+	oracleOffsetFetchSupports.Store(od.dc.Id, support)
+	return support
+}
@@ -24,6 +24,8 @@ const (
 
 type PgsqlDialect struct {
 	dc *dbi.DbConn
+
+	ddlPrepared bool // 是否已创建获取建表ddl的函数
 }
 
 func (pd *PgsqlDialect) GetDbServer() (*dbi.DbServer, error) {
@@ -154,11 +156,21 @@ func (pd *PgsqlDialect) GetTableIndex(tableName string) ([]dbi.Index, error) {
 	return result, nil
 }
 
+// 创建获取建表ddl的函数，逻辑备份时需在只读快照事务开启前调用
+func (pd *PgsqlDialect) PrepareTableDDL() error {
+	if _, err := pd.dc.Exec(dbi.GetLocalSql(PGSQL_META_FILE, PGSQL_TABLE_DDL_KEY)); err != nil {
+		return err
+	}
+	pd.ddlPrepared = true
+	return nil
+}
+
 // 获取建表ddl
 func (pd *PgsqlDialect) GetTableDDL(tableName string) (string, error) {
-	_, err := pd.dc.Exec(dbi.GetLocalSql(PGSQL_META_FILE, PGSQL_TABLE_DDL_KEY))
-	if err != nil {
-		return "", err
+	if !pd.ddlPrepared {
+		if err := pd.PrepareTableDDL(); err != nil {
+			return "", err
+		}
 	}
 
 	_, schemaRes, _ := pd.dc.Query("select current_schema() as schema")
@@ -173,8 +185,17 @@ func (pd *PgsqlDialect) GetTableDDL(tableName string) (string, error) {
 	return res[0]["sql"].(string), nil
 }
 
-func (pd *PgsqlDialect) WalkTableRecord(tableName string, walkFn dbi.WalkQueryRowsFunc) error {
-	return pd.dc.WalkQueryRows(context.Background(), fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
+// 根据表结构定义生成建表或修改表的sql
+func (pd *PgsqlDialect) GenTableDesignSql(td *dbi.TableDefinition) ([]string, error) {
+	return dbi.GenTableDesignSql(pd.dc, td)
+}
+
+func (pd *PgsqlDialect) WalkTableRecord(ctx context.Context, tableName string, walkFn dbi.WalkQueryRowsFunc) error {
+	return pd.dc.WalkQueryRows(ctx, fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
+}
+
+func (pd *PgsqlDialect) GetTableData(ctx context.Context, query *dbi.TableDataQuery) (*dbi.TableDataRes, error) {
+	return dbi.QueryTableData(ctx, pd.dc, query, dbi.LimitOffsetPageSql)
 }
 
 // 获取pgsql当前连接的库可访问的schemaNames
@@ -193,7 +214,7 @@ func (pd *PgsqlDialect) GetSchemas() ([]string, error) {
 
 // GetDbProgram 获取数据库程序模块，用于数据库备份与恢复
 func (pd *PgsqlDialect) GetDbProgram() dbi.DbProgram {
-	panic("implement me")
+	return NewDbProgramPgsql(pd.dc)
 }
 
 func (pd *PgsqlDialect) GetDataType(dbColumnType string) dbi.DataType {
@@ -215,7 +236,7 @@ func (pd *PgsqlDialect) GetDataType(dbColumnType string) dbi.DataType {
 	return dbi.DataTypeString
 }
 
-func (pd *PgsqlDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any) (int64, error) {
+func (pd *PgsqlDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any, duplicateStrategy dbi.DuplicateStrategy, keyColumns ...string) (int64, error) {
 	// 执行批量insert sql，跟mysql一样  pg或高斯支持批量insert语法
 	// insert into table_name (column1, column2, ...) values (value1, value2, ...), (value1, value2, ...), ...
 
@@ -235,7 +256,17 @@ func (pd *PgsqlDialect) BatchInsert(tx *sql.Tx, tableName string, columns []stri
 		placeholders = append(placeholders, "("+strings.Join(placeholder, ", ")+")")
 	}
 
-	sqlStr := fmt.Sprintf("insert into %s (%s) values %s", pd.dc.Info.Type.QuoteIdentifier(tableName), strings.Join(columns, ","), strings.Join(placeholders, ", "))
+	// 数据重复时通过 ON CONFLICT 忽略或更新
+	if duplicateStrategy == dbi.DuplicateStrategyUpdate {
+		var err error
+		keyColumns, err = dbi.GetDuplicateKeyColumns(pd, pd.dc.Info.Type, tableName, keyColumns)
+		if err != nil {
+			return 0, err
+		}
+	}
+	duplicateSql := dbi.GenOnConflictSql(duplicateStrategy, columns, keyColumns)
+
+	sqlStr := fmt.Sprintf("insert into %s (%s) values %s%s", pd.dc.Info.Type.QuoteIdentifier(tableName), strings.Join(columns, ","), strings.Join(placeholders, ", "), duplicateSql)
 	// 执行批量insert sql
 
 	return pd.dc.TxExec(tx, sqlStr, args...)
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"strings"
+)
+
+// 执行计划节点中作为附加信息的条件字段
+var pgsqlExplainExtraKeys = []string{"Filter", "Index Cond", "Hash Cond", "Merge Cond", "Join Filter", "Recheck Cond"}
+
+func (pd *PgsqlDialect) Explain(ctx context.Context, sql string) (*dbi.ExplainNode, error) {
+	sql, err := dbi.CheckExplainSql(pd.dc.Info.Type, sql)
+	if err != nil {
+		return nil, err
+	}
+	_, res, err := pd.dc.QueryContext(ctx, "EXPLAIN (FORMAT JSON) "+sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, errorx.NewBiz("未获取到执行计划")
+	}
+	for _, v := range res[0] {
+		return parsePgsqlExplain(anyx.ToString(v))
+	}
+	return nil, errorx.NewBiz("未获取到执行计划")
+}
+
+// parsePgsqlExplain 解析 EXPLAIN (FORMAT JSON) 的结果
+func parsePgsqlExplain(planJson string) (*dbi.ExplainNode, error) {
+	var plans []struct {
+		Plan map[string]any `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(planJson), &plans); err != nil {
+		return nil, errorx.NewBiz("解析执行计划失败: %s", err.Error())
+	}
+	nodes := make([]*dbi.ExplainNode, 0)
+	for _, plan := range plans {
+		nodes = append(nodes, pgsqlExplainNode(plan.Plan))
+	}
+	return dbi.NewExplainRoot(nodes), nil
+}
+
+func pgsqlExplainNode(plan map[string]any) *dbi.ExplainNode {
+	node := &dbi.ExplainNode{
+		NodeType: anyx.ToString(plan["Node Type"]),
+		Table:    anyx.ToString(plan["Relation Name"]),
+		Index:    anyx.ToString(plan["Index Name"]),
+	}
+	if rows, ok := plan["Plan Rows"].(float64); ok {
+		node.Rows = int64(rows)
+	}
+	if cost, ok := plan["Total Cost"].(float64); ok {
+		node.Cost = cost
+	}
+	node.FullScan = node.NodeType == "Seq Scan"
+
+	extras := make([]string, 0)
+	for _, key := range pgsqlExplainExtraKeys {
+		if v := anyx.ToString(plan[key]); v != "" {
+			extras = append(extras, key+": "+v)
+		}
+	}
+	node.Extra = strings.Join(extras, "; ")
+
+	children, _ := plan["Plans"].([]any)
+	for _, child := range children {
+		if childPlan, ok := child.(map[string]any); ok {
+			node.Children = append(node.Children, pgsqlExplainNode(childPlan))
+		}
+	}
+	return node
+}
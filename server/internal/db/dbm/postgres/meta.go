@@ -80,7 +80,7 @@ func (md *PostgresMeta) GetSqlDb(d *dbi.DbInfo) (*sql.DB, error) {
 }
 
 func (md *PostgresMeta) GetDialect(conn *dbi.DbConn) dbi.Dialect {
-	return &PgsqlDialect{conn}
+	return &PgsqlDialect{dc: conn}
 }
 
 // pgsql dialer
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"mayfly-go/internal/db/config"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/logx"
+)
+
+var _ dbi.DbProgram = (*DbProgramPgsql)(nil)
+
+// DbProgramPgsql 基于 pg_dump 与 pg_restore 实现 PostgreSQL 数据库的备份与恢复
+type DbProgramPgsql struct {
+	dbConn *dbi.DbConn
+	// pgsqlBin 用于集成测试
+	pgsqlBin *config.PgsqlBin
+	// backupPath 用于集成测试
+	backupPath string
+}
+
+func NewDbProgramPgsql(dbConn *dbi.DbConn) *DbProgramPgsql {
+	return &DbProgramPgsql{
+		dbConn: dbConn,
+	}
+}
+
+func (svc *DbProgramPgsql) dbInfo() *dbi.DbInfo {
+	dbInfo := svc.dbConn.Info
+	err := dbInfo.IfUseSshTunnelChangeIpPort()
+	if err != nil {
+		logx.Errorf("通过ssh隧道连接db失败: %s", err.Error())
+	}
+	return dbInfo
+}
+
+func (svc *DbProgramPgsql) getPgsqlBin() *config.PgsqlBin {
+	if svc.pgsqlBin == nil {
+		svc.pgsqlBin = config.GetPgsqlBin()
+	}
+	return svc.pgsqlBin
+}
+
+func (svc *DbProgramPgsql) getBackupPath() string {
+	if len(svc.backupPath) > 0 {
+		return svc.backupPath
+	}
+	return config.GetDbBackupRestore().BackupPath
+}
+
+func (svc *DbProgramPgsql) getDbBackupDir(instanceId, backupId uint64) string {
+	return filepath.Join(
+		svc.getBackupPath(),
+		fmt.Sprintf("instance-%d", instanceId),
+		fmt.Sprintf("backup-%d", backupId))
+}
+
+// 连接参数，密码通过环境变量 PGPASSWORD 传递，避免出现在进程参数中
+func (svc *DbProgramPgsql) connArgs(dbInfo *dbi.DbInfo) []string {
+	return []string{
+		"--host", dbInfo.Host,
+		"--port", strconv.Itoa(dbInfo.Port),
+		"--username", dbInfo.Username,
+		"--no-password",
+	}
+}
+
+func (svc *DbProgramPgsql) command(ctx context.Context, dbInfo *dbi.DbInfo, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+dbInfo.Password)
+	return cmd
+}
+
+// Backup 使用 pg_dump 以自定义归档格式备份数据库，未找到 pg_dump 则使用内置逻辑备份。PostgreSQL 不支持 binlog，返回的 binlog 信息为空
+func (svc *DbProgramPgsql) Backup(ctx context.Context, backupHistory *entity.DbBackupHistory) (*entity.BinlogInfo, error) {
+	if !dbi.IsBinAvailable(svc.getPgsqlBin().PgDumpPath) {
+		logx.Warnf("未找到 pg_dump 可执行文件, 使用内置逻辑备份: %s", backupHistory.DbName)
+		return dbi.NewDbProgramLogical(svc.dbConn).Backup(ctx, backupHistory)
+	}
+
+	dir := svc.getDbBackupDir(backupHistory.DbInstanceId, backupHistory.DbBackupId)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	tmpFile := filepath.Join(dir, "backup.tmp")
+	defer func() {
+		_ = os.Remove(tmpFile)
+	}()
+
+	dbInfo := svc.dbInfo()
+	// 兼容 db/schema 模式，仅备份指定的 schema
+	dbName, schema, _ := strings.Cut(backupHistory.DbName, "/")
+	args := append(svc.connArgs(dbInfo),
+		"--format", "custom",
+		"--dbname", dbName,
+	)
+	if schema != "" {
+		args = append(args, "--schema", schema)
+	}
+
+	file, err := os.Create(tmpFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "创建备份文件失败")
+	}
+	progress := dbi.GetProgress(ctx)
+	progress.SetStage(dbi.ProgressStageBackup)
+
+	cmd := svc.command(ctx, dbInfo, svc.getPgsqlBin().PgDumpPath, args...)
+	// 通过标准输出写入备份文件，以便统计备份进度
+	cmd.Stdout = progress.Writer(file)
+	logx.Debugf("backup database using pg_dump binary: %s", cmd.String())
+	err = runCmd(cmd)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logx.Errorf("运行 pg_dump 程序失败: %v", err)
+		return nil, errors.Wrap(err, "运行 pg_dump 程序失败")
+	}
+
+	if _, err := os.Stat(tmpFile); err != nil {
+		logx.Errorf("未找到备份文件: %v", err)
+		return nil, errors.Wrapf(err, "未找到备份文件")
+	}
+	fileName := filepath.Join(dir, fmt.Sprintf("%s.dump", backupHistory.Uuid))
+	if err := os.Rename(tmpFile, fileName); err != nil {
+		return nil, errors.Wrap(err, "备份文件改名失败")
+	}
+
+	return &entity.BinlogInfo{}, nil
+}
+
+// RestoreBackupHistory 使用 pg_restore 恢复数据库，恢复前会先删除备份中已存在的数据库对象
+func (svc *DbProgramPgsql) RestoreBackupHistory(ctx context.Context, dbName string, backupHistory *entity.DbBackupHistory, openFile dbi.BackupFileOpener) error {
+	if logical := dbi.NewDbProgramLogical(svc.dbConn); logical.IsLogicalBackup(backupHistory) {
+		return logical.RestoreBackupHistory(ctx, dbName, backupHistory, openFile)
+	}
+
+	dbInfo := svc.dbInfo()
+	file, err := openFile()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	dbName, _, _ = strings.Cut(dbName, "/")
+	args := append(svc.connArgs(dbInfo),
+		"--dbname", dbName,
+		"--clean",
+		"--if-exists",
+		"--no-owner",
+		"--single-transaction",
+	)
+
+	progress := dbi.GetProgress(ctx)
+	progress.SetStage(dbi.ProgressStageRestore)
+
+	cmd := svc.command(ctx, dbInfo, svc.getPgsqlBin().PgRestorePath, args...)
+	// 通过标准输入读取备份文件，以便统计恢复进度
+	cmd.Stdin = progress.Reader(file)
+	logx.Debug("恢复数据库: ", cmd.String())
+	if err := runCmd(cmd); err != nil {
+		logx.Errorf("运行 pg_restore 程序失败: %v", err)
+		return errors.Wrap(err, "运行 pg_restore 程序失败")
+	}
+	return nil
+}
+
+// FetchBinlogs PostgreSQL 不支持 binlog，无需下载
+func (svc *DbProgramPgsql) FetchBinlogs(ctx context.Context, downloadLatestBinlogFile bool, earliestBackupSequence, latestBinlogSequence int64) ([]*entity.BinlogFile, error) {
+	return nil, nil
+}
+
+func (svc *DbProgramPgsql) ReplayBinlog(ctx context.Context, originalDatabase, targetDatabase string, restoreInfo *dbi.RestoreInfo) error {
+	return errors.New("PostgreSQL 不支持按时间点恢复")
+}
+
+func (svc *DbProgramPgsql) GetBinlogEventPositionAtOrAfterTime(ctx context.Context, binlogName string, targetTime time.Time) (position int64, parseErr error) {
+	return 0, errors.New("PostgreSQL 不支持按时间点恢复")
+}
+
+func runCmd(cmd *exec.Cmd) error {
+	var stderr strings.Builder
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return errors.New(stderr.String())
+	}
+	return nil
+}
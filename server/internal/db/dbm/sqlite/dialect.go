@@ -183,8 +183,17 @@ func (sd *SqliteDialect) GetTableDDL(tableName string) (string, error) {
 	return builder.String(), nil
 }
 
-func (sd *SqliteDialect) WalkTableRecord(tableName string, walkFn dbi.WalkQueryRowsFunc) error {
-	return sd.dc.WalkQueryRows(context.Background(), fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
+// 根据表结构定义生成建表或修改表的sql
+func (sd *SqliteDialect) GenTableDesignSql(td *dbi.TableDefinition) ([]string, error) {
+	return dbi.GenTableDesignSql(sd.dc, td)
+}
+
+func (sd *SqliteDialect) WalkTableRecord(ctx context.Context, tableName string, walkFn dbi.WalkQueryRowsFunc) error {
+	return sd.dc.WalkQueryRows(ctx, fmt.Sprintf("SELECT * FROM %s", tableName), walkFn)
+}
+
+func (sd *SqliteDialect) GetTableData(ctx context.Context, query *dbi.TableDataQuery) (*dbi.TableDataRes, error) {
+	return dbi.QueryTableData(ctx, sd.dc, query, dbi.LimitOffsetPageSql)
 }
 
 func (sd *SqliteDialect) GetSchemas() ([]string, error) {
@@ -193,7 +202,7 @@ func (sd *SqliteDialect) GetSchemas() ([]string, error) {
 
 // GetDbProgram 获取数据库程序模块，用于数据库备份与恢复
 func (sd *SqliteDialect) GetDbProgram() dbi.DbProgram {
-	panic("implement me")
+	return dbi.NewDbProgramLogical(sd.dc)
 }
 
 func (sd *SqliteDialect) GetDataType(dbColumnType string) dbi.DataType {
@@ -206,7 +215,7 @@ func (sd *SqliteDialect) GetDataType(dbColumnType string) dbi.DataType {
 	return dbi.DataTypeString
 }
 
-func (sd *SqliteDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any) (int64, error) {
+func (sd *SqliteDialect) BatchInsert(tx *sql.Tx, tableName string, columns []string, values [][]any, duplicateStrategy dbi.DuplicateStrategy, keyColumns ...string) (int64, error) {
 	// 执行批量insert sql，跟mysql一样 支持批量insert语法
 	// 生成占位符字符串：如：(?,?)
 	// 重复字符串并用逗号连接
@@ -219,7 +228,17 @@ func (sd *SqliteDialect) BatchInsert(tx *sql.Tx, tableName string, columns []str
 	// 去除最后一个逗号
 	placeholder = strings.TrimSuffix(repeated, ",")
 
-	sqlStr := fmt.Sprintf("insert into %s (%s) values %s", sd.dc.Info.Type.QuoteIdentifier(tableName), strings.Join(columns, ","), placeholder)
+	// 数据重复时通过 ON CONFLICT 忽略或更新
+	if duplicateStrategy == dbi.DuplicateStrategyUpdate {
+		var err error
+		keyColumns, err = dbi.GetDuplicateKeyColumns(sd, sd.dc.Info.Type, tableName, keyColumns)
+		if err != nil {
+			return 0, err
+		}
+	}
+	duplicateSql := dbi.GenOnConflictSql(duplicateStrategy, columns, keyColumns)
+
+	sqlStr := fmt.Sprintf("insert into %s (%s) values %s%s", sd.dc.Info.Type.QuoteIdentifier(tableName), strings.Join(columns, ","), placeholder, duplicateSql)
 
 	// 把二维数组转为一维数组
 	var args []any
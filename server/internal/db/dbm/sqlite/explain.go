@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/anyx"
+	"regexp"
+	"strings"
+)
+
+// 如：SCAN t、SCAN TABLE t（旧版本）、SEARCH t USING INDEX idx_a (a=?)
+var sqliteExplainTableRegexp = regexp.MustCompile(`^(SCAN|SEARCH)(?: TABLE)? (\S+)`)
+var sqliteExplainIndexRegexp = regexp.MustCompile(`USING (?:COVERING )?INDEX (\S+)`)
+
+func (sd *SqliteDialect) Explain(ctx context.Context, sql string) (*dbi.ExplainNode, error) {
+	sql, err := dbi.CheckExplainSql(sd.dc.Info.Type, sql)
+	if err != nil {
+		return nil, err
+	}
+	_, res, err := sd.dc.QueryContext(ctx, "EXPLAIN QUERY PLAN "+sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, errorx.NewBiz("未获取到执行计划")
+	}
+	rows := make([]dbi.ExplainRow, 0, len(res))
+	for _, re := range res {
+		rows = append(rows, dbi.ExplainRow{
+			Id:       anyx.ConvInt64(re["id"]),
+			ParentId: anyx.ConvInt64(re["parent"]),
+			Node:     sqliteExplainNode(anyx.ToString(re["detail"])),
+		})
+	}
+	return dbi.BuildExplainTree(rows), nil
+}
+
+// sqliteExplainNode 解析 EXPLAIN QUERY PLAN 的detail，sqlite不提供预估行数及成本
+func sqliteExplainNode(detail string) *dbi.ExplainNode {
+	node := &dbi.ExplainNode{NodeType: detail, Extra: detail}
+	if match := sqliteExplainTableRegexp.FindStringSubmatch(detail); match != nil {
+		node.NodeType = match[1]
+		node.Table = match[2]
+	}
+	if match := sqliteExplainIndexRegexp.FindStringSubmatch(detail); match != nil {
+		node.Index = match[1]
+	}
+	// 使用了主键（rowid）检索也不属于全表扫描
+	node.FullScan = node.NodeType == "SCAN" && node.Index == "" && !strings.Contains(detail, "PRIMARY KEY")
+	return node
+}
@@ -17,6 +17,8 @@ type DbBackup struct {
 	Repeated  bool          // 是否重复执行
 	DbName    string        // 数据库名称
 	Name      string        // 数据库备份名称
+	Retention string        // 备份保留策略json, 见 DbBackupRetention
+	Storage   string        // 备份存储json, 见 DbBackupStorage, 为空表示本地存储
 }
 
 func (b *DbBackup) GetDbName() string {
@@ -31,7 +33,7 @@ func (b *DbBackup) Schedule() (time.Time, error) {
 		return time.Time{}, runner.ErrJobDisabled
 	}
 	switch b.LastStatus {
-	case DbJobSuccess:
+	case DbJobSuccess, DbJobCanceled:
 		lastTime := b.LastTime.Time
 		if lastTime.Before(b.StartTime) {
 			lastTime = b.StartTime.Add(-b.Interval)
@@ -45,7 +47,7 @@ func (b *DbBackup) Schedule() (time.Time, error) {
 }
 
 func (b *DbBackup) IsFinished() bool {
-	return !b.Repeated && b.LastStatus == DbJobSuccess
+	return !b.Repeated && (b.LastStatus == DbJobSuccess || b.LastStatus == DbJobCanceled)
 }
 
 func (b *DbBackup) IsEnabled() bool {
@@ -60,6 +62,8 @@ func (b *DbBackup) Update(job runner.Job) {
 	backup := job.(*DbBackup)
 	b.StartTime = backup.StartTime
 	b.Interval = backup.Interval
+	b.Retention = backup.Retention
+	b.Storage = backup.Storage
 }
 
 func (b *DbBackup) GetInterval() time.Duration {
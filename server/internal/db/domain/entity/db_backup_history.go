@@ -9,17 +9,31 @@ import (
 type DbBackupHistory struct {
 	model.DeletedModel
 
-	Uuid           string    `json:"uuid"`
-	Name           string    `json:"name"`       // 备份历史名称
-	CreateTime     time.Time `json:"createTime"` // 创建时间: 2023-11-08 02:00:00
-	DbBackupId     uint64    `json:"dbBackupId"`
-	DbInstanceId   uint64    `json:"dbInstanceId"`
-	DbName         string    `json:"dbName"`
-	BinlogFileName string    `json:"binlogFileName"`
-	BinlogSequence int64     `json:"binlogSequence"`
-	BinlogPosition int64     `json:"binlogPosition"`
+	Uuid           string     `json:"uuid"`
+	Name           string     `json:"name"`       // 备份历史名称
+	CreateTime     time.Time  `json:"createTime"` // 创建时间: 2023-11-08 02:00:00
+	DbBackupId     uint64     `json:"dbBackupId"`
+	DbInstanceId   uint64     `json:"dbInstanceId"`
+	DbName         string     `json:"dbName"`
+	BinlogFileName string     `json:"binlogFileName"`
+	BinlogSequence int64      `json:"binlogSequence"`
+	BinlogPosition int64      `json:"binlogPosition"`
+	FileSize       int64      `json:"fileSize"`     // 备份文件大小
+	Checksum       string     `json:"checksum"`     // 备份文件sha256校验和
+	VerifyStatus   int8       `json:"verifyStatus"` // 校验状态
+	VerifyResult   string     `json:"verifyResult"` // 校验结果
+	VerifyTime     *time.Time `json:"verifyTime"`   // 校验时间
+	FileName       string     `json:"fileName"`     // 备份文件名
+	Storage        string     `json:"-"`            // 备份文件所在的备份存储json, 见 DbBackupStorage
+	Progress       string     `json:"progress"`     // 备份进度json
 }
 
+const (
+	DbBackupVerifyRunning int8 = 1  // 校验中
+	DbBackupVerifySuccess int8 = 2  // 校验成功
+	DbBackupVerifyFailed  int8 = -1 // 校验失败
+)
+
 func (d *DbBackupHistory) TableName() string {
 	return "t_db_backup_history"
 }
@@ -0,0 +1,129 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DbBackupRetention 数据库备份保留策略，各项为0表示不限制
+type DbBackupRetention struct {
+	KeepLast    int   `json:"keepLast"`    // 保留最近的备份数
+	KeepDaily   int   `json:"keepDaily"`   // 按天保留，每天保留最后一个备份，保留最近的天数
+	KeepWeekly  int   `json:"keepWeekly"`  // 按周保留，每周保留最后一个备份，保留最近的周数
+	KeepMonthly int   `json:"keepMonthly"` // 按月保留，每月保留最后一个备份，保留最近的月数
+	MaxAgeDay   int   `json:"maxAgeDay"`   // 备份最长保留天数
+	MaxSizeMb   int64 `json:"maxSizeMb"`   // 备份文件总大小上限(MB)
+}
+
+// GetRetention 获取备份保留策略，未配置则返回nil
+func (b *DbBackup) GetRetention() (*DbBackupRetention, error) {
+	if b.Retention == "" {
+		return nil, nil
+	}
+	retention := new(DbBackupRetention)
+	if err := json.Unmarshal([]byte(b.Retention), retention); err != nil {
+		return nil, err
+	}
+	if retention.IsEmpty() {
+		return nil, nil
+	}
+	return retention, nil
+}
+
+// IsEmpty 是否未配置任何保留规则
+func (r *DbBackupRetention) IsEmpty() bool {
+	return r.KeepLast <= 0 && r.KeepDaily <= 0 && r.KeepWeekly <= 0 && r.KeepMonthly <= 0 && r.MaxAgeDay <= 0 && r.MaxSizeMb <= 0
+}
+
+// hasKeepRule 是否配置了按数量或 GFS（日/周/月）保留的规则
+func (r *DbBackupRetention) hasKeepRule() bool {
+	return r.KeepLast > 0 || r.KeepDaily > 0 || r.KeepWeekly > 0 || r.KeepMonthly > 0
+}
+
+// SelectExpired 根据保留策略选出需要删除的备份历史。
+// 最新的备份及 protected 中的备份（如恢复任务依赖的备份）始终保留；
+// 先按数量及 GFS 规则确定保留的备份，再对保留的备份按最长保留天数及总大小上限从最旧的开始删除
+func (r *DbBackupRetention) SelectExpired(histories []*DbBackupHistory, protected map[uint64]bool, now time.Time) []*DbBackupHistory {
+	if r == nil || r.IsEmpty() || len(histories) == 0 {
+		return nil
+	}
+	sorted := make([]*DbBackupHistory, len(histories))
+	copy(sorted, histories)
+	// 按创建时间倒序
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreateTime.After(sorted[j].CreateTime)
+	})
+
+	keep := make(map[uint64]bool, len(sorted))
+	if r.hasKeepRule() {
+		for i, history := range sorted {
+			if i < r.KeepLast {
+				keep[history.Id] = true
+			}
+		}
+		r.keepByPeriod(sorted, keep, r.KeepDaily, func(t time.Time) string { return t.Format(time.DateOnly) })
+		r.keepByPeriod(sorted, keep, r.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%d", year, week)
+		})
+		r.keepByPeriod(sorted, keep, r.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") })
+	} else {
+		for _, history := range sorted {
+			keep[history.Id] = true
+		}
+	}
+
+	var totalSize int64
+	sizeExceeded := false
+	maxSize := r.MaxSizeMb * 1024 * 1024
+	for i, history := range sorted {
+		if i == 0 || protected[history.Id] {
+			keep[history.Id] = true
+			totalSize += history.FileSize
+			continue
+		}
+		if !keep[history.Id] {
+			continue
+		}
+		if r.MaxAgeDay > 0 && now.Sub(history.CreateTime) > time.Duration(r.MaxAgeDay)*24*time.Hour {
+			keep[history.Id] = false
+			continue
+		}
+		if maxSize > 0 && (sizeExceeded || totalSize+history.FileSize > maxSize) {
+			// 超出总大小上限后，更旧的备份均删除
+			sizeExceeded = true
+			keep[history.Id] = false
+			continue
+		}
+		totalSize += history.FileSize
+	}
+
+	expired := make([]*DbBackupHistory, 0)
+	for _, history := range sorted {
+		if !keep[history.Id] {
+			expired = append(expired, history)
+		}
+	}
+	return expired
+}
+
+// keepByPeriod 按周期（日/周/月）保留每个周期内最新的备份，保留最近count个周期
+func (r *DbBackupRetention) keepByPeriod(sorted []*DbBackupHistory, keep map[uint64]bool, count int, period func(t time.Time) string) {
+	if count <= 0 {
+		return
+	}
+	periods := make(map[string]bool, count)
+	for _, history := range sorted {
+		p := period(history.CreateTime)
+		if periods[p] {
+			continue
+		}
+		if len(periods) >= count {
+			return
+		}
+		periods[p] = true
+		keep[history.Id] = true
+	}
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHistories(now time.Time, days int) []*DbBackupHistory {
+	histories := make([]*DbBackupHistory, 0, days)
+	for i := 0; i < days; i++ {
+		history := &DbBackupHistory{CreateTime: now.AddDate(0, 0, -i), FileSize: 1024 * 1024}
+		history.Id = uint64(i + 1)
+		histories = append(histories, history)
+	}
+	return histories
+}
+
+func expiredIds(expired []*DbBackupHistory) []uint64 {
+	ids := make([]uint64, 0, len(expired))
+	for _, history := range expired {
+		ids = append(ids, history.Id)
+	}
+	return ids
+}
+
+func TestDbBackupRetention_SelectExpired(t *testing.T) {
+	now := time.Date(2024, 3, 31, 2, 0, 0, 0, time.Local)
+	histories := newTestHistories(now, 10)
+
+	retention := &DbBackupRetention{KeepLast: 3}
+	require.Equal(t, []uint64{4, 5, 6, 7, 8, 9, 10}, expiredIds(retention.SelectExpired(histories, nil, now)))
+
+	// 恢复任务依赖的备份不删除
+	retention = &DbBackupRetention{KeepLast: 3}
+	require.Equal(t, []uint64{4, 5, 6, 7, 9, 10}, expiredIds(retention.SelectExpired(histories, map[uint64]bool{8: true}, now)))
+
+	// 2024-03-31 为周日，每周保留最新的备份：03-31、03-24
+	retention = &DbBackupRetention{KeepWeekly: 2}
+	require.Equal(t, []uint64{2, 3, 4, 5, 6, 7, 9, 10}, expiredIds(retention.SelectExpired(histories, nil, now)))
+
+	retention = &DbBackupRetention{KeepLast: 2, KeepMonthly: 2}
+	lastMonth := &DbBackupHistory{CreateTime: now.AddDate(0, -1, -5)}
+	lastMonth.Id = 11
+	histories = append(histories, lastMonth)
+	require.Equal(t, []uint64{3, 4, 5, 6, 7, 8, 9, 10}, expiredIds(retention.SelectExpired(histories, nil, now)))
+	histories = histories[:10]
+
+	retention = &DbBackupRetention{MaxAgeDay: 5}
+	require.Equal(t, []uint64{7, 8, 9, 10}, expiredIds(retention.SelectExpired(histories, nil, now)))
+
+	retention = &DbBackupRetention{MaxSizeMb: 3}
+	require.Equal(t, []uint64{4, 5, 6, 7, 8, 9, 10}, expiredIds(retention.SelectExpired(histories, nil, now)))
+
+	// 未配置保留策略则不删除
+	require.Empty(t, (&DbBackupRetention{}).SelectExpired(histories, nil, now))
+}
@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"mayfly-go/internal/common/utils"
+)
+
+const (
+	DbBackupStorageLocal = "local" // 本地磁盘
+	DbBackupStorageSftp  = "sftp"  // 已录入机器的sftp目录
+	DbBackupStorageS3    = "s3"    // 兼容S3协议的对象存储
+)
+
+// DbBackupStorage 数据库备份文件存储位置
+type DbBackupStorage struct {
+	Type      string `json:"type"`
+	MachineId uint64 `json:"machineId,omitempty"` // sftp: 机器id
+	Path      string `json:"path,omitempty"`      // sftp: 远程目录; s3: 对象前缀
+	Endpoint  string `json:"endpoint,omitempty"`  // s3: 服务地址，如 http://127.0.0.1:9000
+	Region    string `json:"region,omitempty"`    // s3: 区域
+	Bucket    string `json:"bucket,omitempty"`    // s3: 存储桶
+	AccessKey string `json:"accessKey,omitempty"` // s3: access key
+	SecretKey string `json:"secretKey,omitempty"` // s3: secret key, 加密保存
+}
+
+// ParseDbBackupStorage 解析备份存储配置，未配置则为本地存储
+func ParseDbBackupStorage(storage string) (*DbBackupStorage, error) {
+	bs := &DbBackupStorage{Type: DbBackupStorageLocal}
+	if storage == "" {
+		return bs, nil
+	}
+	if err := json.Unmarshal([]byte(storage), bs); err != nil {
+		return nil, err
+	}
+	if bs.Type == "" {
+		bs.Type = DbBackupStorageLocal
+	}
+	return bs, nil
+}
+
+// GetStorage 获取备份任务的备份存储配置
+func (b *DbBackup) GetStorage() (*DbBackupStorage, error) {
+	return ParseDbBackupStorage(b.Storage)
+}
+
+// GetStorage 获取备份文件所在的备份存储配置，不包含 secret key
+func (d *DbBackupHistory) GetStorage() (*DbBackupStorage, error) {
+	return ParseDbBackupStorage(d.Storage)
+}
+
+func (bs *DbBackupStorage) IsLocal() bool {
+	return bs.Type == DbBackupStorageLocal
+}
+
+func (bs *DbBackupStorage) String() string {
+	bytes, _ := json.Marshal(bs)
+	return string(bytes)
+}
+
+// RefString 不包含 secret key 的备份存储配置，保存至备份历史用于定位备份文件，访问存储时的 secret key 从所属备份任务的存储配置中获取
+func (bs *DbBackupStorage) RefString() string {
+	ref := *bs
+	ref.SecretKey = ""
+	return ref.String()
+}
+
+// FillSecret 从备份任务当前的存储配置中获取 secret key，备份任务的存储位置或 access key 已变更则返回错误
+func (bs *DbBackupStorage) FillSecret(current *DbBackupStorage) error {
+	if bs.Type != DbBackupStorageS3 {
+		return nil
+	}
+	if current.Type != bs.Type || current.Endpoint != bs.Endpoint || current.Bucket != bs.Bucket || current.AccessKey != bs.AccessKey {
+		return errors.New("备份任务的s3存储配置已变更, 无法访问该备份文件")
+	}
+	bs.SecretKey = current.SecretKey
+	return nil
+}
+
+func (bs *DbBackupStorage) SecretEncrypt() error {
+	secretKey, err := utils.PwdAesEncrypt(bs.SecretKey)
+	if err != nil {
+		return errors.New("加密备份存储secret key失败")
+	}
+	bs.SecretKey = secretKey
+	return nil
+}
+
+func (bs *DbBackupStorage) SecretDecrypt() error {
+	secretKey, err := utils.PwdAesDecrypt(bs.SecretKey)
+	if err != nil {
+		return errors.New("解密备份存储secret key失败")
+	}
+	bs.SecretKey = secretKey
+	return nil
+}
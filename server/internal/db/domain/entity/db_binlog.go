@@ -44,7 +44,7 @@ func (b *DbBinlog) Schedule() (time.Time, error) {
 	switch b.GetJobBase().LastStatus {
 	case DbJobSuccess:
 		return time.Time{}, runner.ErrJobFinished
-	case DbJobFailed:
+	case DbJobFailed, DbJobCanceled:
 		return time.Now().Add(BinlogDownloadInterval), nil
 	default:
 		return time.Now(), nil
@@ -0,0 +1,33 @@
+package entity
+
+import "mayfly-go/pkg/model"
+
+// 数据脱敏规则，查询结果及导出数据中匹配的字段值将进行脱敏处理
+type DataMaskRule struct {
+	model.Model
+
+	Name          string `orm:"column(name)" json:"name"`                    // 规则名
+	TagPath       string `orm:"column(tag_path)" json:"tagPath"`             // 生效的标签路径，多个逗号分隔，为空则对所有数据库生效
+	DbPattern     string `orm:"column(db_pattern)" json:"dbPattern"`         // 数据库名匹配规则，支持*通配符，多个逗号分隔，为空则匹配所有库
+	TablePattern  string `orm:"column(table_pattern)" json:"tablePattern"`   // 表名匹配规则，支持*通配符，多个逗号分隔，为空则匹配所有表
+	ColumnPattern string `orm:"column(column_pattern)" json:"columnPattern"` // 字段名匹配规则，支持*通配符，多个逗号分隔
+	MaskType      string `orm:"column(mask_type)" json:"maskType"`           // 脱敏类型
+	Status        int8   `orm:"column(status)" json:"status"`                // 状态 1启用 -1禁用
+	Remark        string `orm:"column(remark)" json:"remark"`
+}
+
+func (d *DataMaskRule) TableName() string {
+	return "t_db_data_mask_rule"
+}
+
+const (
+	DataMaskRuleStatusEnable  int8 = 1  // 启用状态
+	DataMaskRuleStatusDisable int8 = -1 // 禁用状态
+
+	DataMaskTypePhone    = "phone"    // 手机号，保留前3位及后4位
+	DataMaskTypeIdCard   = "idCard"   // 身份证号，保留前4位及后4位
+	DataMaskTypeEmail    = "email"    // 邮箱，保留用户名首字符及域名
+	DataMaskTypeBankCard = "bankCard" // 银行卡号，保留后4位
+	DataMaskTypeName     = "name"     // 姓名，保留首字符
+	DataMaskTypeFull     = "full"     // 完全隐藏
+)
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"encoding/json"
 	"mayfly-go/pkg/model"
 	"time"
 )
@@ -22,6 +23,7 @@ type DataSyncTask struct {
 	SrcTagPath  string `orm:"column(src_tag_path)" json:"srcTagPath"`
 	DataSql     string `orm:"column(data_sql)" json:"dataSql"`          // 数据源查询sql
 	PageSize    int    `orm:"column(page_size)" json:"pageSize"`        // 配置分页sql查询的条数
+	BatchCommit int8   `orm:"column(batch_commit)" json:"batchCommit"`  // 是否每批次(分页大小)提交一次事务 1是 -1否(所有数据一个事务)
 	UpdField    string `orm:"column(upd_field)" json:"updField"`        //更新字段， 选择由哪个字段为更新字段，查询数据源的时候会带上这个字段，如：where update_time > {最近更新的最大值}
 	UpdFieldVal string `orm:"column(upd_field_val)" json:"updFieldVal"` // 更新字段当前值
 
@@ -30,13 +32,53 @@ type DataSyncTask struct {
 	TargetDbName    string `orm:"column(target_db_name)" json:"targetDbName"`
 	TargetTagPath   string `orm:"column(target_tag_path)" json:"targetTagPath"`
 	TargetTableName string `orm:"column(target_table_name)" json:"targetTableName"`
-	FieldMap        string `orm:"column(field_map)" json:"fieldMap"` // 字段映射json
+	FieldMap        string `orm:"column(field_map)" json:"fieldMap"`   // 字段映射json，可指定字段转换规则
+	RowFilter       string `orm:"column(row_filter)" json:"rowFilter"` // 数据行过滤条件json，不满足条件的数据不同步
+
+	DuplicateStrategy int8   `orm:"column(duplicate_strategy)" json:"duplicateStrategy"` // 数据重复时的处理策略 -1报错 1忽略 2更新
+	DuplicateKey      string `orm:"column(duplicate_key)" json:"duplicateKey"`           // 判断数据重复的目标表字段，多个逗号分隔，为空则使用目标表主键
 }
 
 func (d *DataSyncTask) TableName() string {
 	return "t_db_data_sync_task"
 }
 
+// 获取字段映射
+func (d *DataSyncTask) GetFieldMaps() ([]*DataSyncFieldMap, error) {
+	var fieldMaps []*DataSyncFieldMap
+	if err := json.Unmarshal([]byte(d.FieldMap), &fieldMaps); err != nil {
+		return nil, err
+	}
+	return fieldMaps, nil
+}
+
+// 获取数据行过滤条件
+func (d *DataSyncTask) GetRowFilters() ([]*DataSyncRowFilter, error) {
+	var filters []*DataSyncRowFilter
+	if d.RowFilter == "" {
+		return filters, nil
+	}
+	if err := json.Unmarshal([]byte(d.RowFilter), &filters); err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+// 字段映射，如：{"src":"phone","target":"phone","transform":"mask","param":"3,4"}
+type DataSyncFieldMap struct {
+	Src       string `json:"src"`       // 源字段
+	Target    string `json:"target"`    // 目标字段
+	Transform string `json:"transform"` // 转换规则，为空则直接取源字段值
+	Param     string `json:"param"`     // 转换参数，如常量值、默认值、脱敏保留位数、hash算法、日期格式、表达式等
+}
+
+// 数据行过滤条件，多个条件之间为且的关系，如：{"field":"status","op":"=","value":"1"}
+type DataSyncRowFilter struct {
+	Field string `json:"field"` // 源字段
+	Op    string `json:"op"`    // 操作符
+	Value string `json:"value"` // 比较值
+}
+
 type DataSyncLog struct {
 	model.IdModel
 	TaskId      uint64     `orm:"column(task_id)" json:"taskId"` // 任务表id
@@ -62,4 +104,26 @@ const (
 	DataSyncTaskRunStateRunning int8 = 1 // 运行中状态
 	DataSyncTaskRunStateReady   int8 = 2 // 待运行状态
 	DataSyncTaskRunStateStop    int8 = 3 // 手动停止状态
+
+	DataSyncTaskBatchCommitYes int8 = 1  // 每批次提交事务
+	DataSyncTaskBatchCommitNo  int8 = -1 // 所有数据一个事务提交
+)
+
+const (
+	DataSyncTransformConstant   = "constant"   // 常量值，param为常量
+	DataSyncTransformDefault    = "default"    // 源字段值为空时使用默认值，param为默认值
+	DataSyncTransformMask       = "mask"       // 字符串脱敏，param为首尾保留位数，如：3,4
+	DataSyncTransformHash       = "hash"       // 字符串hash，param为hash算法 md5、sha1、sha256(默认)
+	DataSyncTransformDateFormat = "dateFormat" // 日期格式转换，param为目标格式，如：yyyy-MM-dd HH:mm:ss
+	DataSyncTransformExpr       = "expr"       // 表达式，param为模板表达式，可引用源字段，如：{{.first_name}}-{{.last_name}}
+
+	DataSyncFilterOpEq      = "="
+	DataSyncFilterOpNe      = "!="
+	DataSyncFilterOpGt      = ">"
+	DataSyncFilterOpGe      = ">="
+	DataSyncFilterOpLt      = "<"
+	DataSyncFilterOpLe      = "<="
+	DataSyncFilterOpLike    = "like"    // 包含
+	DataSyncFilterOpNull    = "null"    // 为空
+	DataSyncFilterOpNotNull = "notNull" // 不为空
 )
@@ -19,6 +19,7 @@ const (
 	DbJobRunning DbJobStatus = iota
 	DbJobSuccess
 	DbJobFailed
+	DbJobCanceled
 )
 
 type DbJobType = string
@@ -113,6 +114,8 @@ func (d *DbJobBaseImpl) SetLastStatus(status DbJobStatus, err error) {
 		statusName = "成功"
 	case DbJobFailed:
 		statusName = "失败"
+	case DbJobCanceled:
+		statusName = "已取消"
 	default:
 		return
 	}
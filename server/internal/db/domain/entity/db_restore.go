@@ -21,18 +21,41 @@ type DbRestore struct {
 	DbBackupId          uint64         `json:"dbBackupId"`          // 用于恢复的数据库恢复任务ID
 	DbBackupHistoryId   uint64         `json:"dbBackupHistoryId"`   // 用于恢复的数据库恢复历史ID
 	DbBackupHistoryName string         `json:"dbBackupHistoryName"` // 数据库恢复历史名称
+	TargetDbInstanceId  uint64         `json:"targetDbInstanceId"`  // 恢复目标数据库实例ID，为0表示恢复至备份所在的数据库实例
+	TargetDbName        string         `json:"targetDbName"`        // 恢复目标数据库名称，为空表示恢复至备份的数据库
 }
 
 func (r *DbRestore) GetDbName() string {
 	return r.DbName
 }
 
+// GetTargetDbInstanceId 获取恢复目标数据库实例ID
+func (r *DbRestore) GetTargetDbInstanceId() uint64 {
+	if r.TargetDbInstanceId == 0 {
+		return r.DbInstanceId
+	}
+	return r.TargetDbInstanceId
+}
+
+// GetTargetDbName 获取恢复目标数据库名称
+func (r *DbRestore) GetTargetDbName() string {
+	if r.TargetDbName == "" {
+		return r.DbName
+	}
+	return r.TargetDbName
+}
+
+// IsRestoreToSource 是否恢复至备份所在的数据库
+func (r *DbRestore) IsRestoreToSource() bool {
+	return r.GetTargetDbInstanceId() == r.DbInstanceId && r.GetTargetDbName() == r.DbName
+}
+
 func (r *DbRestore) Schedule() (time.Time, error) {
 	if !r.Enabled {
 		return time.Time{}, runner.ErrJobDisabled
 	}
 	switch r.LastStatus {
-	case DbJobSuccess, DbJobFailed:
+	case DbJobSuccess, DbJobFailed, DbJobCanceled:
 		return time.Time{}, runner.ErrJobFinished
 	default:
 		if time.Now().Sub(r.StartTime) > time.Hour {
@@ -11,6 +11,7 @@ type DbRestoreHistory struct {
 
 	CreateTime  time.Time `orm:"column(create_time)" json:"createTime"` // 创建时间: 2023-11-08 02:00:00
 	DbRestoreId uint64    `orm:"column(db_restore_id)" json:"dbRestoreId"`
+	Progress    string    `json:"progress"` // 恢复进度json
 }
 
 func (d *DbRestoreHistory) TableName() string {
@@ -0,0 +1,36 @@
+package entity
+
+import "mayfly-go/pkg/model"
+
+// sql审核规则，执行sql前根据匹配的规则检查sql风险
+type SqlAuditRule struct {
+	model.Model
+
+	Name      string `orm:"column(name)" json:"name"`           // 规则名
+	TagPath   string `orm:"column(tag_path)" json:"tagPath"`    // 生效的标签路径，多个逗号分隔，为空则对所有数据库生效
+	RuleType  string `orm:"column(rule_type)" json:"ruleType"`  // 规则类型
+	Level     int8   `orm:"column(level)" json:"level"`         // 风险等级 1警告 2需确认 3禁止执行
+	Threshold int64  `orm:"column(threshold)" json:"threshold"` // 表行数阈值，仅对涉及表行数的规则生效，为0则不限制表行数
+	Status    int8   `orm:"column(status)" json:"status"`       // 状态 1启用 -1禁用
+	Remark    string `orm:"column(remark)" json:"remark"`
+}
+
+func (d *SqlAuditRule) TableName() string {
+	return "t_db_sql_audit_rule"
+}
+
+const (
+	SqlAuditRuleStatusEnable  int8 = 1  // 启用状态
+	SqlAuditRuleStatusDisable int8 = -1 // 禁用状态
+
+	SqlAuditLevelWarn    int8 = 1 // 警告，仍执行并返回警告信息
+	SqlAuditLevelConfirm int8 = 2 // 需用户确认后执行
+	SqlAuditLevelBlock   int8 = 3 // 禁止执行
+
+	SqlAuditTypeNoWhere       = "noWhere"       // update、delete未带where条件
+	SqlAuditTypeSelectStar    = "selectStar"    // 对大表执行select *
+	SqlAuditTypeDropTruncate  = "dropTruncate"  // drop、truncate语句
+	SqlAuditTypeNoLimit       = "noLimit"       // 查询未带limit
+	SqlAuditTypeFullScan      = "fullScan"      // 执行计划存在全表扫描
+	SqlAuditTypeDdlLargeTable = "ddlLargeTable" // 对大表执行ddl
+)
@@ -1,6 +1,9 @@
 package entity
 
-import "mayfly-go/pkg/model"
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
 
 // 数据库sql执行记录
 type DbSqlExec struct {
@@ -13,6 +16,15 @@ type DbSqlExec struct {
 	Sql      string `json:"sql"`  // 执行的sql
 	OldValue string `json:"oldValue"`
 	Remark   string `json:"remark"`
+	Status   int8   `json:"status"` // 执行状态
+	Res      string `json:"res"`    // 执行结果
+
+	ApproverId    uint64     `json:"approverId"`    // 审批人id
+	Approver      string     `json:"approver"`      // 审批人
+	ApproveTime   *time.Time `json:"approveTime"`   // 审批时间
+	ApproveRemark string     `json:"approveRemark"` // 审批备注
+	ExpireTime    *time.Time `json:"expireTime"`    // 审批过期时间
+	RollbackId    uint64     `json:"rollbackId"`    // 回滚的sql执行记录id，回滚记录才有值
 }
 
 const (
@@ -21,4 +33,12 @@ const (
 	DbSqlExecTypeDelete int8 = 2  // 删除类型
 	DbSqlExecTypeInsert int8 = 3  // 插入类型
 	DbSqlExecTypeQuery  int8 = 4  // 查询类型，如select、show等
+
+	DbSqlExecStatusWait       int8 = 1  // 待审批
+	DbSqlExecStatusSuccess    int8 = 2  // 执行成功
+	DbSqlExecStatusRolledBack int8 = 3  // 已回滚
+	DbSqlExecStatusApproving  int8 = 4  // 审批执行中
+	DbSqlExecStatusFail       int8 = -1 // 执行失败
+	DbSqlExecStatusReject     int8 = -2 // 审批拒绝
+	DbSqlExecStatusExpired    int8 = -3 // 审批过期
 )
@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"encoding/json"
+	"mayfly-go/pkg/model"
+	"strings"
+	"time"
+)
+
+// 数据库迁移任务，将源库的表结构及数据迁移至目标库（支持不同类型数据库）
+type DbTransferTask struct {
+	model.Model
+
+	TaskName      string `orm:"column(task_name)" json:"taskName"`           // 任务名
+	Mode          int8   `orm:"column(mode)" json:"mode"`                    // 迁移模式 1结构+数据 2仅结构 3仅数据
+	TableStrategy int8   `orm:"column(table_strategy)" json:"tableStrategy"` // 目标表已存在时的处理策略 1保留(不建表) 2删除后重建
+	BatchSize     int    `orm:"column(batch_size)" json:"batchSize"`         // 每批次插入的数据条数
+	RunningState  int8   `orm:"column(running_state)" json:"runningState"`   // 运行时状态 1运行中、2待运行、3已停止
+	RecentState   int8   `orm:"column(recent_state)" json:"recentState"`     // 最近执行状态 1成功 -1失败
+	Res           string `orm:"column(res)" json:"res"`                      // 最近执行结果
+	Checkpoint    string `orm:"column(checkpoint)" json:"checkpoint"`        // 各表迁移进度json，用于中断后继续迁移
+
+	// 源数据库信息
+	SrcDbId    uint64 `orm:"column(src_db_id)" json:"srcDbId"`
+	SrcDbName  string `orm:"column(src_db_name)" json:"srcDbName"`
+	SrcTagPath string `orm:"column(src_tag_path)" json:"srcTagPath"`
+	SrcTables  string `orm:"column(src_tables)" json:"srcTables"` // 需迁移的表名，逗号分隔，为空则迁移所有表
+
+	// 目标数据库信息
+	TargetDbId    uint64 `orm:"column(target_db_id)" json:"targetDbId"`
+	TargetDbName  string `orm:"column(target_db_name)" json:"targetDbName"`
+	TargetTagPath string `orm:"column(target_tag_path)" json:"targetTagPath"`
+}
+
+func (d *DbTransferTask) TableName() string {
+	return "t_db_transfer_task"
+}
+
+// 获取需迁移的表名，为空则迁移所有表
+func (d *DbTransferTask) GetSrcTables() []string {
+	if d.SrcTables == "" {
+		return nil
+	}
+	return strings.Split(d.SrcTables, ",")
+}
+
+// 是否需要迁移表结构
+func (d *DbTransferTask) NeedStruct() bool {
+	return d.Mode != DbTransferModeData
+}
+
+// 是否需要迁移数据
+func (d *DbTransferTask) NeedData() bool {
+	return d.Mode != DbTransferModeStruct
+}
+
+// 获取各表迁移进度
+func (d *DbTransferTask) GetCheckpoint() DbTransferCheckpoint {
+	checkpoint := make(DbTransferCheckpoint)
+	if d.Checkpoint != "" {
+		_ = json.Unmarshal([]byte(d.Checkpoint), &checkpoint)
+	}
+	return checkpoint
+}
+
+// 单表迁移进度
+type DbTransferTableCheckpoint struct {
+	Status     int8           `json:"status"`            // 迁移状态 1迁移中 2已完成 -1失败
+	Rows       int64          `json:"rows"`              // 已迁移的数据条数
+	LastKey    map[string]any `json:"lastKey,omitempty"` // 最后一条已提交数据的主键值，用于中断后按主键继续迁移
+	Err        string         `json:"err"`               // 失败原因
+	UpdateTime *time.Time     `json:"updateTime"`        // 更新时间
+}
+
+// 各表迁移进度，key为表名
+type DbTransferCheckpoint map[string]*DbTransferTableCheckpoint
+
+// 表是否已迁移完成
+func (c DbTransferCheckpoint) IsDone(tableName string) bool {
+	tc := c[tableName]
+	return tc != nil && tc.Status == DbTransferTableStatusDone
+}
+
+func (c DbTransferCheckpoint) Set(tableName string, status int8, rows int64, lastKey map[string]any, err string) {
+	now := time.Now()
+	c[tableName] = &DbTransferTableCheckpoint{Status: status, Rows: rows, LastKey: lastKey, Err: err, UpdateTime: &now}
+}
+
+func (c DbTransferCheckpoint) String() string {
+	bytes, _ := json.Marshal(c)
+	return string(bytes)
+}
+
+const (
+	DbTransferModeAll    int8 = 1 // 迁移结构及数据
+	DbTransferModeStruct int8 = 2 // 仅迁移结构
+	DbTransferModeData   int8 = 3 // 仅迁移数据
+
+	DbTransferTableStrategyKeep     int8 = 1 // 目标表已存在则保留
+	DbTransferTableStrategyRecreate int8 = 2 // 目标表已存在则删除后重建
+
+	DbTransferRunStateRunning int8 = 1 // 运行中状态
+	DbTransferRunStateReady   int8 = 2 // 待运行状态
+	DbTransferRunStateStop    int8 = 3 // 手动停止状态
+
+	DbTransferStateSuccess int8 = 1  // 执行成功状态
+	DbTransferStateFail    int8 = -1 // 执行失败状态
+
+	DbTransferTableStatusRunning int8 = 1  // 表迁移中
+	DbTransferTableStatusDone    int8 = 2  // 表迁移完成
+	DbTransferTableStatusFail    int8 = -1 // 表迁移失败
+)
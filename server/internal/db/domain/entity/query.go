@@ -31,11 +31,12 @@ type DbQuery struct {
 }
 
 type DbSqlExecQuery struct {
-	Id    uint64 `json:"id" form:"id"`
-	DbId  uint64 `json:"dbId" form:"dbId"`
-	Db    string `json:"db" form:"db"`
-	Table string `json:"table" form:"table"`
-	Type  int8   `json:"type" form:"type"` // 类型
+	Id     uint64 `json:"id" form:"id"`
+	DbId   uint64 `json:"dbId" form:"dbId"`
+	Db     string `json:"db" form:"db"`
+	Table  string `json:"table" form:"table"`
+	Type   int8   `json:"type" form:"type"`     // 类型
+	Status int8   `json:"status" form:"status"` // 执行状态
 
 	CreatorId uint64
 }
@@ -74,3 +75,17 @@ type DbRestoreHistoryQuery struct {
 	Id          uint64 `json:"id" form:"id"`
 	DbRestoreId uint64 `json:"dbRestoreId" form:"dbRestoreId"`
 }
+
+type DbTransferTaskQuery struct {
+	Name string `json:"name" form:"name"`
+}
+
+type DataMaskRuleQuery struct {
+	Name    string `json:"name" form:"name"`
+	TagPath string `json:"tagPath" form:"tagPath"`
+}
+
+type SqlAuditRuleQuery struct {
+	Name    string `json:"name" form:"name"`
+	TagPath string `json:"tagPath" form:"tagPath"`
+}
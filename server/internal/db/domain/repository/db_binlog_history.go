@@ -16,6 +16,8 @@ type DbBinlogHistory interface {
 
 	GetLatestHistory(instanceId uint64) (*entity.DbBinlogHistory, bool, error)
 
+	GetEarliestHistory(instanceId uint64) (*entity.DbBinlogHistory, bool, error)
+
 	InsertWithBinlogFiles(ctx context.Context, instanceId uint64, binlogFiles []*entity.BinlogFile) error
 
 	Upsert(ctx context.Context, history *entity.DbBinlogHistory) error
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type DataMaskRule interface {
+	base.Repo[*entity.DataMaskRule]
+
+	// 分页获取数据脱敏规则列表
+	GetPageList(condition *entity.DataMaskRuleQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type SqlAuditRule interface {
+	base.Repo[*entity.SqlAuditRule]
+
+	// 分页获取sql审核规则列表
+	GetPageList(condition *entity.SqlAuditRuleQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+}
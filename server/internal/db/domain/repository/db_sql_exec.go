@@ -4,6 +4,7 @@ import (
 	"mayfly-go/internal/db/domain/entity"
 	"mayfly-go/pkg/base"
 	"mayfly-go/pkg/model"
+	"time"
 )
 
 type DbSqlExec interface {
@@ -11,4 +12,10 @@ type DbSqlExec interface {
 
 	// 分页获取
 	GetPageList(condition *entity.DbSqlExecQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+
+	// 仅当记录状态为fromStatus时才更新为toStatus，返回是否更新成功（用于并发下抢占记录）
+	UpdateStatus(id uint64, fromStatus, toStatus int8) (bool, error)
+
+	// 将审批过期时间早于now的待审批记录置为审批过期，返回更新的记录数
+	ExpireApprovals(now time.Time) (int64, error)
 }
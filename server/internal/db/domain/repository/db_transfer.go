@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type DbTransferTask interface {
+	base.Repo[*entity.DbTransferTask]
+
+	// 分页获取数据库迁移任务列表
+	GetTaskList(condition *entity.DbTransferTaskQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error)
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"fmt"
+	global_cache "mayfly-go/pkg/cache"
+	"mayfly-go/pkg/utils/jsonx"
+	"time"
+)
+
+// 库元数据缓存key，参数为数据库连接id（dbId:库名，pgsql等为dbId:库名/schema）
+const DbMetaCacheKey = "mayfly:db:meta:%s"
+
+// 库元数据缓存，用于sql编辑器的表及字段提示
+type DbMeta struct {
+	BaseVersion int64                 `json:"baseVersion"` // 可增量获取的最小版本，早于该版本则需获取全量信息
+	Version     int64                 `json:"version"`     // 当前版本，表存在变更时更新
+	RefreshTime int64                 `json:"refreshTime"` // 最近刷新时间（毫秒），为0表示已失效需重新加载
+	Tables      map[string]*TableMeta `json:"tables"`
+	Dropped     map[string]int64      `json:"dropped"` // 已删除的表名 -> 删除时的版本
+}
+
+type TableMeta struct {
+	Version int64    `json:"version"` // 表最近变更时的版本
+	Columns []string `json:"columns"` // 字段提示信息，如：name  [varchar(32)][名称]
+}
+
+func SaveDbMeta(connId string, dbMeta *DbMeta) error {
+	return global_cache.SetStr(fmt.Sprintf(DbMetaCacheKey, connId), jsonx.ToStr(dbMeta), 24*time.Hour)
+}
+
+func GetDbMeta(connId string) *DbMeta {
+	cacheStr := global_cache.GetStr(fmt.Sprintf(DbMetaCacheKey, connId))
+	if cacheStr == "" {
+		return nil
+	}
+	dbMeta, err := jsonx.To(cacheStr, new(DbMeta))
+	if err != nil {
+		return nil
+	}
+	return dbMeta
+}
+
+func DelDbMeta(connId string) {
+	global_cache.Del(fmt.Sprintf(DbMetaCacheKey, connId))
+}
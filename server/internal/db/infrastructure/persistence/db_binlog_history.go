@@ -73,6 +73,24 @@ func (repo *dbBinlogHistoryRepoImpl) GetLatestHistory(instanceId uint64) (*entit
 	}
 }
 
+func (repo *dbBinlogHistoryRepoImpl) GetEarliestHistory(instanceId uint64) (*entity.DbBinlogHistory, bool, error) {
+	history := &entity.DbBinlogHistory{}
+	err := gormx.NewQuery(repo.GetModel()).
+		Eq("db_instance_id", instanceId).
+		Undeleted().
+		OrderByAsc("sequence").
+		GenGdb().
+		First(history).Error
+	switch {
+	case err == nil:
+		return history, true, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return history, false, nil
+	default:
+		return nil, false, err
+	}
+}
+
 func (repo *dbBinlogHistoryRepoImpl) Upsert(_ context.Context, history *entity.DbBinlogHistory) error {
 	return gormx.Tx(func(db *gorm.DB) error {
 		old := &entity.DbBinlogHistory{}
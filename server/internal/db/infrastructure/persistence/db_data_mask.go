@@ -0,0 +1,26 @@
+package persistence
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/gormx"
+	"mayfly-go/pkg/model"
+)
+
+type dataMaskRuleRepoImpl struct {
+	base.RepoImpl[*entity.DataMaskRule]
+}
+
+func newDataMaskRuleRepo() repository.DataMaskRule {
+	return &dataMaskRuleRepoImpl{base.RepoImpl[*entity.DataMaskRule]{M: new(entity.DataMaskRule)}}
+}
+
+// 分页获取数据脱敏规则列表
+func (d *dataMaskRuleRepoImpl) GetPageList(condition *entity.DataMaskRuleQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	qd := gormx.NewQuery(new(entity.DataMaskRule)).
+		Like("name", condition.Name).
+		Like("tag_path", condition.TagPath).
+		OrderByDesc("id")
+	return gormx.PageQuery(qd, pageParam, toEntity)
+}
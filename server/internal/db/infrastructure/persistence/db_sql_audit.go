@@ -0,0 +1,26 @@
+package persistence
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/gormx"
+	"mayfly-go/pkg/model"
+)
+
+type sqlAuditRuleRepoImpl struct {
+	base.RepoImpl[*entity.SqlAuditRule]
+}
+
+func newSqlAuditRuleRepo() repository.SqlAuditRule {
+	return &sqlAuditRuleRepoImpl{base.RepoImpl[*entity.SqlAuditRule]{M: new(entity.SqlAuditRule)}}
+}
+
+// 分页获取sql审核规则列表
+func (d *sqlAuditRuleRepoImpl) GetPageList(condition *entity.SqlAuditRuleQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	qd := gormx.NewQuery(new(entity.SqlAuditRule)).
+		Like("name", condition.Name).
+		Like("tag_path", condition.TagPath).
+		OrderByDesc("id")
+	return gormx.PageQuery(qd, pageParam, toEntity)
+}
@@ -4,8 +4,10 @@ import (
 	"mayfly-go/internal/db/domain/entity"
 	"mayfly-go/internal/db/domain/repository"
 	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/global"
 	"mayfly-go/pkg/gormx"
 	"mayfly-go/pkg/model"
+	"time"
 )
 
 type dbSqlExecRepoImpl struct {
@@ -22,7 +24,21 @@ func (d *dbSqlExecRepoImpl) GetPageList(condition *entity.DbSqlExecQuery, pagePa
 		Eq("db_id", condition.DbId).
 		Eq("`table`", condition.Table).
 		Eq("type", condition.Type).
+		Eq("status", condition.Status).
 		Eq("creator_id", condition.CreatorId).
 		RLike("db", condition.Db).WithOrderBy(orderBy...)
 	return gormx.PageQuery(qd, pageParam, toEntity)
 }
+
+func (d *dbSqlExecRepoImpl) UpdateStatus(id uint64, fromStatus, toStatus int8) (bool, error) {
+	res := global.Db.Model(d.GetModel()).Where("id = ? AND status = ?", id, fromStatus).Update("status", toStatus)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (d *dbSqlExecRepoImpl) ExpireApprovals(now time.Time) (int64, error) {
+	res := global.Db.Model(d.GetModel()).Where("status = ? AND expire_time < ?", entity.DbSqlExecStatusWait, now).Update("status", entity.DbSqlExecStatusExpired)
+	return res.RowsAffected, res.Error
+}
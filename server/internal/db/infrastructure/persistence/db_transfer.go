@@ -0,0 +1,25 @@
+package persistence
+
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/gormx"
+	"mayfly-go/pkg/model"
+)
+
+type dbTransferTaskRepoImpl struct {
+	base.RepoImpl[*entity.DbTransferTask]
+}
+
+func newDbTransferTaskRepo() repository.DbTransferTask {
+	return &dbTransferTaskRepoImpl{base.RepoImpl[*entity.DbTransferTask]{M: new(entity.DbTransferTask)}}
+}
+
+// 分页获取数据库迁移任务列表
+func (d *dbTransferTaskRepoImpl) GetTaskList(condition *entity.DbTransferTaskQuery, pageParam *model.PageParam, toEntity any, orderBy ...string) (*model.PageResult[any], error) {
+	qd := gormx.NewQuery(new(entity.DbTransferTask)).
+		Like("task_name", condition.Name).
+		OrderByDesc("id")
+	return gormx.PageQuery(qd, pageParam, toEntity)
+}
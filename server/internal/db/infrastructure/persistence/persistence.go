@@ -14,6 +14,9 @@ func Init() {
 	ioc.Register(NewDbRestoreHistoryRepo(), ioc.WithComponentName("DbRestoreHistoryRepo"))
 	ioc.Register(newDataSyncTaskRepo(), ioc.WithComponentName("DbDataSyncTaskRepo"))
 	ioc.Register(newDataSyncLogRepo(), ioc.WithComponentName("DbDataSyncLogRepo"))
+	ioc.Register(newDbTransferTaskRepo(), ioc.WithComponentName("DbTransferTaskRepo"))
+	ioc.Register(newDataMaskRuleRepo(), ioc.WithComponentName("DbDataMaskRuleRepo"))
+	ioc.Register(newSqlAuditRuleRepo(), ioc.WithComponentName("DbSqlAuditRuleRepo"))
 }
 
 func GetInstanceRepo() repository.Instance {
@@ -31,8 +31,41 @@ func InitDbRouter(router *gin.RouterGroup) {
 
 		req.NewPost(":dbId/exec-sql-file", d.ExecSqlFile).Log(req.NewLogSave("db-执行Sql文件")),
 
+		// 获取sql执行计划
+		req.NewPost(":dbId/explain", d.ExplainSql).Log(req.NewLog("db-获取Sql执行计划")),
+
+		// 分页查询表数据
+		req.NewPost(":dbId/table-data", d.GetTableData),
+
+		// 根据主键保存表数据修改
+		req.NewPost(":dbId/row-changes", d.SaveRowChanges).Log(req.NewLogSave("db-保存表数据修改")),
+
+		// 获取当前账号执行中的sql
+		req.NewGet("running-sqls", d.RunningSqls),
+
+		req.NewPost("running-sqls/:execId/cancel", d.CancelRunningSql).Log(req.NewLog("db-取消执行Sql")),
+
 		req.NewGet(":dbId/dump", d.DumpSql).Log(req.NewLogSave("db-导出sql文件")).NoRes(),
 
+		req.NewGet(":dbId/export-query", d.ExportQuery).Log(req.NewLogSave("db-导出查询结果")).RequiredPermissionCode("db:export:query").NoRes(),
+
+		// 表结构比对
+		req.NewPost("schema-diff", d.SchemaDiff).Log(req.NewLog("db-表结构比对")),
+
+		req.NewGet("schema-diff/script", d.DownloadSchemaDiffSql).Log(req.NewLog("db-下载表结构同步sql")).NoRes(),
+
+		req.NewPost("schema-diff/exec", d.ExecSchemaDiffSql).Log(req.NewLogSave("db-执行表结构同步sql")),
+
+		// 根据表结构定义预览及执行建表或修改表sql
+		req.NewPost(":dbId/table-design/preview", d.PreviewTableDesignSql),
+
+		req.NewPost(":dbId/table-design/exec", d.ExecTableDesignSql).Log(req.NewLogSave("db-执行表结构设计sql")),
+
+		// 导入csv、xlsx、jsonl数据文件
+		req.NewPost(":dbId/import-data/preview", d.PreviewImportData),
+
+		req.NewPost(":dbId/import-data", d.ImportData).Log(req.NewLogSave("db-导入数据文件")),
+
 		req.NewGet(":dbId/t-infos", d.TableInfos),
 
 		req.NewGet(":dbId/t-index", d.TableIndex),
@@ -41,6 +74,8 @@ func InitDbRouter(router *gin.RouterGroup) {
 
 		req.NewGet(":dbId/hint-tables", d.HintTables),
 
+		req.NewGet(":dbId/hint-tables/changes", d.HintTablesChange),
+
 		req.NewGet(":dbId/restore-task", d.GetRestoreTask),
 		req.NewPost(":dbId/restore-task", d.SaveRestoreTask).
 			Log(req.NewLogSave("db-保存数据库恢复任务")),
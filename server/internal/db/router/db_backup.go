@@ -3,6 +3,8 @@ package router
 import (
 	"mayfly-go/internal/db/api"
 	"mayfly-go/internal/db/application"
+	machineapp "mayfly-go/internal/machine/application"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/req"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +16,8 @@ func InitDbBackupRouter(router *gin.RouterGroup) {
 	d := &api.DbBackup{
 		DbBackupApp: application.GetDbBackupApp(),
 		DbApp:       application.GetDbApp(),
+		MachineApp:  machineapp.GetMachineApp(),
+		TagApp:      tagapp.GetTagTreeApp(),
 	}
 
 	reqs := []*req.Conf{
@@ -29,6 +33,8 @@ func InitDbBackupRouter(router *gin.RouterGroup) {
 		req.NewPut(":dbId/backups/:backupId/disable", d.Disable).Log(req.NewLogSave("db-禁用数据库备份任务")),
 		// 立即启动数据库备份任务
 		req.NewPut(":dbId/backups/:backupId/start", d.Start).Log(req.NewLogSave("db-立即启动数据库备份任务")),
+		// 取消正在运行的数据库备份任务
+		req.NewPut(":dbId/backups/:backupId/cancel", d.Cancel).Log(req.NewLogSave("db-取消数据库备份任务")),
 		// 删除数据库备份任务
 		req.NewDelete(":dbId/backups/:backupId", d.Delete),
 		// 获取未配置定时备份的数据库名称
@@ -36,6 +42,8 @@ func InitDbBackupRouter(router *gin.RouterGroup) {
 
 		// 获取数据库备份历史
 		req.NewGet(":dbId/backup-histories/", d.GetHistoryPageList),
+		// 校验数据库备份
+		req.NewPost(":dbId/backup-histories/:historyId/verify", d.VerifyHistory).Log(req.NewLogSave("db-校验数据库备份")),
 	}
 
 	req.BatchSetGroup(dbs, reqs)
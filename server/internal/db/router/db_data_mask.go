@@ -0,0 +1,28 @@
+package router
+
+import (
+	"mayfly-go/internal/db/api"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ioc"
+	"mayfly-go/pkg/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InitDbDataMaskRouter(router *gin.RouterGroup) {
+	rules := router.Group("/dbdatamask/rules")
+
+	d := new(api.DataMaskRule)
+	biz.ErrIsNil(ioc.Inject(d))
+
+	reqs := [...]*req.Conf{
+		// 获取数据脱敏规则列表
+		req.NewGet("", d.Rules),
+
+		req.NewPost("", d.SaveRule).Log(req.NewLogSave("db-保存数据脱敏规则")).RequiredPermissionCode("db:datamask:save"),
+
+		req.NewDelete(":ruleId", d.DeleteRule).Log(req.NewLogSave("db-删除数据脱敏规则")).RequiredPermissionCode("db:datamask:del"),
+	}
+
+	req.BatchSetGroup(rules, reqs[:])
+}
@@ -24,6 +24,9 @@ func InitDbDataSyncRouter(router *gin.RouterGroup) {
 		// 保存任务 /datasync/save
 		req.NewPost("save", d.SaveTask).Log(req.NewLogSave("datasync-保存数据同步任务信息")).RequiredPermissionCode("db:sync:save"),
 
+		// 预览字段转换后的同步数据 /datasync/preview
+		req.NewPost("preview", d.Preview).RequiredPermissionCode("db:sync:save"),
+
 		// 获取单个详情 /datasync/:taskId
 		req.NewGet(":taskId", d.GetTask),
 
@@ -33,11 +36,11 @@ func InitDbDataSyncRouter(router *gin.RouterGroup) {
 		// 启停用任务 /datasync/status
 		req.NewPost(":taskId/status", d.ChangeStatus).Log(req.NewLogSave("datasync-启停任务")).RequiredPermissionCode("db:sync:status"),
 
-		// 立即执行任务 /datasync/run
-		req.NewPost(":taskId/run", d.Run),
+		// 立即执行任务，已停止的任务从上次提交的位置继续执行 /datasync/run
+		req.NewPost(":taskId/run", d.Run).Log(req.NewLogSave("datasync-执行任务")),
 
-		// 停止正在执行中的任务
-		req.NewPost(":taskId/stop", d.Stop),
+		// 停止正在执行中的任务，停止后定时任务不再执行，需手动执行以恢复
+		req.NewPost(":taskId/stop", d.Stop).Log(req.NewLogSave("datasync-停止任务")),
 	}
 
 	req.BatchSetGroup(instances, reqs[:])
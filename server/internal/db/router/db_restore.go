@@ -3,6 +3,7 @@ package router
 import (
 	"mayfly-go/internal/db/api"
 	"mayfly-go/internal/db/application"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/req"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,7 @@ func InitDbRestoreRouter(router *gin.RouterGroup) {
 	d := &api.DbRestore{
 		DbRestoreApp: application.GetDbRestoreApp(),
 		DbApp:        application.GetDbApp(),
+		TagApp:       tagapp.GetTagTreeApp(),
 	}
 
 	reqs := []*req.Conf{
@@ -27,6 +29,8 @@ func InitDbRestoreRouter(router *gin.RouterGroup) {
 		req.NewPut(":dbId/restores/:restoreId/enable", d.Enable).Log(req.NewLogSave("db-启用数据库恢复任务")),
 		// 禁用数据库备份任务
 		req.NewPut(":dbId/restores/:restoreId/disable", d.Disable).Log(req.NewLogSave("db-禁用数据库恢复任务")),
+		// 取消正在运行的数据库恢复任务
+		req.NewPut(":dbId/restores/:restoreId/cancel", d.Cancel).Log(req.NewLogSave("db-取消数据库恢复任务")),
 		// 删除数据库备份任务
 		req.NewDelete(":dbId/restores/:restoreId", d.Delete),
 		// 获取未配置定时恢复的数据库名称
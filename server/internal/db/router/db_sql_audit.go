@@ -0,0 +1,28 @@
+package router
+
+import (
+	"mayfly-go/internal/db/api"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ioc"
+	"mayfly-go/pkg/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InitDbSqlAuditRouter(router *gin.RouterGroup) {
+	rules := router.Group("/dbsqlaudit/rules")
+
+	d := new(api.SqlAuditRule)
+	biz.ErrIsNil(ioc.Inject(d))
+
+	reqs := [...]*req.Conf{
+		// 获取sql审核规则列表
+		req.NewGet("", d.Rules),
+
+		req.NewPost("", d.SaveRule).Log(req.NewLogSave("db-保存sql审核规则")).RequiredPermissionCode("db:sqlaudit:save"),
+
+		req.NewDelete(":ruleId", d.DeleteRule).Log(req.NewLogSave("db-删除sql审核规则")).RequiredPermissionCode("db:sqlaudit:del"),
+	}
+
+	req.BatchSetGroup(rules, reqs[:])
+}
@@ -15,7 +15,20 @@ func InitDbSqlExecRouter(router *gin.RouterGroup) {
 	d := new(api.DbSqlExec)
 	biz.ErrIsNil(ioc.Inject(d))
 
-	// 获取所有数据库sql执行记录列表
-	req.NewGet("", d.DbSqlExecs).Group(db)
+	reqs := [...]*req.Conf{
+		// 获取所有数据库sql执行记录列表
+		req.NewGet("", d.DbSqlExecs),
+
+		// 审批待执行的sql
+		req.NewPost(":execId/approve", d.Approve).Log(req.NewLogSave("db-审批sql执行")),
+
+		// 获取回滚sql
+		req.NewGet(":execId/rollback-sql", d.GetRollbackSql),
+
+		// 执行回滚sql
+		req.NewPost(":execId/rollback", d.Rollback).Log(req.NewLogSave("db-回滚sql执行")),
+	}
+
+	req.BatchSetGroup(db, reqs[:])
 
 }
@@ -0,0 +1,35 @@
+package router
+
+import (
+	"mayfly-go/internal/db/api"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/ioc"
+	"mayfly-go/pkg/req"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InitDbTransferRouter(router *gin.RouterGroup) {
+	tasks := router.Group("/dbtransfer/tasks")
+
+	d := new(api.DbTransferTask)
+	biz.ErrIsNil(ioc.Inject(d))
+
+	reqs := [...]*req.Conf{
+		// 获取迁移任务列表
+		req.NewGet("", d.Tasks),
+
+		req.NewPost("save", d.SaveTask).Log(req.NewLogSave("dbtransfer-保存数据库迁移任务")).RequiredPermissionCode("db:transfer:save"),
+
+		req.NewDelete(":taskId/del", d.DeleteTask).Log(req.NewLogSave("dbtransfer-删除数据库迁移任务")).RequiredPermissionCode("db:transfer:del"),
+
+		// 预览目标库建表语句
+		req.NewGet(":taskId/create-table-sql", d.CreateTableSql),
+
+		req.NewPost(":taskId/run", d.Run).Log(req.NewLogSave("dbtransfer-执行数据库迁移任务")).RequiredPermissionCode("db:transfer:run"),
+
+		req.NewPost(":taskId/stop", d.Stop).Log(req.NewLogSave("dbtransfer-停止数据库迁移任务")).RequiredPermissionCode("db:transfer:run"),
+	}
+
+	req.BatchSetGroup(tasks, reqs[:])
+}
@@ -10,4 +10,7 @@ func Init(router *gin.RouterGroup) {
 	InitDbBackupRouter(router)
 	InitDbRestoreRouter(router)
 	InitDbDataSyncRouter(router)
+	InitDbTransferRouter(router)
+	InitDbDataMaskRouter(router)
+	InitDbSqlAuditRouter(router)
 }
@@ -33,6 +33,7 @@ type wrapper[T Job] struct {
 	removed  bool
 	status   JobStatus
 	job      T
+	cancel   context.CancelCauseFunc // 取消正在运行的任务
 }
 
 func newWrapper[T Job](job T) *wrapper[T] {
@@ -16,6 +16,8 @@ var (
 	ErrJobFinished = errors.New("job already finished")
 	ErrJobDisabled = errors.New("job has been disabled")
 	ErrJobTimeout  = errors.New("job has timed out")
+	ErrJobCanceled = errors.New("job has been canceled")
+	ErrJobNotRun   = errors.New("job is not running")
 )
 
 type JobKey = string
@@ -214,7 +216,13 @@ func (r *Runner[T]) doRun(wrap *wrapper[T]) {
 		}
 	}()
 
-	r.runJob(r.context, wrap.job)
+	ctx, cancel := context.WithCancelCause(r.context)
+	defer cancel(nil)
+	r.mutex.Lock()
+	wrap.cancel = cancel
+	r.mutex.Unlock()
+
+	r.runJob(ctx, wrap.job)
 }
 
 func (r *Runner[T]) afterRun(wrap *wrapper[T]) {
@@ -224,6 +232,7 @@ func (r *Runner[T]) afterRun(wrap *wrapper[T]) {
 	r.running.Remove(wrap.key)
 	delete(r.all, wrap.key)
 	wrap.status = JobUnknown
+	wrap.cancel = nil
 	r.trigger()
 	if wrap.removed {
 		return
@@ -376,3 +385,16 @@ func (r *Runner[T]) Remove(ctx context.Context, key JobKey) error {
 	}
 	return nil
 }
+
+// Cancel 取消正在运行的任务，任务的 context 将被取消，取消原因为 ErrJobCanceled
+func (r *Runner[T]) Cancel(key JobKey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	wrap, ok := r.running.Get(key)
+	if !ok || wrap.cancel == nil {
+		return ErrJobNotRun
+	}
+	wrap.cancel(ErrJobCanceled)
+	return nil
+}
@@ -121,3 +121,26 @@ func TestJob_UpdateStatus(t *testing.T) {
 	assert.Equal(t, finished, first.status)
 	assert.Equal(t, finished, second.status)
 }
+
+func TestRunner_Cancel(t *testing.T) {
+	started := make(chan struct{})
+	canceled := make(chan error, 1)
+	runner := NewRunner[*testJob](1, func(ctx context.Context, job *testJob) {
+		close(started)
+		<-ctx.Done()
+		canceled <- context.Cause(ctx)
+	})
+	defer runner.Close()
+
+	job := newTestJob("cancel")
+	require.ErrorIs(t, runner.Cancel(job.GetKey()), ErrJobNotRun)
+	require.NoError(t, runner.Add(context.Background(), job))
+	<-started
+	require.NoError(t, runner.Cancel(job.GetKey()))
+	select {
+	case err := <-canceled:
+		require.ErrorIs(t, err, ErrJobCanceled)
+	case <-time.After(time.Second):
+		require.FailNow(t, "任务未能及时取消")
+	}
+}
@@ -55,6 +55,8 @@ CREATE TABLE IF NOT EXISTS "t_db_backup" (
   "last_status" integer(4),
   "last_result" text(256),
   "last_time"  datetime,
+  "retention" text(500),
+  "storage" text(1000),
   "create_time"  datetime,
   "creator_id" integer(20),
   "creator" text(32),
@@ -77,6 +79,14 @@ CREATE TABLE IF NOT EXISTS "t_db_backup_history" (
   "binlog_file_name" text(32),
   "binlog_sequence" integer(20),
   "binlog_position" integer(20),
+  "file_size" integer(20),
+  "checksum" text(64),
+  "verify_status" integer(4),
+  "verify_result" text(500),
+  "verify_time"  datetime,
+  "file_name"  text(64),
+  "storage"  text(1000),
+  "progress"  text(1000),
   "create_time"  datetime,
   "is_deleted" integer(1) NOT NULL,
   "delete_time"  datetime,
@@ -148,11 +158,15 @@ CREATE TABLE IF NOT EXISTS "t_db_data_sync_task" (
   "target_table_name" text(100),
   "data_sql" text NOT NULL,
   "page_size" integer(11) NOT NULL,
+  "batch_commit" integer(1) NOT NULL DEFAULT -1,
   "upd_field" text(100) NOT NULL,
   "upd_field_val" text(100),
   "id_rule" integer(2) NOT NULL,
   "pk_field" text(100),
   "field_map" text,
+  "row_filter" text,
+  "duplicate_strategy" integer(1) NOT NULL DEFAULT -1,
+  "duplicate_key" text(100),
   "is_deleted" integer(8),
   "delete_time"  datetime,
   "status" integer(1) NOT NULL,
@@ -162,6 +176,78 @@ CREATE TABLE IF NOT EXISTS "t_db_data_sync_task" (
   PRIMARY KEY ("id")
 );
 
+-- Table: t_db_transfer_task
+CREATE TABLE IF NOT EXISTS "t_db_transfer_task" (
+  "id" integer NOT NULL,
+  "creator_id" integer(20) NOT NULL,
+  "creator" text(100) NOT NULL,
+  "create_time"  datetime NOT NULL,
+  "update_time"  datetime NOT NULL,
+  "modifier" text(100) NOT NULL,
+  "modifier_id" integer(20) NOT NULL,
+  "task_name" text(500) NOT NULL,
+  "mode" integer(1) NOT NULL,
+  "table_strategy" integer(1) NOT NULL,
+  "batch_size" integer(11) NOT NULL,
+  "src_db_id" integer(20) NOT NULL,
+  "src_db_name" text(100),
+  "src_tag_path" text(200),
+  "src_tables" text,
+  "target_db_id" integer(20) NOT NULL,
+  "target_db_name" text(100),
+  "target_tag_path" text(200),
+  "running_state" integer(1),
+  "recent_state" integer(1) NOT NULL DEFAULT 0,
+  "res" text(1000),
+  "checkpoint" text,
+  "is_deleted" integer(8),
+  "delete_time"  datetime,
+  PRIMARY KEY ("id")
+);
+
+-- Table: t_db_data_mask_rule
+CREATE TABLE IF NOT EXISTS "t_db_data_mask_rule" (
+  "id" integer NOT NULL,
+  "creator_id" integer(20) NOT NULL,
+  "creator" text(100) NOT NULL,
+  "create_time"  datetime NOT NULL,
+  "update_time"  datetime NOT NULL,
+  "modifier" text(100) NOT NULL,
+  "modifier_id" integer(20) NOT NULL,
+  "name" text(100) NOT NULL,
+  "tag_path" text(500),
+  "db_pattern" text(255),
+  "table_pattern" text(255),
+  "column_pattern" text(255) NOT NULL,
+  "mask_type" text(32) NOT NULL,
+  "status" integer(1) NOT NULL DEFAULT 1,
+  "remark" text(255),
+  "is_deleted" integer(8),
+  "delete_time"  datetime,
+  PRIMARY KEY ("id")
+);
+
+-- Table: t_db_sql_audit_rule
+CREATE TABLE IF NOT EXISTS "t_db_sql_audit_rule" (
+  "id" integer NOT NULL,
+  "creator_id" integer(20) NOT NULL,
+  "creator" text(100) NOT NULL,
+  "create_time"  datetime NOT NULL,
+  "update_time"  datetime NOT NULL,
+  "modifier" text(100) NOT NULL,
+  "modifier_id" integer(20) NOT NULL,
+  "name" text(100) NOT NULL,
+  "tag_path" text(500),
+  "rule_type" text(32) NOT NULL,
+  "level" integer(1) NOT NULL,
+  "threshold" integer(20) NOT NULL DEFAULT 0,
+  "status" integer(1) NOT NULL DEFAULT 1,
+  "remark" text(255),
+  "is_deleted" integer(8),
+  "delete_time"  datetime,
+  PRIMARY KEY ("id")
+);
+
 -- Table: t_db_instance
 CREATE TABLE IF NOT EXISTS "t_db_instance" (
   "id" integer NOT NULL,
@@ -203,6 +289,8 @@ CREATE TABLE IF NOT EXISTS "t_db_restore" (
   "db_backup_id" integer(20),
   "db_backup_history_id" integer(20),
   "db_backup_history_name" text(64),
+  "target_db_instance_id" integer(20),
+  "target_db_name" text(64),
   "create_time"  datetime,
   "creator_id" integer(20),
   "creator" text(32),
@@ -218,6 +306,7 @@ CREATE TABLE IF NOT EXISTS "t_db_restore" (
 CREATE TABLE IF NOT EXISTS "t_db_restore_history" (
   "id" integer NOT NULL,
   "db_restore_id" integer(20) NOT NULL,
+  "progress"  text(1000),
   "create_time"  datetime,
   "is_deleted" integer(4) NOT NULL,
   "delete_time"  datetime,
@@ -253,6 +342,14 @@ CREATE TABLE IF NOT EXISTS "t_db_sql_exec" (
   "sql" text(5000) NOT NULL,
   "old_value" text(5000),
   "remark" text(128),
+  "status" integer(4) NOT NULL DEFAULT 2, -- 执行状态 1:待审批 2:成功 3:已回滚 4:审批执行中 -1:失败 -2:审批拒绝 -3:审批过期
+  "res" text(1000),
+  "approver_id" integer(20),
+  "approver" text(36),
+  "approve_time" datetime,
+  "approve_remark" text(255),
+  "expire_time" datetime,
+  "rollback_id" integer(20),
   "create_time"  datetime NOT NULL,
   "creator" text(36) NOT NULL,
   "creator_id" integer(20) NOT NULL,
@@ -681,6 +778,9 @@ INSERT INTO t_sys_config (id, name, key, params, value, remark, permission, crea
 INSERT INTO t_sys_config (id, name, key, params, value, remark, permission, create_time, creator_id, creator, update_time, modifier_id, modifier, is_deleted, delete_time) VALUES (10, '数据库备份恢复', 'DbBackupRestore', '[{"model":"backupPath","name":"备份路径","placeholder":"备份文件存储路径"}]', '{"backupPath":"./db/backup"}', '', 'admin,', '2023-12-29 09:55:26', 1, 'admin', '2023-12-29 15:45:24', 1, 'admin', 0, NULL);
 INSERT INTO t_sys_config (id, name, key, params, value, remark, permission, create_time, creator_id, creator, update_time, modifier_id, modifier, is_deleted, delete_time) VALUES (11, 'Mysql可执行文件', 'MysqlBin', '[{"model":"path","name":"路径","placeholder":"可执行文件路径","required":true},{"model":"mysql","name":"mysql","placeholder":"mysql命令路径(空则为 路径/mysql)","required":false},{"model":"mysqldump","name":"mysqldump","placeholder":"mysqldump命令路径(空则为 路径/mysqldump)","required":false},{"model":"mysqlbinlog","name":"mysqlbinlog","placeholder":"mysqlbinlog命令路径(空则为 路径/mysqlbinlog)","required":false}]', '{"mysql":"","mysqldump":"","mysqlbinlog":"","path":"./db/mysql/bin"}', '', 'admin,', '2023-12-29 10:01:33', 1, 'admin', '2023-12-29 13:34:40', 1, 'admin', 0, NULL);
 INSERT INTO t_sys_config (id, name, key, params, value, remark, permission, create_time, creator_id, creator, update_time, modifier_id, modifier, is_deleted, delete_time) VALUES (12, 'MariaDB可执行文件', 'MariadbBin', '[{"model":"path","name":"路径","placeholder":"可执行文件路径","required":true},{"model":"mysql","name":"mysql","placeholder":"mysql命令路径(空则为 路径/mysql)","required":false},{"model":"mysqldump","name":"mysqldump","placeholder":"mysqldump命令路径(空则为 路径/mysqldump)","required":false},{"model":"mysqlbinlog","name":"mysqlbinlog","placeholder":"mysqlbinlog命令路径(空则为 路径/mysqlbinlog)","required":false}]', '{"mysql":"","mysqldump":"","mysqlbinlog":"","path":"./db/mariadb/bin"}', '', 'admin,', '2023-12-29 10:01:33', 1, 'admin', '2023-12-29 13:34:40', 1, 'admin', 0, NULL);
+INSERT INTO t_sys_config (id, name, key, params, value, remark, permission, create_time, creator_id, creator, update_time, modifier_id, modifier, is_deleted, delete_time) VALUES (13, '数据库sql执行审批', 'DbSqlApproval', '[{"model":"enable","name":"是否启用","placeholder":"是否启用sql执行审批","options":"true,false"},{"model":"tagPaths","name":"标签路径","placeholder":"需要审批的数据库标签路径, 多个使用逗号分隔, 如: default/prod/"},{"model":"approverRoleId","name":"审批角色id","placeholder":"拥有该角色的账号可审批"},{"model":"approverTeamId","name":"审批团队id","placeholder":"该团队成员可审批"},{"model":"expireMinutes","name":"过期时间","placeholder":"审批过期时间(分钟), 默认1440"}]', '{"enable":"false","tagPaths":"","approverRoleId":"","approverTeamId":"","expireMinutes":"1440"}', '非查询类sql需审批后才执行', 'admin,', '2024-01-20 10:00:00', 1, 'admin', '2024-01-20 10:00:00', 1, 'admin', 0, NULL);
+INSERT INTO t_sys_config (id, name, key, params, value, remark, permission, create_time, creator_id, creator, update_time, modifier_id, modifier, is_deleted, delete_time) VALUES (14, '数据库sql执行超时时间', 'DbExecTimeout', '[]', '58', 'sql控制台执行sql的超时时间(秒), 需小于前端请求超时时间(60秒)', 'admin,', '2024-01-20 10:00:00', 1, 'admin', '2024-01-20 10:00:00', 1, 'admin', 0, NULL);
+INSERT INTO t_sys_config (id, name, key, params, value, remark, permission, create_time, creator_id, creator, update_time, modifier_id, modifier, is_deleted, delete_time) VALUES (15, 'PostgreSQL可执行文件', 'PgsqlBin', '[{"model":"path","name":"路径","placeholder":"可执行文件路径","required":true},{"model":"pg_dump","name":"pg_dump","placeholder":"pg_dump命令路径(空则为 路径/pg_dump)","required":false},{"model":"pg_restore","name":"pg_restore","placeholder":"pg_restore命令路径(空则为 路径/pg_restore)","required":false}]', '{"pg_dump":"","pg_restore":"","path":"./db/postgres/bin"}', '', 'admin,', '2024-03-01 10:00:00', 1, 'admin', '2024-03-01 10:00:00', 1, 'admin', 0, NULL);
 
 -- Table: t_sys_log
 CREATE TABLE IF NOT EXISTS "t_sys_log" (
@@ -826,6 +926,15 @@ INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight,
 INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (153, 150, 'Jra0n7De/pLOA2UYz/', 2, 1, '删除', 'db:sync:del', 1703641342, 'null', 12, 'liuzongyang', 12, 'liuzongyang', '2023-12-27 09:42:22', '2023-12-27 09:42:22', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (154, 150, 'Jra0n7De/VBt68CDx/', 2, 1, '启停', 'db:sync:status', 1703641364, 'null', 12, 'liuzongyang', 12, 'liuzongyang', '2023-12-27 09:42:45', '2023-12-27 09:42:45', 0, NULL);
 INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (155, 150, 'Jra0n7De/PigmSGVg/', 2, 1, '日志', 'db:sync:log', 1704266866, 'null', 12, 'liuzongyang', 12, 'liuzongyang', '2024-01-03 15:27:47', '2024-01-03 15:27:47', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (156, 38, 'dbms23ax/exaeca2x/Qm4tXe7A/', 2, 1, '导出查询结果', 'db:export:query', 1705716000, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (157, 150, 'Jra0n7De/Yx2Wq8Ta/', 2, 1, '迁移-编辑', 'db:transfer:save', 1705716001, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (158, 150, 'Jra0n7De/Kc7Hn3Rb/', 2, 1, '迁移-删除', 'db:transfer:del', 1705716002, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (159, 150, 'Jra0n7De/Pm4Dv9Se/', 2, 1, '迁移-执行', 'db:transfer:run', 1705716003, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (160, 38, 'dbms23ax/exaeca2x/Vd8kRm2Q/', 2, 1, '查看敏感数据原始值', 'db:data:raw', 1705716004, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (161, 49, 'dbms23ax/xleaiec2/Hs5nWq3L/', 2, 1, '脱敏规则-编辑', 'db:datamask:save', 1705716005, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (162, 49, 'dbms23ax/xleaiec2/Bt7cZe4P/', 2, 1, '脱敏规则-删除', 'db:datamask:del', 1705716006, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (163, 49, 'dbms23ax/xleaiec2/Qa6rSd2K/', 2, 1, 'sql审核规则-编辑', 'db:sqlaudit:save', 1705716007, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, type, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES (164, 49, 'dbms23ax/xleaiec2/Lu9vAx5M/', 2, 1, 'sql审核规则-删除', 'db:sqlaudit:del', 1705716008, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
 
 -- Table: t_sys_role
 CREATE TABLE IF NOT EXISTS "t_sys_role" (
@@ -0,0 +1,127 @@
+ALTER TABLE `t_db_sql_exec`
+    ADD COLUMN `status` tinyint(4) NOT NULL DEFAULT 2 COMMENT '执行状态 1:待审批 2:成功 3:已回滚 4:审批执行中 -1:失败 -2:审批拒绝 -3:审批过期' AFTER `remark`,
+    ADD COLUMN `res` varchar(1000) DEFAULT NULL COMMENT '执行结果' AFTER `status`,
+    ADD COLUMN `approver_id` bigint(20) DEFAULT NULL COMMENT '审批人id' AFTER `res`,
+    ADD COLUMN `approver` varchar(36) DEFAULT NULL COMMENT '审批人' AFTER `approver_id`,
+    ADD COLUMN `approve_time` datetime DEFAULT NULL COMMENT '审批时间' AFTER `approver`,
+    ADD COLUMN `approve_remark` varchar(255) DEFAULT NULL COMMENT '审批备注' AFTER `approve_time`,
+    ADD COLUMN `expire_time` datetime DEFAULT NULL COMMENT '审批过期时间' AFTER `approve_remark`,
+    ADD COLUMN `rollback_id` bigint(20) DEFAULT NULL COMMENT '回滚的sql执行记录id' AFTER `expire_time`;
+
+INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('数据库sql执行审批', 'DbSqlApproval', '[{"model":"enable","name":"是否启用","placeholder":"是否启用sql执行审批","options":"true,false"},{"model":"tagPaths","name":"标签路径","placeholder":"需要审批的数据库标签路径, 多个使用逗号分隔, 如: default/prod/"},{"model":"approverRoleId","name":"审批角色id","placeholder":"拥有该角色的账号可审批"},{"model":"approverTeamId","name":"审批团队id","placeholder":"该团队成员可审批"},{"model":"expireMinutes","name":"过期时间","placeholder":"审批过期时间(分钟), 默认1440"}]', '{"enable":"false","tagPaths":"","approverRoleId":"","approverTeamId":"","expireMinutes":"1440"}', '非查询类sql需审批后才执行', 'admin,', '2024-01-20 10:00:00', 1, 'admin', '2024-01-20 10:00:00', 1, 'admin', 0, NULL);
+INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('数据库sql执行超时时间', 'DbExecTimeout', '[]', '58', 'sql控制台执行sql的超时时间(秒), 需小于前端请求超时时间(60秒)', 'admin,', '2024-01-20 10:00:00', 1, 'admin', '2024-01-20 10:00:00', 1, 'admin', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(156, 38, 'dbms23ax/exaeca2x/Qm4tXe7A/', 2, 1, '导出查询结果', 'db:export:query', 1705716000, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+
+CREATE TABLE `t_db_transfer_task`
+(
+    `id`              bigint(20) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+    `creator_id`      bigint(20) NOT NULL COMMENT '创建人id',
+    `creator`         varchar(100) NOT NULL COMMENT '创建人姓名',
+    `create_time`     datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `update_time`     datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '修改时间',
+    `modifier`        varchar(100) NOT NULL COMMENT '修改人姓名',
+    `modifier_id`     bigint(20) NOT NULL COMMENT '修改人id',
+    `task_name`       varchar(500) NOT NULL COMMENT '任务名',
+    `mode`            tinyint(1) NOT NULL DEFAULT '1' COMMENT '迁移模式 1结构+数据 2仅结构 3仅数据',
+    `table_strategy`  tinyint(1) NOT NULL DEFAULT '1' COMMENT '目标表已存在时的处理策略 1保留 2删除后重建',
+    `batch_size`      int(11) NOT NULL DEFAULT '1000' COMMENT '每批次插入的数据条数',
+    `src_db_id`       bigint(20) NOT NULL COMMENT '源数据库ID',
+    `src_db_name`     varchar(100)          DEFAULT NULL COMMENT '源数据库名',
+    `src_tag_path`    varchar(200)          DEFAULT NULL COMMENT '源数据库tag路径',
+    `src_tables`      text COMMENT '需迁移的表名，逗号分隔，为空则迁移所有表',
+    `target_db_id`    bigint(20) NOT NULL COMMENT '目标数据库ID',
+    `target_db_name`  varchar(100)          DEFAULT NULL COMMENT '目标数据库名',
+    `target_tag_path` varchar(200)          DEFAULT NULL COMMENT '目标数据库tag路径',
+    `running_state`   tinyint(1) DEFAULT '2' COMMENT '运行时状态 1运行中、2待运行、3已停止',
+    `recent_state`    tinyint(1) NOT NULL DEFAULT '0' COMMENT '最近一次状态 0未执行 1成功 -1失败',
+    `res`             varchar(1000)         DEFAULT NULL COMMENT '最近一次执行结果',
+    `checkpoint`      text COMMENT '各表迁移进度',
+    `is_deleted`      tinyint(8) DEFAULT '0',
+    `delete_time`     datetime              DEFAULT NULL,
+    PRIMARY KEY (`id`)
+) COMMENT='数据库迁移任务';
+
+CREATE TABLE `t_db_data_mask_rule`
+(
+    `id`             bigint(20) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+    `creator_id`     bigint(20) NOT NULL COMMENT '创建人id',
+    `creator`        varchar(100) NOT NULL COMMENT '创建人姓名',
+    `create_time`    datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `update_time`    datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '修改时间',
+    `modifier`       varchar(100) NOT NULL COMMENT '修改人姓名',
+    `modifier_id`    bigint(20) NOT NULL COMMENT '修改人id',
+    `name`           varchar(100) NOT NULL COMMENT '规则名',
+    `tag_path`       varchar(500)          DEFAULT NULL COMMENT '生效的标签路径，多个逗号分隔，为空则对所有数据库生效',
+    `db_pattern`     varchar(255)          DEFAULT NULL COMMENT '数据库名匹配规则，支持*通配符，多个逗号分隔',
+    `table_pattern`  varchar(255)          DEFAULT NULL COMMENT '表名匹配规则，支持*通配符，多个逗号分隔',
+    `column_pattern` varchar(255) NOT NULL COMMENT '字段名匹配规则，支持*通配符，多个逗号分隔',
+    `mask_type`      varchar(32)  NOT NULL COMMENT '脱敏类型 phone、idCard、email、bankCard、name、full',
+    `status`         tinyint(1)   NOT NULL DEFAULT '1' COMMENT '状态 1启用 -1禁用',
+    `remark`         varchar(255)          DEFAULT NULL COMMENT '备注',
+    `is_deleted`     tinyint(8) DEFAULT '0',
+    `delete_time`    datetime              DEFAULT NULL,
+    PRIMARY KEY (`id`)
+) COMMENT='数据脱敏规则';
+
+CREATE TABLE `t_db_sql_audit_rule`
+(
+    `id`          bigint(20) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+    `creator_id`  bigint(20) NOT NULL COMMENT '创建人id',
+    `creator`     varchar(100) NOT NULL COMMENT '创建人姓名',
+    `create_time` datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `update_time` datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '修改时间',
+    `modifier`    varchar(100) NOT NULL COMMENT '修改人姓名',
+    `modifier_id` bigint(20) NOT NULL COMMENT '修改人id',
+    `name`        varchar(100) NOT NULL COMMENT '规则名',
+    `tag_path`    varchar(500)          DEFAULT NULL COMMENT '生效的标签路径，多个逗号分隔，为空则对所有数据库生效',
+    `rule_type`   varchar(32)  NOT NULL COMMENT '规则类型 noWhere、selectStar、dropTruncate、noLimit、fullScan、ddlLargeTable',
+    `level`       tinyint(1)   NOT NULL COMMENT '风险等级 1警告 2需确认 3禁止执行',
+    `threshold`   bigint(20)   NOT NULL DEFAULT '0' COMMENT '表行数阈值，为0则不限制表行数',
+    `status`      tinyint(1)   NOT NULL DEFAULT '1' COMMENT '状态 1启用 -1禁用',
+    `remark`      varchar(255)          DEFAULT NULL COMMENT '备注',
+    `is_deleted`  tinyint(8) DEFAULT '0',
+    `delete_time` datetime              DEFAULT NULL,
+    PRIMARY KEY (`id`)
+) COMMENT='sql审核规则';
+
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(157, 150, 'Jra0n7De/Yx2Wq8Ta/', 2, 1, '迁移-编辑', 'db:transfer:save', 1705716001, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(158, 150, 'Jra0n7De/Kc7Hn3Rb/', 2, 1, '迁移-删除', 'db:transfer:del', 1705716002, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(159, 150, 'Jra0n7De/Pm4Dv9Se/', 2, 1, '迁移-执行', 'db:transfer:run', 1705716003, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+
+ALTER TABLE `t_db_data_sync_task`
+    ADD COLUMN `duplicate_strategy` tinyint(1) NOT NULL DEFAULT '-1' COMMENT '数据重复处理策略 -1报错 1忽略 2更新' AFTER `field_map`,
+    ADD COLUMN `duplicate_key` varchar(100) DEFAULT NULL COMMENT '判断数据重复的目标表字段，多个逗号分隔，为空则使用主键' AFTER `duplicate_strategy`;
+
+ALTER TABLE `t_db_data_sync_task`
+    ADD COLUMN `row_filter` text COMMENT '数据行过滤条件json' AFTER `field_map`;
+
+ALTER TABLE `t_db_data_sync_task`
+    ADD COLUMN `batch_commit` tinyint(1) NOT NULL DEFAULT '-1' COMMENT '是否每批次提交事务 1是 -1否' AFTER `page_size`;
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(160, 38, 'dbms23ax/exaeca2x/Vd8kRm2Q/', 2, 1, '查看敏感数据原始值', 'db:data:raw', 1705716004, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(161, 49, 'dbms23ax/xleaiec2/Hs5nWq3L/', 2, 1, '脱敏规则-编辑', 'db:datamask:save', 1705716005, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(162, 49, 'dbms23ax/xleaiec2/Bt7cZe4P/', 2, 1, '脱敏规则-删除', 'db:datamask:del', 1705716006, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+
+INSERT INTO `t_sys_config` (`name`, `key`, `params`, `value`, `remark`, `permission`, `create_time`, `creator_id`, `creator`, `update_time`, `modifier_id`, `modifier`, `is_deleted`, `delete_time`) VALUES('PostgreSQL可执行文件', 'PgsqlBin', '[{"model":"path","name":"路径","placeholder":"可执行文件路径","required":true},{"model":"pg_dump","name":"pg_dump","placeholder":"pg_dump命令路径(空则为 路径/pg_dump)","required":false},{"model":"pg_restore","name":"pg_restore","placeholder":"pg_restore命令路径(空则为 路径/pg_restore)","required":false}]', '{"pg_dump":"","pg_restore":"","path":"./db/postgres/bin"}', '', 'admin,', '2024-03-01 10:00:00', 1, 'admin', '2024-03-01 10:00:00', 1, 'admin', 0, NULL);
+
+ALTER TABLE `t_db_backup`
+    ADD COLUMN `retention` varchar(500) DEFAULT NULL COMMENT '备份保留策略' AFTER `last_time`,
+    ADD COLUMN `storage` varchar(1000) DEFAULT NULL COMMENT '备份存储' AFTER `retention`;
+
+ALTER TABLE `t_db_backup_history`
+    ADD COLUMN `file_size` bigint(20) DEFAULT NULL COMMENT '备份文件大小' AFTER `binlog_position`,
+    ADD COLUMN `checksum` varchar(64) DEFAULT NULL COMMENT '备份文件sha256校验和' AFTER `file_size`,
+    ADD COLUMN `verify_status` tinyint(4) DEFAULT NULL COMMENT '校验状态 1:校验中 2:成功 -1:失败' AFTER `checksum`,
+    ADD COLUMN `verify_result` varchar(500) DEFAULT NULL COMMENT '校验结果' AFTER `verify_status`,
+    ADD COLUMN `verify_time` datetime DEFAULT NULL COMMENT '校验时间' AFTER `verify_result`,
+    ADD COLUMN `file_name` varchar(64) DEFAULT NULL COMMENT '备份文件名' AFTER `verify_time`,
+    ADD COLUMN `storage` varchar(1000) DEFAULT NULL COMMENT '备份存储' AFTER `file_name`,
+    ADD COLUMN `progress` varchar(1000) DEFAULT NULL COMMENT '备份进度' AFTER `storage`;
+
+ALTER TABLE `t_db_restore_history`
+    ADD COLUMN `progress` varchar(1000) DEFAULT NULL COMMENT '恢复进度' AFTER `db_restore_id`;
+
+ALTER TABLE `t_db_restore`
+    ADD COLUMN `target_db_instance_id` bigint(20) unsigned DEFAULT NULL COMMENT '恢复目标数据库实例ID' AFTER `db_backup_history_name`,
+    ADD COLUMN `target_db_name` varchar(64) DEFAULT NULL COMMENT '恢复目标数据库名称' AFTER `target_db_instance_id`;
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(163, 49, 'dbms23ax/xleaiec2/Qa6rSd2K/', 2, 1, 'sql审核规则-编辑', 'db:sqlaudit:save', 1705716007, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);
+INSERT INTO t_sys_resource (id, pid, ui_path, `type`, status, name, code, weight, meta, creator_id, creator, modifier_id, modifier, create_time, update_time, is_deleted, delete_time) VALUES(164, 49, 'dbms23ax/xleaiec2/Lu9vAx5M/', 2, 1, 'sql审核规则-删除', 'db:sqlaudit:del', 1705716008, 'null', 1, 'admin', 1, 'admin', '2024-01-20 10:00:00', '2024-01-20 10:00:00', 0, NULL);